@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// AdminHandler returns an http.Handler for operating the logger on a live
+// service. Every request must carry "Authorization: Bearer <token>" matching
+// the token from tokens, which is re-read on each request so it can be
+// rotated; requests are refused if the token is empty. Mount it under a
+// prefix with http.StripPrefix. Routes:
+//
+//	GET  /level    current level
+//	PUT  /level    set the level from {"level": "debug"}
+//	GET  /filters  current filter rules
+//	PUT  /filters  replace the filter rules from {"rules": [{"logger":
+//	               "db", "level": "warn"}]}; an empty list removes them
+//	GET  /health   Health as JSON
+//	GET  /stats    Stats as JSON
+//	GET  /metrics  Stats in the Prometheus text format
+//	GET  /recent   the latest entries from Recent, up to ?n= (default all)
+//	POST /selftest run SelfTest, returning the report with status 503 if a
+//	               sink failed
+func (l *Logger) AdminHandler(tokens CredentialProvider) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /level", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]string{"level": levelName(l.Level())})
+	})
+	mux.HandleFunc("PUT /level", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&body); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			return
+		}
+		previous := levelName(l.Level())
+		if err := l.SetLevel(body.Level); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		diagnosticsLogger(l.Logger).Warn("log level changed through admin API",
+			zap.String("from", previous),
+			zap.String("to", levelName(l.Level())),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		writeAdminJSON(w, http.StatusOK, map[string]string{"level": levelName(l.Level())})
+	})
+	mux.HandleFunc("GET /filters", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, currentAdminFilters(l))
+	})
+	mux.HandleFunc("PUT /filters", func(w http.ResponseWriter, r *http.Request) {
+		var body adminFilters
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&body); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			return
+		}
+		previous := len(l.FilterRules())
+		if err := l.SetFilterRules(body.Rules); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		diagnosticsLogger(l.Logger).Warn("filter rules changed through admin API",
+			zap.Int("from_rules", previous),
+			zap.Int("to_rules", len(body.Rules)),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		writeAdminJSON(w, http.StatusOK, currentAdminFilters(l))
+	})
+	mux.Handle("GET /health", l.HealthHandler())
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, l.Stats())
+	})
+	mux.Handle("GET /metrics", l.MetricsHandler())
+	mux.HandleFunc("GET /recent", func(w http.ResponseWriter, r *http.Request) {
+		n := 0
+		if s := r.URL.Query().Get("n"); s != "" {
+			var err error
+			if n, err = strconv.Atoi(s); err != nil || n < 0 {
+				writeAdminError(w, http.StatusBadRequest, "invalid n")
+				return
+			}
+		}
+		recent := l.Recent(n)
+		entries := make([]viewerEntry, 0, len(recent))
+		for _, e := range recent {
+			entries = append(entries, newViewerEntry(e))
+		}
+		writeAdminJSON(w, http.StatusOK, entries)
+	})
+	mux.HandleFunc("POST /selftest", func(w http.ResponseWriter, r *http.Request) {
+		report := l.SelfTest(r.Context())
+		status := http.StatusOK
+		if report.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		writeAdminJSON(w, status, report)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !adminAuthorized(r, tokens) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="logger"`)
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// adminFilters is the body of the /filters routes
+type adminFilters struct {
+	Rules []FilterRule `json:"rules"`
+}
+
+// currentAdminFilters returns l's filter rules, as an empty list if there
+// are none
+func currentAdminFilters(l *Logger) adminFilters {
+	rules := l.FilterRules()
+	if rules == nil {
+		rules = []FilterRule{}
+	}
+	return adminFilters{Rules: rules}
+}
+
+// adminAuthorized checks the request's bearer token in constant time
+func adminAuthorized(r *http.Request, tokens CredentialProvider) bool {
+	creds, err := tokens.Credentials()
+	if err != nil || creds.Token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(creds.Token)) == 1
+}
+
+// writeAdminJSON writes v as the JSON response body
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeAdminError writes an error response
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	writeAdminJSON(w, status, map[string]string{"error": msg})
+}
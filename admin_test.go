@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// adminRequest sends an authenticated request to h, returning the status
+// and body
+func adminRequest(h http.Handler, method, path, body string) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestAdminHandler(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	obs, logs := observer.New(zapcore.WarnLevel)
+	l.AddSink(obs)
+	h := l.AdminHandler(StaticCredentials(Credentials{Token: "secret"}))
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		want               string
+	}{
+		{http.MethodGet, "/level", "", http.StatusOK, `{"level":"info"}`},
+		{http.MethodPut, "/level", `{"level":"debug"}`, http.StatusOK, `{"level":"debug"}`},
+		{http.MethodGet, "/level", "", http.StatusOK, `{"level":"debug"}`},
+		{http.MethodPut, "/level", `{"level":"loud"}`, http.StatusBadRequest, `"error"`},
+		{http.MethodPut, "/level", `not json`, http.StatusBadRequest, `invalid body`},
+		{http.MethodPut, "/filters", `{"rules":[{"logger":"db","level":"warn"}]}`, http.StatusOK, `"db"`},
+		{http.MethodGet, "/stats", "", http.StatusOK, `"BufferPoolGets"`},
+		{http.MethodGet, "/health", "", http.StatusOK, `"status"`},
+		{http.MethodGet, "/metrics", "", http.StatusOK, "# TYPE"},
+		{http.MethodPost, "/selftest", "", http.StatusOK, `"status":"ok"`},
+		{http.MethodDelete, "/level", "", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/missing", "", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		status, body := adminRequest(h, tt.method, tt.path, tt.body)
+		if status != tt.status || !strings.Contains(body, tt.want) {
+			t.Errorf("%s %s: %d %s, want %d with %s", tt.method, tt.path, status, body, tt.status, tt.want)
+		}
+	}
+
+	// Changes are audited through the diagnostics logger
+	var audited []string
+	for _, e := range logs.All() {
+		if e.LoggerName == diagnosticsLoggerName {
+			audited = append(audited, e.Message)
+		}
+	}
+	want := []string{"log level changed through admin API", "filter rules changed through admin API"}
+	if strings.Join(audited, "|") != strings.Join(want, "|") {
+		t.Errorf("audited %q, want %q", audited, want)
+	}
+}
+
+func TestAdminHandlerAuth(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	var token atomic.Value
+	h := l.AdminHandler(CredentialProviderFunc(func() (Credentials, error) {
+		return Credentials{Token: token.Load().(string)}, nil
+	}))
+
+	tests := []struct {
+		name  string
+		token string
+		auth  string
+		want  int
+	}{
+		{"valid", "secret", "Bearer secret", http.StatusOK},
+		{"no header", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer guess", http.StatusUnauthorized},
+		{"basic scheme", "secret", "Basic c2VjcmV0", http.StatusUnauthorized},
+		{"rotated", "rotated", "Bearer secret", http.StatusUnauthorized},
+		{"rotated new token", "rotated", "Bearer rotated", http.StatusOK},
+		{"empty token", "", "Bearer ", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		token.Store(tt.token)
+		req := httptest.NewRequest(http.MethodGet, "/level", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 without a WWW-Authenticate challenge", tt.name)
+		}
+	}
+}
+
+func TestAdminSelfTestFailure(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	l.AddSink(selfTestingCore{Core: zapcore.NewNopCore(), err: io.ErrClosedPipe})
+	h := l.AdminHandler(StaticCredentials(Credentials{Token: "secret"}))
+
+	status, body := adminRequest(h, http.MethodPost, "/selftest", "")
+	var report SelfTestReport
+	if err := json.Unmarshal([]byte(body), &report); err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusServiceUnavailable || report.Status != "failed" {
+		t.Errorf("self-test: %d %+v, want 503 and the failed report", status, report)
+	}
+}
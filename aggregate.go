@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxAggregateGroups bounds the number of groups held open at once; entries
+// that would start a new group beyond it are written immediately
+const maxAggregateGroups = 10000
+
+// aggregator collapses identical entries seen within a window
+type aggregator struct {
+	window time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*aggregateGroup
+	closed bool
+}
+
+// aggregateGroup is the first entry of a group and how often it repeated
+type aggregateGroup struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	count  uint64
+	last   time.Time
+}
+
+// newAggregator creates an aggregator with the given window
+func newAggregator(window time.Duration) *aggregator {
+	return &aggregator{window: window, groups: make(map[string]*aggregateGroup)}
+}
+
+// add counts ent in the group for key, opening the group and starting its
+// window on first occurrence. It reports false if ent must be written
+// directly instead.
+func (a *aggregator) add(key string, core zapcore.Core, ent zapcore.Entry, fields []zapcore.Field) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return false
+	}
+	if g, ok := a.groups[key]; ok {
+		g.count++
+		g.last = ent.Time
+		return true
+	}
+	if len(a.groups) >= maxAggregateGroups {
+		return false
+	}
+
+	g := &aggregateGroup{
+		core:   core,
+		ent:    ent,
+		fields: append([]zapcore.Field(nil), fields...),
+		count:  1,
+		last:   ent.Time,
+	}
+	a.groups[key] = g
+	time.AfterFunc(a.window, func() { a.expire(key, g) })
+	return true
+}
+
+// expire writes g when its window ends, unless a flush already did
+func (a *aggregator) expire(key string, g *aggregateGroup) {
+	a.mu.Lock()
+	if a.groups[key] != g {
+		a.mu.Unlock()
+		return
+	}
+	delete(a.groups, key)
+	a.mu.Unlock()
+	g.write()
+}
+
+// flush writes every open group
+func (a *aggregator) flush() {
+	a.mu.Lock()
+	groups := a.groups
+	a.groups = make(map[string]*aggregateGroup)
+	a.mu.Unlock()
+
+	for _, g := range groups {
+		g.write()
+	}
+}
+
+// close flushes open groups; later entries are written directly
+func (a *aggregator) close() {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+	a.flush()
+}
+
+// write writes the group's first entry, with the repeat count and the
+// first and last timestamps if it repeated
+func (g *aggregateGroup) write() {
+	fields := g.fields
+	if g.count > 1 {
+		fields = append(fields,
+			zap.Uint64("aggregate_count", g.count),
+			zap.Time("first_seen", g.ent.Time),
+			zap.Time("last_seen", g.last),
+		)
+	}
+	_ = g.core.Write(g.ent, fields)
+}
+
+// aggregateCore holds entries back for the aggregation window so repeats
+// of the same level, message, and field keys are written once with a count.
+// DPanic and higher entries are written immediately.
+type aggregateCore struct {
+	zapcore.Core
+	agg *aggregator
+	// contextKeys are the keys of fields added with With
+	contextKeys []string
+}
+
+// With returns a child core sharing the same aggregator
+func (c *aggregateCore) With(fields []zapcore.Field) zapcore.Core {
+	keys := make([]string, 0, len(c.contextKeys)+len(fields))
+	keys = append(keys, c.contextKeys...)
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	return &aggregateCore{Core: c.Core.With(fields), agg: c.agg, contextKeys: keys}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *aggregateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write adds the entry to its group
+func (c *aggregateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level > zapcore.ErrorLevel || !c.agg.add(c.key(ent, fields), c.Core, ent, fields) {
+		return c.Core.Write(ent, fields)
+	}
+	return nil
+}
+
+// key identifies entries that aggregate together
+func (c *aggregateCore) key(ent zapcore.Entry, fields []zapcore.Field) string {
+	keys := make([]string, 0, len(c.contextKeys)+len(fields))
+	keys = append(keys, c.contextKeys...)
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString(ent.Level.String())
+	b.WriteByte('|')
+	b.WriteString(ent.LoggerName)
+	b.WriteByte('|')
+	b.WriteString(ent.Message)
+	for _, k := range keys {
+		b.WriteByte('|')
+		b.WriteString(k)
+	}
+	return b.String()
+}
+
+// Sync writes open groups and then syncs the wrapped core
+func (c *aggregateCore) Sync() error {
+	c.agg.flush()
+	return c.Core.Sync()
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAggregate(t *testing.T) {
+	type write struct {
+		level  zapcore.Level
+		msg    string
+		fields []zap.Field
+	}
+	tests := []struct {
+		name   string
+		writes []write
+		// want is the sorted aggregate_count of each written entry, 0 when
+		// written without one
+		want []uint64
+	}{
+		{"repeats", []write{
+			{zapcore.InfoLevel, "retry", []zap.Field{zap.Int("attempt", 1)}},
+			{zapcore.InfoLevel, "retry", []zap.Field{zap.Int("attempt", 2)}},
+			{zapcore.InfoLevel, "retry", []zap.Field{zap.Int("attempt", 3)}},
+		}, []uint64{3}},
+		{"different keys", []write{
+			{zapcore.InfoLevel, "retry", []zap.Field{zap.Int("attempt", 1)}},
+			{zapcore.InfoLevel, "retry", []zap.Field{zap.String("host", "a")}},
+		}, []uint64{0, 0}},
+		{"different levels", []write{
+			{zapcore.InfoLevel, "retry", nil},
+			{zapcore.WarnLevel, "retry", nil},
+			{zapcore.WarnLevel, "retry", nil},
+		}, []uint64{0, 2}},
+		{"dpanic written immediately", []write{
+			{zapcore.DPanicLevel, "broken", nil},
+			{zapcore.DPanicLevel, "broken", nil},
+		}, []uint64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.DebugLevel)
+			core := &aggregateCore{Core: obs, agg: newAggregator(time.Hour)}
+			for _, w := range tt.writes {
+				ent := zapcore.Entry{Level: w.level, Message: w.msg, Time: time.Now()}
+				if err := core.Write(ent, w.fields); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := core.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []uint64
+			for _, e := range logs.All() {
+				n, _ := e.ContextMap()["aggregate_count"].(uint64)
+				got = append(got, n)
+			}
+			// Groups flush in no particular order
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("aggregate counts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateWindow(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	agg := newAggregator(20 * time.Millisecond)
+	core := &aggregateCore{Core: obs, agg: agg}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "retry", Time: time.Now()}
+	core.Write(ent, nil)
+	core.Write(ent, nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("wrote %d entries after the window, want 1", logs.Len())
+	}
+	if n := logs.All()[0].ContextMap()["aggregate_count"]; n != uint64(2) {
+		t.Errorf("aggregate_count = %v, want 2", n)
+	}
+
+	agg.close()
+	core.Write(ent, nil)
+	if logs.Len() != 2 {
+		t.Errorf("entry after close was held back")
+	}
+}
@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultAsyncQueueSize is the capacity of each async lane
+const defaultAsyncQueueSize = 1024
+
+// asyncEntry is an entry waiting to be written by the async worker. A flush
+// entry carries no core and signals done once everything before it is written.
+type asyncEntry struct {
+	core   zapcore.Core
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	done   chan struct{}
+	// enqueued is when the entry entered the queue
+	enqueued time.Time
+}
+
+// asyncQueue moves entries off the caller's goroutine. Error and higher
+// entries travel in a separate lane that the worker always drains first, so
+// alerts aren't stuck behind a backlog of debug and info entries.
+type asyncQueue struct {
+	high       chan asyncEntry
+	low        chan asyncEntry
+	dropOnFull bool
+	dropped    atomic.Uint64
+	wg         sync.WaitGroup
+
+	// Age watermarks, in nanoseconds: lastAge is how long the most recently
+	// written entry waited, maxAge the longest wait seen, and writing the
+	// enqueue time of the entry being written, or 0 when idle
+	lastAge atomic.Int64
+	maxAge  atomic.Int64
+	writing atomic.Int64
+
+	// mu guards closed; senders hold it for reading so the lanes are
+	// never written after close
+	mu     sync.RWMutex
+	closed bool
+}
+
+// newAsyncQueue starts the worker goroutine
+func newAsyncQueue(size int, dropOnFull bool) *asyncQueue {
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+	q := &asyncQueue{
+		high:       make(chan asyncEntry, size),
+		low:        make(chan asyncEntry, size),
+		dropOnFull: dropOnFull,
+	}
+	q.wg.Add(1)
+	go q.run()
+	return q
+}
+
+// run writes queued entries until the low lane is closed
+func (q *asyncQueue) run() {
+	defer q.wg.Done()
+	for {
+		select {
+		case e := <-q.high:
+			q.write(e)
+			continue
+		default:
+		}
+
+		select {
+		case e := <-q.high:
+			q.write(e)
+		case e, ok := <-q.low:
+			if !ok {
+				q.drainHigh()
+				return
+			}
+			q.write(e)
+		}
+	}
+}
+
+// drainHigh writes whatever is left in the priority lane
+func (q *asyncQueue) drainHigh() {
+	for {
+		select {
+		case e := <-q.high:
+			q.write(e)
+		default:
+			return
+		}
+	}
+}
+
+// write hands a queued entry to its core, re-checking so per-core levels
+// still apply
+func (q *asyncQueue) write(e asyncEntry) {
+	if e.done != nil {
+		q.drainHigh()
+		close(e.done)
+		return
+	}
+	q.writing.Store(e.enqueued.UnixNano())
+	if ce := e.core.Check(e.ent, nil); ce != nil {
+		ce.Write(e.fields...)
+	}
+	q.writing.Store(0)
+
+	age := int64(time.Since(e.enqueued))
+	q.lastAge.Store(age)
+	for {
+		prev := q.maxAge.Load()
+		if age <= prev || q.maxAge.CompareAndSwap(prev, age) {
+			break
+		}
+	}
+}
+
+// lag returns how far the worker is behind: the wait of the most recently
+// written entry, or the age of the entry being written if that is longer,
+// as when a sink blocks
+func (q *asyncQueue) lag() time.Duration {
+	lag := time.Duration(q.lastAge.Load())
+	if writing := q.writing.Load(); writing != 0 {
+		if age := time.Since(time.Unix(0, writing)); age > lag {
+			lag = age
+		}
+	}
+	return lag
+}
+
+// queued returns the number of entries waiting in both lanes
+func (q *asyncQueue) queued() int {
+	return len(q.high) + len(q.low)
+}
+
+// enqueue queues an entry, blocking when its lane is full unless the queue
+// drops low-priority entries under backpressure. Once the queue is closed,
+// entries are written synchronously.
+func (q *asyncQueue) enqueue(e asyncEntry) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	e.enqueued = time.Now()
+	if q.closed {
+		q.write(e)
+		return
+	}
+
+	if e.ent.Level >= zapcore.ErrorLevel {
+		q.high <- e
+		return
+	}
+	if q.dropOnFull {
+		select {
+		case q.low <- e:
+		default:
+			q.dropped.Add(1)
+		}
+		return
+	}
+	q.low <- e
+}
+
+// flush blocks until every entry queued before the call has been written
+func (q *asyncQueue) flush() {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return
+	}
+	done := make(chan struct{})
+	q.low <- asyncEntry{done: done}
+	<-done
+}
+
+// close flushes the queue and stops the worker
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.low)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// asyncCore defers writes to an asyncQueue
+type asyncCore struct {
+	zapcore.Core
+	queue *asyncQueue
+}
+
+// With returns a child core sharing the same queue
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncCore{Core: c.Core.With(fields), queue: c.queue}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write queues the entry. DPanic, Panic, and Fatal entries are written
+// synchronously after flushing the queue, since the process may not survive
+// long enough for the worker to reach them.
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level > zapcore.ErrorLevel {
+		c.queue.flush()
+		return c.Core.Write(ent, fields)
+	}
+	c.queue.enqueue(asyncEntry{core: c.Core, ent: ent, fields: fields})
+	return nil
+}
+
+// Sync flushes the queue and then the wrapped core
+func (c *asyncCore) Sync() error {
+	c.queue.flush()
+	return c.Core.Sync()
+}
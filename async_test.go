@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// gatedCore blocks its first write until release is closed
+type gatedCore struct {
+	zapcore.Core
+	once    *sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c gatedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c gatedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.once.Do(func() {
+		close(c.entered)
+		<-c.release
+	})
+	return c.Core.Write(ent, fields)
+}
+
+// TestAsyncQueue stalls the worker on a first entry, queues more behind it,
+// and checks the order they are written in once it resumes
+func TestAsyncQueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		dropOnFull  bool
+		want        []string
+		wantDropped uint64
+	}{
+		{name: "errors first", size: 8, want: []string{"stalled", "alert", "a", "b", "c"}},
+		{name: "drop on full", size: 2, dropOnFull: true, want: []string{"stalled", "alert", "a", "b"}, wantDropped: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.DebugLevel)
+			gate := gatedCore{Core: obs, once: new(sync.Once), entered: make(chan struct{}), release: make(chan struct{})}
+			q := newAsyncQueue(tt.size, tt.dropOnFull)
+			core := &asyncCore{Core: gate, queue: q}
+			log := func(level zapcore.Level, msg string) {
+				if ce := core.Check(zapcore.Entry{Level: level, Message: msg}, nil); ce != nil {
+					ce.Write()
+				}
+			}
+
+			log(zapcore.InfoLevel, "stalled")
+			<-gate.entered
+			for _, msg := range []string{"a", "b", "c"} {
+				log(zapcore.InfoLevel, msg)
+			}
+			log(zapcore.ErrorLevel, "alert")
+			if got := q.queued(); got != len(tt.want)-1 {
+				t.Errorf("queued = %d while stalled, want %d", got, len(tt.want)-1)
+			}
+			close(gate.release)
+			if err := core.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, e := range logs.All() {
+				got = append(got, e.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("written %q, want %q", got, tt.want)
+			}
+			if dropped := q.dropped.Load(); dropped != tt.wantDropped {
+				t.Errorf("dropped = %d, want %d", dropped, tt.wantDropped)
+			}
+
+			// After close, entries are written synchronously
+			q.close()
+			log(zapcore.InfoLevel, "after close")
+			if n := logs.FilterMessage("after close").Len(); n != 1 {
+				t.Errorf("entry logged after close written %d times, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCallerDepth bounds the frames captureCaller walks to get out of zap
+// and this package
+const maxCallerDepth = 16
+
+// callerPackages are the function name prefixes of the frames a lazy
+// caller skips: zap, this package, and the runtime, which starts the
+// goroutines this package logs from
+var callerPackages = []string{
+	"go.uber.org/zap.",
+	"go.uber.org/zap/",
+	reflect.TypeFor[Logger]().PkgPath() + ".",
+	"runtime.",
+}
+
+// loggingPCs memoizes whether a program counter lies in a function of
+// callerPackages, so that capturing a caller looks no symbols up once a
+// call site has been seen
+var loggingPCs sync.Map
+
+// captureCaller returns the caller of the logging call for
+// Config.AsyncCaller: the first frame outside zap and this package, with
+// only its program counter set. resolveCaller fills in the rest. skip is
+// the number of frames above captureCaller's caller to ignore.
+func captureCaller(skip int) zapcore.EntryCaller {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	for _, pc := range pcs[:n] {
+		if !isLoggingPC(pc) {
+			return zapcore.EntryCaller{Defined: true, PC: pc}
+		}
+	}
+	return zapcore.EntryCaller{}
+}
+
+// isLoggingPC reports whether the return address pc lies in a function of
+// callerPackages. runtime.Callers gives inlined functions their own
+// entries, so logging functions inlined into the caller's are skipped too.
+func isLoggingPC(pc uintptr) bool {
+	if v, ok := loggingPCs.Load(pc); ok {
+		return v.(bool)
+	}
+	fn := runtime.FuncForPC(pc - 1)
+	logging := fn != nil && isLoggingFunc(fn.Name())
+	loggingPCs.Store(pc, logging)
+	return logging
+}
+
+// isLoggingFunc reports whether the function named name is in one of
+// callerPackages
+func isLoggingFunc(name string) bool {
+	for _, prefix := range callerPackages {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCaller fills in the file, line, and function of a caller captured
+// by captureCaller
+func resolveCaller(caller zapcore.EntryCaller) zapcore.EntryCaller {
+	if !caller.Defined || caller.File != "" {
+		return caller
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{caller.PC}).Next()
+	return zapcore.EntryCaller{
+		Defined:  frame.PC != 0,
+		PC:       frame.PC,
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: frame.Function,
+	}
+}
+
+// callerCore resolves the callers captured for Config.AsyncCaller just
+// before the sinks, so that with Config.Async the lookup happens on the
+// worker, and only for entries that survive sampling
+type callerCore struct {
+	zapcore.Core
+}
+
+// With returns a child core
+func (c *callerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &callerCore{Core: c.Core.With(fields)}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *callerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write resolves the entry's caller and writes it
+func (c *callerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Caller = resolveCaller(ent.Caller)
+	return c.Core.Write(ent, fields)
+}
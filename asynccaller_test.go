@@ -0,0 +1,86 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	logger "go-logger"
+	"go.uber.org/zap"
+)
+
+// TestAsyncCaller logs from outside package logger, as the caller lookup
+// of Config.AsyncCaller skips every frame inside it, test functions included
+func TestAsyncCaller(t *testing.T) {
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = null
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		null.Close()
+	})
+
+	for _, async := range []bool{false, true} {
+		t.Run("async "+strconv.FormatBool(async), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l, err := logger.NewLogger(logger.Config{
+				Level: "trace", Format: logger.FormatJSON, EnableFile: true, FilePath: path,
+				Async: async, AsyncCaller: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Info("info", here())
+			l.Trace("trace", here())
+			l.Success("success", here())
+			l.WithField("k", "v").Warn("derived", here())
+			l.Sugar().Infow("sugar", here())
+			l.FieldLogger().Error("adapter", here())
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := 0
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			for dec.More() {
+				var entry struct {
+					Logger string `json:"logger"`
+					Msg    string `json:"msg"`
+					Caller string `json:"caller"`
+					Want   string `json:"want"`
+				}
+				if err := dec.Decode(&entry); err != nil {
+					t.Fatal(err)
+				}
+				if entry.Logger != "" {
+					continue
+				}
+				n++
+				if entry.Caller != entry.Want {
+					t.Errorf("%s entry caller %q, want %q", entry.Msg, entry.Caller, entry.Want)
+				}
+			}
+			if n != 6 {
+				t.Errorf("read %d entries, want 6", n)
+			}
+		})
+	}
+}
+
+// here returns a field holding the caller's location as the encoders write it
+func here() zap.Field {
+	_, file, line, _ := runtime.Caller(1)
+	return zap.String("want", filepath.Base(filepath.Dir(file))+"/"+filepath.Base(file)+":"+strconv.Itoa(line))
+}
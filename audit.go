@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// auditLoggerName is the logger name carried by every audit record
+const auditLoggerName = "audit"
+
+// auditMarkerKey marks audit records, which VerifyAuditLog checks; other
+// lines, including entries of a logger named "audit", are skipped
+const auditMarkerKey = "audit_record"
+
+// AuditEvent describes a single auditable action
+type AuditEvent struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	Fields   []zap.Field
+}
+
+// AuditCheckpoint identifies the last record of an audit chain
+type AuditCheckpoint struct {
+	Seq  uint64
+	Hash string
+}
+
+// AuditLogger emits append-only audit records to a trail of its own, apart
+// from the application's sinks. Each record carries a monotonically
+// increasing sequence number and a hash chained to the previous record, so
+// edits, deletions, and reordering can be detected with VerifyAuditLog.
+type AuditLogger struct {
+	clock zapcore.Clock
+	enc   zapcore.Encoder
+
+	mu       sync.Mutex
+	out      io.Writer
+	seq      uint64
+	prevHash string
+}
+
+// NewAuditLogger creates an audit logger writing records to out as JSON
+// lines, the only format VerifyAuditLog reads, whatever l's formats. l
+// supplies the clock. Records are written synchronously with only the
+// fields their hash covers, and out is synced after each one if it has a
+// Sync method. opts are EncoderOptions such as WithRenamedKey; pass the
+// same to VerifyAuditLog.
+func NewAuditLogger(l *Logger, out io.Writer, opts ...EncoderOption) *AuditLogger {
+	return &AuditLogger{
+		clock: pipelineClock{pipe: &l.state.pipe},
+		enc:   newJSONEncoder(applyEncoderOptions(fileEncoderConfig(), opts)),
+		out:   out,
+	}
+}
+
+// ResumeFrom continues an existing chain, typically using the checkpoint
+// returned by VerifyAuditLog after a restart
+func (a *AuditLogger) ResumeFrom(cp AuditCheckpoint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.seq = cp.Seq
+	a.prevHash = cp.Hash
+}
+
+// Checkpoint returns the sequence number and hash of the last record written
+func (a *AuditLogger) Checkpoint() AuditCheckpoint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AuditCheckpoint{Seq: a.seq, Hash: a.prevHash}
+}
+
+// Record writes an audit event. The chain advances once the record is
+// encoded, so a record whose write fails still holds its sequence number
+// and VerifyAuditLog reports the gap it leaves.
+func (a *AuditLogger) Record(event AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	fields := make([]zap.Field, 0, len(event.Fields)+9)
+	fields = append(fields,
+		zap.Bool(auditMarkerKey, true),
+		zap.Uint64("audit_seq", a.seq+1),
+		zap.String("audit_prev_hash", a.prevHash),
+		zap.String("audit_time", now.UTC().Format(time.RFC3339Nano)),
+		zap.String("actor", event.Actor),
+		zap.String("action", event.Action),
+		zap.String("resource", event.Resource),
+		zap.String("outcome", event.Outcome),
+	)
+	fields = append(fields, event.Fields...)
+
+	payload := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(payload)
+	}
+	hash, err := auditHash(a.prevHash, payload.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to hash audit record: %w", err)
+	}
+	fields = append(fields, zap.String("audit_hash", hash))
+
+	ent := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       now,
+		LoggerName: auditLoggerName,
+		Message:    "audit",
+	}
+	buf, err := a.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	defer buf.Free()
+	a.seq++
+	a.prevHash = hash
+
+	if _, err := a.out.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if s, ok := a.out.(interface{ Sync() error }); ok {
+		if err := syncError(s.Sync()); err != nil {
+			return fmt.Errorf("failed to sync audit record: %w", err)
+		}
+	}
+	return nil
+}
+
+// auditHash chains prevHash with the canonical JSON form of payload
+func auditHash(prevHash string, payload map[string]any) (string, error) {
+	canonical, err := canonicalJSON(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(prevHash))
+	sum.Write([]byte{'\n'})
+	sum.Write(canonical)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// canonicalJSON marshals v with sorted keys after normalizing it through a
+// JSON round trip, so a record decoded from a log file hashes identically
+func canonicalJSON(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var normalized any
+	if err := dec.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalized)
+}
+
+// auditEnvelopeKeys returns the entry keys written by an encoder with cfg
+// rather than by the audit record itself, and the hash
+func auditEnvelopeKeys(cfg zapcore.EncoderConfig) []string {
+	return []string{
+		cfg.TimeKey, cfg.LevelKey, cfg.NameKey, cfg.CallerKey, cfg.FunctionKey,
+		cfg.MessageKey, cfg.StacktraceKey, "audit_hash",
+	}
+}
+
+// VerifyAuditLog reads the JSON lines written by an AuditLogger from r and
+// verifies the audit chain they contain. Lines that aren't audit records
+// are skipped. opts are the EncoderOptions the log was written with, such
+// as WithRenamedKey, so that the keys the encoder adds are told apart from
+// the record's. It returns the checkpoint of the last valid record, or an
+// error describing the first broken link.
+func VerifyAuditLog(r io.Reader, opts ...EncoderOption) (AuditCheckpoint, error) {
+	cfg := applyEncoderOptions(fileEncoderConfig(), opts)
+	envelope := auditEnvelopeKeys(cfg)
+	var cp AuditCheckpoint
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.UseNumber()
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil || record[auditMarkerKey] != true {
+			continue
+		}
+
+		hash, _ := record["audit_hash"].(string)
+		prev, _ := record["audit_prev_hash"].(string)
+		seqNum, _ := record["audit_seq"].(json.Number)
+		seq, err := seqNum.Int64()
+		if err != nil {
+			return cp, fmt.Errorf("line %d: invalid audit_seq", line)
+		}
+		if uint64(seq) != cp.Seq+1 {
+			return cp, fmt.Errorf("line %d: expected audit_seq %d, got %d", line, cp.Seq+1, seq)
+		}
+		if prev != cp.Hash {
+			return cp, fmt.Errorf("line %d: audit_prev_hash does not match previous record", line)
+		}
+
+		for _, k := range envelope {
+			delete(record, k)
+		}
+		want, err := auditHash(prev, record)
+		if err != nil {
+			return cp, fmt.Errorf("line %d: %w", line, err)
+		}
+		if want != hash {
+			return cp, fmt.Errorf("line %d: audit_hash mismatch, record was modified", line)
+		}
+		cp = AuditCheckpoint{Seq: uint64(seq), Hash: hash}
+	}
+	if err := scanner.Err(); err != nil {
+		return cp, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return cp, nil
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails the writes whose index is in fail
+type failingWriter struct {
+	bytes.Buffer
+	n    int
+	fail map[int]bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.n++
+	if w.fail[w.n] {
+		return 0, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestAuditLog(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []EncoderOption
+		// edit changes the written trail before it is verified
+		edit    func(trail string) string
+		fail    map[int]bool
+		wantSeq uint64
+		wantErr string
+	}{
+		{name: "plain", wantSeq: 3},
+		{name: "renamed time key", opts: []EncoderOption{WithRenamedKey("time", "@timestamp")}, wantSeq: 3},
+		{
+			name: "other lines",
+			edit: func(trail string) string {
+				return `{"level":"info","logger":"audit","msg":"user logged in"}` + "\n" + trail + "not json\n"
+			},
+			wantSeq: 3,
+		},
+		{
+			name:    "edited record",
+			edit:    func(trail string) string { return strings.Replace(trail, `"actor":"bob"`, `"actor":"eve"`, 1) },
+			wantSeq: 1,
+			wantErr: "line 2: audit_hash mismatch",
+		},
+		{
+			name: "deleted record",
+			edit: func(trail string) string {
+				lines := strings.SplitAfter(trail, "\n")
+				return lines[0] + lines[2]
+			},
+			wantSeq: 1,
+			wantErr: "line 2: expected audit_seq 2, got 3",
+		},
+		{
+			name:    "failed write",
+			fail:    map[int]bool{2: true},
+			wantSeq: 1,
+			wantErr: "line 2: expected audit_seq 2, got 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			out := &failingWriter{fail: tt.fail}
+			audit := NewAuditLogger(l, out, tt.opts...)
+			for _, actor := range []string{"alice", "bob", "carol"} {
+				err := audit.Record(AuditEvent{Actor: actor, Action: "delete", Resource: "invoice/7", Outcome: "ok"})
+				if (err != nil) != tt.fail[out.n] {
+					t.Fatalf("Record for %s: %v", actor, err)
+				}
+			}
+			if cp := audit.Checkpoint(); cp.Seq != 3 {
+				t.Errorf("Checkpoint().Seq = %d after three records, want 3", cp.Seq)
+			}
+			l.Named("audit").Info("not a record")
+
+			trail := out.String()
+			if tt.edit != nil {
+				trail = tt.edit(trail)
+			}
+			cp, err := VerifyAuditLog(strings.NewReader(trail), tt.opts...)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Errorf("VerifyAuditLog error %v, want %q", err, tt.wantErr)
+			}
+			if cp.Seq != tt.wantSeq {
+				t.Errorf("checkpoint seq = %d, want %d", cp.Seq, tt.wantSeq)
+			}
+		})
+	}
+}
+
+// TestAuditLogSeparateFromSinks checks that audit records stay out of the
+// application's file and resume across loggers
+func TestAuditLogSeparateFromSinks(t *testing.T) {
+	dir := t.TempDir()
+	appPath, auditPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "audit.log")
+	l := newBenchLogger(t, Config{Level: "info", EnableFile: true, FilePath: appPath, FileFormat: FileFormatMsgpack})
+
+	var cp AuditCheckpoint
+	for range 2 {
+		f, err := os.OpenFile(auditPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		audit := NewAuditLogger(l, f)
+		audit.ResumeFrom(cp)
+		if err := audit.Record(AuditEvent{Actor: "alice", Action: "login", Outcome: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+		cp = audit.Checkpoint()
+		f.Close()
+	}
+	l.Info("between records")
+	if err := syncError(l.Sync()); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := os.ReadFile(appPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(app, []byte("audit_seq")) {
+		t.Error("audit records reached the application's file")
+	}
+	f, err := os.Open(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	verified, err := VerifyAuditLog(f)
+	if err != nil || verified != cp || verified.Seq != 2 {
+		t.Errorf("VerifyAuditLog = %+v, %v; want %+v", verified, err, cp)
+	}
+}
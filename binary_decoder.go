@@ -0,0 +1,397 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// maxBinaryLength bounds any single string, byte slice, map, or array
+	// read from a binary log file, so a corrupt length can't exhaust memory
+	maxBinaryLength = 64 << 20
+	// maxBinaryDepth bounds nesting of maps and arrays
+	maxBinaryDepth = 100
+)
+
+// errBinaryTooLarge is returned for lengths beyond maxBinaryLength
+var errBinaryTooLarge = errors.New("binary log value exceeds size limit")
+
+// BinaryDecoder reads entries written with FileFormatMsgpack or FileFormatCBOR
+type BinaryDecoder struct {
+	r      *bufio.Reader
+	format string
+}
+
+// NewBinaryDecoder returns a decoder reading entries in format from r
+func NewBinaryDecoder(r io.Reader, format string) (*BinaryDecoder, error) {
+	switch format {
+	case FileFormatMsgpack, FileFormatCBOR:
+	default:
+		return nil, fmt.Errorf("unsupported binary format %q", format)
+	}
+	return &BinaryDecoder{r: bufio.NewReader(r), format: format}, nil
+}
+
+// Decode reads the next entry. It returns io.EOF when no entries remain and
+// io.ErrUnexpectedEOF if the input ends partway through an entry.
+func (d *BinaryDecoder) Decode() (map[string]any, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+
+	var v any
+	var err error
+	if d.format == FileFormatMsgpack {
+		v, err = d.msgpackValue(0)
+	} else {
+		v, err = d.cborValue(0)
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("binary log record is %T, not a map", v)
+	}
+	return entry, nil
+}
+
+// Logcat decodes the binary or delta-encoded log in r and writes each
+// complete entry to w as a line of JSON, for reading those log files with
+// ordinary tools
+func Logcat(w io.Writer, r io.Reader, format string) error {
+	if format == FileFormatJSONDelta {
+		return logcatDelta(w, r)
+	}
+	dec, err := NewBinaryDecoder(r, format)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for {
+		entry, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// readN reads exactly n bytes. Lengths beyond the reader's buffer are read
+// in chunks, so a corrupt length in a short input allocates no more than
+// the input holds.
+func (d *BinaryDecoder) readN(n uint64) ([]byte, error) {
+	if n > maxBinaryLength {
+		return nil, errBinaryTooLarge
+	}
+	if n <= uint64(d.r.Size()) {
+		b := make([]byte, n)
+		_, err := io.ReadFull(d.r, b)
+		return b, err
+	}
+	var buf bytes.Buffer
+	copied, err := io.CopyN(&buf, d.r, int64(n))
+	if errors.Is(err, io.EOF) && copied > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+// readUint reads a big-endian unsigned integer of size bytes
+func (d *BinaryDecoder) readUint(size int) (uint64, error) {
+	b, err := d.readN(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// msgpackValue decodes one MessagePack value
+func (d *BinaryDecoder) msgpackValue(depth int) (any, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("binary log value nested too deeply")
+	}
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.msgpackMap(uint64(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.msgpackArray(uint64(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		b, err := d.readN(uint64(c & 0x1f))
+		return string(b), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.readN(n)
+	case 0xc7, 0xc8, 0xc9, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.msgpackExt(c)
+	case 0xca:
+		u, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		return u, err
+	case 0xd0:
+		u, err := d.readUint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := d.readUint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := d.readUint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := d.readUint(8)
+		return int64(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readN(n)
+		return string(b), err
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.msgpackMap(n, depth)
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%02x", c)
+}
+
+// msgpackExt decodes an extension value, understanding timestamps
+func (d *BinaryDecoder) msgpackExt(c byte) (any, error) {
+	var n uint64
+	var err error
+	switch c {
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		n = 1 << (c - 0xd4)
+	default:
+		n, err = d.readUint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+	}
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.readN(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != -1 {
+		return data, nil
+	}
+
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)), nil
+	}
+	return nil, errors.New("invalid msgpack timestamp")
+}
+
+// msgpackMap decodes n key/value pairs
+func (d *BinaryDecoder) msgpackMap(n uint64, depth int) (map[string]any, error) {
+	if n > maxBinaryLength {
+		return nil, errBinaryTooLarge
+	}
+	m := make(map[string]any, min(n, 64))
+	for i := uint64(0); i < n; i++ {
+		k, err := d.msgpackValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.msgpackValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[mapKey(k)] = v
+	}
+	return m, nil
+}
+
+// msgpackArray decodes n values
+func (d *BinaryDecoder) msgpackArray(n uint64, depth int) ([]any, error) {
+	if n > maxBinaryLength {
+		return nil, errBinaryTooLarge
+	}
+	a := make([]any, 0, min(n, 64))
+	for i := uint64(0); i < n; i++ {
+		v, err := d.msgpackValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+// cborValue decodes one CBOR data item. Indefinite-length items are not
+// produced by the encoder and are rejected.
+func (d *BinaryDecoder) cborValue(depth int) (any, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("binary log value nested too deeply")
+	}
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := c>>5, c&0x1f
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 26:
+			u, err := d.readUint(4)
+			return float64(math.Float32frombits(uint32(u))), err
+		case 27:
+			u, err := d.readUint(8)
+			return math.Float64frombits(u), err
+		}
+		return nil, fmt.Errorf("unsupported cbor simple value %d", info)
+	}
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		arg, err = d.readUint(1 << (info - 24))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cbor additional info %d", info)
+	}
+
+	switch major {
+	case 0:
+		return arg, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor negative integer out of range")
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		return d.readN(arg)
+	case 3:
+		b, err := d.readN(arg)
+		return string(b), err
+	case 4:
+		if arg > maxBinaryLength {
+			return nil, errBinaryTooLarge
+		}
+		a := make([]any, 0, min(arg, 64))
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.cborValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	case 5:
+		if arg > maxBinaryLength {
+			return nil, errBinaryTooLarge
+		}
+		m := make(map[string]any, min(arg, 64))
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.cborValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.cborValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[mapKey(k)] = v
+		}
+		return m, nil
+	default: // 6: tag
+		v, err := d.cborValue(depth + 1)
+		if err != nil || arg != 1 {
+			return v, err
+		}
+		switch secs := v.(type) {
+		case float64:
+			whole, frac := math.Modf(secs)
+			return time.Unix(int64(whole), int64(frac*1e9)), nil
+		case uint64:
+			return time.Unix(int64(secs), 0), nil
+		case int64:
+			return time.Unix(secs, 0), nil
+		}
+		return v, nil
+	}
+}
+
+// mapKey converts a decoded map key to a string
+func mapKey(k any) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
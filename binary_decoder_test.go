@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// seedEntries encodes a few representative entries in format, as a file
+// sink would write them, to seed the fuzz targets
+func seedEntries(tb testing.TB, format string) []byte {
+	tb.Helper()
+	cfg := fileEncoderConfig()
+	enc, err := newFileEncoder(format, cfg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var out bytes.Buffer
+	var w io.Writer = &out
+	if format == FileFormatJSONDelta {
+		w = newDeltaWriter(zapcore.AddSync(&out), cfg, "file")
+	}
+	entries := []struct {
+		ent    zapcore.Entry
+		fields []zapcore.Field
+	}{
+		{zapcore.Entry{Level: zapcore.InfoLevel, Message: "started"}, []zapcore.Field{zap.String("service", "billing")}},
+		{zapcore.Entry{Level: zapcore.WarnLevel, Message: "slow"}, []zapcore.Field{zap.String("service", "billing"), zap.Duration("took", 3*time.Second)}},
+		{zapcore.Entry{Level: zapcore.ErrorLevel, Message: "failed"}, []zapcore.Field{
+			zap.Error(errors.New("boom")), zap.Ints("codes", []int{1, 2}), zap.Binary("raw", []byte{0, 0xff}),
+			zap.Float64("ratio", 0.5), zap.Bool("retry", true), zap.Any("meta", map[string]any{"n": 1}),
+		}},
+	}
+	for i, e := range entries {
+		e.ent.Time = time.Date(2024, 1, 2, 3, 4, 5+i, 0, time.UTC)
+		buf, err := enc.EncodeEntry(e.ent, e.fields)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			tb.Fatal(err)
+		}
+		buf.Free()
+	}
+	return out.Bytes()
+}
+
+// fuzzBinaryDecoder decodes data in format until an error, which must come
+// before the input runs out of bytes to consume
+func fuzzBinaryDecoder(f *testing.F, format string) {
+	seed := seedEntries(f, format)
+	f.Add(seed)
+	f.Add(seed[:len(seed)/2])
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dec, err := NewBinaryDecoder(bytes.NewReader(data), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; ; n++ {
+			if n > len(data) {
+				t.Fatalf("decoded %d entries from %d bytes", n, len(data))
+			}
+			if _, err := dec.Decode(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func FuzzBinaryDecoderMsgpack(f *testing.F) {
+	fuzzBinaryDecoder(f, FileFormatMsgpack)
+}
+
+func FuzzBinaryDecoderCBOR(f *testing.F) {
+	fuzzBinaryDecoder(f, FileFormatCBOR)
+}
+
+func TestBinaryDecoderRoundTrip(t *testing.T) {
+	for _, format := range []string{FileFormatMsgpack, FileFormatCBOR} {
+		t.Run(format, func(t *testing.T) {
+			dec, err := NewBinaryDecoder(bytes.NewReader(seedEntries(t, format)), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var msgs []string
+			for {
+				entry, err := dec.Decode()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				msgs = append(msgs, entry["msg"].(string))
+			}
+			if want := []string{"started", "slow", "failed"}; !slices.Equal(msgs, want) {
+				t.Errorf("decoded messages %q, want %q", msgs, want)
+			}
+		})
+	}
+}
+
+func TestBinaryDecoderLongValues(t *testing.T) {
+	// A one-entry map {"msg": <string of n bytes>} with a 4-byte length
+	entry := func(format string, n int, value string) []byte {
+		var head []byte
+		if format == FileFormatMsgpack {
+			head = []byte{0x81, 0xa3, 'm', 's', 'g', 0xdb}
+		} else {
+			head = []byte{0xa1, 0x63, 'm', 's', 'g', 0x7a}
+		}
+		head = binary.BigEndian.AppendUint32(head, uint32(n))
+		return append(head, value...)
+	}
+	long := strings.Repeat("x", 10000)
+	for _, format := range []string{FileFormatMsgpack, FileFormatCBOR} {
+		t.Run(format, func(t *testing.T) {
+			dec, err := NewBinaryDecoder(bytes.NewReader(entry(format, len(long), long)), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := dec.Decode(); err != nil || got["msg"] != long {
+				t.Errorf("Decode of a %d-byte value failed: %v", len(long), err)
+			}
+
+			// A corrupt length just under the limit must not be allocated up front
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			dec, _ = NewBinaryDecoder(bytes.NewReader(entry(format, maxBinaryLength-1, "truncated")), format)
+			if _, err := dec.Decode(); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("Decode of a truncated value = %v, want io.ErrUnexpectedEOF", err)
+			}
+			runtime.ReadMemStats(&after)
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+				t.Errorf("decoding a truncated value allocated %d bytes", allocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package logger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported values for Config.FileFormat
+const (
+	// FileFormatJSON writes one JSON object per line (the default)
+	FileFormatJSON = "json"
+	// FileFormatMsgpack writes each entry as a MessagePack map
+	FileFormatMsgpack = "msgpack"
+	// FileFormatCBOR writes each entry as a CBOR map
+	FileFormatCBOR = "cbor"
+	// FileFormatJSONDelta writes JSON lines holding only the keys that
+	// changed since the previous entry, plus the time and message, with
+	// complete entries every thousand lines. LogReader and Logcat restore
+	// the omitted keys. The keys "_k", "_d", and "_u" are reserved.
+	FileFormatJSONDelta = "json-delta"
+)
+
+// isBinaryFormat reports whether format is one of the binary file formats
+func isBinaryFormat(format string) bool {
+	return format == FileFormatMsgpack || format == FileFormatCBOR
+}
+
+// binaryWriter appends values in a self-describing binary format
+type binaryWriter interface {
+	mapHeader(n int)
+	arrayHeader(n int)
+	str(s string)
+	bin(b []byte)
+	int(i int64)
+	uint(u uint64)
+	float(f float64)
+	bool(b bool)
+	null()
+	time(t time.Time)
+}
+
+// binaryEncoder encodes entries as maps in a binary format. Records are
+// self-delimiting, so files are a plain concatenation of entries.
+type binaryEncoder struct {
+	mapEncoder
+	cfg       zapcore.EncoderConfig
+	newWriter func(*buffer.Buffer) binaryWriter
+}
+
+// newBinaryEncoder creates an encoder for the given file format
+func newBinaryEncoder(format string, cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	enc := &binaryEncoder{mapEncoder: newMapEncoder(), cfg: cfg}
+	switch format {
+	case FileFormatMsgpack:
+		enc.newWriter = func(b *buffer.Buffer) binaryWriter { return msgpackWriter{b} }
+	case FileFormatCBOR:
+		enc.newWriter = func(b *buffer.Buffer) binaryWriter { return cborWriter{b} }
+	default:
+		return nil, fmt.Errorf("unsupported binary format %q", format)
+	}
+	return enc, nil
+}
+
+// Clone copies the accumulated context fields
+func (e *binaryEncoder) Clone() zapcore.Encoder {
+	return &binaryEncoder{mapEncoder: e.clone(), cfg: e.cfg, newWriter: e.newWriter}
+}
+
+// EncodeEntry writes the entry as a single map, entry keys first
+func (e *binaryEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.withFields(fields)
+
+	type kv struct {
+		key   string
+		value any
+	}
+	var head []kv
+	addKey := func(key string, value any) {
+		if key != zapcore.OmitKey && key != "" {
+			head = append(head, kv{key, value})
+		}
+	}
+	addKey(e.cfg.LevelKey, levelName(ent.Level))
+	addKey(e.cfg.TimeKey, ent.Time)
+	if ent.LoggerName != "" {
+		addKey(e.cfg.NameKey, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		addKey(e.cfg.CallerKey, ent.Caller.TrimmedPath())
+	}
+	addKey(e.cfg.MessageKey, ent.Message)
+	if ent.Stack != "" {
+		addKey(e.cfg.StacktraceKey, ent.Stack)
+	}
+
+	keys := make([]string, 0, len(final.Fields))
+	for k := range final.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := bufferPool.Get()
+	w := e.newWriter(buf)
+	w.mapHeader(len(head) + len(keys))
+	for _, h := range head {
+		w.str(h.key)
+		writeBinaryValue(w, h.value)
+	}
+	for _, k := range keys {
+		w.str(k)
+		writeBinaryValue(w, final.Fields[k])
+	}
+	return buf, nil
+}
+
+// writeBinaryValue writes a value produced by mapEncoder
+func writeBinaryValue(w binaryWriter, v any) {
+	switch val := v.(type) {
+	case nil:
+		w.null()
+	case string:
+		w.str(val)
+	case []byte:
+		w.bin(val)
+	case bool:
+		w.bool(val)
+	case int:
+		w.int(int64(val))
+	case int8:
+		w.int(int64(val))
+	case int16:
+		w.int(int64(val))
+	case int32:
+		w.int(int64(val))
+	case int64:
+		w.int(val)
+	case uint:
+		w.uint(uint64(val))
+	case uint8:
+		w.uint(uint64(val))
+	case uint16:
+		w.uint(uint64(val))
+	case uint32:
+		w.uint(uint64(val))
+	case uint64:
+		w.uint(val)
+	case uintptr:
+		w.uint(uint64(val))
+	case float32:
+		w.float(float64(val))
+	case float64:
+		w.float(val)
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			w.int(i)
+		} else if f, err := val.Float64(); err == nil {
+			w.float(f)
+		} else {
+			w.str(val.String())
+		}
+	case complex64, complex128:
+		w.str(fmt.Sprint(val))
+	case time.Time:
+		w.time(val)
+	case time.Duration:
+		w.int(int64(val))
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.mapHeader(len(keys))
+		for _, k := range keys {
+			w.str(k)
+			writeBinaryValue(w, val[k])
+		}
+	case []any:
+		w.arrayHeader(len(val))
+		for _, item := range val {
+			writeBinaryValue(w, item)
+		}
+	case error:
+		w.str(val.Error())
+	case fmt.Stringer:
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			w.null()
+			return
+		}
+		w.str(val.String())
+	default:
+		w.str(fmt.Sprintf("%+v", val))
+	}
+}
+
+// msgpackWriter writes MessagePack
+type msgpackWriter struct {
+	buf *buffer.Buffer
+}
+
+func (w msgpackWriter) byte(b byte) { w.buf.AppendByte(b) }
+
+func (w msgpackWriter) be(v uint64, size int) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	w.buf.Write(b[8-size:])
+}
+
+func (w msgpackWriter) mapHeader(n int) {
+	switch {
+	case n < 16:
+		w.byte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		w.byte(0xde)
+		w.be(uint64(n), 2)
+	default:
+		w.byte(0xdf)
+		w.be(uint64(n), 4)
+	}
+}
+
+func (w msgpackWriter) arrayHeader(n int) {
+	switch {
+	case n < 16:
+		w.byte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		w.byte(0xdc)
+		w.be(uint64(n), 2)
+	default:
+		w.byte(0xdd)
+		w.be(uint64(n), 4)
+	}
+}
+
+func (w msgpackWriter) str(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		w.byte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.byte(0xd9)
+		w.byte(byte(n))
+	case n <= math.MaxUint16:
+		w.byte(0xda)
+		w.be(uint64(n), 2)
+	default:
+		w.byte(0xdb)
+		w.be(uint64(n), 4)
+	}
+	w.buf.AppendString(s)
+}
+
+func (w msgpackWriter) bin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		w.byte(0xc4)
+		w.byte(byte(n))
+	case n <= math.MaxUint16:
+		w.byte(0xc5)
+		w.be(uint64(n), 2)
+	default:
+		w.byte(0xc6)
+		w.be(uint64(n), 4)
+	}
+	w.buf.Write(b)
+}
+
+func (w msgpackWriter) int(i int64) {
+	if i >= 0 {
+		w.uint(uint64(i))
+		return
+	}
+	switch {
+	case i >= -32:
+		w.byte(byte(i))
+	case i >= math.MinInt8:
+		w.byte(0xd0)
+		w.byte(byte(i))
+	case i >= math.MinInt16:
+		w.byte(0xd1)
+		w.be(uint64(i), 2)
+	case i >= math.MinInt32:
+		w.byte(0xd2)
+		w.be(uint64(i), 4)
+	default:
+		w.byte(0xd3)
+		w.be(uint64(i), 8)
+	}
+}
+
+func (w msgpackWriter) uint(u uint64) {
+	switch {
+	case u < 128:
+		w.byte(byte(u))
+	case u <= math.MaxUint8:
+		w.byte(0xcc)
+		w.byte(byte(u))
+	case u <= math.MaxUint16:
+		w.byte(0xcd)
+		w.be(u, 2)
+	case u <= math.MaxUint32:
+		w.byte(0xce)
+		w.be(u, 4)
+	default:
+		w.byte(0xcf)
+		w.be(u, 8)
+	}
+}
+
+func (w msgpackWriter) float(f float64) {
+	w.byte(0xcb)
+	w.be(math.Float64bits(f), 8)
+}
+
+func (w msgpackWriter) bool(b bool) {
+	if b {
+		w.byte(0xc3)
+	} else {
+		w.byte(0xc2)
+	}
+}
+
+func (w msgpackWriter) null() { w.byte(0xc0) }
+
+// time writes the timestamp extension type (-1) in its 96-bit form
+func (w msgpackWriter) time(t time.Time) {
+	w.byte(0xc7)
+	w.byte(12)
+	w.byte(0xff)
+	w.be(uint64(t.Nanosecond()), 4)
+	w.be(uint64(t.Unix()), 8)
+}
+
+// cborWriter writes CBOR (RFC 8949)
+type cborWriter struct {
+	buf *buffer.Buffer
+}
+
+// head writes a major type with its argument in the shortest form
+func (w cborWriter) head(major byte, arg uint64) {
+	m := major << 5
+	switch {
+	case arg < 24:
+		w.buf.AppendByte(m | byte(arg))
+	case arg <= math.MaxUint8:
+		w.buf.AppendByte(m | 24)
+		w.buf.AppendByte(byte(arg))
+	case arg <= math.MaxUint16:
+		w.buf.AppendByte(m | 25)
+		w.buf.Write([]byte{byte(arg >> 8), byte(arg)})
+	case arg <= math.MaxUint32:
+		w.buf.AppendByte(m | 26)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(arg))
+		w.buf.Write(b[:])
+	default:
+		w.buf.AppendByte(m | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], arg)
+		w.buf.Write(b[:])
+	}
+}
+
+func (w cborWriter) mapHeader(n int)   { w.head(5, uint64(n)) }
+func (w cborWriter) arrayHeader(n int) { w.head(4, uint64(n)) }
+
+func (w cborWriter) str(s string) {
+	w.head(3, uint64(len(s)))
+	w.buf.AppendString(s)
+}
+
+func (w cborWriter) bin(b []byte) {
+	w.head(2, uint64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w cborWriter) int(i int64) {
+	if i >= 0 {
+		w.head(0, uint64(i))
+		return
+	}
+	w.head(1, uint64(-1-i))
+}
+
+func (w cborWriter) uint(u uint64) { w.head(0, u) }
+
+func (w cborWriter) float(f float64) {
+	w.buf.AppendByte(0xfb)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	w.buf.Write(b[:])
+}
+
+func (w cborWriter) bool(b bool) {
+	if b {
+		w.buf.AppendByte(0xf5)
+	} else {
+		w.buf.AppendByte(0xf4)
+	}
+}
+
+func (w cborWriter) null() { w.buf.AppendByte(0xf6) }
+
+// time writes tag 1 (epoch-based date/time) with a float seconds value
+func (w cborWriter) time(t time.Time) {
+	w.head(6, 1)
+	w.float(float64(t.UnixNano()) / 1e9)
+}
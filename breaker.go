@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed passes writes through to the primary writer
+	BreakerClosed BreakerState = iota
+	// BreakerOpen diverts writes to the fallback writer
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe write through to the primary writer
+	BreakerHalfOpen
+)
+
+// String returns the lowercase name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a CircuitBreaker
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before probing the
+	// primary writer again. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// OnStateChange is called after every state transition
+	OnStateChange func(from, to BreakerState)
+}
+
+// CircuitBreaker is a WriteSyncer that stops writing to a degraded primary
+// writer after repeated failures and diverts entries to a fallback instead,
+// so a slow or failing backend doesn't stall the logging path
+type CircuitBreaker struct {
+	primary  zapcore.WriteSyncer
+	fallback zapcore.WriteSyncer
+	config   BreakerConfig
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker wraps primary with a circuit breaker. fallback may be nil,
+// in which case entries written while the breaker is open are dropped.
+func NewCircuitBreaker(primary, fallback zapcore.WriteSyncer, config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		primary:  primary,
+		fallback: fallback,
+		config:   config,
+	}
+}
+
+// newSinkBreaker creates the breaker guarding the remote sink named name
+// for Config.Breaker. Its state changes are passed to config's
+// OnStateChange and logged through the diagnostics logger diag returns,
+// once there is one.
+func newSinkBreaker(name string, config BreakerConfig, diag func() *zap.Logger) *CircuitBreaker {
+	onChange := config.OnStateChange
+	config.OnStateChange = func(from, to BreakerState) {
+		if zl := diag(); zl != nil {
+			level := zapcore.InfoLevel
+			if to == BreakerOpen {
+				level = zapcore.WarnLevel
+			}
+			if ce := zl.Check(level, "sink circuit breaker "+to.String()); ce != nil {
+				ce.Write(zap.String("sink", name), zap.Stringer("from", from), zap.Stringer("to", to))
+			}
+		}
+		if onChange != nil {
+			onChange(from, to)
+		}
+	}
+	return NewCircuitBreaker(nil, nil, config)
+}
+
+// State returns the current breaker state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Write sends p to the primary writer unless the breaker is open
+func (b *CircuitBreaker) Write(p []byte) (int, error) {
+	if !b.allow() {
+		return b.writeFallback(p)
+	}
+
+	n, err := b.primary.Write(p)
+	b.record(err)
+	if err != nil {
+		return b.writeFallback(p)
+	}
+	return n, nil
+}
+
+// Sync flushes the primary writer while it is reachable and the fallback
+func (b *CircuitBreaker) Sync() error {
+	var errs []error
+	if b.State() != BreakerOpen {
+		errs = append(errs, b.primary.Sync())
+	}
+	if b.fallback != nil {
+		errs = append(errs, b.fallback.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// allow reports whether the next write may go to the primary writer
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	var from, to BreakerState
+	changed := false
+	defer func() {
+		b.mu.Unlock()
+		if changed {
+			b.notify(from, to)
+		}
+	}()
+
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		from, to, changed = b.state, BreakerHalfOpen, true
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	default:
+		// Only one probe is in flight while half-open
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+}
+
+// record updates the breaker after a write to the primary writer
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	from := b.state
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.state = BreakerClosed
+	} else {
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(from, to)
+	}
+}
+
+// notify reports a state transition
+func (b *CircuitBreaker) notify(from, to BreakerState) {
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
+
+// writeFallback writes p to the fallback writer, if any
+func (b *CircuitBreaker) writeFallback(p []byte) (int, error) {
+	if b.fallback == nil {
+		return len(p), nil
+	}
+	return b.fallback.Write(p)
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/time/rate"
+)
+
+// breakerMessages returns the breaker state changes logged as diagnostics
+// of the sink named sink
+func breakerMessages(logs *observer.ObservedLogs, sink string) []string {
+	var msgs []string
+	for _, e := range logs.All() {
+		if e.LoggerName == diagnosticsLoggerName && e.ContextMap()["sink"] == sink {
+			msgs = append(msgs, e.Level.String()+" "+e.Message)
+		}
+	}
+	return msgs
+}
+
+func TestBreakerGuardsWebhook(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	l := newBenchLogger(t, Config{
+		Level:   "info",
+		Breaker: &BreakerConfig{FailureThreshold: 1, OpenTimeout: 50 * time.Millisecond},
+	})
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l.AddSink(obs)
+	webhook, err := NewWebhookCore(WebhookConfig{URL: srv.URL, Level: zapcore.ErrorLevel, RateLimit: rate.Inf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.AddSink(webhook)
+
+	// The first alert fails and opens the breaker, which drops the second
+	l.Error("first")
+	l.Sync()
+	l.Error("second")
+	l.Sync()
+	time.Sleep(60 * time.Millisecond)
+	// The third is the half-open probe, whose success closes the breaker
+	l.Error("third")
+	l.Sync()
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("webhook received %d requests, want 2", got)
+	}
+	want := []string{"warn sink circuit breaker open", "info sink circuit breaker half-open", "info sink circuit breaker closed"}
+	if got := breakerMessages(logs, addedSinkName(webhook)); !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+func TestBreakerGuardsNetworkSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var changes atomic.Int64
+	l := newBenchLogger(t, Config{
+		Level:   "info",
+		Network: &NetworkConfig{Protocol: "tcp", Address: addr, MaxBackoff: time.Millisecond},
+		Breaker: &BreakerConfig{
+			FailureThreshold: 3,
+			OpenTimeout:      time.Hour,
+			OnStateChange:    func(from, to BreakerState) { changes.Add(1) },
+		},
+	})
+	obs, logs := observer.New(zapcore.DebugLevel)
+	l.AddSink(obs)
+	l.Info("unsent")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(breakerMessages(logs, "network "+addr)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Once open, the worker stops dialing and keeps the entries buffered
+	time.Sleep(20 * time.Millisecond)
+	want := []string{"warn sink circuit breaker open"}
+	if got := breakerMessages(logs, "network "+addr); !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+	if got := changes.Load(); got != 1 {
+		t.Errorf("OnStateChange called %d times, want 1", got)
+	}
+	if stats := l.state.pipe.Load().res.network.stats(); stats.Buffered == 0 {
+		t.Error("network sink dropped its entries while the breaker was open")
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap/zapcore"
+)
+
+// Link formats for WithCallerLinks. In a format, {path} is replaced by the
+// caller's absolute source path, {line} by its line number, and {host} by
+// the hostname.
+const (
+	// CallerLinkFile opens the source file with the system's handler
+	CallerLinkFile = "file://{host}{path}"
+	// CallerLinkVSCode opens the line in Visual Studio Code
+	CallerLinkVSCode = "vscode://file{path}:{line}"
+	// CallerLinkCursor opens the line in Cursor
+	CallerLinkCursor = "cursor://file{path}:{line}"
+	// CallerLinkJetBrains opens the line in the running JetBrains IDE,
+	// such as GoLand
+	CallerLinkJetBrains = "idea://open?file={path}&line={line}"
+)
+
+// WithCallerLinks makes the console's caller an OSC 8 hyperlink, so that
+// clicking it in a terminal supporting them, such as iTerm2, kitty,
+// WezTerm, or Windows Terminal, opens the source location. format is one
+// of the CallerLink constants or a URL of the same form for another
+// editor. The caller reads as before; links are written only while colors
+// are, so redirected output stays plain. Structured sinks ignore it.
+func WithCallerLinks(format string) EncoderOption {
+	host, _ := os.Hostname()
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+			h, ok := enc.(*headerEncoder)
+			if !ok || !caller.Defined || color.NoColor {
+				shortCallerEncoder(caller, enc)
+				return
+			}
+			h.next()
+			h.buf.AppendString("\x1b]8;;")
+			h.buf.AppendString(callerLink(format, host, caller))
+			h.buf.AppendString("\x1b\\")
+			h.buf.AppendString(trimCallerFile(caller.File))
+			h.buf.AppendByte(':')
+			h.buf.AppendInt(int64(caller.Line))
+			h.buf.AppendString("\x1b]8;;\x1b\\")
+		}
+	}
+}
+
+// callerLink fills in format for caller. The path is escaped, which also
+// keeps control characters out of the escape sequence.
+func callerLink(format, host string, caller zapcore.EntryCaller) string {
+	path := caller.File
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths such as C:/src/main.go
+		path = "/" + path
+	}
+	return strings.NewReplacer(
+		"{path}", (&url.URL{Path: path}).EscapedPath(),
+		"{line}", strconv.Itoa(caller.Line),
+		"{host}", url.PathEscape(host),
+	).Replace(format)
+}
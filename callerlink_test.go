@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCallerLink(t *testing.T) {
+	tests := []struct {
+		format string
+		file   string
+		want   string
+	}{
+		{CallerLinkFile, "/src/app/main.go", "file://build-1/src/app/main.go"},
+		{CallerLinkVSCode, "/src/app/main.go", "vscode://file/src/app/main.go:42"},
+		{CallerLinkCursor, "/src/app/main.go", "cursor://file/src/app/main.go:42"},
+		{CallerLinkJetBrains, "/src/app/main.go", "idea://open?file=/src/app/main.go&line=42"},
+		{CallerLinkVSCode, "C:/src/app/main.go", "vscode://file/C:/src/app/main.go:42"},
+		// Escaping keeps spaces and control characters out of the sequence
+		{CallerLinkVSCode, "/src/my app/\x1b\\main.go", "vscode://file/src/my%20app/%1B%5Cmain.go:42"},
+	}
+	for _, tt := range tests {
+		caller := zapcore.NewEntryCaller(0, tt.file, 42, true)
+		if got := callerLink(tt.format, "build-1", caller); got != tt.want {
+			t.Errorf("callerLink(%q, %q) = %q, want %q", tt.format, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestWithCallerLinks(t *testing.T) {
+	ent := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2024, 3, 10, 12, 30, 45, 0, time.UTC),
+		Message: "linked",
+		Caller:  zapcore.NewEntryCaller(0, "/src/app/main.go", 42, true),
+	}
+	cfg := applyEncoderOptions(consoleEncoderConfig(), []EncoderOption{WithCallerLinks(CallerLinkVSCode)})
+	encode := func(t *testing.T, noColor bool) string {
+		t.Helper()
+		prev := color.NoColor
+		color.NoColor = noColor
+		defer func() { color.NoColor = prev }()
+		buf, err := newConsoleEncoder(cfg).EncodeEntry(ent, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	link := "\x1b]8;;vscode://file/src/app/main.go:42\x1b\\app/main.go:42\x1b]8;;\x1b\\"
+	if line := encode(t, false); !strings.Contains(line, link) {
+		t.Errorf("colored entry %q is missing the link %q", line, link)
+	}
+	line := encode(t, true)
+	if strings.Contains(line, "\x1b]8") || !strings.Contains(line, "app/main.go:42") {
+		t.Errorf("plain entry %q, want the caller without a link", line)
+	}
+
+	// Structured encoders keep the plain caller, even with colors
+	withoutColor(t)
+	color.NoColor = false
+	buf, err := newJSONEncoder(applyEncoderOptions(fileEncoderConfig(), []EncoderOption{WithCallerLinks(CallerLinkVSCode)})).EncodeEntry(ent, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("JSON entry %q has a link", buf.String())
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// pipelineClock follows the current pipeline's Config.Clock, so a reload
+// can replace it
+type pipelineClock struct {
+	pipe *atomic.Pointer[pipeline]
+}
+
+// current returns the configured clock or the system clock
+func (c pipelineClock) current() zapcore.Clock {
+	if clock := c.pipe.Load().config.Clock; clock != nil {
+		return clock
+	}
+	return zapcore.DefaultClock
+}
+
+// Now returns the current time of the configured clock
+func (c pipelineClock) Now() time.Time {
+	return c.current().Now()
+}
+
+// NewTicker returns a ticker of the configured clock
+func (c pipelineClock) NewTicker(d time.Duration) *time.Ticker {
+	return c.current().NewTicker(d)
+}
+
+// now returns the time entries built by this package are stamped with
+func (l *Logger) now() time.Time {
+	return pipelineClock{pipe: &l.state.pipe}.Now()
+}
+
+// monotonicClock reports the wall time at its creation advanced by the
+// monotonic time elapsed since
+type monotonicClock struct {
+	start time.Time
+}
+
+// NewMonotonicClock returns a clock for Config.Clock that reads the wall
+// clock once and then advances with the monotonic clock, so timestamps
+// never jump backwards or forwards when the wall clock is stepped, as
+// happens in virtual machines after a pause or migration. They drift from
+// the wall clock by whatever corrections it receives.
+func NewMonotonicClock() zapcore.Clock {
+	return monotonicClock{start: time.Now()}
+}
+
+// Now returns the start time plus the monotonic time elapsed since
+func (c monotonicClock) Now() time.Time {
+	return c.start.Add(time.Since(c.start)).Round(0)
+}
+
+// NewTicker returns a standard ticker, which already uses the monotonic
+// clock
+func (c monotonicClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	first := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	config := Config{EnableFile: true, FilePath: path, Clock: fixedClock(first),
+		FileEncoderOptions: []EncoderOption{WithRFC3339Time()}}
+	l := newBenchLogger(t, config)
+	l.Info("before reload")
+	config.Clock = fixedClock(second)
+	if err := l.Reload(config); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.now(); !got.Equal(second) {
+		t.Errorf("now = %v after reload, want %v", got, second)
+	}
+	l.Info("after reload")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{first.Format(time.RFC3339), second.Format(time.RFC3339)}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("file has %d entries, want %d", len(lines), len(want))
+	}
+	for i, line := range lines {
+		var entry struct{ Time string }
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Time != want[i] {
+			t.Errorf("entry %d stamped %s, want %s", i+1, entry.Time, want[i])
+		}
+	}
+}
+
+func TestMonotonicClock(t *testing.T) {
+	clock := NewMonotonicClock()
+	prev := clock.Now()
+	for range 100 {
+		now := clock.Now()
+		if now.Before(prev) {
+			t.Fatalf("clock went backwards from %v to %v", prev, now)
+		}
+		if now != now.Round(0) {
+			t.Fatal("clock returned a time with a monotonic reading")
+		}
+		prev = now
+	}
+	if d := time.Since(prev); d < 0 || d > time.Minute {
+		t.Errorf("clock is %v away from the wall clock", d)
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Close flushes pending rate-limit summaries, drains the async queue, syncs
+// every sink, and closes the files the logger opened. It returns ctx.Err()
+// if ctx is done first; the shutdown keeps running in the background. Close
+// affects the logger and every logger derived from it, and only the first
+// call does any work. Entries logged once Close is called are dropped.
+func (l *Logger) Close(ctx context.Context) error {
+	s := l.state
+	s.closeOnce.Do(func() {
+		s.closed = make(chan struct{})
+		go func() {
+			defer close(s.closed)
+			s.closeErr = l.shutdown()
+		}()
+	})
+
+	select {
+	case <-s.closed:
+		return s.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown performs the work behind Close
+func (l *Logger) shutdown() error {
+	s := l.state
+	s.flushRateLimiters()
+
+	s.reloadMu.Lock()
+	retiring := append(s.retiring, s.pipe.Load())
+	s.retiring = nil
+	// Later entries are dropped; the pipeline is released once the
+	// writes in progress finish
+	retiring[len(retiring)-1].retire()
+	s.reloadMu.Unlock()
+
+	errs := make([]error, 0, len(retiring))
+	for _, p := range retiring {
+		errs = append(errs, p.wait())
+	}
+	return errors.Join(errs...)
+}
+
+// syncError drops the errors returned when syncing a terminal or pipe,
+// which cannot be fsynced, from err. Joined errors are filtered part by part.
+func syncError(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			if e = syncError(e); e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.EBADF) {
+		return nil
+	}
+	return err
+}
+
+// CloseOnSignal closes l with the given timeout when the process receives
+// one of signals (SIGINT and SIGTERM by default), then exits with the
+// conventional 128+signal status. The returned function stops the handling;
+// calling it again does nothing.
+func (l *Logger) CloseOnSignal(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+
+	go func() {
+		select {
+		case sig := <-ch:
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			if err := l.Close(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: close on %s: %v\n", sig, err)
+			}
+			cancel()
+			code := 1
+			if n, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(n)
+			}
+			os.Exit(code)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCloseOnSignalStopTwice(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	stop := l.CloseOnSignal(time.Second, syscall.SIGUSR1)
+	stop()
+	stop()
+}
+
+// TestCloseDeadline checks that Close gives up at its context's deadline
+// while a write is stalled, and finishes the shutdown once it completes
+func TestCloseDeadline(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	stalling := stallingCore{
+		countingCore: countingCore{n: &atomic.Int64{}},
+		stall:        &atomic.Bool{},
+		entered:      make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	stalling.stall.Store(true)
+	l.AddSink(stalling)
+	go l.Info("stalled")
+	<-stalling.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close with a stalled write = %v, want context.DeadlineExceeded", err)
+	}
+	l.Info("after close")
+
+	close(stalling.release)
+	for range 2 {
+		if err := syncError(l.Close(context.Background())); err != nil {
+			t.Errorf("Close after the write finished: %v", err)
+		}
+	}
+	if n := stalling.n.Load(); n != 1 {
+		t.Errorf("sink wrote %d entries, want only the stalled one", n)
+	}
+}
@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCmdLine is the longest output line a wrapped command's entry holds.
+// Longer lines are split into entries marked truncated, so a child that
+// never writes a newline can't grow the held back line without bound.
+const maxCmdLine = 64 << 10
+
+// CmdOption configures the capture of a command wrapped with WrapCmd
+type CmdOption func(*cmdLogger)
+
+// WithJSONLines logs output lines holding a JSON object as structured
+// entries: "msg" or "message" becomes the message, "level" or "severity"
+// the level, in any of the names LevelFromName accepts, and the other keys
+// fields, except "time" and "ts", which the entry's own time replaces.
+// Other lines are logged as text.
+func WithJSONLines() CmdOption {
+	return func(cl *cmdLogger) {
+		cl.jsonLines = true
+	}
+}
+
+// WithStreamLevels sets the levels of lines read from stdout and stderr.
+// Defaults to info and error.
+func WithStreamLevels(stdout, stderr zapcore.Level) CmdOption {
+	return func(cl *cmdLogger) {
+		cl.stdoutLevel = stdout
+		cl.stderrLevel = stderr
+	}
+}
+
+// cmdLogger holds the capture settings of a wrapped command
+type cmdLogger struct {
+	logger      *zap.Logger
+	cmd         *exec.Cmd
+	jsonLines   bool
+	stdoutLevel zapcore.Level
+	stderrLevel zapcore.Level
+}
+
+// WrapCmd captures the stdout and stderr of cmd, which must not have been
+// started, logging each line as an entry with pid, command, and stream
+// fields. Start and wait for cmd as usual; every line, including a last
+// one without a trailing newline, is logged before Wait returns. It fails
+// if cmd's Stdout or Stderr is already set.
+func (l *Logger) WrapCmd(cmd *exec.Cmd, opts ...CmdOption) error {
+	if cmd.Process != nil {
+		return errors.New("command already started")
+	}
+	if cmd.Stdout != nil || cmd.Stderr != nil {
+		return errors.New("command output is already redirected")
+	}
+	cl := &cmdLogger{
+		// The capturing goroutine's caller and stack say nothing about the
+		// child, so neither is recorded
+		logger:      l.Logger.WithOptions(zap.WithCaller(false), zap.AddStacktrace(zapcore.InvalidLevel)),
+		cmd:         cmd,
+		stdoutLevel: zapcore.InfoLevel,
+		stderrLevel: zapcore.ErrorLevel,
+	}
+	for _, opt := range opts {
+		opt(cl)
+	}
+	cmd.Stdout = &cmdStream{cl: cl, name: "stdout", level: cl.stdoutLevel}
+	cmd.Stderr = &cmdStream{cl: cl, name: "stderr", level: cl.stderrLevel}
+	return nil
+}
+
+// cmdStream logs one output stream of a wrapped command line by line
+type cmdStream struct {
+	cl    *cmdLogger
+	name  string
+	level zapcore.Level
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// Write logs each complete line in p, holding back a trailing partial
+// line. Lines longer than maxCmdLine are logged in pieces as they arrive.
+func (s *cmdStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		for i > maxCmdLine {
+			s.cl.logLine(s, s.partial[:maxCmdLine], true)
+			s.partial = s.partial[maxCmdLine:]
+			i -= maxCmdLine
+		}
+		s.cl.logLine(s, s.partial[:i], false)
+		s.partial = s.partial[i+1:]
+	}
+	for len(s.partial) > maxCmdLine {
+		s.cl.logLine(s, s.partial[:maxCmdLine], true)
+		s.partial = s.partial[maxCmdLine:]
+	}
+	// Release the buffer once it's drained so a long line doesn't pin it
+	if len(s.partial) == 0 {
+		s.partial = nil
+	}
+	return len(p), nil
+}
+
+// ReadFrom copies the command's output until it closes, then logs any
+// partial line left. exec.Cmd copies output with io.Copy, which uses
+// ReadFrom, so the last line is logged before Wait returns.
+func (s *cmdStream) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadSlice('\n')
+		n += int64(len(line))
+		if len(line) > 0 {
+			_, _ = s.Write(line)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			s.flush()
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+}
+
+// flush logs a partial line held back by Write
+func (s *cmdStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.partial) > 0 {
+		s.cl.logLine(s, s.partial, false)
+		s.partial = nil
+	}
+}
+
+// logLine writes one line of output as an entry. A truncated line is
+// continued by the next entry.
+func (cl *cmdLogger) logLine(s *cmdStream, line []byte, truncated bool) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	level, msg := s.level, string(line)
+	var extra []zap.Field
+	if cl.jsonLines && !truncated {
+		if l, m, f, ok := parseJSONLine(line); ok {
+			if l != nil {
+				level = *l
+			}
+			msg, extra = m, f
+		}
+	}
+
+	ce := cl.logger.Check(level, msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(extra)+4)
+	if cl.cmd.Process != nil {
+		fields = append(fields, zap.Int("pid", cl.cmd.Process.Pid))
+	}
+	fields = append(fields,
+		zap.String("command", filepath.Base(cl.cmd.Path)),
+		zap.String("stream", s.name),
+	)
+	if truncated {
+		fields = append(fields, zap.Bool("truncated", true))
+	}
+	ce.Write(append(fields, extra...)...)
+}
+
+// parseJSONLine splits a line holding a JSON object into its level, if it
+// has a known one, message, and remaining fields in key order
+func parseJSONLine(line []byte) (*zapcore.Level, string, []zap.Field, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, "", nil, false
+	}
+	var obj map[string]any
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, "", nil, false
+	}
+
+	var msg string
+	for _, k := range []string{"msg", "message"} {
+		if s, ok := obj[k].(string); ok {
+			msg = s
+			delete(obj, k)
+			break
+		}
+	}
+	var level *zapcore.Level
+	for _, k := range []string{"level", "severity"} {
+		if s, ok := obj[k].(string); ok {
+			if l, ok := LevelFromName(s); ok {
+				level = &l
+				delete(obj, k)
+				break
+			}
+		}
+	}
+	delete(obj, "time")
+	delete(obj, "ts")
+
+	keys := slices.Sorted(maps.Keys(obj))
+	fields := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		v := obj[k]
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else if f, err := n.Float64(); err == nil {
+				v = f
+			}
+		}
+		fields = append(fields, zap.Any(k, v))
+	}
+	return level, msg, fields, true
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWrapCmdLongLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "long line",
+			script: `head -c 150000 /dev/zero | tr '\0' a; printf '\nshort\n'`,
+			want:   []string{"65536 truncated", "65536 truncated", "18928", "5"},
+		},
+		{
+			name:   "no newline",
+			script: `head -c 140000 /dev/zero | tr '\0' a`,
+			want:   []string{"65536 truncated", "65536 truncated", "8928"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			cmd := exec.Command("sh", "-c", tt.script)
+			if err := l.WrapCmd(cmd); err != nil {
+				t.Fatal(err)
+			}
+			if err := cmd.Run(); err != nil {
+				t.Skipf("sh: %v", err)
+			}
+
+			var got []string
+			for _, e := range logs.All() {
+				entry := strconv.Itoa(len(e.Message))
+				if e.ContextMap()["truncated"] == true {
+					entry += " truncated"
+				}
+				got = append(got, entry)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("logged lines of %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseJSONLine(f *testing.F) {
+	f.Add([]byte(`{"level":"warn","msg":"disk low","free":12.5,"ts":1}`))
+	f.Add([]byte(`{"severity":"ERROR","message":"failed","n":9007199254740993,"nested":{"a":[1,{"b":null}]}}`))
+	f.Add([]byte(`  {"msg":5,"level":"nope"} trailing`))
+	f.Add([]byte(`{"a":` + strings.Repeat("[", 1000)))
+	f.Add([]byte("plain text \xff"))
+	f.Fuzz(func(t *testing.T, line []byte) {
+		level, msg, fields, ok := parseJSONLine(line)
+		if !ok {
+			return
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) == 0 || trimmed[0] != '{' {
+			t.Errorf("parsed %q, which isn't an object", line)
+		}
+		if level != nil && (*level < TraceLevel || *level > zapcore.FatalLevel) {
+			t.Errorf("parsed level %v from %q", *level, line)
+		}
+		for _, f := range fields {
+			if f.Key == "time" || f.Key == "ts" {
+				t.Errorf("kept %q from %q", f.Key, line)
+			}
+		}
+		_ = msg
+	})
+}
@@ -0,0 +1,382 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Collectors supported by Config.CollectorConfig
+const (
+	CollectorVector    = "vector"
+	CollectorFluentBit = "fluent-bit"
+	CollectorPromtail  = "promtail"
+)
+
+// collectorTimeLayouts are the timestamp layouts the encoder options
+// produce, which collector configs can parse
+var collectorTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.000Z0700",
+	"01/02/2006 3:04:05 PM",
+	"02/01/2006 15:04:05",
+	"02.01.2006 15:04:05",
+	"02-01-2006 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006. 01. 02. 15:04:05",
+}
+
+// collectorProbeTime is encoded to recognize the file's timestamp format
+var collectorProbeTime = time.Date(2009, time.November, 10, 23, 4, 5, 123456789, time.Local)
+
+// fileLayout describes how the file sink encodes entries, as needed by a
+// collector to parse them
+type fileLayout struct {
+	path string
+	keys zapcore.EncoderConfig
+
+	// timeLayout is the Go layout of string timestamps, or "" when they are
+	// numbers or unrecognized
+	timeLayout string
+	// epochUnit is the unit of numeric timestamps, or 0 for strings
+	epochUnit time.Duration
+	// durations describes how duration fields are written
+	durations string
+	// zone is the Config.TimeZone of timestamps, or nil for local time
+	zone *time.Location
+}
+
+// CollectorConfig writes a config snippet for collector (CollectorVector,
+// CollectorFluentBit, or CollectorPromtail) that tails the JSON file sink
+// described by c, parsing its entries with c's key names and timestamp
+// format, so collector configs need not be kept in sync by hand
+func (c Config) CollectorConfig(w io.Writer, collector string) error {
+	layout, err := c.fileLayout()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by go-logger for %s\n", layout.path)
+	fmt.Fprintf(&b, "# Durations are written as %s.\n", layout.durations)
+	switch collector {
+	case CollectorVector:
+		layout.vector(&b)
+	case CollectorFluentBit:
+		layout.fluentBit(&b)
+	case CollectorPromtail:
+		layout.promtail(&b)
+	default:
+		return fmt.Errorf("unsupported collector %q", collector)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// CollectorConfigFlag registers a -log-collector-config flag on fs. Call the
+// returned function with the final Config after parsing flags; if the flag
+// names a collector, it prints that collector's config to stdout and exits.
+func CollectorConfigFlag(fs *flag.FlagSet) func(Config) {
+	collector := fs.String("log-collector-config", "",
+		"print a collector config for the log file (vector, fluent-bit, or promtail) and exit")
+	return func(c Config) {
+		if *collector == "" {
+			return
+		}
+		if err := c.CollectorConfig(os.Stdout, *collector); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+}
+
+// fileLayout resolves how the file sink described by c encodes entries
+func (c Config) fileLayout() (*fileLayout, error) {
+	if !c.EnableFile || c.FilePath == "" {
+		return nil, errors.New("collector configs require the file sink")
+	}
+	if c.FileFormat != "" && c.FileFormat != FileFormatJSON {
+		return nil, fmt.Errorf("collector configs require JSON files, not %q", c.FileFormat)
+	}
+	if c.FileFraming != "" && c.FileFraming != FramingNewline && c.FileFraming != FramingCRLF {
+		return nil, fmt.Errorf("collector configs require newline-delimited files, not %q framing", c.FileFraming)
+	}
+	path, err := filepath.Abs(c.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve log file path: %w", err)
+	}
+	zone, err := resolveTimeZone(c.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	cfg := withTimeZone(applyEncoderOptions(fileEncoderConfigIn(zone), c.EncoderOptions, c.FileEncoderOptions), zone)
+	layout := &fileLayout{path: path, keys: cfg, zone: zone}
+
+	// Encode a probe entry and recognize the timestamp and duration formats
+	// from the output, since the encoder options only leave functions behind
+	probeKey := "duration_probe"
+	buf, err := zapcore.NewJSONEncoder(cfg).EncodeEntry(
+		zapcore.Entry{Time: collectorProbeTime},
+		[]zapcore.Field{zap.Duration(probeKey, 1500*time.Millisecond)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode probe entry: %w", err)
+	}
+	var probe map[string]any
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.UseNumber()
+	err = dec.Decode(&probe)
+	buf.Free()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode probe entry: %w", err)
+	}
+
+	switch t := probe[cfg.TimeKey].(type) {
+	case string:
+		probeTime := collectorProbeTime
+		if zone != nil {
+			probeTime = probeTime.In(zone)
+		}
+		for _, l := range collectorTimeLayouts {
+			if probeTime.Format(l) == t {
+				layout.timeLayout = l
+				break
+			}
+		}
+	case json.Number:
+		f, _ := t.Float64()
+		switch sec := float64(collectorProbeTime.Unix()); {
+		case math.Abs(f-sec) < 1:
+			layout.epochUnit = time.Second
+		case math.Abs(f-sec*1e3) < 1e3:
+			layout.epochUnit = time.Millisecond
+		case math.Abs(f-sec*1e6) < 1e6:
+			layout.epochUnit = time.Microsecond
+		default:
+			layout.epochUnit = time.Nanosecond
+		}
+	}
+
+	switch d := probe[probeKey].(type) {
+	case string:
+		layout.durations = "strings such as " + d
+	case json.Number:
+		switch d.String() {
+		case "1.5":
+			layout.durations = "seconds"
+		case "1500":
+			layout.durations = "milliseconds"
+		default:
+			layout.durations = "nanoseconds"
+		}
+	default:
+		layout.durations = "numbers"
+	}
+	return layout, nil
+}
+
+// zoneName names the zone of timestamps written without one, as Vector
+// does: "local" or an IANA name
+func (f *fileLayout) zoneName() string {
+	if f.zone == nil || f.zone == time.Local {
+		return "local"
+	}
+	return f.zone.String()
+}
+
+// hasKey reports whether key is written
+func hasKey(key string) bool {
+	return key != "" && key != zapcore.OmitKey
+}
+
+// vector writes a Vector file source and remap transform
+func (f *fileLayout) vector(b *strings.Builder) {
+	fmt.Fprintf(b, "sources:\n  go_logger_file:\n    type: file\n    include:\n      - %q\n", f.path)
+	b.WriteString("transforms:\n  go_logger_parse:\n    type: remap\n    inputs:\n      - go_logger_file\n    source: |\n")
+	b.WriteString("      . = parse_json!(string!(.message))\n")
+	if hasKey(f.keys.MessageKey) && f.keys.MessageKey != "message" {
+		fmt.Fprintf(b, "      .message = del(.%s)\n", vrlPath(f.keys.MessageKey))
+	}
+	if hasKey(f.keys.TimeKey) {
+		field := vrlPath(f.keys.TimeKey)
+		switch {
+		case f.timeLayout != "":
+			tz := ""
+			if !layoutHasZone(f.timeLayout) {
+				tz = fmt.Sprintf(", timezone: %q", f.zoneName())
+			}
+			fmt.Fprintf(b, "      .timestamp = parse_timestamp!(string!(del(.%s)), format: %q%s)\n",
+				field, strftimeLayout(f.timeLayout, false), tz)
+		case f.epochUnit != 0:
+			// Seconds are fractional, so they are converted to milliseconds
+			value, unit := "to_int!(del(.%s))", "milliseconds"
+			switch f.epochUnit {
+			case time.Second:
+				value = "to_int(float!(del(.%s)) * 1000)"
+			case time.Microsecond:
+				unit = "microseconds"
+			case time.Nanosecond:
+				unit = "nanoseconds"
+			}
+			fmt.Fprintf(b, "      .timestamp = from_unix_timestamp!("+value+", unit: %q)\n", field, unit)
+		default:
+			b.WriteString("      # The timestamp layout isn't recognized; it is kept as written\n")
+		}
+	}
+}
+
+// fluentBit writes a Fluent Bit tail input and the JSON parser it uses,
+// which belongs in the parsers file
+func (f *fileLayout) fluentBit(b *strings.Builder) {
+	fmt.Fprintf(b, "[INPUT]\n    Name   tail\n    Path   %s\n    Parser go_logger\n    Tag    go_logger\n\n", f.path)
+	b.WriteString("# In the parsers file:\n")
+	b.WriteString("[PARSER]\n    Name        go_logger\n    Format      json\n")
+	if hasKey(f.keys.TimeKey) && f.timeLayout != "" {
+		fmt.Fprintf(b, "    Time_Key    %s\n    Time_Format %s\n    Time_Keep   Off\n",
+			f.keys.TimeKey, strftimeLayout(f.timeLayout, true))
+		if !layoutHasZone(f.timeLayout) && f.zone != time.UTC {
+			fmt.Fprintf(b, "    # Timestamps are %s time without a zone; set Time_Offset to match\n", f.zoneName())
+		}
+	} else if hasKey(f.keys.TimeKey) {
+		b.WriteString("    # Timestamps are kept as written; the ingestion time is used\n")
+	}
+}
+
+// promtail writes a Promtail scrape config
+func (f *fileLayout) promtail(b *strings.Builder) {
+	b.WriteString("scrape_configs:\n  - job_name: go-logger\n    static_configs:\n      - targets: [localhost]\n")
+	fmt.Fprintf(b, "        labels:\n          job: go-logger\n          __path__: %q\n", f.path)
+	b.WriteString("    pipeline_stages:\n      - json:\n          expressions:\n")
+	var labels []string
+	for _, key := range []string{f.keys.TimeKey, f.keys.LevelKey, f.keys.NameKey, f.keys.MessageKey} {
+		if hasKey(key) {
+			fmt.Fprintf(b, "            %s: %q\n", promtailName(key), key)
+		}
+	}
+	for _, key := range []string{f.keys.LevelKey, f.keys.NameKey} {
+		if hasKey(key) {
+			labels = append(labels, promtailName(key))
+		}
+	}
+	if len(labels) > 0 {
+		b.WriteString("      - labels:\n")
+		for _, l := range labels {
+			fmt.Fprintf(b, "          %s:\n", l)
+		}
+	}
+	if !hasKey(f.keys.TimeKey) {
+		return
+	}
+	format := f.timeLayout
+	switch f.epochUnit {
+	case time.Second:
+		format = "Unix"
+	case time.Millisecond:
+		format = "UnixMs"
+	case time.Microsecond:
+		format = "UnixUs"
+	case time.Nanosecond:
+		format = "UnixNs"
+	}
+	if format == "" {
+		b.WriteString("      # The timestamp layout isn't recognized; the scrape time is used\n")
+		return
+	}
+	fmt.Fprintf(b, "      - timestamp:\n          source: %s\n          format: %q\n", promtailName(f.keys.TimeKey), format)
+	if f.epochUnit == 0 && !layoutHasZone(f.timeLayout) {
+		if f.zone != nil && f.zone != time.Local {
+			fmt.Fprintf(b, "          location: %q\n", f.zone.String())
+		} else {
+			b.WriteString("          # Timestamps are local time without a zone; set location to the\n")
+			b.WriteString("          # writer's IANA time zone\n")
+		}
+	}
+}
+
+// vrlPath quotes key for use as a VRL path segment when needed
+func vrlPath(key string) string {
+	for _, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Sprintf("%q", key)
+		}
+	}
+	return key
+}
+
+// promtailName turns key into a name usable for extracted values and labels
+func promtailName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.TrimLeft(key, "@"))
+}
+
+// layoutHasZone reports whether a Go time layout records the time zone
+func layoutHasZone(layout string) bool {
+	return strings.Contains(layout, "07") || strings.Contains(layout, "MST")
+}
+
+// strftimeLayout converts a Go time layout to strftime directives, in
+// Fluent Bit's dialect if fluentBit is set and Vector's (chrono) otherwise
+func strftimeLayout(layout string, fluentBit bool) string {
+	tokens := []struct{ gofmt, chrono, fluent string }{
+		{"2006", "%Y", "%Y"},
+		{".000000000", "%.9f", ".%L"},
+		{".999999999", "%.f", ".%L"},
+		{".000000", "%.6f", ".%L"},
+		{".000", "%.3f", ".%L"},
+		{"Z07:00", "%:z", "%z"},
+		{"-07:00", "%:z", "%z"},
+		{"Z0700", "%z", "%z"},
+		{"-0700", "%z", "%z"},
+		{"MST", "%Z", "%Z"},
+		{"01", "%m", "%m"},
+		{"02", "%d", "%d"},
+		{"15", "%H", "%H"},
+		{"03", "%I", "%I"},
+		{"04", "%M", "%M"},
+		{"05", "%S", "%S"},
+		{"PM", "%p", "%p"},
+		{"3", "%-I", "%I"},
+	}
+	var b strings.Builder
+	for len(layout) > 0 {
+		matched := false
+		for _, t := range tokens {
+			if strings.HasPrefix(layout, t.gofmt) {
+				if fluentBit {
+					b.WriteString(t.fluent)
+				} else {
+					b.WriteString(t.chrono)
+				}
+				layout = layout[len(t.gofmt):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if layout[0] == '%' {
+				b.WriteByte('%')
+			}
+			b.WriteByte(layout[0])
+			layout = layout[1:]
+		}
+	}
+	return b.String()
+}
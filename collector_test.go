@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectorConfig(t *testing.T) {
+	path := "/var/log/app.log"
+	file := func(opts ...EncoderOption) Config {
+		return Config{EnableFile: true, FilePath: path, FileEncoderOptions: opts}
+	}
+	tests := []struct {
+		name      string
+		config    Config
+		collector string
+		want      []string
+	}{
+		{"vector defaults", file(), CollectorVector, []string{
+			`- "/var/log/app.log"`,
+			`.message = del(.msg)`,
+			`.timestamp = parse_timestamp!(string!(del(.time)), format: "%Y-%m-%d %H:%M:%S", timezone: "local")`,
+			"# Durations are written as seconds.",
+		}},
+		{"vector epoch millis", file(WithEpochTime(time.Millisecond), WithMillisDurations()), CollectorVector, []string{
+			`.timestamp = from_unix_timestamp!(to_int!(del(.time)), unit: "milliseconds")`,
+			"# Durations are written as milliseconds.",
+		}},
+		{"vector renamed keys", file(WithRenamedKey("time", "@timestamp"), WithRFC3339NanoTime()), CollectorVector, []string{
+			`del(."@timestamp")`,
+			`format: "%Y-%m-%dT%H:%M:%S%.f%:z")`,
+		}},
+		{"fluent bit", Config{EnableFile: true, FilePath: path, TimeZone: "UTC"}, CollectorFluentBit, []string{
+			"Path   /var/log/app.log",
+			"Time_Key    time",
+			"Time_Format %Y-%m-%dT%H:%M:%S%z",
+		}},
+		{"promtail epoch", file(WithEpochTime(time.Second)), CollectorPromtail, []string{
+			`__path__: "/var/log/app.log"`,
+			`level: "level"`,
+			`format: "Unix"`,
+		}},
+		{"promtail zone", Config{EnableFile: true, FilePath: path, TimeZone: "Europe/Berlin", FileEncoderOptions: []EncoderOption{WithTimeLayout("2006-01-02 15:04:05")}}, CollectorPromtail, []string{
+			`format: "2006-01-02 15:04:05"`,
+			`location: "Europe/Berlin"`,
+		}},
+		{"human durations", file(WithHumanDurations()), CollectorPromtail, []string{
+			"# Durations are written as strings such as 1.5s.",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.config.CollectorConfig(&b, tt.collector); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("config is missing %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCollectorConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		collector string
+	}{
+		{"no file sink", Config{}, CollectorVector},
+		{"binary file", Config{EnableFile: true, FilePath: "app.log", FileFormat: FileFormatMsgpack}, CollectorVector},
+		{"length framing", Config{EnableFile: true, FilePath: "app.log", FileFraming: FramingLength}, CollectorVector},
+		{"unknown collector", Config{EnableFile: true, FilePath: "app.log"}, "logstash"},
+	}
+	for _, tt := range tests {
+		if err := tt.config.CollectorConfig(io.Discard, tt.collector); err == nil {
+			t.Errorf("%s: CollectorConfig succeeded", tt.name)
+		}
+	}
+}
+
+func TestStrftimeLayout(t *testing.T) {
+	tests := []struct {
+		layout, chrono, fluent string
+	}{
+		{"2006-01-02 15:04:05", "%Y-%m-%d %H:%M:%S", "%Y-%m-%d %H:%M:%S"},
+		{time.RFC3339Nano, "%Y-%m-%dT%H:%M:%S%.f%:z", "%Y-%m-%dT%H:%M:%S.%L%z"},
+		{"01/02/2006 3:04:05 PM", "%m/%d/%Y %-I:%M:%S %p", "%m/%d/%Y %I:%M:%S %p"},
+		{"2006-01-02T15:04:05.000Z0700", "%Y-%m-%dT%H:%M:%S%.3f%z", "%Y-%m-%dT%H:%M:%S.%L%z"},
+	}
+	for _, tt := range tests {
+		if got := strftimeLayout(tt.layout, false); got != tt.chrono {
+			t.Errorf("strftimeLayout(%q) = %q, want %q", tt.layout, got, tt.chrono)
+		}
+		if got := strftimeLayout(tt.layout, true); got != tt.fluent {
+			t.Errorf("strftimeLayout(%q, fluentBit) = %q, want %q", tt.layout, got, tt.fluent)
+		}
+	}
+}
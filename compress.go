@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Supported values for Config.FileCompression
+const (
+	// FileCompressionNone writes the file uncompressed (the default)
+	FileCompressionNone = "none"
+	// FileCompressionGzip writes the file as a gzip stream
+	FileCompressionGzip = "gzip"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// resolveFileCompression validates a Config.FileCompression value,
+// returning "" for none
+func resolveFileCompression(compression string) (string, error) {
+	switch compression {
+	case "", FileCompressionNone:
+		return "", nil
+	case FileCompressionGzip:
+		return compression, nil
+	default:
+		return "", fmt.Errorf("unsupported file compression %q", compression)
+	}
+}
+
+// gzipFile compresses everything written to a log file. Each time the file
+// is opened a new gzip member is appended, and concatenated members read
+// back as one stream, so restarts and reloads keep the file valid.
+type gzipFile struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// newGzipFile starts a gzip member at the end of file
+func newGzipFile(file *os.File) *gzipFile {
+	return &gzipFile{file: file, gz: gzip.NewWriter(file)}
+}
+
+// Write compresses p; it reaches the file on Sync or when the compressor's
+// buffer fills
+func (f *gzipFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.gz.Write(p)
+}
+
+// Sync flushes the compressed entries so far and syncs the file. A file
+// cut off after a Sync decompresses up to it.
+func (f *gzipFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.gz.Flush(); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close ends the gzip member and closes the file
+func (f *gzipFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.gz.Close()
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Name returns the file's path
+func (f *gzipFile) Name() string {
+	return f.file.Name()
+}
+
+// Stat describes the file, reporting its compressed size
+func (f *gzipFile) Stat() (os.FileInfo, error) {
+	return f.file.Stat()
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFileCompressionRoundTrip(t *testing.T) {
+	for _, format := range []string{FileFormatJSON, FileFormatMsgpack} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log.gz")
+			config := Config{Level: "info", EnableFile: true, FilePath: path, FileFormat: format, FileCompression: FileCompressionGzip}
+
+			// Each logger appends a gzip member
+			l := newBenchLogger(t, config)
+			l.Info("started")
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			l = newBenchLogger(t, config)
+			l.Info("resumed")
+			l.Warn(strings.Repeat("x", 100<<10))
+			if err := syncError(l.Sync()); err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(raw, gzipMagic) {
+				t.Fatal("file isn't gzip compressed")
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The open member has no trailer yet
+			plain, err := io.ReadAll(zr)
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("decompressing: %v", err)
+			}
+			if !bytes.Contains(plain, []byte("resumed")) {
+				t.Error("decompressed file lacks the entry synced before it was cut off")
+			}
+
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			msgs, err := readLogMessages(t, path, WithReadFormat(format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"started", "resumed", strings.Repeat("x", 100<<10)}; !slices.Equal(msgs, want) {
+				t.Errorf("read %d messages, want %d: %.40q", len(msgs), len(want), msgs)
+			}
+		})
+	}
+}
+
+func TestFileCompressionInvalid(t *testing.T) {
+	_, err := NewLogger(Config{EnableFile: true, FilePath: filepath.Join(t.TempDir(), "app.log"), FileCompression: "zstd"})
+	if err == nil {
+		t.Error("NewLogger accepted an unsupported compression")
+	}
+}
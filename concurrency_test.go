@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// countingCore counts the entries written to it
+type countingCore struct {
+	n *atomic.Int64
+}
+
+func (c countingCore) Enabled(zapcore.Level) bool        { return true }
+func (c countingCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c countingCore) Write(zapcore.Entry, []zapcore.Field) error {
+	c.n.Add(1)
+	return nil
+}
+func (c countingCore) Sync() error { return nil }
+
+func (c countingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+// TestConcurrentReconfiguration logs from several goroutines while others
+// reload, add sinks, change the level, and silence the logger. Every error
+// entry must reach the files exactly once, so none was written to a file
+// closed by a reload, and nothing logged after Close may. Run it with
+// -race.
+func TestConcurrentReconfiguration(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		// lossy is set when a full async queue drops entries
+		lossy bool
+	}{
+		{name: "sync"},
+		{name: "async", config: Config{Async: true}},
+		{name: "async drop", config: Config{Async: true, AsyncQueueSize: 16, AsyncDropOnFull: true}, lossy: true},
+		{name: "async caller", config: Config{Async: true, AsyncCaller: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := tt.config
+			config.Level = "info"
+			config.EnableFile = true
+			config.FilePath = filepath.Join(dir, "app.log")
+			l := newBenchLogger(t, config)
+
+			const entries = 200
+			var written atomic.Int64
+			var wg sync.WaitGroup
+			run := func(f func()) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					f()
+				}()
+			}
+			for i := range 4 {
+				run(func() {
+					child := l.WithField("worker", i)
+					for j := range entries {
+						child.Error("request failed", zap.Int("n", j))
+						switch j % 4 {
+						case 0:
+							l.Info("request handled", zap.Int("n", j))
+						case 1:
+							child.Warning("slow request", zap.Int("n", j))
+						case 2:
+							child.Sugar().Infow("request handled", "n", j)
+						default:
+							child.Debug("cache lookup", zap.Int("n", j))
+						}
+					}
+				})
+			}
+			run(func() {
+				for i := range 10 {
+					next := config
+					next.FilePath = filepath.Join(dir, "app.log")
+					if i%2 == 0 {
+						next.Format = FormatJSON
+						next.FilePath = filepath.Join(dir, "reloaded.log")
+					}
+					if err := l.Reload(next); err != nil {
+						t.Errorf("Reload: %v", err)
+					}
+				}
+			})
+			run(func() {
+				for range 5 {
+					l.AddSink(countingCore{n: &written})
+				}
+			})
+			run(func() {
+				for i := range 50 {
+					level := "info"
+					if i%2 == 0 {
+						level = "debug"
+					}
+					if err := l.SetLevel(level); err != nil {
+						t.Errorf("SetLevel: %v", err)
+					}
+				}
+			})
+			run(func() {
+				for range 50 {
+					restore := l.Silence(zapcore.InfoLevel, zapcore.WarnLevel)
+					l.Info("silenced")
+					restore()
+				}
+			})
+			wg.Wait()
+
+			if err := syncError(l.Sync()); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			before := written.Load()
+			l.Info("done")
+			if err := syncError(l.Sync()); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			// Entries queued in pipelines retired by Reload may still arrive
+			if got := written.Load() - before; got < 5 {
+				t.Errorf("added sinks wrote %d entries after Sync, want at least 5", got)
+			}
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			l.Error("request failed", zap.Int("worker", -1), zap.Int("n", 0))
+
+			seen := make(map[string]int)
+			for _, name := range []string{"app.log", "reloaded.log"} {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for line := range bytes.Lines(data) {
+					var e struct {
+						Msg    string `json:"msg"`
+						Worker int    `json:"worker"`
+						N      int    `json:"n"`
+					}
+					if err := json.Unmarshal(line, &e); err != nil {
+						t.Fatalf("%s holds %q: %v", name, line, err)
+					}
+					if e.Msg == "request failed" {
+						seen[fmt.Sprintf("%d/%d", e.Worker, e.N)]++
+					}
+				}
+			}
+			if seen["-1/0"] > 0 {
+				t.Error("an entry logged after Close was written")
+			}
+			for i := range 4 {
+				for j := range entries {
+					if n := seen[fmt.Sprintf("%d/%d", i, j)]; n > 1 || n == 0 && !tt.lossy {
+						t.Errorf("error %d of worker %d written %d times, want once", j, i, n)
+					}
+				}
+			}
+		})
+	}
+}
+
+// stallingCore stalls its first write until release is closed
+type stallingCore struct {
+	countingCore
+	stall   *atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c stallingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c stallingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.stall.CompareAndSwap(true, false) {
+		close(c.entered)
+		<-c.release
+	}
+	return c.countingCore.Write(ent, fields)
+}
+
+// TestReloadWaitsForStalledWrite checks that a pipeline replaced by Reload
+// is released only once a write stalled in it finishes, however long that
+// takes
+func TestReloadWaitsForStalledWrite(t *testing.T) {
+	config := Config{Level: "info", EnableFile: true, FilePath: filepath.Join(t.TempDir(), "app.log")}
+	l := newBenchLogger(t, config)
+	stalling := stallingCore{
+		countingCore: countingCore{n: &atomic.Int64{}},
+		stall:        &atomic.Bool{},
+		entered:      make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	stalling.stall.Store(true)
+	l.AddSink(stalling)
+
+	old := l.state.pipe.Load()
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		l.Info("stalled")
+	}()
+	<-stalling.entered
+
+	if err := l.Reload(config); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-old.res.released:
+		t.Fatal("the replaced pipeline was released during a write")
+	case <-time.After(1200 * time.Millisecond):
+	}
+
+	close(stalling.release)
+	<-written
+	select {
+	case <-old.res.released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replaced pipeline wasn't released after the write finished")
+	}
+	if err := old.wait(); err != nil {
+		t.Errorf("releasing the replaced pipeline: %v", err)
+	}
+}
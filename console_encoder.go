@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// consoleEncoder produces the same output as zapcore's console encoder, but
+// appends the entry header straight into the output buffer. zapcore boxes
+// each header element and prints it with fmt, which costs several
+// allocations per entry.
+type consoleEncoder struct {
+	// body renders the context fields, stack trace, and line ending
+	zapcore.Encoder
+	cfg zapcore.EncoderConfig
+}
+
+// newConsoleEncoder creates the encoder used by the default console format
+func newConsoleEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	if cfg.ConsoleSeparator == "" {
+		cfg.ConsoleSeparator = "\t"
+	}
+	body := cfg
+	body.TimeKey = zapcore.OmitKey
+	body.LevelKey = zapcore.OmitKey
+	body.NameKey = zapcore.OmitKey
+	body.CallerKey = zapcore.OmitKey
+	body.FunctionKey = zapcore.OmitKey
+	body.MessageKey = zapcore.OmitKey
+	return &consoleEncoder{Encoder: zapcore.NewConsoleEncoder(body), cfg: cfg}
+}
+
+// Clone copies the accumulated context fields
+func (e *consoleEncoder) Clone() zapcore.Encoder {
+	return &consoleEncoder{Encoder: e.Encoder.Clone(), cfg: e.cfg}
+}
+
+// EncodeEntry renders the header, then the body, indented for the entry's
+// group
+func (e *consoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	depth, boundary, fields := splitGroup(fields)
+	if boundary != nil {
+		return renderGroupBoundary(boundary, depth), nil
+	}
+	line := bufferPool.Get()
+
+	header := headerEncoderPool.Get().(*headerEncoder)
+	header.buf = line
+	header.sep = e.cfg.ConsoleSeparator
+	if e.cfg.TimeKey != "" && e.cfg.EncodeTime != nil {
+		e.cfg.EncodeTime(ent.Time, header)
+	}
+	if e.cfg.LevelKey != "" && e.cfg.EncodeLevel != nil {
+		e.cfg.EncodeLevel(ent.Level, header)
+	}
+	if ent.LoggerName != "" && e.cfg.NameKey != "" {
+		encodeName := e.cfg.EncodeName
+		if encodeName == nil {
+			encodeName = zapcore.FullNameEncoder
+		}
+		encodeName(ent.LoggerName, header)
+	}
+	if ent.Caller.Defined {
+		if e.cfg.CallerKey != "" && e.cfg.EncodeCaller != nil {
+			e.cfg.EncodeCaller(ent.Caller, header)
+		}
+		if e.cfg.FunctionKey != "" {
+			header.AppendString(ent.Caller.Function)
+		}
+	}
+	header.buf = nil
+	headerEncoderPool.Put(header)
+
+	if e.cfg.MessageKey != "" {
+		if line.Len() > 0 {
+			line.AppendString(e.cfg.ConsoleSeparator)
+		}
+		line.AppendString(ent.Message)
+	}
+
+	tbl, fields := splitTable(fields)
+	body, err := e.Encoder.EncodeEntry(zapcore.Entry{Stack: ent.Stack}, fields)
+	if err != nil {
+		bufferPool.put(line)
+		return nil, err
+	}
+	if body.Len() > 0 && body.Bytes()[0] == '{' && line.Len() > 0 {
+		line.AppendString(e.cfg.ConsoleSeparator)
+	}
+	_, _ = line.Write(body.Bytes())
+	body.Free()
+	if tbl != nil {
+		tbl.render(line, "    ")
+	}
+	return indentGroup(line, depth), nil
+}
+
+// headerEncoderPool reuses headerEncoders across entries
+var headerEncoderPool = sync.Pool{
+	New: func() any { return &headerEncoder{} },
+}
+
+// headerEncoder writes header elements to buf, separated by sep
+type headerEncoder struct {
+	buf *buffer.Buffer
+	sep string
+}
+
+// next writes the separator before every element but the first
+func (h *headerEncoder) next() {
+	if h.buf.Len() > 0 {
+		h.buf.AppendString(h.sep)
+	}
+}
+
+func (h *headerEncoder) AppendBool(v bool)         { h.next(); h.buf.AppendBool(v) }
+func (h *headerEncoder) AppendByteString(v []byte) { h.next(); _, _ = h.buf.Write(v) }
+func (h *headerEncoder) AppendComplex128(v complex128) {
+	h.next()
+	h.buf.AppendString(strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (h *headerEncoder) AppendComplex64(v complex64) {
+	h.next()
+	h.buf.AppendString(strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (h *headerEncoder) AppendFloat64(v float64) { h.next(); h.buf.AppendFloat(v, 64) }
+func (h *headerEncoder) AppendFloat32(v float32) { h.next(); h.buf.AppendFloat(float64(v), 32) }
+func (h *headerEncoder) AppendInt(v int)         { h.next(); h.buf.AppendInt(int64(v)) }
+func (h *headerEncoder) AppendInt64(v int64)     { h.next(); h.buf.AppendInt(v) }
+func (h *headerEncoder) AppendInt32(v int32)     { h.next(); h.buf.AppendInt(int64(v)) }
+func (h *headerEncoder) AppendInt16(v int16)     { h.next(); h.buf.AppendInt(int64(v)) }
+func (h *headerEncoder) AppendInt8(v int8)       { h.next(); h.buf.AppendInt(int64(v)) }
+func (h *headerEncoder) AppendString(v string)   { h.next(); h.buf.AppendString(v) }
+func (h *headerEncoder) AppendUint(v uint)       { h.next(); h.buf.AppendUint(uint64(v)) }
+func (h *headerEncoder) AppendUint64(v uint64)   { h.next(); h.buf.AppendUint(v) }
+func (h *headerEncoder) AppendUint32(v uint32)   { h.next(); h.buf.AppendUint(uint64(v)) }
+func (h *headerEncoder) AppendUint16(v uint16)   { h.next(); h.buf.AppendUint(uint64(v)) }
+func (h *headerEncoder) AppendUint8(v uint8)     { h.next(); h.buf.AppendUint(uint64(v)) }
+func (h *headerEncoder) AppendUintptr(v uintptr) { h.next(); h.buf.AppendUint(uint64(v)) }
+func (h *headerEncoder) AppendTimeLayout(t time.Time, layout string) {
+	h.next()
+	h.buf.AppendTime(t, layout)
+}
+
+// shortCallerEncoder is zapcore.ShortCallerEncoder, without allocating when
+// writing a console header
+func shortCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	h, ok := enc.(*headerEncoder)
+	if !ok || !caller.Defined {
+		zapcore.ShortCallerEncoder(caller, enc)
+		return
+	}
+	h.next()
+	h.buf.AppendString(trimCallerFile(caller.File))
+	h.buf.AppendByte(':')
+	h.buf.AppendInt(int64(caller.Line))
+}
+
+// trimCallerFile keeps the package directory and file name, as
+// EntryCaller.TrimmedPath does
+func trimCallerFile(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx == -1 {
+		return file
+	}
+	idx = strings.LastIndexByte(file[:idx], '/')
+	if idx == -1 {
+		return file
+	}
+	return file[idx+1:]
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// nestedValue wraps value in depth alternating maps and slices under key
+func nestedValue(key, value string, depth uint8) any {
+	var v any = value
+	for i := range min(int(depth), 64) {
+		if i%2 == 0 {
+			v = map[string]any{key: v}
+		} else {
+			v = []any{v, i}
+		}
+	}
+	return v
+}
+
+func FuzzConsoleEncoder(f *testing.F) {
+	f.Add(int8(zapcore.InfoLevel), "app.db", "request handled", "user", "alice", uint8(0), "")
+	f.Add(int8(TraceLevel), "", "", "", "", uint8(3), "")
+	f.Add(int8(100), "n\x00me", "café ‮\U0001F600", "k\"ey", "\xff\xfe", uint8(40), "goroutine 1 [running]:\n\tmain.go:7")
+	f.Add(int8(-100), "\t", "line\nbreak\r", "=", "\x1b[31mred", uint8(255), "\n")
+	encoders := []struct {
+		name string
+		enc  zapcore.Encoder
+	}{
+		{FormatConsole, newConsoleEncoder(consoleEncoderConfig())},
+		{FormatDev, newDevEncoder(consoleEncoderConfig())},
+	}
+	f.Fuzz(func(t *testing.T, level int8, name, msg, key, value string, depth uint8, stack string) {
+		ent := zapcore.Entry{
+			Level:      zapcore.Level(level),
+			Time:       time.Date(2024, 3, 10, 12, 30, 45, 0, time.UTC),
+			LoggerName: name,
+			Message:    msg,
+			Stack:      stack,
+			Caller:     zapcore.NewEntryCaller(0, "/src/"+name+"/main.go", 7, true),
+		}
+		fields := []zapcore.Field{
+			zap.String(key, value),
+			zap.Any(key, nestedValue(key, value, depth)),
+			zap.Binary(key, []byte(value)),
+			zap.Int8("level", level),
+		}
+		for _, e := range encoders {
+			enc := e.enc.Clone()
+			zap.String(value, key).AddTo(enc)
+			buf, err := enc.EncodeEntry(ent, fields)
+			if err != nil {
+				continue
+			}
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				t.Errorf("%s entry doesn't end a line: %q", e.name, buf.Bytes())
+			}
+			buf.Free()
+		}
+	})
+}
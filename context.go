@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the type of the context keys used by this package
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	fieldsContextKey
+	correlationContextKey
+)
+
+// nopLogger is returned by FromContext when no logger is attached
+var nopLogger = NewNop()
+
+// NewContext returns a copy of ctx carrying l
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// ContextWithFields returns a copy of ctx carrying fields in addition to any
+// already attached. Loggers obtained through FromContext or Ctx include them
+// automatically, so code deep in a call tree (a worker's ID, a shard) logs
+// them without receiving them explicitly.
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	existing := FieldsFromContext(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, resolveFields(fields)...)
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+// FieldsFromContext returns the fields attached to ctx
+func FieldsFromContext(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsContextKey).([]zap.Field)
+	return fields
+}
+
+// FromContext returns the logger attached to ctx, with the context's fields
+// applied. It returns a no-op logger if none is attached.
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerContextKey).(*Logger)
+	if !ok {
+		l = nopLogger
+	}
+	return l.Ctx(ctx)
+}
+
+// Ctx returns a logger carrying the fields attached to ctx
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.derive(l.Logger.With(fields...))
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestContextFields(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	ctx := ContextWithFields(context.Background(), zap.String("worker", "w1"))
+	child := ContextWithFields(ctx, zap.Int("shard", 3))
+	ctx = NewContext(ctx, l)
+	child = NewContext(child, l)
+	if ContextWithFields(ctx) != ctx {
+		t.Error("ContextWithFields without fields copied the context")
+	}
+
+	FromContext(ctx).Info("parent")
+	FromContext(child).Info("child")
+	l.Ctx(context.Background()).Info("bare")
+	FromContext(context.Background()).Info("no logger")
+
+	want := []map[string]any{
+		{"worker": "w1"},
+		{"worker": "w1", "shard": int64(3)},
+		{},
+	}
+	entries := logs.All()
+	if len(entries) != len(want) {
+		t.Fatalf("logged %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if got := e.ContextMap(); !maps.Equal(got, want[i]) {
+			t.Errorf("%s fields = %v, want %v", e.Message, got, want[i])
+		}
+	}
+}
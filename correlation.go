@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Headers carrying correlation IDs between services
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	RequestIDHeader     = "X-Request-ID"
+)
+
+// correlationIDKey is the field key for correlation IDs
+const correlationIDKey = "correlation_id"
+
+// maxCorrelationIDLength bounds incoming IDs, which are otherwise logged as
+// sent by the client
+const maxCorrelationIDLength = 128
+
+// NewCorrelationID returns a random version 4 UUID
+func NewCorrelationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// WithCorrelationID returns a logger adding id as the correlation_id field
+func (l *Logger) WithCorrelationID(id string) *Logger {
+	return l.derive(l.Logger.With(zap.String(correlationIDKey, id)))
+}
+
+// ContextWithCorrelationID returns a copy of ctx carrying id. Loggers
+// obtained through FromContext or Ctx log it as correlation_id, and
+// NewLoggingRoundTripper sends it on outgoing requests.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationContextKey, id)
+	return ContextWithFields(ctx, zap.String(correlationIDKey, id))
+}
+
+// CorrelationIDFromContext returns the correlation ID attached to ctx, or ""
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationContextKey).(string)
+	return id
+}
+
+// CorrelationMiddleware takes each request's correlation ID from its
+// X-Correlation-ID or X-Request-ID header, generating one with
+// Config.IDGenerator if neither holds a usable ID, and echoes it on the
+// response. The request context carries the ID and the logger, so handlers
+// log it through FromContext.
+func (l *Logger) CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := CorrelationIDHeader
+		id := r.Header.Get(CorrelationIDHeader)
+		if !validCorrelationID(id) {
+			header = RequestIDHeader
+			id = r.Header.Get(RequestIDHeader)
+		}
+		if !validCorrelationID(id) {
+			header = CorrelationIDHeader
+			id = l.NewID()
+		}
+		w.Header().Set(header, id)
+
+		ctx := ContextWithCorrelationID(r.Context(), id)
+		ctx = NewContext(ctx, l)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validCorrelationID reports whether an incoming ID is short and printable
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCorrelationMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantHeader string
+		wantID     string
+	}{
+		{"correlation header", map[string]string{CorrelationIDHeader: "abc-123"}, CorrelationIDHeader, "abc-123"},
+		{"request header", map[string]string{RequestIDHeader: "req-9"}, RequestIDHeader, "req-9"},
+		{"invalid correlation header falls back", map[string]string{CorrelationIDHeader: "bad id", RequestIDHeader: "req-9"}, RequestIDHeader, "req-9"},
+		{"too long", map[string]string{CorrelationIDHeader: strings.Repeat("a", maxCorrelationIDLength+1)}, CorrelationIDHeader, ""},
+		{"generated", nil, CorrelationIDHeader, ""},
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			var fromContext string
+			handler := l.CorrelationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = CorrelationIDFromContext(r.Context())
+				FromContext(r.Context()).Info("handled")
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(tt.wantHeader)
+			if tt.wantID != "" && id != tt.wantID {
+				t.Errorf("echoed %s = %q, want %q", tt.wantHeader, id, tt.wantID)
+			}
+			if tt.wantID == "" && !uuid.MatchString(id) {
+				t.Errorf("generated ID %q is not a version 4 UUID", id)
+			}
+			if fromContext != id {
+				t.Errorf("context ID = %q, want %q", fromContext, id)
+			}
+			if got := logs.All()[0].ContextMap()[correlationIDKey]; got != id {
+				t.Errorf("logged correlation_id = %v, want %q", got, id)
+			}
+		})
+	}
+}
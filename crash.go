@@ -0,0 +1,313 @@
+package logger
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultCrashRecent is how many recent entries a crash report holds
+	// by default
+	defaultCrashRecent = 200
+	// defaultCrashMaxReports is how many reports are kept by default
+	defaultCrashMaxReports = 10
+	// crashReportPrefix starts the name of each report file
+	crashReportPrefix = "crash-"
+)
+
+// CrashReportConfig enables crash reports: on a Panic or Fatal entry, or
+// a call to Logger.CrashReport, a JSON file is written holding the entry,
+// every goroutine's stack, runtime and memory statistics, build info, and
+// the entries logged just before, as a flight recorder for post-mortem
+// debugging. Reports may contain sensitive data and are created readable
+// by their owner only.
+type CrashReportConfig struct {
+	// Dir receives the reports. Defaults to the file sink's directory, or
+	// the system temporary directory without one.
+	Dir string
+	// RecentEntries is how many of the latest entries each report holds,
+	// at most Config.RecentEntries, which must be set for reports to hold
+	// any. Defaults to 200.
+	RecentEntries int
+	// MaxReports is how many reports are kept in Dir, the oldest removed
+	// first. Defaults to 10; negative keeps every report.
+	MaxReports int
+}
+
+// withDefaults fills in the defaults of c
+func (c CrashReportConfig) withDefaults(config Config) CrashReportConfig {
+	if c.Dir == "" {
+		if config.EnableFile && config.FilePath != "" {
+			c.Dir = filepath.Dir(config.FilePath)
+		} else {
+			c.Dir = os.TempDir()
+		}
+	}
+	if c.RecentEntries <= 0 {
+		c.RecentEntries = defaultCrashRecent
+	}
+	if c.MaxReports == 0 {
+		c.MaxReports = defaultCrashMaxReports
+	}
+	return c
+}
+
+// crashReporter writes the crash reports of a pipeline
+type crashReporter struct {
+	config CrashReportConfig
+	// recent holds the entries logged before a crash; nil when
+	// Config.RecentEntries disables it
+	recent *recentBuffer
+}
+
+// newCrashReporter creates the reporter for config, taking the recent
+// entries from recent
+func newCrashReporter(config Config, recent *recentBuffer) *crashReporter {
+	return &crashReporter{config: config.CrashReport.withDefaults(config), recent: recent}
+}
+
+// core returns the core writing reports on Panic and Fatal entries
+func (r *crashReporter) core() zapcore.Core {
+	return &crashCore{reporter: r, ctx: newMapEncoder()}
+}
+
+// crashReport is the content of a report file
+type crashReport struct {
+	Time       string         `json:"time"`
+	Reason     string         `json:"reason"`
+	Entry      crashEntry     `json:"entry"`
+	Goroutines string         `json:"goroutines"`
+	Runtime    map[string]any `json:"runtime"`
+	Memory     map[string]any `json:"memory"`
+	Build      map[string]any `json:"build,omitempty"`
+	Recent     []crashEntry   `json:"recent"`
+}
+
+// crashEntry is an entry as written to a report
+type crashEntry struct {
+	Time    string         `json:"time,omitempty"`
+	Level   string         `json:"level"`
+	Logger  string         `json:"logger,omitempty"`
+	Caller  string         `json:"caller,omitempty"`
+	Message string         `json:"msg"`
+	Stack   string         `json:"stack,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// newCrashEntry converts e for a report
+func newCrashEntry(e LogEntry) crashEntry {
+	c := crashEntry{
+		Level:   levelName(e.Level),
+		Logger:  e.Logger,
+		Caller:  e.Caller,
+		Message: e.Message,
+		Stack:   e.Stack,
+		Fields:  e.Fields,
+	}
+	if !e.Time.IsZero() {
+		c.Time = e.Time.Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+// write writes a report for entry, returning its path
+func (r *crashReporter) write(reason string, entry LogEntry) (string, error) {
+	now := time.Now()
+	report := crashReport{
+		Time:       now.Format(time.RFC3339Nano),
+		Reason:     reason,
+		Entry:      newCrashEntry(entry),
+		Goroutines: goroutineDump(),
+		Runtime:    crashRuntime(),
+		Memory:     crashMemory(),
+		Build:      crashBuild(),
+	}
+	if r.recent != nil {
+		for _, e := range r.recent.last(r.config.RecentEntries) {
+			report.Recent = append(report.Recent, newCrashEntry(e))
+		}
+	}
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode crash report: %w", err)
+	}
+
+	if err := os.MkdirAll(r.config.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+	name := crashReportPrefix + now.UTC().Format("20060102T150405.000000000Z") +
+		"-" + strconv.Itoa(os.Getpid()) + ".json"
+	path := filepath.Join(r.config.Dir, name)
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	r.prune()
+	return path, nil
+}
+
+// prune removes the oldest reports beyond MaxReports
+func (r *crashReporter) prune() {
+	if r.config.MaxReports < 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(r.config.Dir, crashReportPrefix+"*.json"))
+	if err != nil || len(matches) <= r.config.MaxReports {
+		return
+	}
+	// Names start with the UTC time, so they sort oldest first
+	slices.Sort(matches)
+	for _, path := range matches[:len(matches)-r.config.MaxReports] {
+		_ = os.Remove(path)
+	}
+}
+
+// goroutineDump returns the stacks of every goroutine
+func goroutineDump() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// crashRuntime describes the process and Go runtime
+func crashRuntime() map[string]any {
+	host, _ := os.Hostname()
+	return map[string]any{
+		"go_version":    runtime.Version(),
+		"os":            runtime.GOOS,
+		"arch":          runtime.GOARCH,
+		"pid":           os.Getpid(),
+		"hostname":      host,
+		"num_cpu":       runtime.NumCPU(),
+		"gomaxprocs":    runtime.GOMAXPROCS(0),
+		"num_goroutine": runtime.NumGoroutine(),
+	}
+}
+
+// crashMemory reports the runtime's memory statistics
+func crashMemory() map[string]any {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	memory := map[string]any{
+		"alloc_bytes":       m.Alloc,
+		"total_alloc_bytes": m.TotalAlloc,
+		"sys_bytes":         m.Sys,
+		"heap_alloc_bytes":  m.HeapAlloc,
+		"heap_inuse_bytes":  m.HeapInuse,
+		"heap_objects":      m.HeapObjects,
+		"stack_inuse_bytes": m.StackInuse,
+		"num_gc":            m.NumGC,
+		"gc_pause_total":    time.Duration(m.PauseTotalNs).String(),
+	}
+	if m.LastGC > 0 {
+		memory["last_gc"] = time.Unix(0, int64(m.LastGC)).Format(time.RFC3339Nano)
+	}
+	return memory
+}
+
+// crashBuild reports the binary's module and VCS information
+func crashBuild() map[string]any {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	build := map[string]any{
+		"path":       info.Path,
+		"main":       info.Main.Path + "@" + cmp.Or(info.Main.Version, "(devel)"),
+		"go_version": info.GoVersion,
+	}
+	settings := make(map[string]string)
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	if len(settings) > 0 {
+		build["settings"] = settings
+	}
+	var deps []string
+	for _, d := range info.Deps {
+		deps = append(deps, d.Path+"@"+d.Version)
+	}
+	if len(deps) > 0 {
+		build["deps"] = deps
+	}
+	return build
+}
+
+// crashCore writes a report on Panic and Fatal entries
+type crashCore struct {
+	reporter *crashReporter
+	ctx      mapEncoder
+}
+
+// Enabled reports whether level is Panic or Fatal
+func (c *crashCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.PanicLevel
+}
+
+// With returns a child core whose entries carry fields
+func (c *crashCore) With(fields []zapcore.Field) zapcore.Core {
+	return &crashCore{reporter: c.reporter, ctx: c.ctx.withFields(fields)}
+}
+
+// Check adds this core to the checked entry for Panic and Fatal
+func (c *crashCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes a report for the entry
+func (c *crashCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := newLogEntry(ent, c.ctx.withFields(fields).Fields)
+	if _, err := c.reporter.write(levelName(ent.Level), entry); err != nil {
+		writeFatalError("crash report", err)
+	}
+	return nil
+}
+
+// Sync does nothing; reports are written synchronously
+func (c *crashCore) Sync() error {
+	return nil
+}
+
+// CrashReport writes a crash report for err now, as Config.CrashReport
+// does on Panic and Fatal entries, and returns its path. Use it from a
+// recover handler or before an orderly shutdown after an unrecoverable
+// error. It fails unless Config.CrashReport is set.
+func (l *Logger) CrashReport(err error) (string, error) {
+	p := l.state.pipe.Load()
+	if p.res.crash == nil {
+		return "", errors.New("crash reports are not enabled")
+	}
+	entry := LogEntry{
+		Time:    l.now(),
+		Level:   zapcore.ErrorLevel,
+		Message: "crash report",
+	}
+	if err != nil {
+		entry.Message = err.Error()
+		entry.Fields = map[string]any{"error": err.Error()}
+	}
+	if _, file, line, ok := runtime.Caller(1); ok {
+		entry.Caller = zapcore.NewEntryCaller(0, file, line, true).TrimmedPath()
+	}
+	entry.Stack = strings.TrimSpace(string(debug.Stack()))
+	return p.res.crash.write("report", entry)
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// readCrashReport decodes the report at path
+func readCrashReport(t *testing.T, path string) crashReport {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report crashReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestCrashReport(t *testing.T) {
+	dir := t.TempDir()
+	l := newBenchLogger(t, Config{
+		Level:         "info",
+		RecentEntries: 10,
+		CrashReport:   &CrashReportConfig{Dir: dir, RecentEntries: 2},
+	})
+	l.Info("starting")
+	l.Info("loading", zap.String("file", "a.csv"))
+	l.Warn("retrying")
+
+	path, err := l.CrashReport(errors.New("out of disk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), crashReportPrefix) {
+		t.Errorf("report written to %s, want a crash- file in %s", path, dir)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("report mode %v, want 0600", mode)
+	}
+
+	report := readCrashReport(t, path)
+	if report.Reason != "report" || report.Entry.Message != "out of disk" || report.Entry.Fields["error"] != "out of disk" {
+		t.Errorf("report entry %+v for reason %q", report.Entry, report.Reason)
+	}
+	if !strings.Contains(report.Entry.Caller, "crash_test.go") {
+		t.Errorf("report caller %q, want the caller of CrashReport", report.Entry.Caller)
+	}
+	if !strings.Contains(report.Goroutines, "TestCrashReport") {
+		t.Error("report is missing the goroutine stacks")
+	}
+	if report.Runtime["pid"] != float64(os.Getpid()) || report.Memory["heap_alloc_bytes"] == nil {
+		t.Errorf("report runtime %v memory %v", report.Runtime, report.Memory)
+	}
+	if len(report.Recent) != 2 || report.Recent[0].Message != "loading" || report.Recent[1].Message != "retrying" {
+		t.Errorf("recent entries %+v, want the last two", report.Recent)
+	}
+	if report.Recent[0].Fields["file"] != "a.csv" {
+		t.Errorf("recent entry fields %v", report.Recent[0].Fields)
+	}
+}
+
+func TestCrashReportOnPanic(t *testing.T) {
+	dir := t.TempDir()
+	l := newBenchLogger(t, Config{Level: "info", CrashReport: &CrashReportConfig{Dir: dir}})
+	func() {
+		defer func() { _ = recover() }()
+		l.With(zap.String("job", "import")).Panic("invariant broken", zap.Int("row", 7))
+	}()
+
+	matches, err := filepath.Glob(filepath.Join(dir, crashReportPrefix+"*.json"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("reports %v: %v", matches, err)
+	}
+	report := readCrashReport(t, matches[0])
+	if report.Reason != "panic" || report.Entry.Message != "invariant broken" {
+		t.Errorf("report %q entry %+v", report.Reason, report.Entry)
+	}
+	if report.Entry.Fields["job"] != "import" || report.Entry.Fields["row"] != float64(7) {
+		t.Errorf("report fields %v, want context and entry fields", report.Entry.Fields)
+	}
+	if report.Recent != nil {
+		t.Errorf("recent entries %v without Config.RecentEntries", report.Recent)
+	}
+}
+
+func TestCrashReportPrune(t *testing.T) {
+	dir := t.TempDir()
+	l := newBenchLogger(t, Config{CrashReport: &CrashReportConfig{Dir: dir, MaxReports: 2}})
+	var paths []string
+	for range 3 {
+		path, err := l.CrashReport(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Errorf("oldest report was kept: %v", err)
+	}
+	for _, path := range paths[1:] {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("recent report was removed: %v", err)
+		}
+	}
+}
+
+func TestCrashReportDisabled(t *testing.T) {
+	l := newBenchLogger(t, Config{})
+	if _, err := l.CrashReport(errors.New("boom")); err == nil {
+		t.Error("CrashReport succeeded without Config.CrashReport")
+	}
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials holds the secret material a remote sink authenticates with
+type Credentials struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// Apply sets the Authorization header on req, preferring a bearer token over basic auth
+func (c Credentials) Apply(req *http.Request) {
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "" || c.Password != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// CredentialProvider supplies sink credentials on demand so they can be
+// rotated without restarting the process
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to the CredentialProvider interface
+type CredentialProviderFunc func() (Credentials, error)
+
+// Credentials calls f
+func (f CredentialProviderFunc) Credentials() (Credentials, error) {
+	return f()
+}
+
+// StaticCredentials returns a provider that always yields c
+func StaticCredentials(c Credentials) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		return c, nil
+	})
+}
+
+// fileCredentials re-reads a credentials file whenever it changes on disk
+type fileCredentials struct {
+	path  string
+	basic bool
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	cached  Credentials
+}
+
+// FileTokenCredentials returns a provider that reads a bearer token from path.
+// The file is re-read whenever its modification time or size changes, so a
+// rotated token takes effect on the next request.
+func FileTokenCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+// FileBasicCredentials returns a provider that reads "username:password" from
+// path, re-reading it whenever the file changes
+func FileBasicCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path, basic: true}
+}
+
+// Credentials returns the current credentials from the file
+func (f *fileCredentials) Credentials() (Credentials, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to stat credentials file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.cached, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+
+	var creds Credentials
+	if f.basic {
+		user, pass, ok := strings.Cut(secret, ":")
+		if !ok {
+			return Credentials{}, fmt.Errorf("credentials file %s: expected username:password", f.path)
+		}
+		creds = Credentials{Username: user, Password: pass}
+	} else {
+		creds = Credentials{Token: secret}
+	}
+
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.cached = creds
+	return creds, nil
+}
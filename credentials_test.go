@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentialsApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		creds Credentials
+		want  string
+	}{
+		{name: "none"},
+		{name: "token", creds: Credentials{Token: "t0k"}, want: "Bearer t0k"},
+		{name: "basic", creds: Credentials{Username: "ops", Password: "pw"}, want: "Basic b3BzOnB3"},
+		{name: "token preferred", creds: Credentials{Token: "t0k", Username: "ops", Password: "pw"}, want: "Bearer t0k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "http://collector.example", nil)
+			tt.creds.Apply(req)
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(path string) CredentialProvider
+		contents []string
+		want     []Credentials
+		wantErr  string
+	}{
+		{
+			name:     "token",
+			provider: FileTokenCredentials,
+			contents: []string{"first\n", "second-token\n"},
+			want:     []Credentials{{Token: "first"}, {Token: "second-token"}},
+		},
+		{
+			name:     "basic",
+			provider: FileBasicCredentials,
+			contents: []string{"ops:pw\n", "ops:rotated\n"},
+			want:     []Credentials{{Username: "ops", Password: "pw"}, {Username: "ops", Password: "rotated"}},
+		},
+		{
+			name:     "basic without password",
+			provider: FileBasicCredentials,
+			contents: []string{"ops\n"},
+			wantErr:  "expected username:password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "secret")
+			p := tt.provider(path)
+			if _, err := p.Credentials(); err == nil {
+				t.Error("Credentials succeeded without a file")
+			}
+			mtime := time.Now()
+			for i, content := range tt.contents {
+				if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				// Rotations within the file system's timestamp resolution
+				// are told apart by size, or by the time set here
+				mtime = mtime.Add(time.Second)
+				if err := os.Chtimes(path, mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+				got, err := p.Credentials()
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Errorf("Credentials error %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil || got != tt.want[i] {
+					t.Errorf("Credentials after write %d = %+v, %v; want %+v", i, got, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
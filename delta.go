@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys reserved by FileFormatJSONDelta
+const (
+	// deltaKeyframeKey marks a complete entry, naming its stream
+	deltaKeyframeKey = "_k"
+	// deltaKey marks an entry relative to the previous one in its stream
+	deltaKey = "_d"
+	// deltaUnsetKey lists the keys of the previous entry a delta drops
+	deltaUnsetKey = "_u"
+)
+
+// deltaKeyframeInterval is how many entries a delta stream writes between
+// complete entries, bounding the damage of a lost line
+const deltaKeyframeInterval = 1000
+
+// deltaField is one key of an encoded JSON entry
+type deltaField struct {
+	key string
+	raw json.RawMessage
+}
+
+// deltaWriter rewrites each JSON entry written to it to contain only the
+// keys that changed since the previous one, plus the time and message.
+// Entries are rewritten and written under one lock, so their order in the
+// file is the order they were compared in.
+type deltaWriter struct {
+	out    zapcore.WriteSyncer
+	always map[string]bool
+	stream string
+
+	mu    sync.Mutex
+	prev  []deltaField
+	count int
+	buf   bytes.Buffer
+}
+
+// newDeltaWriter wraps out, always keeping the time and message keys of
+// cfg, naming its stream stream
+func newDeltaWriter(out zapcore.WriteSyncer, cfg zapcore.EncoderConfig, stream string) *deltaWriter {
+	return &deltaWriter{
+		out:    out,
+		always: map[string]bool{cfg.TimeKey: true, cfg.MessageKey: true},
+		stream: stream,
+	}
+}
+
+// Write writes p, one encoded entry, as a delta of the previous entry.
+// Lines that aren't JSON objects are written unchanged.
+func (w *deltaWriter) Write(p []byte) (int, error) {
+	fields, err := parseJSONFields(p)
+	if err != nil {
+		return w.out.Write(p)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keyframe := w.count%deltaKeyframeInterval == 0
+	w.count++
+
+	b := &w.buf
+	b.Reset()
+	b.WriteByte('{')
+	seen := make(map[string]bool, len(fields))
+	prev := make(map[string]json.RawMessage, len(w.prev))
+	for _, f := range w.prev {
+		prev[f.key] = f.raw
+	}
+	for _, f := range fields {
+		seen[f.key] = true
+		if !keyframe && !w.always[f.key] {
+			if old, ok := prev[f.key]; ok && bytes.Equal(old, f.raw) {
+				continue
+			}
+		}
+		writeDeltaField(b, f.key, f.raw)
+	}
+	if keyframe {
+		writeDeltaField(b, deltaKeyframeKey, mustMarshal(w.stream))
+	} else {
+		writeDeltaField(b, deltaKey, mustMarshal(w.stream))
+		var unset []string
+		for _, f := range w.prev {
+			if !seen[f.key] {
+				unset = append(unset, f.key)
+			}
+		}
+		if len(unset) > 0 {
+			writeDeltaField(b, deltaUnsetKey, mustMarshal(unset))
+		}
+	}
+	b.WriteString("}\n")
+	w.prev = fields
+
+	if _, err := w.out.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the output
+func (w *deltaWriter) Sync() error {
+	return w.out.Sync()
+}
+
+// writeDeltaField appends "key":raw to an object being written
+func writeDeltaField(b *bytes.Buffer, key string, raw []byte) {
+	if b.Len() > 1 {
+		b.WriteByte(',')
+	}
+	b.Write(mustMarshal(key))
+	b.WriteByte(':')
+	b.Write(raw)
+}
+
+// mustMarshal encodes strings and string slices, which can't fail
+func mustMarshal(v any) []byte {
+	raw, _ := json.Marshal(v)
+	return raw
+}
+
+// parseJSONFields splits a JSON object into its keys, in order
+func parseJSONFields(p []byte) ([]deltaField, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("not a JSON object")
+	}
+	var fields []deltaField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		fields = append(fields, deltaField{key: key, raw: raw})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// deltaState reconstructs the entries of a FileFormatJSONDelta file
+type deltaState struct {
+	streams map[string]map[string]any
+}
+
+// newDeltaState creates the state for reading a file from its start
+func newDeltaState() *deltaState {
+	return &deltaState{streams: make(map[string]map[string]any)}
+}
+
+// apply returns the complete entry for raw, as decoded from one line.
+// Entries without delta keys are returned as they are.
+func (s *deltaState) apply(raw map[string]any) (map[string]any, error) {
+	if stream, ok := raw[deltaKeyframeKey].(string); ok {
+		delete(raw, deltaKeyframeKey)
+		s.streams[stream] = raw
+		return maps.Clone(raw), nil
+	}
+	stream, ok := raw[deltaKey].(string)
+	if !ok {
+		return raw, nil
+	}
+	base, ok := s.streams[stream]
+	if !ok {
+		return nil, fmt.Errorf("delta entry for stream %s without a preceding keyframe", stream)
+	}
+	entry := maps.Clone(base)
+	if unset, ok := raw[deltaUnsetKey].([]any); ok {
+		for _, k := range unset {
+			if k, ok := k.(string); ok {
+				delete(entry, k)
+			}
+		}
+	}
+	for k, v := range raw {
+		if k != deltaKey && k != deltaUnsetKey {
+			entry[k] = v
+		}
+	}
+	s.streams[stream] = entry
+	return maps.Clone(entry), nil
+}
+
+// logcatDelta is Logcat for FileFormatJSONDelta
+func logcatDelta(w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+	state := newDeltaState()
+	enc := json.NewEncoder(w)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var raw map[string]any
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("malformed log entry: %w", err)
+			}
+			entry, err := state.apply(raw)
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func FuzzLogcatDelta(f *testing.F) {
+	seed := seedEntries(f, FileFormatJSONDelta)
+	f.Add(seed)
+	f.Add(seed[bytes.IndexByte(seed, '\n')+1:])
+	f.Add([]byte(`{"_k":"file","msg":"x"}` + "\n" + `{"_d":"file","_u":["msg",1]}` + "\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Logcat(io.Discard, bytes.NewReader(data), FileFormatJSONDelta)
+	})
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DeterministicEpoch is when the clock of Config.Deterministic starts.
+// With Config.Deterministic, entries are stamped by a clock starting at
+// DeterministicEpoch and advancing a millisecond per reading, IDs come
+// from SequentialIDs, the IDs correlating split and delta entries are
+// sequential too, and each sink writes fields sorted by key, so two runs
+// of the same code write the same bytes. Config.Clock and
+// Config.IDGenerator still apply when set. Entries must be logged in the
+// same order, so log from one goroutine; encrypted files and durations
+// measured by the code under test still vary.
+var DeterministicEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// deterministicStep is how far the clock of Config.Deterministic advances
+// per reading
+const deterministicStep = time.Millisecond
+
+// deterministic returns c with the clock and ID generator of
+// Config.Deterministic filled in
+func (c Config) deterministic() Config {
+	if c.Clock == nil {
+		c.Clock = NewStepClock(DeterministicEpoch, deterministicStep)
+	}
+	if c.IDGenerator == nil {
+		c.IDGenerator = SequentialIDs("id-")
+	}
+	return c
+}
+
+// stepClock advances by a fixed step each time it is read
+type stepClock struct {
+	start time.Time
+	step  time.Duration
+	reads atomic.Int64
+}
+
+// NewStepClock returns a clock for Config.Clock that reads start first and
+// advances by step on each reading after, for tests asserting timestamps.
+// Its tickers are standard tickers.
+func NewStepClock(start time.Time, step time.Duration) zapcore.Clock {
+	return &stepClock{start: start, step: step}
+}
+
+// Now returns start advanced by step for every earlier reading
+func (c *stepClock) Now() time.Time {
+	return c.start.Add(time.Duration(c.reads.Add(1)-1) * c.step)
+}
+
+// NewTicker returns a standard ticker
+func (c *stepClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+// SequentialIDs returns an IDGenerator for tests, generating prefix
+// followed by 000001, 000002, and so on
+func SequentialIDs(prefix string) IDGenerator {
+	var n atomic.Uint64
+	return func() string {
+		return fmt.Sprintf("%s%06d", prefix, n.Add(1))
+	}
+}
+
+// sortedCore sorts the fields a sink receives by key, within each run
+// between namespaces, for Config.Deterministic
+type sortedCore struct {
+	zapcore.Core
+}
+
+// With returns a child core, passing fields on sorted
+func (c *sortedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sortedCore{Core: c.Core.With(sortFields(fields))}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *sortedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with its fields sorted
+func (c *sortedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, sortFields(fields))
+}
+
+// sortFields returns a copy of fields stably sorted by key, keeping each
+// namespace in place so the fields after it stay inside it
+func sortFields(fields []zapcore.Field) []zapcore.Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	sorted := slices.Clone(fields)
+	start := 0
+	for i := 0; i <= len(sorted); i++ {
+		if i < len(sorted) && sorted[i].Type != zapcore.NamespaceType {
+			continue
+		}
+		slices.SortStableFunc(sorted[start:i], func(a, b zapcore.Field) int {
+			return cmp.Compare(a.Key, b.Key)
+		})
+		start = i + 1
+	}
+	return sorted
+}
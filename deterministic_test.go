@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDeterministic(t *testing.T) {
+	run := func() string {
+		path := filepath.Join(t.TempDir(), "app.log")
+		l := newBenchLogger(t, Config{
+			Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path,
+			Deterministic: true, EntryIDs: true,
+		})
+		l.With(zap.String("zone", "eu"), zap.String("app", "shop")).
+			Info("order", zap.Int("qty", 2), zap.String("id", l.NewID()), zap.Namespace("item"), zap.String("sku", "a1"), zap.Int("price", 5))
+		l.Warn("low stock")
+		if err := l.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+
+	first, second := run(), run()
+	if first != second {
+		t.Fatalf("runs differ:\n%s\n%s", first, second)
+	}
+	for _, want := range []string{
+		`"time":"2000-01-01 00:00:00"`,
+		// Context and entry fields each sorted, the namespace kept in place
+		`"app":"shop","zone":"eu","entry_id":"id-000002","id":"id-000001","qty":2,"item":{"price":5,"sku":"a1"}}`,
+		`"msg":"low stock","entry_id":"id-000003"}`,
+	} {
+		if !strings.Contains(first, want) {
+			t.Errorf("output is missing %s:\n%s", want, first)
+		}
+	}
+}
+
+func TestStepClock(t *testing.T) {
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := NewStepClock(start, time.Second)
+	for i := range 3 {
+		if got, want := clock.Now(), start.Add(time.Duration(i)*time.Second); !got.Equal(want) {
+			t.Errorf("reading %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSequentialIDs(t *testing.T) {
+	next := SequentialIDs("req-")
+	for _, want := range []string{"req-000001", "req-000002", "req-000003"} {
+		if got := next(); got != want {
+			t.Errorf("ID %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	fields := []zapcore.Field{
+		zap.Int("c", 1), zap.Int("a", 2), zap.Int("a", 3),
+		zap.Namespace("ns"), zap.Int("z", 4), zap.Int("b", 5),
+	}
+	var keys []string
+	for _, f := range sortFields(fields) {
+		keys = append(keys, f.Key)
+	}
+	if got, want := strings.Join(keys, ","), "a,a,c,ns,b,z"; got != want {
+		t.Errorf("sorted keys %s, want %s", got, want)
+	}
+	if fields[0].Key != "c" {
+		t.Error("sortFields changed its argument")
+	}
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// devEncoder renders the entry header like the console encoder and then
+// places each field on its own indented line, pretty-printing nested values
+type devEncoder struct {
+	mapEncoder
+	header zapcore.Encoder
+}
+
+// newDevEncoder creates the encoder used by the "dev" format
+func newDevEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	cfg.StacktraceKey = zapcore.OmitKey
+	return &devEncoder{
+		mapEncoder: newMapEncoder(),
+		header:     newConsoleEncoder(cfg),
+	}
+}
+
+// Clone copies the accumulated context fields
+func (e *devEncoder) Clone() zapcore.Encoder {
+	return &devEncoder{mapEncoder: e.clone(), header: e.header}
+}
+
+// EncodeEntry renders the header line followed by one line per field,
+// indented for the entry's group
+func (e *devEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	depth, boundary, fields := splitGroup(fields)
+	if boundary != nil {
+		return renderGroupBoundary(boundary, depth), nil
+	}
+	stack := ent.Stack
+	ent.Stack = ""
+
+	header, err := e.header.EncodeEntry(ent, nil)
+	if err != nil {
+		return nil, err
+	}
+	tbl, fields := splitTable(fields)
+	final := e.withFields(fields)
+
+	buf := bufferPool.Get()
+	_, _ = buf.Write(bytes.TrimRight(header.Bytes(), "\r\n"))
+	buf.AppendByte('\n')
+	bufferPool.put(header)
+
+	keys := make([]string, 0, len(final.Fields))
+	for k := range final.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.AppendString("    ")
+		buf.AppendString(cyan(k))
+		buf.AppendString(": ")
+		buf.AppendString(indentContinuation(prettyValue(final.Fields[k]), "      "))
+		buf.AppendByte('\n')
+	}
+
+	if tbl != nil {
+		tbl.render(buf, "    ")
+	}
+
+	if stack != "" {
+		buf.AppendString("    ")
+		buf.AppendString(red("stacktrace:"))
+		buf.AppendByte('\n')
+		for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+			if strings.HasPrefix(line, "\t") {
+				buf.AppendString("          ")
+				buf.AppendString(strings.TrimPrefix(line, "\t"))
+			} else {
+				buf.AppendString("      ")
+				buf.AppendString(white(line))
+			}
+			buf.AppendByte('\n')
+		}
+	}
+	return indentGroup(buf, depth), nil
+}
+
+// prettyValue formats a field value, indenting structured values as JSON
+func prettyValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		trimmed := strings.TrimSpace(val)
+		if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+			var decoded any
+			if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+				return marshalIndent(decoded, val)
+			}
+		}
+		return val
+	case nil:
+		return "null"
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		return marshalIndent(v, fmt.Sprintf("%+v", v))
+	}
+	return fmt.Sprint(v)
+}
+
+// marshalIndent pretty-prints v as JSON, returning fallback if that fails
+func marshalIndent(v any, fallback string) string {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fallback
+	}
+	return string(out)
+}
+
+// indentContinuation prefixes every line after the first with indent
+func indentContinuation(s, indent string) string {
+	if !strings.Contains(s, "\n") {
+		return s
+	}
+	return strings.ReplaceAll(s, "\n", "\n"+indent)
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// withoutColor disables colors until the test ends, as when the output
+// isn't a terminal
+func withoutColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+}
+
+func TestDevEncoder(t *testing.T) {
+	withoutColor(t)
+	enc := newDevEncoder(consoleEncoderConfig())
+	zap.String("service", "api").AddTo(enc)
+	ent := zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2024, 3, 10, 12, 30, 45, 0, time.UTC),
+		Message: "request failed",
+		Stack:   "main.handle\n\t/src/main.go:7",
+	}
+	buf, err := enc.EncodeEntry(ent, []zapcore.Field{
+		zap.Int("status", 502),
+		zap.Any("upstream", map[string]any{"host": "db", "ports": []int{5432, 5433}}),
+		zap.String("body", "line one\nline two"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields are sorted, one per line, with continuations and nested values
+	// indented under them
+	want := "2024-03-10 12:30:45\t[ERROR]\trequest failed\n" +
+		"    body: line one\n" +
+		"      line two\n" +
+		"    service: api\n" +
+		"    status: 502\n" +
+		"    upstream: {\n" +
+		"        \"host\": \"db\",\n" +
+		"        \"ports\": [\n" +
+		"          5432,\n" +
+		"          5433\n" +
+		"        ]\n" +
+		"      }\n" +
+		"    stacktrace:\n" +
+		"      main.handle\n" +
+		"          /src/main.go:7\n"
+	if got := buf.String(); got != want {
+		t.Errorf("dev entry:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"github.com/fatih/color"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// diagnosticsLoggerName is the logger name of self-diagnostic entries
+const diagnosticsLoggerName = "logger"
+
+// diagnostic is a note about the logger's own configuration, collected
+// during construction and emitted through the logger once it exists
+type diagnostic struct {
+	level  zapcore.Level
+	msg    string
+	fields []zap.Field
+}
+
+// diagnostics collects notes during construction
+type diagnostics []diagnostic
+
+// add records a diagnostic
+func (d *diagnostics) add(level zapcore.Level, msg string, fields ...zap.Field) {
+	*d = append(*d, diagnostic{level: level, msg: msg, fields: fields})
+}
+
+// emit writes the collected diagnostics through zl
+func (d diagnostics) emit(zl *zap.Logger) {
+	if len(d) == 0 {
+		return
+	}
+	zl = diagnosticsLogger(zl)
+	for _, diag := range d {
+		if ce := zl.Check(diag.level, diag.msg); ce != nil {
+			ce.Write(diag.fields...)
+		}
+	}
+}
+
+// diagnosticsLogger returns the logger self-diagnostic entries are written
+// through, derived from zl
+func diagnosticsLogger(zl *zap.Logger) *zap.Logger {
+	return zl.Named(diagnosticsLoggerName).WithOptions(zap.WithCaller(false))
+}
+
+// checkConfig records diagnostics for settings that are ignored or
+// degraded rather than rejected
+func checkConfig(config Config, d *diagnostics) {
+	if color.NoColor {
+		d.add(zapcore.DebugLevel, "colors disabled: output is not a TTY or NO_COLOR is set")
+	}
+	switch config.Format {
+	case "", FormatConsole, FormatDev, FormatJSON:
+	default:
+		d.add(zapcore.WarnLevel, "unknown format, using console",
+			zap.String("format", config.Format))
+	}
+	if (config.StacktraceLevel == "off" || config.StacktraceLevel == "none") &&
+		(config.StacktraceMaxFrames > 0 || config.StacktraceTrimPaths) {
+		d.add(zapcore.WarnLevel, "stacktrace options ignored: stack traces are disabled")
+	}
+	if config.RateLimit <= 0 && config.RateLimitBurst > 0 {
+		d.add(zapcore.WarnLevel, "RateLimitBurst ignored: RateLimit is not set")
+	}
+	if config.AggregateWindow < 0 {
+		d.add(zapcore.WarnLevel, "AggregateWindow ignored: negative window",
+			zap.Duration("aggregate_window", config.AggregateWindow))
+	}
+	if config.ErrorSampleWindow < 0 {
+		d.add(zapcore.WarnLevel, "ErrorSampleWindow ignored: negative window",
+			zap.Duration("error_sample_window", config.ErrorSampleWindow))
+	}
+	if !config.Async && (config.AsyncQueueSize > 0 || config.AsyncDropOnFull) {
+		d.add(zapcore.WarnLevel, "async options ignored: Async is not enabled")
+	}
+	if len(config.FileEncoderOptions) > 0 && !config.EnableFile {
+		d.add(zapcore.WarnLevel, "FileEncoderOptions ignored: EnableFile is not set")
+	}
+	if config.Network != nil && config.Network.TLS != nil && config.Network.Protocol == "udp" {
+		d.add(zapcore.WarnLevel, "network TLS ignored: not supported over UDP")
+	}
+	if config.FileSinkOptional && !config.EnableFile {
+		d.add(zapcore.WarnLevel, "FileSinkOptional ignored: EnableFile is not set")
+	}
+	if config.FilePath != "" && !config.EnableFile {
+		d.add(zapcore.WarnLevel, "FilePath ignored: EnableFile is not set",
+			zap.String("file_path", config.FilePath))
+	}
+}
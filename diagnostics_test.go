@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"clean", Config{Format: FormatJSON}, nil},
+		{"unknown format", Config{Format: "xml"}, []string{"unknown format, using console"}},
+		{"stacktrace disabled", Config{StacktraceLevel: "off", StacktraceMaxFrames: 5},
+			[]string{"stacktrace options ignored: stack traces are disabled"}},
+		{"burst without rate", Config{RateLimitBurst: 5},
+			[]string{"RateLimitBurst ignored: RateLimit is not set"}},
+		{"negative windows", Config{AggregateWindow: -time.Second, ErrorSampleWindow: -time.Second},
+			[]string{"AggregateWindow ignored: negative window", "ErrorSampleWindow ignored: negative window"}},
+		{"async off", Config{AsyncQueueSize: 10},
+			[]string{"async options ignored: Async is not enabled"}},
+		{"file off", Config{FilePath: "app.log", FileSinkOptional: true},
+			[]string{"FileSinkOptional ignored: EnableFile is not set", "FilePath ignored: EnableFile is not set"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d diagnostics
+			checkConfig(tt.config, &d)
+			core, logs := observer.New(zapcore.WarnLevel)
+			d.emit(zap.New(core))
+
+			entries := logs.All()
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d diagnostics, want %d: %v", len(entries), len(tt.want), entries)
+			}
+			for i, e := range entries {
+				if e.Message != tt.want[i] {
+					t.Errorf("diagnostic %d = %q, want %q", i, e.Message, tt.want[i])
+				}
+				if e.LoggerName != diagnosticsLoggerName {
+					t.Errorf("diagnostic %d logger = %q, want %q", i, e.LoggerName, diagnosticsLoggerName)
+				}
+				if e.Caller.Defined {
+					t.Errorf("diagnostic %d has a caller", i)
+				}
+			}
+		})
+	}
+}
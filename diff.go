@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DiffOption configures LogDiff
+type DiffOption func(*diffOptions)
+
+// diffOptions holds the settings of one LogDiff call
+type diffOptions struct {
+	redactor *Redactor
+}
+
+// WithDiffRedactor masks the old and new values of sensitive keys, and of
+// everything nested under them. Defaults to NewRedactor().
+func WithDiffRedactor(r *Redactor) DiffOption {
+	return func(o *diffOptions) {
+		o.redactor = r
+	}
+}
+
+// LogDiff logs the paths that differ between before and after, structs or
+// maps compared in their JSON form, as an info entry "<name> changed" with
+// a changes field mapping each path, such as "db.hosts[1]", to its old and
+// new values. Added paths have no old value and removed paths no new one.
+// Sensitive keys are redacted. Nothing is logged when nothing changed.
+func (l *Logger) LogDiff(name string, before, after any, opts ...DiffOption) {
+	o := diffOptions{redactor: NewRedactor()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	old, err := diffValue(before)
+	if err != nil {
+		l.logAt(zapcore.WarnLevel, colorText{}, name+" diff failed", []zap.Field{zap.Error(err)})
+		return
+	}
+	cur, err := diffValue(after)
+	if err != nil {
+		l.logAt(zapcore.WarnLevel, colorText{}, name+" diff failed", []zap.Field{zap.Error(err)})
+		return
+	}
+
+	changes := make(map[string]any)
+	diffPaths(changes, "", old, cur, false, o.redactor)
+	if len(changes) == 0 {
+		return
+	}
+	fields := []zap.Field{zap.Int("change_count", len(changes))}
+	fields = l.appendMapField(fields, "changes", changes)
+	l.logAt(zapcore.InfoLevel, colorText{}, name+" changed", fields)
+}
+
+// diffValue converts v to its JSON form: maps, slices, and scalars
+func diffValue(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+	return out, nil
+}
+
+// diffPaths records the differences between old and cur under path.
+// redacted is set below a sensitive key.
+func diffPaths(changes map[string]any, path string, old, cur any, redacted bool, r *Redactor) {
+	oldMap, oldIsMap := old.(map[string]any)
+	curMap, curIsMap := cur.(map[string]any)
+	if oldIsMap && curIsMap {
+		keys := slices.Collect(maps.Keys(oldMap))
+		for k := range curMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			o, inOld := oldMap[k]
+			c, inCur := curMap[k]
+			switch {
+			case !inOld:
+				recordChange(changes, child, nil, c, false, true, redacted || r.Redacts(k), r)
+			case !inCur:
+				recordChange(changes, child, o, nil, true, false, redacted || r.Redacts(k), r)
+			default:
+				diffPaths(changes, child, o, c, redacted || r.Redacts(k), r)
+			}
+		}
+		return
+	}
+
+	oldSlice, oldIsSlice := old.([]any)
+	curSlice, curIsSlice := cur.([]any)
+	if oldIsSlice && curIsSlice {
+		for i := range max(len(oldSlice), len(curSlice)) {
+			child := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(oldSlice):
+				recordChange(changes, child, nil, curSlice[i], false, true, redacted, r)
+			case i >= len(curSlice):
+				recordChange(changes, child, oldSlice[i], nil, true, false, redacted, r)
+			default:
+				diffPaths(changes, child, oldSlice[i], curSlice[i], redacted, r)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, cur) {
+		recordChange(changes, path, old, cur, true, true, redacted, r)
+	}
+}
+
+// recordChange adds the old and new values of path, masking them when
+// redacted and redacting sensitive keys nested in them otherwise
+func recordChange(changes map[string]any, path string, old, cur any, hasOld, hasCur, redacted bool, r *Redactor) {
+	if path == "" {
+		path = "."
+	}
+	change := make(map[string]any, 2)
+	if hasOld {
+		change["old"] = diffRedact(old, redacted, r)
+	}
+	if hasCur {
+		change["new"] = diffRedact(cur, redacted, r)
+	}
+	changes[path] = change
+}
+
+// diffRedact masks v when redacted, and otherwise its sensitive keys
+func diffRedact(v any, redacted bool, r *Redactor) any {
+	if redacted {
+		return RedactedValue
+	}
+	return r.Value("", v)
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type diffConfig struct {
+	Port     int      `json:"port"`
+	Hosts    []string `json:"hosts"`
+	Password string   `json:"password"`
+	Debug    bool     `json:"debug,omitempty"`
+}
+
+func TestLogDiff(t *testing.T) {
+	before := diffConfig{Port: 80, Hosts: []string{"a", "b"}, Password: "old"}
+	tests := []struct {
+		name  string
+		after any
+		want  map[string]any
+	}{
+		{"unchanged", before, nil},
+		{"changed values", diffConfig{Port: 8080, Hosts: []string{"a", "c", "d"}, Password: "old"}, map[string]any{
+			"port":     map[string]any{"old": float64(80), "new": float64(8080)},
+			"hosts[1]": map[string]any{"old": "b", "new": "c"},
+			"hosts[2]": map[string]any{"new": "d"},
+		}},
+		{"added and redacted", diffConfig{Port: 80, Hosts: []string{"a", "b"}, Password: "new", Debug: true}, map[string]any{
+			"password": map[string]any{"old": RedactedValue, "new": RedactedValue},
+			"debug":    map[string]any{"new": true},
+		}},
+		{"removed", map[string]any{"port": 80, "hosts": []string{"a"}, "password": "old"}, map[string]any{
+			"hosts[1]": map[string]any{"old": "b"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			l.LogDiff("config", before, tt.after)
+			if tt.want == nil {
+				if logs.Len() != 0 {
+					t.Errorf("logged %d entries for identical values", logs.Len())
+				}
+				return
+			}
+			if logs.Len() != 1 {
+				t.Fatalf("logged %d entries, want 1", logs.Len())
+			}
+			e := logs.All()[0]
+			fields := e.ContextMap()
+			if e.Message != "config changed" || fields["change_count"] != int64(len(tt.want)) {
+				t.Errorf("logged %q with %v changes", e.Message, fields["change_count"])
+			}
+			if !reflect.DeepEqual(fields["changes"], tt.want) {
+				t.Errorf("changes = %v, want %v", fields["changes"], tt.want)
+			}
+		})
+	}
+}
+
+func TestLogDiffUnencodable(t *testing.T) {
+	l := newBenchLogger(t, Config{})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+	l.LogDiff("config", func() {}, nil)
+	if logs.FilterMessage("config diff failed").Len() != 1 {
+		t.Errorf("logged %v, want a diff failure", logs.All())
+	}
+}
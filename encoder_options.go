@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderOption customizes an encoder configuration. Options are applied
+// after the defaults, in order, so later options win. The msgpack and CBOR
+// file formats honor key names but always write native timestamps,
+// durations, and lowercase levels.
+type EncoderOption func(*zapcore.EncoderConfig)
+
+// WithTimeLayout formats timestamps with a time.Format layout
+func WithTimeLayout(layout string) EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeTime = zapcore.TimeEncoderOfLayout(layout)
+	}
+}
+
+// WithRFC3339Time formats timestamps as RFC 3339 with the zone's offset,
+// such as 2006-01-02T15:04:05Z or 2006-01-02T15:04:05+02:00, so they
+// compare correctly across regions
+func WithRFC3339Time() EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeTime = zapcore.RFC3339TimeEncoder
+	}
+}
+
+// WithRFC3339NanoTime formats timestamps as RFC 3339 with nanoseconds
+func WithRFC3339NanoTime() EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	}
+}
+
+// WithEpochTime writes timestamps as time since the Unix epoch in units of
+// precision. Seconds and milliseconds are floating-point; other units, such
+// as time.Microsecond, are whole numbers.
+func WithEpochTime(precision time.Duration) EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		switch precision {
+		case time.Second:
+			cfg.EncodeTime = zapcore.EpochTimeEncoder
+		case time.Millisecond:
+			cfg.EncodeTime = zapcore.EpochMillisTimeEncoder
+		case time.Nanosecond:
+			cfg.EncodeTime = zapcore.EpochNanosTimeEncoder
+		default:
+			cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+				enc.AppendInt64(t.UnixNano() / int64(precision))
+			}
+		}
+	}
+}
+
+// WithRenamedKey renames the entry key currently named from, for example
+// WithRenamedKey("msg", "message") or WithRenamedKey("time", "@timestamp")
+// for Elasticsearch. Renaming to zapcore.OmitKey drops the key.
+func WithRenamedKey(from, to string) EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		for _, key := range []*string{
+			&cfg.MessageKey, &cfg.LevelKey, &cfg.TimeKey, &cfg.NameKey,
+			&cfg.CallerKey, &cfg.FunctionKey, &cfg.StacktraceKey,
+		} {
+			if *key == from {
+				*key = to
+				return
+			}
+		}
+	}
+}
+
+// WithDurationEncoder sets how duration fields are written
+func WithDurationEncoder(enc zapcore.DurationEncoder) EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeDuration = enc
+	}
+}
+
+// WithMillisDurations writes durations as whole milliseconds
+func WithMillisDurations() EncoderOption {
+	return WithDurationEncoder(zapcore.MillisDurationEncoder)
+}
+
+// WithUppercaseLevels writes levels as "INFO", "WARN", and so on, without
+// brackets or colors
+func WithUppercaseLevels() EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeLevel = uppercaseLevelEncoder
+	}
+}
+
+// WithLowercaseLevels writes levels as "info", "warn", and so on, without
+// brackets or colors
+func WithLowercaseLevels() EncoderOption {
+	return func(cfg *zapcore.EncoderConfig) {
+		cfg.EncodeLevel = lowercaseLevelEncoder
+	}
+}
+
+// WithHumanDurations writes durations as short strings such as "1m23s" or
+// "2.41ms", rounded to about three significant digits
+func WithHumanDurations() EncoderOption {
+	return WithDurationEncoder(func(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(humanizeDuration(d))
+	})
+}
+
+// WithLocaleTime formats timestamps with the date layout conventional for
+// the viewer's locale, taken from LC_ALL, LC_TIME, or LANG (for example
+// 01/02/2006 3:04:05 PM for en_US and 02.01.2006 15:04:05 for de_DE).
+// Unknown locales get 2006-01-02 15:04:05. Times are in the sink's time
+// zone, local unless Config.TimeZone or ConsoleTimeZone say otherwise.
+func WithLocaleTime() EncoderOption {
+	return WithTimeLayout(localeTimeLayout(currentLocale()))
+}
+
+// humanizeDuration rounds d to about three significant digits and formats it
+func humanizeDuration(d time.Duration) string {
+	abs := d.Abs()
+	switch {
+	case abs >= time.Minute:
+		d = d.Round(time.Second)
+	case abs >= 10*time.Second:
+		d = d.Round(100 * time.Millisecond)
+	case abs >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case abs >= 10*time.Millisecond:
+		d = d.Round(100 * time.Microsecond)
+	case abs >= time.Millisecond:
+		d = d.Round(10 * time.Microsecond)
+	case abs >= 10*time.Microsecond:
+		d = d.Round(100 * time.Nanosecond)
+	case abs >= time.Microsecond:
+		d = d.Round(10 * time.Nanosecond)
+	}
+	return d.String()
+}
+
+// currentLocale returns the locale used for times, from the environment
+func currentLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// localeTimeLayout returns the conventional timestamp layout for a locale
+// such as "en_US.UTF-8"
+func localeTimeLayout(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	lang, region, _ := strings.Cut(locale, "_")
+	switch {
+	case lang == "en" && (region == "US" || region == "PH"):
+		return "01/02/2006 3:04:05 PM"
+	case lang == "en" && region != "", lang == "fr", lang == "es", lang == "it", lang == "pt", lang == "el":
+		return "02/01/2006 15:04:05"
+	case lang == "de", lang == "ru", lang == "pl", lang == "cs", lang == "fi", lang == "nb", lang == "da", lang == "tr", lang == "uk":
+		return "02.01.2006 15:04:05"
+	case lang == "nl":
+		return "02-01-2006 15:04:05"
+	case lang == "ja", lang == "zh":
+		return "2006/01/02 15:04:05"
+	case lang == "ko":
+		return "2006. 01. 02. 15:04:05"
+	}
+	return "2006-01-02 15:04:05"
+}
+
+// uppercaseLevelEncoder is zapcore.CapitalLevelEncoder with trace support
+func uppercaseLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(strings.ToUpper(levelName(level)))
+}
+
+// applyEncoderOptions returns cfg with opts applied
+func applyEncoderOptions(cfg zapcore.EncoderConfig, opts ...[]EncoderOption) zapcore.EncoderConfig {
+	for _, list := range opts {
+		for _, opt := range list {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
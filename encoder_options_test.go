@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEncoderOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []EncoderOption
+		want map[string]any
+	}{
+		{"defaults", nil, map[string]any{
+			"time": "2024-03-01 12:30:45", "level": "info", "msg": "hello", "elapsed": 1.5,
+		}},
+		{"rfc3339", []EncoderOption{WithRFC3339Time()}, map[string]any{
+			"time": "2024-03-01T12:30:45Z",
+		}},
+		{"epoch millis", []EncoderOption{WithEpochTime(time.Millisecond)}, map[string]any{
+			"time": float64(1709296245000),
+		}},
+		{"epoch micros", []EncoderOption{WithEpochTime(time.Microsecond)}, map[string]any{
+			"time": float64(1709296245000000),
+		}},
+		{"renamed keys", []EncoderOption{WithRenamedKey("msg", "message"), WithRenamedKey("level", zapcore.OmitKey)}, map[string]any{
+			"message": "hello", "msg": nil, "level": nil,
+		}},
+		{"later option wins", []EncoderOption{WithUppercaseLevels(), WithLowercaseLevels()}, map[string]any{
+			"level": "info",
+		}},
+		{"uppercase levels", []EncoderOption{WithUppercaseLevels()}, map[string]any{
+			"level": "INFO",
+		}},
+		{"millis durations", []EncoderOption{WithMillisDurations()}, map[string]any{
+			"elapsed": float64(1500),
+		}},
+		{"human durations", []EncoderOption{WithHumanDurations()}, map[string]any{
+			"elapsed": "1.5s",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := zapcore.NewJSONEncoder(applyEncoderOptions(fileEncoderConfig(), tt.opts))
+			ent := zapcore.Entry{
+				Level:   zapcore.InfoLevel,
+				Time:    time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC),
+				Message: "hello",
+			}
+			buf, err := enc.EncodeEntry(ent, []zap.Field{zap.Duration("elapsed", 1500*time.Millisecond)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer buf.Free()
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, got[key], want, buf.Bytes())
+				}
+			}
+		})
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{83*time.Second + 400*time.Millisecond, "1m23s"},
+		{2412345 * time.Nanosecond, "2.41ms"},
+		{12345 * time.Millisecond, "12.3s"},
+		{-2412345 * time.Nanosecond, "-2.41ms"},
+		{512 * time.Nanosecond, "512ns"},
+	}
+	for _, tt := range tests {
+		if got := humanizeDuration(tt.in); got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleTimeLayout(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en_US.UTF-8", "01/02/2006 3:04:05 PM"},
+		{"en_GB.UTF-8", "02/01/2006 15:04:05"},
+		{"de_DE@euro", "02.01.2006 15:04:05"},
+		{"ja_JP", "2006/01/02 15:04:05"},
+		{"C", "2006-01-02 15:04:05"},
+		{"", "2006-01-02 15:04:05"},
+	}
+	for _, tt := range tests {
+		if got := localeTimeLayout(tt.locale); got != tt.want {
+			t.Errorf("localeTimeLayout(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
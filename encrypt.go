@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// encryptedPrefix starts each encrypted line, telling it apart from
+// entries written before encryption was enabled
+const encryptedPrefix = "gcm:"
+
+// FileEncryption configures the encryption of the file sink. Each entry is
+// sealed with AES-GCM under a random nonce and written as one line of
+// base64, so encrypted files still rotate, append, and follow line by line.
+// Entries are unreadable without the key: keep it outside the log
+// directory, such as in a secret store.
+type FileEncryption struct {
+	// Key is the AES key: 16, 24, or 32 bytes for AES-128, AES-192, or
+	// AES-256. In JSON it is base64.
+	Key []byte
+}
+
+// newFileCipher returns the AEAD sealing entries with key
+func newFileCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptWriter seals each entry written to it as one line
+type encryptWriter struct {
+	out  zapcore.WriteSyncer
+	aead cipher.AEAD
+}
+
+// newEncryptWriter wraps out, sealing entries with aead
+func newEncryptWriter(out zapcore.WriteSyncer, aead cipher.AEAD) *encryptWriter {
+	return &encryptWriter{out: out, aead: aead}
+}
+
+// Write seals p, one framed entry, and writes it as a line
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	nonce := make([]byte, w.aead.NonceSize(), w.aead.NonceSize()+len(p)+w.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return 0, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := w.aead.Seal(nonce, nonce, p, nil)
+
+	line := make([]byte, 0, len(encryptedPrefix)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	line = append(line, encryptedPrefix...)
+	line = base64.StdEncoding.AppendEncode(line, sealed)
+	line = append(line, '\n')
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the output
+func (w *encryptWriter) Sync() error {
+	return w.out.Sync()
+}
+
+// decryptLine opens one line of an encrypted file, returning the entry as
+// it was written. Lines without the encrypted prefix are returned as they
+// are.
+func decryptLine(aead cipher.AEAD, line []byte) ([]byte, error) {
+	sealed, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte(encryptedPrefix))
+	if !ok {
+		return line, nil
+	}
+	raw, err := base64.StdEncoding.AppendDecode(nil, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted entry: %w", err)
+	}
+	if len(raw) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted entry: too short")
+	}
+	nonce, ciphertext := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt entry: %w", err)
+	}
+	return plain, nil
+}
+
+// decryptReader reads the decrypted entries of an encrypted file
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	pending []byte
+}
+
+// NewDecryptReader returns a reader of the entries of a file written with
+// Config.Encryption, decrypted with key, as the file sink would have
+// written them unencrypted. Pass it to Logcat or NewBinaryDecoder, or copy
+// it to a file. Reading fails at the first entry that doesn't decrypt.
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newFileCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: bufio.NewReader(r), aead: aead}, nil
+}
+
+// Read returns decrypted bytes, decrypting the next line when the previous
+// one is used up
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		line, err := d.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			plain, derr := decryptLine(d.aead, line)
+			if derr != nil {
+				return 0, derr
+			}
+			d.pending = plain
+		}
+		if err != nil && len(d.pending) == 0 {
+			return 0, err
+		}
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
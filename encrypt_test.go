@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFileEncryptionKeySize(t *testing.T) {
+	for _, size := range []int{0, 15, 16, 24, 32, 33} {
+		valid := size == 16 || size == 24 || size == 32
+		key := make([]byte, size)
+		config := Config{EnableFile: true, FilePath: filepath.Join(t.TempDir(), "app.log"), Encryption: &FileEncryption{Key: key}}
+		if l, err := NewLogger(config); (err == nil) != valid {
+			t.Errorf("NewLogger with a %d-byte key: error %v, want error %v", size, err, !valid)
+		} else if err == nil {
+			l.Sync()
+		}
+		if _, err := NewDecryptReader(strings.NewReader(""), key); (err == nil) != valid {
+			t.Errorf("NewDecryptReader with a %d-byte key: error %v, want error %v", size, err, !valid)
+		}
+	}
+}
+
+func TestFileEncryptionRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	wrongKey := make([]byte, 32)
+
+	for _, format := range []string{FileFormatJSON, FileFormatMsgpack} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l := newBenchLogger(t, Config{Level: "info", EnableFile: true, FilePath: path, FileFormat: format, Encryption: &FileEncryption{Key: key}})
+			l.Info("card charged")
+			l.Error("card declined")
+			if err := syncError(l.Sync()); err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for line := range bytes.Lines(raw) {
+				if !bytes.HasPrefix(line, []byte(encryptedPrefix)) || bytes.Contains(line, []byte("card")) {
+					t.Errorf("line isn't encrypted: %q", line)
+				}
+			}
+
+			msgs, err := readLogMessages(t, path, WithReadFormat(format), WithDecryptionKey(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"card charged", "card declined"}; !slices.Equal(msgs, want) {
+				t.Errorf("decrypted messages %q, want %q", msgs, want)
+			}
+
+			r, err := NewDecryptReader(bytes.NewReader(raw), wrongKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.ReadAll(r); err == nil {
+				t.Error("decrypting with the wrong key succeeded")
+			}
+		})
+	}
+}
+
+// TestDecryptReaderPlainLines checks that lines written before encryption
+// was enabled pass through unchanged
+func TestDecryptReaderPlainLines(t *testing.T) {
+	key := make([]byte, 16)
+	var buf bytes.Buffer
+	buf.WriteString(`{"msg":"before"}` + "\n")
+	aead, err := newFileCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newEncryptWriter(zapcore.AddSync(&buf), aead)
+	if _, err := w.Write([]byte(`{"msg":"after"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewDecryptReader(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if want := `{"msg":"before"}` + "\n" + `{"msg":"after"}` + "\n"; err != nil || string(got) != want {
+		t.Errorf("decrypted %q, %v; want %q", got, err, want)
+	}
+}
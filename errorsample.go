@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxErrorSamples bounds the number of distinct errors tracked at once;
+// errors beyond it are written in full
+const maxErrorSamples = 10000
+
+// errorSampler writes the first of a run of identical errors in full and
+// counts the rest, reporting them in a rollup when the window ends
+type errorSampler struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	samples map[string]*errorSample
+	closed  bool
+}
+
+// errorSample is the first entry of a run and how often it repeated since
+type errorSample struct {
+	core    zapcore.Core
+	ent     zapcore.Entry
+	errText string
+	repeats uint64
+	last    time.Time
+}
+
+// newErrorSampler creates a sampler with the given window
+func newErrorSampler(window time.Duration) *errorSampler {
+	return &errorSampler{window: window, samples: make(map[string]*errorSample)}
+}
+
+// sample reports whether ent should be written in full. Repeats of an error
+// already sampled in the current window are counted instead.
+func (s *errorSampler) sample(key, errText string, core zapcore.Core, ent zapcore.Entry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return true
+	}
+	if e, ok := s.samples[key]; ok {
+		e.repeats++
+		e.last = ent.Time
+		return false
+	}
+	if len(s.samples) >= maxErrorSamples {
+		return true
+	}
+
+	e := &errorSample{core: core, ent: ent, errText: errText, last: ent.Time}
+	s.samples[key] = e
+	time.AfterFunc(s.window, func() { s.expire(key, e) })
+	return true
+}
+
+// expire ends e's window, writing its rollup unless a flush already did
+func (s *errorSampler) expire(key string, e *errorSample) {
+	s.mu.Lock()
+	if s.samples[key] != e {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.samples, key)
+	s.mu.Unlock()
+	e.rollup()
+}
+
+// flush ends every open window
+func (s *errorSampler) flush() {
+	s.mu.Lock()
+	samples := s.samples
+	s.samples = make(map[string]*errorSample)
+	s.mu.Unlock()
+
+	for _, e := range samples {
+		e.rollup()
+	}
+}
+
+// close flushes open windows; later errors are written in full
+func (s *errorSampler) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.flush()
+}
+
+// rollup reports how often the sampled error repeated, without its stack
+// or fields. Nothing is written if it didn't repeat.
+func (e *errorSample) rollup() {
+	if e.repeats == 0 {
+		return
+	}
+	summary := zapcore.Entry{
+		Level:      e.ent.Level,
+		Time:       e.last,
+		LoggerName: e.ent.LoggerName,
+		Message:    "error repeated " + strconv.FormatUint(e.repeats, 10) + " times",
+	}
+	fields := []zapcore.Field{
+		zap.String("sampled_msg", e.ent.Message),
+		zap.Uint64("repeats", e.repeats),
+		zap.Time("first_seen", e.ent.Time),
+		zap.Time("last_seen", e.last),
+	}
+	if e.errText != "" {
+		fields = append(fields, zap.String("error", e.errText))
+	}
+	if ce := e.core.Check(summary, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// errorSampleCore samples error entries that repeat the same level,
+// message, and error text. Other levels pass through.
+type errorSampleCore struct {
+	zapcore.Core
+	sampler *errorSampler
+}
+
+// With returns a child core sharing the same sampler
+func (c *errorSampleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &errorSampleCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *errorSampleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry unless it repeats an error sampled in this window
+func (c *errorSampleCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level != zapcore.ErrorLevel {
+		return c.Core.Write(ent, fields)
+	}
+	errText := errorText(fields)
+	key := ent.LoggerName + "|" + ent.Message + "|" + errText
+	if !c.sampler.sample(key, errText, c.Core, ent) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync writes pending rollups and then syncs the wrapped core
+func (c *errorSampleCore) Sync() error {
+	c.sampler.flush()
+	return c.Core.Sync()
+}
+
+// errorText joins the messages of the error fields among fields
+func errorText(fields []zapcore.Field) string {
+	var texts []string
+	for _, f := range fields {
+		if f.Type != zapcore.ErrorType {
+			continue
+		}
+		if err, ok := f.Interface.(error); ok && err != nil {
+			texts = append(texts, err.Error())
+		}
+	}
+	return strings.Join(texts, "; ")
+}
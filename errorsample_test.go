@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErrorSample(t *testing.T) {
+	type write struct {
+		level zapcore.Level
+		msg   string
+		err   error
+	}
+	timeout := errors.New("timeout")
+	tests := []struct {
+		name   string
+		writes []write
+		want   []string
+	}{
+		{"repeats rolled up", []write{
+			{zapcore.ErrorLevel, "query failed", timeout},
+			{zapcore.ErrorLevel, "query failed", timeout},
+			{zapcore.ErrorLevel, "query failed", timeout},
+		}, []string{"query failed", "error repeated 2 times"}},
+		{"single error has no rollup", []write{
+			{zapcore.ErrorLevel, "query failed", timeout},
+		}, []string{"query failed"}},
+		{"different errors", []write{
+			{zapcore.ErrorLevel, "query failed", timeout},
+			{zapcore.ErrorLevel, "query failed", errors.New("connection reset")},
+		}, []string{"query failed", "query failed"}},
+		{"other levels pass through", []write{
+			{zapcore.WarnLevel, "slow query", nil},
+			{zapcore.WarnLevel, "slow query", nil},
+		}, []string{"slow query", "slow query"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.DebugLevel)
+			core := &errorSampleCore{Core: obs, sampler: newErrorSampler(time.Hour)}
+			for _, w := range tt.writes {
+				var fields []zap.Field
+				if w.err != nil {
+					fields = append(fields, zap.Error(w.err))
+				}
+				core.Write(zapcore.Entry{Level: w.level, Message: w.msg, Time: time.Now()}, fields)
+			}
+			if err := core.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, e := range logs.All() {
+				got = append(got, e.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+			if rollups := logs.FilterMessage("error repeated 2 times").All(); len(rollups) == 1 {
+				fields := rollups[0].ContextMap()
+				if fields["sampled_msg"] != "query failed" || fields["repeats"] != uint64(2) || fields["error"] != "timeout" {
+					t.Errorf("rollup fields = %v", fields)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorSampleWindow(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	sampler := newErrorSampler(20 * time.Millisecond)
+	core := &errorSampleCore{Core: obs, sampler: sampler}
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "query failed", Time: time.Now()}
+	core.Write(ent, nil)
+	core.Write(ent, nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logs.FilterMessage("error repeated 1 times").Len() != 1 {
+		t.Fatalf("no rollup after the window: %v", logs.All())
+	}
+
+	core.Write(ent, nil)
+	if logs.FilterMessage("query failed").Len() != 2 {
+		t.Error("error after the window was not written in full")
+	}
+	sampler.close()
+	core.Write(ent, nil)
+	if logs.FilterMessage("query failed").Len() != 3 {
+		t.Error("error after close was sampled")
+	}
+}
@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EventSchema defines an event: its name, the level and message it is
+// logged with, and the fields it carries
+type EventSchema struct {
+	// Name identifies the event and is logged as the "event" field
+	Name string
+	// Level is the level the event is logged at. Defaults to info.
+	Level zapcore.Level
+	// Message is the entry message. Defaults to Name.
+	Message string
+	// Fields lists the fields the event may carry, in the order they are
+	// logged
+	Fields []EventField
+}
+
+// EventField is one field of an event
+type EventField struct {
+	Name string
+	// Required fields must be present in the payload, and non-zero in
+	// struct payloads
+	Required bool
+}
+
+// EventRegistry holds the event schemas shared by a service's loggers, so
+// every team logs the same event with the same field names
+type EventRegistry struct {
+	mu     sync.RWMutex
+	events map[string]EventSchema
+}
+
+// NewEventRegistry creates an empty registry
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{events: make(map[string]EventSchema)}
+}
+
+// Register adds schema to the registry
+func (r *EventRegistry) Register(schema EventSchema) error {
+	if schema.Name == "" {
+		return errors.New("event name is required")
+	}
+	seen := make(map[string]bool, len(schema.Fields))
+	for _, f := range schema.Fields {
+		if f.Name == "" || seen[f.Name] {
+			return fmt.Errorf("event %q: empty or duplicate field name %q", schema.Name, f.Name)
+		}
+		seen[f.Name] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[schema.Name]; ok {
+		return fmt.Errorf("event %q is already registered", schema.Name)
+	}
+	schema.Fields = slices.Clone(schema.Fields)
+	r.events[schema.Name] = schema
+	return nil
+}
+
+// RegisterStruct registers an event whose fields are taken from the struct
+// type of payload. Each exported field is named by its log tag, or its Go
+// name without one; a "required" option marks it required and "-" skips it:
+//
+//	type FileDownloaded struct {
+//		FileName string `log:"file_name,required"`
+//		Size     int64  `log:"file_size"`
+//	}
+func (r *EventRegistry) RegisterStruct(name string, level zapcore.Level, payload any) error {
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("event %q: payload must be a struct, got %T", name, payload)
+	}
+	schema := EventSchema{Name: name, Level: level}
+	for i := range t.NumField() {
+		if name, required, ok := eventFieldTag(t.Field(i)); ok {
+			schema.Fields = append(schema.Fields, EventField{Name: name, Required: required})
+		}
+	}
+	return r.Register(schema)
+}
+
+// Schema returns the schema registered under name
+func (r *EventRegistry) Schema(name string) (EventSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.events[name]
+	return s, ok
+}
+
+// Events returns the registered event names, sorted
+func (r *EventRegistry) Events() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.events))
+	for name := range r.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Event logs the event registered under name in Config.Events. payload is
+// a map[string]any or a struct tagged as for RegisterStruct. The entry
+// carries an "event" field and the payload's fields in schema order. If the
+// event is unknown or the payload doesn't match its schema, the entry is
+// still written, with the problem in a "schema_error" field, and the error
+// is returned.
+func (l *Logger) Event(name string, payload any) error {
+	registry := l.state.pipe.Load().config.Events
+	var schema EventSchema
+	ok := false
+	if registry != nil {
+		schema, ok = registry.Schema(name)
+	}
+	if !ok {
+		schema = EventSchema{Name: name}
+	}
+
+	level := schema.Level
+	if !l.Enabled(level) {
+		return nil
+	}
+	values, err := eventValues(payload)
+	var fields []zap.Field
+	if err == nil {
+		if ok {
+			fields, err = schema.fields(values)
+		} else {
+			err = fmt.Errorf("unknown event %q", name)
+		}
+	}
+	if err != nil {
+		fields = fields[:0]
+		for _, k := range sortedKeys(values) {
+			fields = append(fields, Any(k, values[k].value))
+		}
+		fields = append(fields, zap.String("schema_error", strings.ReplaceAll(err.Error(), "\n", "; ")))
+	}
+
+	msg := schema.Message
+	if msg == "" {
+		msg = schema.Name
+	}
+	fields = append([]zap.Field{zap.String("event", name)}, fields...)
+	l.logAt(level, colorText{}, msg, fields)
+	return err
+}
+
+// eventValue is a payload value and whether it is the zero value
+type eventValue struct {
+	value any
+	zero  bool
+}
+
+// fields orders values by the schema, checking required and unknown fields
+func (s EventSchema) fields(values map[string]eventValue) ([]zap.Field, error) {
+	var errs []error
+	fields := make([]zap.Field, 0, len(values))
+	known := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		known[f.Name] = true
+		v, ok := values[f.Name]
+		if !ok || (f.Required && v.zero) {
+			if f.Required {
+				errs = append(errs, fmt.Errorf("missing required field %q", f.Name))
+			}
+			continue
+		}
+		fields = append(fields, Any(f.Name, v.value))
+	}
+	for _, k := range sortedKeys(values) {
+		if !known[k] {
+			errs = append(errs, fmt.Errorf("unknown field %q", k))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("event %q: %w", s.Name, errors.Join(errs...))
+	}
+	return fields, nil
+}
+
+// eventValues extracts the named values of a map or struct payload
+func eventValues(payload any) (map[string]eventValue, error) {
+	values := make(map[string]eventValue)
+	if payload == nil {
+		return values, nil
+	}
+	if m, ok := payload.(map[string]any); ok {
+		for k, v := range m {
+			values[k] = eventValue{value: v, zero: v == nil}
+		}
+		return values, nil
+	}
+
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return values, fmt.Errorf("event payload must be a map or struct, got %T", payload)
+	}
+	for i := range v.NumField() {
+		if name, _, ok := eventFieldTag(v.Type().Field(i)); ok {
+			fv := v.Field(i)
+			values[name] = eventValue{value: fv.Interface(), zero: fv.IsZero()}
+		}
+	}
+	return values, nil
+}
+
+// eventFieldTag parses a struct field's log tag, reporting false for
+// unexported and skipped fields
+func eventFieldTag(f reflect.StructField) (name string, required, ok bool) {
+	if !f.IsExported() {
+		return "", false, false
+	}
+	tag := f.Tag.Get("log")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "required" {
+			required = true
+		}
+	}
+	return name, required, true
+}
+
+// sortedKeys returns the keys of values in order
+func sortedKeys(values map[string]eventValue) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fileDownloaded struct {
+	FileName string `log:"file_name,required"`
+	Size     int64  `log:"file_size"`
+	Mirror   string
+	internal string
+	Checksum string `log:"-"`
+}
+
+func TestEventRegistry(t *testing.T) {
+	r := NewEventRegistry()
+	if err := r.RegisterStruct("file_downloaded", zapcore.InfoLevel, &fileDownloaded{}); err != nil {
+		t.Fatal(err)
+	}
+	schema, ok := r.Schema("file_downloaded")
+	if !ok {
+		t.Fatal("file_downloaded is not registered")
+	}
+	want := []EventField{{Name: "file_name", Required: true}, {Name: "file_size"}, {Name: "Mirror"}}
+	if !slices.Equal(schema.Fields, want) {
+		t.Errorf("fields = %v, want %v", schema.Fields, want)
+	}
+
+	invalid := []struct {
+		name   string
+		schema EventSchema
+	}{
+		{"no name", EventSchema{}},
+		{"duplicate field", EventSchema{Name: "login", Fields: []EventField{{Name: "user"}, {Name: "user"}}}},
+		{"empty field", EventSchema{Name: "login", Fields: []EventField{{}}}},
+		{"registered twice", EventSchema{Name: "file_downloaded"}},
+	}
+	for _, tt := range invalid {
+		if err := r.Register(tt.schema); err == nil {
+			t.Errorf("%s: Register accepted %+v", tt.name, tt.schema)
+		}
+	}
+	if err := r.RegisterStruct("bad", zapcore.InfoLevel, "not a struct"); err == nil {
+		t.Error("RegisterStruct accepted a string payload")
+	}
+	if got := r.Events(); !slices.Equal(got, []string{"file_downloaded"}) {
+		t.Errorf("Events = %v", got)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	r := NewEventRegistry()
+	if err := r.RegisterStruct("file_downloaded", zapcore.InfoLevel, fileDownloaded{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(EventSchema{Name: "login_failed", Level: zapcore.WarnLevel, Message: "login failed",
+		Fields: []EventField{{Name: "user", Required: true}, {Name: "reason"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		event      string
+		payload    any
+		wantMsg    string
+		wantLevel  zapcore.Level
+		wantKeys   []string
+		wantSchema string
+	}{
+		{"struct", "file_downloaded", fileDownloaded{FileName: "a.zip", Size: 10}, "file_downloaded", zapcore.InfoLevel,
+			[]string{"event", "file_name", "file_size", "Mirror"}, ""},
+		{"map in schema order", "login_failed", map[string]any{"reason": "bad password", "user": "ana"}, "login failed", zapcore.WarnLevel,
+			[]string{"event", "user", "reason"}, ""},
+		{"missing required", "file_downloaded", fileDownloaded{Size: 10}, "file_downloaded", zapcore.InfoLevel,
+			[]string{"event", "Mirror", "file_name", "file_size", "schema_error"}, `missing required field "file_name"`},
+		{"unknown field", "login_failed", map[string]any{"user": "ana", "ip": "10.0.0.1"}, "login failed", zapcore.WarnLevel,
+			[]string{"event", "ip", "user", "schema_error"}, `unknown field "ip"`},
+		{"unknown event", "logout", map[string]any{"user": "ana"}, "logout", zapcore.InfoLevel,
+			[]string{"event", "user", "schema_error"}, `unknown event "logout"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Events: r})
+			obs, logs := observer.New(zapcore.DebugLevel)
+			l.AddSink(obs)
+
+			err := l.Event(tt.event, tt.payload)
+			if (err != nil) != (tt.wantSchema != "") {
+				t.Errorf("Event error = %v", err)
+			}
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Message != tt.wantMsg || e.Level != tt.wantLevel {
+				t.Errorf("logged %q at %v, want %q at %v", e.Message, e.Level, tt.wantMsg, tt.wantLevel)
+			}
+			var keys []string
+			for _, f := range e.Context {
+				keys = append(keys, f.Key)
+			}
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("field keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if got, _ := e.ContextMap()["schema_error"].(string); !strings.Contains(got, tt.wantSchema) {
+				t.Errorf("schema_error = %q, want it to mention %q", got, tt.wantSchema)
+			}
+		})
+	}
+}
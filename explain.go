@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Explain writes the fully resolved configuration to w, with defaults filled
+// in, without building the logger. It returns the error NewLogger would
+// return for an invalid level or format.
+func (c Config) Explain(w io.Writer) error {
+	level, err := parseLevel(c.Level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	if _, err := parseStacktraceLevel(c.StacktraceLevel); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	section := func(name string) { fmt.Fprintf(&b, "%s:\n", name) }
+	item := func(key string, value any) { fmt.Fprintf(&b, "  %-18s %v\n", key+":", value) }
+
+	consoleOutput, jsonOutput, err := resolveOutputs(c)
+	if err != nil {
+		return err
+	}
+
+	section("console")
+	item("output", consoleOutput)
+	item("level", levelName(level))
+	format := c.Format
+	switch format {
+	case "":
+		format = FormatConsole
+	case FormatConsole, FormatDev, FormatJSON:
+	default:
+		format = FormatConsole + " (unknown format " + fmt.Sprintf("%q", c.Format) + ")"
+	}
+	item("format", format)
+	consoleMultilineDefault := MultilineIndent
+	if c.Format == FormatJSON {
+		consoleMultilineDefault = MultilineEscape
+	}
+	consoleMultiline, err := resolveMultiline(c.ConsoleMultiline, consoleMultilineDefault)
+	if err != nil {
+		return err
+	}
+	item("multiline", consoleMultiline)
+	consoleFraming, err := resolveFraming(c.ConsoleFraming)
+	if err != nil {
+		return fmt.Errorf("console sink: %w", err)
+	}
+	item("framing", consoleFraming)
+	if n := len(c.EncoderOptions) + len(c.ConsoleEncoderOptions); n > 0 {
+		item("encoder options", n)
+	}
+
+	section("json output")
+	if jsonOutput != "" {
+		item("output", jsonOutput)
+		item("level", levelName(level))
+	} else {
+		item("enabled", false)
+	}
+
+	section("file")
+	if c.EnableFile {
+		fileFormat := c.FileFormat
+		if fileFormat == "" {
+			fileFormat = FileFormatJSON
+		}
+		if _, err := newFileEncoder(c.FileFormat, fileEncoderConfig()); err != nil {
+			return err
+		}
+		item("path", c.FilePath)
+		item("level", levelName(level))
+		item("format", fileFormat)
+		if c.FileSinkOptional {
+			item("optional", true)
+		}
+		fileMultiline, err := resolveMultiline(c.FileMultiline, MultilineEscape)
+		if err != nil {
+			return err
+		}
+		item("multiline", fileMultiline)
+		fileFraming, err := resolveFraming(c.FileFraming)
+		if err != nil {
+			return fmt.Errorf("file sink: %w", err)
+		}
+		if c.FileFraming == "" && isBinaryFormat(c.FileFormat) {
+			fileFraming = "none"
+		}
+		item("framing", fileFraming)
+		fileCompression, err := resolveFileCompression(c.FileCompression)
+		if err != nil {
+			return fmt.Errorf("file sink: %w", err)
+		}
+		item("compression", cmp.Or(fileCompression, FileCompressionNone))
+		if c.Encryption != nil {
+			if _, err := newFileCipher(c.Encryption.Key); err != nil {
+				return fmt.Errorf("file sink: %w", err)
+			}
+			item("encryption", fmt.Sprintf("AES-%d-GCM", len(c.Encryption.Key)*8))
+		}
+		if n := len(c.EncoderOptions) + len(c.FileEncoderOptions); n > 0 {
+			item("encoder options", n)
+		}
+	} else {
+		item("enabled", false)
+	}
+
+	section("session")
+	if c.Session != nil {
+		session, err := c.Session.withDefaults()
+		if err != nil {
+			return err
+		}
+		level, _ := parseLevel(session.Level)
+		item("dir", session.Dir)
+		item("level", levelName(level))
+		if session.MaxFiles < 0 {
+			item("max files", "unlimited")
+		} else {
+			item("max files", session.MaxFiles)
+		}
+		for _, class := range slices.Sorted(maps.Keys(session.Retention)) {
+			item("retention "+class, session.Retention[class])
+		}
+	} else {
+		item("enabled", false)
+	}
+
+	section("network")
+	if c.Network != nil {
+		n, err := c.Network.withDefaults()
+		if err != nil {
+			return err
+		}
+		format := n.Format
+		if format == "" {
+			format = FileFormatJSON
+		}
+		item("address", n.Protocol+"://"+n.Address)
+		item("level", levelName(level))
+		item("format", format)
+		item("framing", n.Framing)
+		item("tls", n.TLS != nil && n.Protocol == "tcp")
+		item("buffer size", n.BufferSize)
+		item("circuit breaker", c.Breaker != nil)
+		item("warmup", n.Warmup != nil)
+	} else {
+		item("enabled", false)
+	}
+
+	section("time zone")
+	if _, err := resolveTimeZone(c.TimeZone); err != nil {
+		return err
+	}
+	if _, err := resolveTimeZone(c.ConsoleTimeZone); err != nil {
+		return fmt.Errorf("console: %w", err)
+	}
+	zone := cmp.Or(c.TimeZone, "clock's zone")
+	item("sinks", zone)
+	item("console", cmp.Or(c.ConsoleTimeZone, zone))
+
+	section("last resort")
+	item("stderr", !c.DisableStderrFallback)
+
+	section("fatal")
+	if c.FatalAsError {
+		item("action", "log as error and return")
+	} else {
+		item("action", "flush and exit")
+	}
+	item("on fatal hook", c.OnFatal != nil)
+	if c.CrashReport != nil {
+		crash := c.CrashReport.withDefaults(c)
+		item("crash reports", crash.Dir)
+		item("recent entries", crash.RecentEntries)
+	} else {
+		item("crash reports", false)
+	}
+
+	section("stacktraces")
+	stackLevel := c.StacktraceLevel
+	if stackLevel == "" {
+		stackLevel = "error"
+	}
+	item("level", stackLevel)
+	if c.StacktraceMaxFrames > 0 {
+		item("max frames", c.StacktraceMaxFrames)
+	} else {
+		item("max frames", "unlimited")
+	}
+	item("trim paths", c.StacktraceTrimPaths)
+
+	section("async")
+	if c.Async {
+		size := c.AsyncQueueSize
+		if size <= 0 {
+			size = defaultAsyncQueueSize
+		}
+		item("queue size", size)
+		if c.AsyncDropOnFull {
+			item("when full", "drop debug/info/warn")
+		} else {
+			item("when full", "block")
+		}
+	} else {
+		item("enabled", false)
+	}
+	if c.AsyncCaller {
+		item("caller lookup", "when written")
+	}
+
+	section("rate limit")
+	if c.RateLimit > 0 {
+		burst := c.RateLimitBurst
+		if burst <= 0 {
+			burst = 1
+		}
+		item("per message", fmt.Sprintf("%g/s", float64(c.RateLimit)))
+		item("burst", burst)
+	} else {
+		item("enabled", false)
+	}
+
+	section("error samples")
+	if c.ErrorSampleWindow > 0 {
+		item("window", c.ErrorSampleWindow)
+	} else {
+		item("enabled", false)
+	}
+
+	section("aggregation")
+	if c.AggregateWindow > 0 {
+		item("window", c.AggregateWindow)
+	} else {
+		item("enabled", false)
+	}
+
+	section("provenance")
+	item("enabled", c.Provenance)
+
+	section("volume")
+	item("enabled", c.TrackVolume)
+
+	section("metrics")
+	item("forwarded", c.Metrics != nil)
+	item("tracked", c.TrackMetrics)
+
+	section("deterministic")
+	item("enabled", c.Deterministic)
+
+	section("clock skew")
+	if c.ClockSkew != nil {
+		interval := c.ClockSkew.Interval
+		if interval <= 0 {
+			interval = defaultSkewInterval
+		}
+		item("interval", interval)
+	} else {
+		item("enabled", false)
+	}
+
+	if len(c.SLOs) > 0 {
+		section("slos")
+		for _, slo := range c.SLOs {
+			t := newSLOTracker(slo)
+			item(slo.Name, fmt.Sprintf("objective %g over %s", slo.Objective, t.config.Window))
+		}
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// ExplainFlag registers a -log-explain flag on fs. Call the returned function
+// with the final Config after parsing flags; if the flag was set, it prints
+// the resolved configuration to stdout and exits.
+func ExplainFlag(fs *flag.FlagSet) func(Config) {
+	explain := fs.Bool("log-explain", false, "print the resolved logging configuration and exit")
+	return func(c Config) {
+		if !*explain {
+			return
+		}
+		if err := c.Explain(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+}
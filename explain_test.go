@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"defaults", Config{}, []string{
+			"output: stdout",
+			"format: console",
+			"action: flush and exit",
+			"max frames: unlimited",
+		}},
+		{"trace level", Config{Level: "trace", Format: FormatJSON}, []string{
+			"level: trace",
+			"format: json",
+		}},
+		{"unknown format", Config{Format: "xml"}, []string{
+			`format: console (unknown format "xml")`,
+		}},
+		{"file", Config{EnableFile: true, FilePath: "app.log", FileCompression: FileCompressionGzip}, []string{
+			"path: app.log",
+			"compression: gzip",
+		}},
+		{"async", Config{Async: true, AsyncDropOnFull: true}, []string{
+			"when full: drop debug/info/warn",
+		}},
+		{"rate limit", Config{RateLimit: 5}, []string{
+			"per message: 5/s",
+			"burst: 1",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tt.config.Explain(&b); err != nil {
+				t.Fatal(err)
+			}
+			var lines []string
+			for line := range strings.Lines(b.String()) {
+				lines = append(lines, strings.Join(strings.Fields(line), " "))
+			}
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("explanation is missing %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExplainInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"level", Config{Level: "verbose"}},
+		{"console output", Config{ConsoleOutput: "printer"}},
+		{"file format", Config{EnableFile: true, FileFormat: "csv"}},
+		{"file compression", Config{EnableFile: true, FileCompression: "zip"}},
+		{"encryption key", Config{EnableFile: true, Encryption: &FileEncryption{Key: make([]byte, 7)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Explain(io.Discard); err == nil {
+				t.Error("Explain accepted an invalid config")
+			}
+		})
+	}
+}
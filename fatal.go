@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// exitProcess ends the process after a fatal entry
+var exitProcess = os.Exit
+
+// fatalHook runs after a Fatal entry is written. It flushes every sink, so
+// async, aggregated, and network entries (including the fatal one) reach
+// their destination, then runs Config.OnFatal and exits. With
+// Config.FatalAsError it returns instead.
+type fatalHook struct {
+	pipe *atomic.Pointer[pipeline]
+}
+
+// OnWrite implements zapcore.CheckWriteHook
+func (h fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []zapcore.Field) {
+	p := h.pipe.Load()
+	if p.config.FatalAsError {
+		return
+	}
+	if p.res.acquire() {
+		if err := syncError(p.core.Sync()); err != nil {
+			writeFatalError("failed to flush before exit", err)
+		}
+		p.res.finish()
+	}
+	if p.config.OnFatal != nil {
+		runOnFatal(p.config.OnFatal, ce.Entry)
+	}
+	exitProcess(1)
+}
+
+// runOnFatal calls hook, reporting a panic instead of letting it stop the exit
+func runOnFatal(hook func(zapcore.Entry), ent zapcore.Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			writeFatalError("OnFatal panicked", fmt.Errorf("%v", r))
+		}
+	}()
+	hook(ent)
+}
+
+// writeFatalError reports a problem while exiting to lastResortOutput
+func writeFatalError(msg string, err error) {
+	lastResortMu.Lock()
+	defer lastResortMu.Unlock()
+	_, _ = io.WriteString(lastResortOutput, "logger: "+msg+": "+err.Error()+"\n")
+}
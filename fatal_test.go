@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFatal(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		onFatal   func(zapcore.Entry)
+		wantExit  bool
+		wantLevel string
+		wantErr   string
+	}{
+		{"exits", Config{}, nil, true, "fatal", ""},
+		{"async flushed before exit", Config{Async: true}, nil, true, "fatal", ""},
+		{"as error", Config{FatalAsError: true}, nil, false, "error", ""},
+		{"panicking hook", Config{}, func(zapcore.Entry) { panic("alert failed") }, true, "fatal",
+			"logger: OnFatal panicked: alert failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exits []int
+			prevExit := exitProcess
+			exitProcess = func(code int) { exits = append(exits, code) }
+			var stderr bytes.Buffer
+			prevOut := lastResortOutput
+			lastResortOutput = &stderr
+			t.Cleanup(func() {
+				exitProcess = prevExit
+				lastResortOutput = prevOut
+			})
+
+			var hooked []string
+			config := tt.config
+			config.EnableFile = true
+			config.FilePath = filepath.Join(t.TempDir(), "app.log")
+			config.OnFatal = func(ent zapcore.Entry) {
+				hooked = append(hooked, ent.Message)
+				if tt.onFatal != nil {
+					tt.onFatal(ent)
+				}
+			}
+			l := newBenchLogger(t, config)
+
+			// With an async sink the entry only reaches the file if the
+			// hook flushes before exiting, since exitProcess is stubbed
+			// and nothing else syncs before the file is read
+			l.Fatal("cannot start")
+			line := string(readOnlyLine(t, config.FilePath))
+			if !strings.Contains(line, `"level":"`+tt.wantLevel+`"`) || !strings.Contains(line, "cannot start") {
+				t.Errorf("file entry = %s, want a %s entry", line, tt.wantLevel)
+			}
+
+			if tt.wantExit {
+				if len(exits) != 1 || exits[0] != 1 {
+					t.Errorf("exit calls = %v, want [1]", exits)
+				}
+				if len(hooked) != 1 || hooked[0] != "cannot start" {
+					t.Errorf("OnFatal saw %q, want the fatal entry", hooked)
+				}
+			} else if len(exits) > 0 || len(hooked) > 0 {
+				t.Errorf("FatalAsError exited %v and ran OnFatal %d times", exits, len(hooked))
+			}
+			if got := strings.TrimSpace(stderr.String()); got != tt.wantErr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantErr)
+			}
+			l.Close(context.Background())
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldLogger is the subset of Logger most code logs through. Depend on it
+// instead of *Logger to substitute logmock.Mock in unit tests and assert
+// the calls made, without capturing output:
+//
+//	func NewService(log logger.FieldLogger) *Service
+//
+//	svc := NewService(appLogger.FieldLogger())
+type FieldLogger interface {
+	Trace(msg string, fields ...zap.Field)
+	Debug(msg string, fields ...zap.Field)
+	Info(msg string, fields ...zap.Field)
+	Warn(msg string, fields ...zap.Field)
+	Error(msg string, fields ...zap.Field)
+	// Enabled reports whether entries at level would be logged
+	Enabled(level zapcore.Level) bool
+	// WithField returns a FieldLogger adding key to every entry
+	WithField(key string, value any) FieldLogger
+	// WithFields returns a FieldLogger adding fields to every entry
+	WithFields(fields map[string]any) FieldLogger
+}
+
+// FieldLogger returns l as a FieldLogger. *Logger can't implement it
+// directly, as its WithField and WithFields return *Logger.
+func (l *Logger) FieldLogger() FieldLogger {
+	return fieldLogger{l.derive(l.Logger.WithOptions(zap.AddCallerSkip(1)))}
+}
+
+// fieldLogger adapts a Logger to FieldLogger. Its Logger skips the
+// adapter's frame when reporting callers.
+type fieldLogger struct {
+	l *Logger
+}
+
+// Trace logs at trace level
+func (f fieldLogger) Trace(msg string, fields ...zap.Field) { f.l.Trace(msg, fields...) }
+
+// Debug logs at debug level
+func (f fieldLogger) Debug(msg string, fields ...zap.Field) { f.l.Debug(msg, fields...) }
+
+// Info logs at info level
+func (f fieldLogger) Info(msg string, fields ...zap.Field) { f.l.Info(msg, fields...) }
+
+// Warn logs at warn level
+func (f fieldLogger) Warn(msg string, fields ...zap.Field) { f.l.Warn(msg, fields...) }
+
+// Error logs at error level
+func (f fieldLogger) Error(msg string, fields ...zap.Field) { f.l.Error(msg, fields...) }
+
+// Enabled reports whether entries at level would be logged
+func (f fieldLogger) Enabled(level zapcore.Level) bool { return f.l.Enabled(level) }
+
+// WithField returns a FieldLogger adding key to every entry
+func (f fieldLogger) WithField(key string, value any) FieldLogger {
+	return fieldLogger{f.l.WithField(key, value)}
+}
+
+// WithFields returns a FieldLogger adding fields to every entry
+func (f fieldLogger) WithFields(fields map[string]any) FieldLogger {
+	return fieldLogger{f.l.WithFields(fields)}
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFieldLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path})
+	var log FieldLogger = l.FieldLogger()
+
+	if log.Enabled(zapcore.DebugLevel) || !log.Enabled(zapcore.InfoLevel) {
+		t.Error("Enabled doesn't follow the logger's level")
+	}
+	log.Debug("skipped")
+	log.WithField("user", "ada").
+		WithFields(map[string]any{"attempt": 2}).
+		Warn("login failed", zap.String("reason", "expired"))
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(readOnlyLine(t, path), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["msg"] != "login failed" || entry["level"] != "warn" {
+		t.Errorf("entry %v", entry)
+	}
+	if entry["user"] != "ada" || entry["attempt"] != float64(2) || entry["reason"] != "expired" {
+		t.Errorf("entry fields %v, want every derived field", entry)
+	}
+	// The adapter's frame is skipped, for every level and derived logger
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "fieldlogger_test.go") {
+		t.Errorf("caller %q, want the test", caller)
+	}
+}
+
+func TestFieldLoggerTrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "trace", Format: FormatJSON, EnableFile: true, FilePath: path})
+	l.FieldLogger().Trace("wire bytes")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line := range strings.Lines(string(raw)) {
+		if strings.Contains(line, `"msg":"wire bytes"`) {
+			if !strings.Contains(line, `"level":"trace"`) || !strings.Contains(line, "fieldlogger_test.go") {
+				t.Errorf("trace entry %s", line)
+			}
+			return
+		}
+	}
+	t.Errorf("no trace entry in %s", raw)
+}
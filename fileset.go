@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// logFile is a log file opened by a pipeline
+type logFile interface {
+	Write(p []byte) (int, error)
+	Sync() error
+	Close() error
+	Name() string
+	Stat() (os.FileInfo, error)
+}
+
+// fileSet shares open log files by path between the loggers of a Registry,
+// so tenants writing to the same file use one descriptor. A nil fileSet
+// opens a private file per call.
+type fileSet struct {
+	mu    sync.Mutex
+	files map[string]*sharedFile
+	// sessions holds the session file created in each directory
+	sessions map[string]string
+}
+
+// sharedFile is a file held open while any pipeline references it
+type sharedFile struct {
+	logFile
+	set         *fileSet
+	key         string
+	compression string
+	refs        int
+}
+
+// newFileSet creates an empty file set
+func newFileSet() *fileSet {
+	return &fileSet{files: make(map[string]*sharedFile), sessions: make(map[string]string)}
+}
+
+// open opens path for appending with compression, reusing an open handle
+// when there is one
+func (s *fileSet) open(path, compression string) (logFile, error) {
+	if s == nil {
+		return openSinkFile(path, compression)
+	}
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve log file path: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.files[key]; ok {
+		if f.compression != compression {
+			return nil, fmt.Errorf("%s is already open with another compression", path)
+		}
+		f.refs++
+		return f, nil
+	}
+	file, err := openSinkFile(path, compression)
+	if err != nil {
+		return nil, err
+	}
+	f := &sharedFile{logFile: file, set: s, key: key, compression: compression, refs: 1}
+	s.files[key] = f
+	return f, nil
+}
+
+// forget makes later opens of f's path open a new handle, leaving f open
+// for the pipelines already using it
+func (s *fileSet) forget(f logFile) {
+	sf, ok := f.(*sharedFile)
+	if s == nil || !ok {
+		return
+	}
+	s.mu.Lock()
+	if s.files[sf.key] == sf {
+		delete(s.files, sf.key)
+	}
+	s.mu.Unlock()
+}
+
+// Close releases one reference, closing the file when none remain
+func (f *sharedFile) Close() error {
+	s := f.set
+	s.mu.Lock()
+	f.refs--
+	last := f.refs == 0
+	if last && s.files[f.key] == f {
+		delete(s.files, f.key)
+	}
+	s.mu.Unlock()
+
+	if !last {
+		return nil
+	}
+	return f.logFile.Close()
+}
+
+// openSinkFile opens path for appending, compressing what is written with
+// compression
+func openSinkFile(path, compression string) (logFile, error) {
+	file, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if compression == FileCompressionGzip {
+		return newGzipFile(file), nil
+	}
+	return file, nil
+}
+
+// openLogFile opens path for appending, creating it if needed
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
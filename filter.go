@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// FilterRule drops the entries matching it below its level. Rules are set
+// with Config.FilterRules and can be replaced on a running logger with
+// SetFilterRules or the admin API. They only drop entries the logger's
+// level lets through, never enable more.
+type FilterRule struct {
+	// Logger matches entries of the named logger and the loggers named
+	// under it: "db" matches "db" and "db.pool". Empty matches every
+	// logger.
+	Logger string `json:"logger,omitempty"`
+	// Message matches entries whose message contains this text. Empty
+	// matches every message.
+	Message string `json:"message,omitempty"`
+	// Level is the minimum level of the matching entries kept, or "off" to
+	// drop them all
+	Level string `json:"level"`
+}
+
+// filterSet is a parsed list of filter rules
+type filterSet struct {
+	rules  []FilterRule
+	levels []zapcore.Level
+}
+
+// newFilterSet parses rules, returning nil if there are none
+func newFilterSet(rules []FilterRule) (*filterSet, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	s := &filterSet{rules: slices.Clone(rules), levels: make([]zapcore.Level, len(rules))}
+	for i, r := range rules {
+		if r.Level == "off" || r.Level == "none" {
+			s.levels[i] = zapcore.FatalLevel + 1
+			continue
+		}
+		level, err := parseLevel(r.Level)
+		if err != nil {
+			return nil, fmt.Errorf("filter rule %d: %w", i, err)
+		}
+		s.levels[i] = level
+	}
+	return s, nil
+}
+
+// drops reports whether ent is filtered out. The first rule matching an
+// entry decides; entries no rule matches are kept.
+func (s *filterSet) drops(ent zapcore.Entry) bool {
+	for i, r := range s.rules {
+		if r.matches(ent) {
+			return ent.Level < s.levels[i]
+		}
+	}
+	return false
+}
+
+// matches reports whether the rule applies to ent
+func (r FilterRule) matches(ent zapcore.Entry) bool {
+	if r.Logger != "" && ent.LoggerName != r.Logger && !strings.HasPrefix(ent.LoggerName, r.Logger+".") {
+		return false
+	}
+	return r.Message == "" || strings.Contains(ent.Message, r.Message)
+}
+
+// SetFilterRules replaces the filter rules of the logger and every logger
+// sharing its sinks. It is safe to call while other goroutines are
+// logging. A nil or empty list removes the rules.
+func (l *Logger) SetFilterRules(rules []FilterRule) error {
+	s, err := newFilterSet(rules)
+	if err != nil {
+		return err
+	}
+	l.state.filters.Store(s)
+	return nil
+}
+
+// FilterRules returns the current filter rules
+func (l *Logger) FilterRules() []FilterRule {
+	if s := l.state.filters.Load(); s != nil {
+		return slices.Clone(s.rules)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// logFilterProbes logs one entry per level to a few loggers and returns
+// the messages that got through, as "logger level message"
+func logFilterProbes(l *Logger, logs *observer.ObservedLogs) []string {
+	logs.TakeAll()
+	for _, name := range []string{"", "db", "db.pool", "dbx"} {
+		nl := l.Logger
+		if name != "" {
+			nl = nl.Named(name)
+		}
+		nl.Debug("tick")
+		nl.Info("tick")
+		nl.Info("health check ok")
+		nl.Warn("tick")
+	}
+	var got []string
+	for _, e := range logs.TakeAll() {
+		if e.LoggerName != diagnosticsLoggerName {
+			got = append(got, strings.TrimSpace(e.LoggerName+" "+e.Level.String()+" "+e.Message))
+		}
+	}
+	return got
+}
+
+// allFilterProbes are the probes an info sink receives without rules
+var allFilterProbes = []string{
+	"info tick", "info health check ok", "warn tick",
+	"db info tick", "db info health check ok", "db warn tick",
+	"db.pool info tick", "db.pool info health check ok", "db.pool warn tick",
+	"dbx info tick", "dbx info health check ok", "dbx warn tick",
+}
+
+func TestFilterRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []FilterRule
+		drop  []string
+	}{
+		{name: "no rules"},
+		{
+			name:  "logger and children",
+			rules: []FilterRule{{Logger: "db", Level: "warn"}},
+			drop:  []string{"db info tick", "db info health check ok", "db.pool info tick", "db.pool info health check ok"},
+		},
+		{
+			name:  "message",
+			rules: []FilterRule{{Message: "health check", Level: "off"}},
+			drop:  []string{"info health check ok", "db info health check ok", "db.pool info health check ok", "dbx info health check ok"},
+		},
+		{
+			name:  "first match decides",
+			rules: []FilterRule{{Logger: "db.pool", Level: "info"}, {Logger: "db", Level: "off"}},
+			drop:  []string{"db info tick", "db info health check ok", "db warn tick"},
+		},
+		{
+			name:  "lower level keeps everything",
+			rules: []FilterRule{{Logger: "dbx", Level: "debug"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info", FilterRules: tt.rules})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			want := slices.DeleteFunc(slices.Clone(allFilterProbes), func(s string) bool {
+				return slices.Contains(tt.drop, s)
+			})
+			if got := logFilterProbes(l, logs); !slices.Equal(got, want) {
+				t.Errorf("logged %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSetFilterRules(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	if err := l.SetFilterRules([]FilterRule{{Logger: "db", Level: "bogus"}}); err == nil {
+		t.Error("SetFilterRules accepted an invalid level")
+	}
+	if err := l.WithField("k", 1).SetFilterRules([]FilterRule{{Logger: "db", Level: "off"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, probe := range logFilterProbes(l, logs) {
+		if strings.HasPrefix(probe, "db ") || strings.HasPrefix(probe, "db.pool ") {
+			t.Errorf("logged %q after SetFilterRules", probe)
+		}
+	}
+
+	if err := l.Reload(Config{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+	if rules := l.FilterRules(); rules != nil {
+		t.Errorf("rules after Reload = %v, want none", rules)
+	}
+	if got := logFilterProbes(l, logs); !slices.Equal(got, allFilterProbes) {
+		t.Errorf("logged %q after Reload, want %q", got, allFilterProbes)
+	}
+}
+
+func TestAdminFilters(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	h := l.AdminHandler(StaticCredentials(Credentials{Token: "secret"}))
+	do := func(method, body string) (int, string) {
+		req := httptest.NewRequest(method, "/filters", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	tests := []struct {
+		method, body string
+		status       int
+		want         string
+	}{
+		{http.MethodGet, "", http.StatusOK, `{"rules":[]}`},
+		{http.MethodPut, `{"rules":[{"logger":"db","level":"warn"}]}`, http.StatusOK, `{"rules":[{"logger":"db","level":"warn"}]}`},
+		{http.MethodPut, `{"rules":[{"level":"loud"}]}`, http.StatusBadRequest, ""},
+		{http.MethodGet, "", http.StatusOK, `{"rules":[{"logger":"db","level":"warn"}]}`},
+		{http.MethodPut, `{"rules":[]}`, http.StatusOK, `{"rules":[]}`},
+	}
+	for _, tt := range tests {
+		status, body := do(tt.method, tt.body)
+		if status != tt.status || (tt.want != "" && body != tt.want) {
+			t.Errorf("%s %s: %d %s, want %d %s", tt.method, tt.body, status, body, tt.status, tt.want)
+		}
+	}
+}
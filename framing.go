@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported values for Config.ConsoleFraming, Config.FileFraming, and
+// NetworkConfig.Framing
+const (
+	// FramingNewline ends each entry with a newline, as expected by
+	// line-oriented collectors such as Logstash's json_lines codec (the
+	// default)
+	FramingNewline = "newline"
+	// FramingCRLF ends each entry with a carriage return and a newline, for
+	// Windows tools
+	FramingCRLF = "crlf"
+	// FramingNUL ends each entry with a NUL byte, so entries may contain
+	// raw line breaks
+	FramingNUL = "nul"
+	// FramingLength prefixes each entry with its length as a 4-byte
+	// big-endian integer
+	FramingLength = "length"
+)
+
+// resolveFraming validates framing, returning FramingNewline when it is
+// empty
+func resolveFraming(framing string) (string, error) {
+	switch framing {
+	case "":
+		return FramingNewline, nil
+	case FramingNewline, FramingCRLF, FramingNUL, FramingLength:
+		return framing, nil
+	}
+	return "", fmt.Errorf("invalid framing %q", framing)
+}
+
+// frameBuffer is what writeFrame writes to: a bytes.Buffer or zap's buffer
+type frameBuffer interface {
+	Write(p []byte) (int, error)
+	WriteByte(c byte) error
+}
+
+// writeFrame writes an encoded entry to dst in framing's format. The
+// trailing newline of text entries is replaced by the framing; binary
+// entries have none and are framed as they are.
+func writeFrame(dst frameBuffer, p []byte, framing string, binaryFormat bool) {
+	if !binaryFormat && len(p) > 0 && p[len(p)-1] == '\n' {
+		p = p[:len(p)-1]
+		if len(p) > 0 && p[len(p)-1] == '\r' {
+			p = p[:len(p)-1]
+		}
+	}
+	if framing == FramingLength {
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(p)))
+		_, _ = dst.Write(n[:])
+	}
+	_, _ = dst.Write(p)
+	switch framing {
+	case FramingNewline:
+		_ = dst.WriteByte('\n')
+	case FramingCRLF:
+		_, _ = dst.Write([]byte("\r\n"))
+	case FramingNUL:
+		_ = dst.WriteByte(0)
+	}
+}
+
+// framePool holds the buffers framedWriter frames entries in
+var framePool = buffer.NewPool()
+
+// framedWriter reframes each entry written to it, which the sink cores
+// write one per call
+type framedWriter struct {
+	out          zapcore.WriteSyncer
+	framing      string
+	binaryFormat bool
+}
+
+// newFramedWriter wraps out, returning it unchanged for newline framing of
+// text entries, which the encoders already produce
+func newFramedWriter(out zapcore.WriteSyncer, framing string, binaryFormat bool) zapcore.WriteSyncer {
+	if framing == FramingNewline && !binaryFormat {
+		return out
+	}
+	return &framedWriter{out: out, framing: framing, binaryFormat: binaryFormat}
+}
+
+// Write writes p as one framed entry
+func (w *framedWriter) Write(p []byte) (int, error) {
+	buf := framePool.Get()
+	defer buf.Free()
+	writeFrame(buf, p, w.framing, w.binaryFormat)
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the output
+func (w *framedWriter) Sync() error {
+	return w.out.Sync()
+}
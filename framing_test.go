@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		framing string
+		binary  bool
+		want    string
+	}{
+		{"newline", "{}\n", FramingNewline, false, "{}\n"},
+		{"crlf", "{}\n", FramingCRLF, false, "{}\r\n"},
+		{"crlf input", "{}\r\n", FramingNUL, false, "{}\x00"},
+		{"length", "{}\n", FramingLength, false, "\x00\x00\x00\x02{}"},
+		{"binary keeps trailing newline byte", "\x81\n", FramingLength, true, "\x00\x00\x00\x02\x81\n"},
+		{"binary newline", "\x81", FramingNewline, true, "\x81\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			writeFrame(&b, []byte(tt.in), tt.framing, tt.binary)
+			if got := b.String(); got != tt.want {
+				t.Errorf("framed %q as %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+	if _, err := resolveFraming("tab"); err == nil {
+		t.Error("resolveFraming accepted an unknown framing")
+	}
+}
+
+func TestFileFraming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{EnableFile: true, FilePath: path, FileFraming: FramingNUL, FileMultiline: MultilineIndent})
+	l.Info("first\nsecond")
+	l.Info("third")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	if len(records) != 2 || !strings.Contains(records[0], `"msg":"first\n    second"`) || strings.Contains(string(data), "}\n") {
+		t.Errorf("file records = %q, want two NUL-terminated entries", records)
+	}
+}
@@ -4,12 +4,13 @@ go 1.24
 
 require (
 	github.com/fatih/color v1.13.0
+	github.com/mattn/go-isatty v0.0.14
 	go.uber.org/zap v1.24.0
+	golang.org/x/time v0.9.0
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.9 // indirect
-	github.com/mattn/go-isatty v0.0.14 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/multierr v1.6.0 // indirect
 	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// groupIndent indents console entries once per enclosing group
+const groupIndent = "  "
+
+// Group is a logger scoped to a section of work, started with Logger.Group.
+// On the console its entries are indented under a colored header, and End
+// prints a footer with the elapsed time. The other sinks get a group field
+// instead, holding the titles of the enclosing groups joined by " > ".
+type Group struct {
+	*Logger
+	title string
+	start time.Time
+	once  sync.Once
+}
+
+// groupName is the group field's value. It is recognized by the console
+// sink, which indents entries depth times instead of showing it.
+type groupName struct {
+	path  string
+	depth int
+}
+
+// String returns the group path written by the other sinks
+func (g groupName) String() string {
+	return g.path
+}
+
+// groupDepth carries a console entry's depth to the console encoders. It is
+// added as a skipped field, so other encoders ignore it.
+type groupDepth int
+
+// groupBoundary marks a group's header and footer entries, which the
+// console encoders render as section lines. It is added as a skipped field.
+type groupBoundary struct {
+	title   string
+	end     bool
+	elapsed time.Duration
+}
+
+// Group starts a group titled title nested in l's group, if any, printing
+// its header on the console. Call End when the section is done.
+func (l *Logger) Group(title string) *Group {
+	name := groupName{path: title, depth: 1}
+	if parent, ok := currentGroup(l.Logger.Core()); ok {
+		name = groupName{path: parent.path + " > " + title, depth: parent.depth + 1}
+	}
+	field := zap.Stringer("group", name)
+	zl := l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return withRootField(core, field, isGroupField)
+	}))
+	g := &Group{Logger: l.derive(zl), title: title, start: l.now()}
+	g.boundary("Group started", groupBoundary{title: title})
+	return g
+}
+
+// End prints the group's footer with the elapsed time. Later calls do
+// nothing; the group's logger stays usable.
+func (g *Group) End() {
+	g.once.Do(func() {
+		elapsed := g.now().Sub(g.start)
+		g.boundary("Group completed", groupBoundary{title: g.title, end: true, elapsed: elapsed},
+			zap.Duration("elapsed", elapsed))
+	})
+}
+
+// boundary writes a header or footer entry
+func (g *Group) boundary(msg string, b groupBoundary, fields ...zap.Field) {
+	fields = append(fields, zap.Field{Type: zapcore.SkipType, Interface: b})
+	g.Logger.WithOptions(zap.WithCaller(false)).Info(msg, fields...)
+}
+
+// currentGroup returns the group of a logger's core, if any
+func currentGroup(core zapcore.Core) (groupName, bool) {
+	if root := rootSwapCore(core); root != nil {
+		for _, f := range root.fields {
+			if isGroupField(f) {
+				return f.Interface.(groupName), true
+			}
+		}
+	}
+	return groupName{}, false
+}
+
+// isGroupField reports whether f is a group field
+func isGroupField(f zapcore.Field) bool {
+	_, ok := f.Interface.(groupName)
+	return ok && f.Type == zapcore.StringerType
+}
+
+// groupCore wraps the console sink, replacing the group field with the
+// depth its entries are indented by
+type groupCore struct {
+	zapcore.Core
+	depth int
+}
+
+// newGroupCore wraps the console sink core
+func newGroupCore(core zapcore.Core) zapcore.Core {
+	return &groupCore{Core: core}
+}
+
+// With returns a child core, taking the depth from a group field
+func (c *groupCore) With(fields []zapcore.Field) zapcore.Core {
+	depth := c.depth
+	kept := fields
+	for i, f := range fields {
+		if isGroupField(f) {
+			depth = f.Interface.(groupName).depth
+			kept = make([]zapcore.Field, 0, len(fields)-1)
+			kept = append(kept, fields[:i]...)
+			kept = append(kept, fields[i+1:]...)
+			break
+		}
+	}
+	return &groupCore{Core: c.Core.With(kept), depth: depth}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *groupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write passes the depth to the encoder
+func (c *groupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.depth > 0 {
+		with := make([]zapcore.Field, 0, len(fields)+1)
+		with = append(with, fields...)
+		fields = append(with, zapcore.Field{Type: zapcore.SkipType, Interface: groupDepth(c.depth)})
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// splitGroup removes the group depth and boundary fields, returning them
+// separately for the console encoders
+func splitGroup(fields []zapcore.Field) (int, *groupBoundary, []zapcore.Field) {
+	depth := 0
+	var boundary *groupBoundary
+	rest := fields
+	for i := 0; i < len(rest); i++ {
+		if rest[i].Type != zapcore.SkipType {
+			continue
+		}
+		switch v := rest[i].Interface.(type) {
+		case groupDepth:
+			depth = int(v)
+		case groupBoundary:
+			boundary = &v
+		default:
+			continue
+		}
+		if len(rest) == len(fields) {
+			rest = append([]zapcore.Field(nil), fields...)
+		}
+		rest = append(rest[:i], rest[i+1:]...)
+		i--
+	}
+	return depth, boundary, rest
+}
+
+// renderGroupBoundary renders a header or footer line indented for the
+// enclosing groups
+func renderGroupBoundary(b *groupBoundary, depth int) *buffer.Buffer {
+	buf := bufferPool.Get()
+	buf.AppendString(strings.Repeat(groupIndent, max(depth-1, 0)))
+	if b.end {
+		buf.AppendString(green("✓ " + b.title))
+		buf.AppendString(" ")
+		buf.AppendString(white("(" + b.elapsed.Round(time.Millisecond).String() + ")"))
+	} else {
+		buf.AppendString(cyan("▸ " + b.title))
+	}
+	buf.AppendByte('\n')
+	return buf
+}
+
+// indentGroup prefixes each line of an encoded entry for depth groups,
+// returning buf to the pool and the indented copy
+func indentGroup(buf *buffer.Buffer, depth int) *buffer.Buffer {
+	if depth <= 0 {
+		return buf
+	}
+	prefix := strings.Repeat(groupIndent, depth)
+	out := bufferPool.Get()
+	for line := range bytes.Lines(buf.Bytes()) {
+		out.AppendString(prefix)
+		_, _ = out.Write(line)
+	}
+	bufferPool.put(buf)
+	return out
+}
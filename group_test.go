@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	withoutColor(t)
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevStdout := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() {
+		os.Stdout = prevStdout
+		stdout.Close()
+	})
+
+	path := filepath.Join(dir, "app.log")
+	clock := fixedClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	l, err := NewLogger(Config{EnableFile: true, FilePath: path, Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer := l.Group("deploy")
+	outer.Info("building")
+	inner := outer.Group("migrate")
+	inner.Info("applied")
+	inner.End()
+	inner.End()
+	outer.End()
+	l.Info("done")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range bytes.Lines(data) {
+		var entry struct{ Msg, Group string }
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry.Group+": "+entry.Msg)
+	}
+	want := []string{
+		"deploy: Group started",
+		"deploy: building",
+		"deploy > migrate: Group started",
+		"deploy > migrate: applied",
+		"deploy > migrate: Group completed",
+		"deploy: Group completed",
+		": done",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("file entries = %q, want %q", got, want)
+	}
+
+	console, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prefixes []string
+	for line := range strings.Lines(string(console)) {
+		trimmed := strings.TrimLeft(line, " ")
+		prefixes = append(prefixes, line[:len(line)-len(trimmed)]+strings.Fields(trimmed)[0])
+	}
+	wantPrefixes := []string{"▸", "  2024-03-01", "  ▸", "    2024-03-01", "  ✓", "✓", "2024-03-01"}
+	if !slices.Equal(prefixes, wantPrefixes) || !strings.Contains(string(console), "✓ migrate (0s)") {
+		t.Errorf("console lines start %q, want %q:\n%s", prefixes, wantPrefixes, console)
+	}
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Health is a snapshot of the logging subsystem's state, for detecting a
+// full disk or an unreachable collector that would otherwise only show up
+// as missing logs
+type Health struct {
+	// Status is "ok", or "degraded" when any sink is
+	Status string       `json:"status"`
+	Level  string       `json:"level"`
+	Sinks  []SinkHealth `json:"sinks"`
+	// AsyncQueued and AsyncDropped report the async queue, if enabled
+	AsyncQueued  int    `json:"async_queued"`
+	AsyncDropped uint64 `json:"async_dropped"`
+}
+
+// SinkHealth describes one sink
+type SinkHealth struct {
+	// Kind is "console", "json", "file", "session", or "network"
+	Kind string `json:"kind"`
+	// Name is the file path or collector address
+	Name string `json:"name"`
+	// Status is "ok", or "degraded" when the last write or sync failed or
+	// the collector is unreachable with entries waiting
+	Status string `json:"status"`
+
+	LastWrite     time.Time `json:"last_write,omitzero"`
+	LastSync      time.Time `json:"last_sync,omitzero"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorTime time.Time `json:"last_error_time,omitzero"`
+	// Errors counts failed writes and syncs
+	Errors uint64 `json:"errors"`
+
+	// FileSize is the size of a file sink's file
+	FileSize int64 `json:"file_size,omitempty"`
+	// Queued and Dropped report a network sink's buffer
+	Queued  int    `json:"queued,omitempty"`
+	Dropped uint64 `json:"dropped,omitempty"`
+}
+
+// Health returns the state of each sink and the async queue. Loggers
+// derived from the same root share it.
+func (l *Logger) Health() Health {
+	p := l.state.pipe.Load()
+	h := Health{Status: "ok", Level: levelName(l.Level())}
+	if p.res.async != nil {
+		h.AsyncQueued = p.res.async.queued()
+		h.AsyncDropped = p.res.async.dropped.Load()
+	}
+	for _, m := range p.res.monitors {
+		s := m.health()
+		if s.Status != "ok" {
+			h.Status = "degraded"
+		}
+		h.Sinks = append(h.Sinks, s)
+	}
+	return h
+}
+
+// HealthHandler returns an http.Handler serving Health as JSON, with status
+// 503 while the logger is degraded
+func (l *Logger) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		h := l.Health()
+		status := http.StatusOK
+		if h.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		writeAdminJSON(w, status, h)
+	})
+}
+
+// sinkMonitor wraps a sink's output, recording the outcome of its writes
+// and syncs for Health
+type sinkMonitor struct {
+	out  zapcore.WriteSyncer
+	kind string
+	name string
+	// file is set for file sinks, to report their size
+	file logFile
+	// network is set for the network sink, to report its buffer
+	network *networkWriter
+
+	lastWrite atomic.Int64
+	lastSync  atomic.Int64
+	errors    atomic.Uint64
+	// failing is set while the latest write or sync failed
+	failing atomic.Bool
+
+	mu        sync.Mutex
+	lastErr   string
+	lastErrAt time.Time
+}
+
+// newSinkMonitor wraps out
+func newSinkMonitor(kind, name string, out zapcore.WriteSyncer) *sinkMonitor {
+	return &sinkMonitor{out: out, kind: kind, name: name}
+}
+
+// Write writes p, recording the outcome
+func (m *sinkMonitor) Write(p []byte) (int, error) {
+	n, err := m.out.Write(p)
+	m.record(err, &m.lastWrite)
+	return n, err
+}
+
+// Sync syncs the output, recording the outcome
+func (m *sinkMonitor) Sync() error {
+	err := m.out.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+		// Terminals and pipes can't be synced, which isn't a failure
+		m.record(nil, &m.lastSync)
+		return err
+	}
+	m.record(err, &m.lastSync)
+	return err
+}
+
+// record stores the outcome of a write or sync, setting at on success
+func (m *sinkMonitor) record(err error, at *atomic.Int64) {
+	if err == nil {
+		at.Store(time.Now().UnixNano())
+		m.failing.Store(false)
+		return
+	}
+	m.errors.Add(1)
+	m.mu.Lock()
+	m.lastErr = err.Error()
+	m.lastErrAt = time.Now()
+	m.mu.Unlock()
+	m.failing.Store(true)
+}
+
+// health returns the sink's state
+func (m *sinkMonitor) health() SinkHealth {
+	s := SinkHealth{
+		Kind:      m.kind,
+		Name:      m.name,
+		Status:    "ok",
+		LastWrite: unixNanoTime(m.lastWrite.Load()),
+		LastSync:  unixNanoTime(m.lastSync.Load()),
+		Errors:    m.errors.Load(),
+	}
+	m.mu.Lock()
+	s.LastError, s.LastErrorTime = m.lastErr, m.lastErrAt
+	m.mu.Unlock()
+	if m.failing.Load() {
+		s.Status = "degraded"
+	}
+
+	if f, ok := m.file.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := f.Stat(); err == nil {
+			s.FileSize = info.Size()
+		}
+	}
+	if m.network != nil {
+		ns := m.network.stats()
+		s.Queued, s.Dropped = ns.Buffered, ns.Dropped
+		if err, at := m.network.lastError(); err != "" && at.After(s.LastErrorTime) {
+			s.LastError, s.LastErrorTime = err, at
+		}
+		if !ns.Connected && ns.Buffered > 0 {
+			s.Status = "degraded"
+		}
+	}
+	return s
+}
+
+// unixNanoTime converts a stored timestamp, leaving zero as the zero time
+func unixNanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
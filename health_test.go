@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHealth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "warn", EnableFile: true, FilePath: path})
+	l.Warn("disk almost full")
+
+	h := l.Health()
+	if h.Status != "ok" || h.Level != "warn" {
+		t.Errorf("health = %s at %s, want ok at warn", h.Status, h.Level)
+	}
+	kinds := map[string]SinkHealth{}
+	for _, s := range h.Sinks {
+		kinds[s.Kind] = s
+	}
+	file, ok := kinds["file"]
+	if !ok || kinds["console"].Status != "ok" {
+		t.Fatalf("sinks = %+v, want console and file", h.Sinks)
+	}
+	if file.Name != path || file.FileSize == 0 || file.LastWrite.IsZero() {
+		t.Errorf("file sink health = %+v", file)
+	}
+
+	rec := httptest.NewRecorder()
+	l.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var served Health
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || served.Status != "ok" || len(served.Sinks) != len(h.Sinks) {
+		t.Errorf("handler served %d %+v", rec.Code, served)
+	}
+}
+
+func TestSinkMonitor(t *testing.T) {
+	w := &failingWriter{fail: map[int]bool{2: true}}
+	m := newSinkMonitor("file", "app.log", zapcore.AddSync(w))
+
+	steps := []struct {
+		want       string
+		wantErrors uint64
+	}{
+		{"ok", 0},
+		{"degraded", 1},
+		{"ok", 1},
+	}
+	for i, step := range steps {
+		m.Write([]byte("entry\n"))
+		s := m.health()
+		if s.Status != step.want || s.Errors != step.wantErrors {
+			t.Errorf("after write %d: status %s with %d errors, want %s with %d", i+1, s.Status, s.Errors, step.want, step.wantErrors)
+		}
+		if s.Errors > 0 && (s.LastError != "disk full" || s.LastErrorTime.IsZero()) {
+			t.Errorf("after write %d: last error %q at %v", i+1, s.LastError, s.LastErrorTime)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportHTMLFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.log")
+	lines := []string{
+		`{"level":"info","time":"2024-03-01T12:00:00Z","msg":"started","zone":"eu","attempt":2}`,
+		`{broken`,
+		`{"level":"error","time":"2024-03-01T12:00:01Z","logger":"db","msg":"<script>alert(1)</script>","stacktrace":"main.run\n\tmain.go:10"}`,
+	}
+	if err := os.WriteFile(src, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "incident.html")
+	stats, err := ExportHTMLFile(dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (HTMLStats{Entries: 2, Skipped: 1}) {
+		t.Errorf("stats %+v, want 2 entries and 1 skipped", stats)
+	}
+	raw, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(raw)
+	for _, want := range []string{
+		"<title>app.log</title>",
+		"2024-03-01 12:00:00 &#43;00:00",
+		`white-space:nowrap;color:#18802b">INFO</td>`,
+		`white-space:nowrap;color:#c00;font-weight:bold">ERROR</td>`,
+		// Fields in key order, non-strings as JSON
+		`started <span style="color:#236">attempt</span>=2 <span style="color:#236">zone</span>=eu`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"main.run\n\tmain.go:10</pre>",
+		"2 entries, 1 malformed entries left out",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page is missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") || strings.Contains(page, "src=") {
+		t.Error("page has a script or external resource")
+	}
+}
+
+func TestExportHTMLFileSamePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportHTMLFile(path, path); err == nil {
+		t.Error("ExportHTMLFile overwrote its source")
+	}
+}
+
+func TestExportHTMLFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenLogFile(path, WithFollow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := ExportHTML(context.Background(), &strings.Builder{}, r, "live"); err == nil {
+		t.Error("ExportHTML accepted a followed reader")
+	}
+}
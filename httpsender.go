@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// httpSenderQueueSize bounds the requests waiting to be sent by an alerting
+// sink; more are dropped
+const httpSenderQueueSize = 100
+
+// httpPayload is a request body waiting to be sent
+type httpPayload struct {
+	url         string
+	contentType string
+	header      http.Header
+	body        []byte
+}
+
+// httpSender posts payloads from a background goroutine, which runs only
+// while there is something to send, so alerting sinks never block logging
+// on the network
+type httpSender struct {
+	name        string
+	client      *http.Client
+	credentials CredentialProvider
+
+	mu      sync.Mutex
+	queue   []httpPayload
+	running bool
+	pending sync.WaitGroup
+	dropped atomic.Uint64
+	// breaker, if set, drops payloads instead of posting them while it is
+	// open
+	breaker *CircuitBreaker
+}
+
+// newHTTPSender creates a sender; client defaults to one with timeout
+func newHTTPSender(name string, client *http.Client, timeout time.Duration, credentials CredentialProvider) *httpSender {
+	if client == nil {
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	return &httpSender{name: name, client: client, credentials: credentials}
+}
+
+// send queues p, dropping it if the queue is full
+func (s *httpSender) send(p httpPayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) >= httpSenderQueueSize {
+		s.dropped.Add(1)
+		return
+	}
+	s.pending.Add(1)
+	s.queue = append(s.queue, p)
+	if !s.running {
+		s.running = true
+		go s.run()
+	}
+}
+
+// run sends queued payloads until the queue is empty
+func (s *httpSender) run() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		p := s.queue[0]
+		s.queue = s.queue[1:]
+		breaker := s.breaker
+		s.mu.Unlock()
+
+		if breaker != nil && !breaker.allow() {
+			s.dropped.Add(1)
+			s.pending.Done()
+			continue
+		}
+		err := s.post(context.Background(), p)
+		if breaker != nil {
+			breaker.record(err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: %s: %v\n", s.name, err)
+		}
+		s.pending.Done()
+	}
+}
+
+// useBreaker guards the sender with breaker unless it already has one
+func (s *httpSender) useBreaker(breaker *CircuitBreaker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.breaker == nil {
+		s.breaker = breaker
+	}
+}
+
+// post sends one payload
+func (s *httpSender) post(ctx context.Context, p httpPayload) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(p.body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range p.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", p.contentType)
+	if s.credentials != nil {
+		creds, err := s.credentials.Credentials()
+		if err != nil {
+			return fmt.Errorf("failed to get credentials: %w", err)
+		}
+		creds.Apply(req)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// sync waits until every queued payload has been sent or has failed
+func (s *httpSender) sync() {
+	s.pending.Wait()
+}
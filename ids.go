@@ -0,0 +1,193 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// entryIDKey is the field key of Config.EntryIDs
+const entryIDKey = "entry_id"
+
+// IDGenerator returns a new unique ID. It must be safe for concurrent use.
+type IDGenerator func() string
+
+// idGenerator returns the configured generator or NewCorrelationID
+func (c Config) idGenerator() IDGenerator {
+	if c.IDGenerator != nil {
+		return c.IDGenerator
+	}
+	return NewCorrelationID
+}
+
+// NewID returns an ID from Config.IDGenerator, for correlation or session
+// IDs that should sort like the ones the logger generates
+func (l *Logger) NewID() string {
+	return l.state.pipe.Load().config.idGenerator()()
+}
+
+// uuidV7State keeps UUIDv7 IDs increasing within a millisecond
+var uuidV7State struct {
+	mu      sync.Mutex
+	ms      int64
+	counter uint16
+}
+
+// UUIDv7 returns a version 7 UUID: a millisecond timestamp followed by
+// random bits, so IDs sort by creation time. IDs from one process
+// increase strictly, using the 12 bits after the timestamp as a counter
+// within a millisecond.
+func UUIDv7() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	s := &uuidV7State
+	s.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= s.ms {
+		s.counter++
+		if s.counter > 0x0fff {
+			s.ms++
+			s.counter = 0
+		}
+		ms = s.ms
+	} else {
+		s.ms = ms
+		s.counter = binary.BigEndian.Uint16(b[6:8]) & 0x07ff
+	}
+	counter := s.counter
+	s.mu.Unlock()
+
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	b[6] = 0x70 | byte(counter>>8)
+	b[7] = byte(counter)
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ulidEncoding is the Crockford base32 alphabet of ULIDs
+const ulidEncoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidState keeps ULIDs increasing within a millisecond
+var ulidState struct {
+	mu      sync.Mutex
+	ms      int64
+	entropy [10]byte
+}
+
+// ULID returns a ULID: 26 characters encoding a millisecond timestamp and
+// 80 random bits, so IDs sort by creation time. IDs from one process
+// increase strictly, incrementing the random bits within a millisecond.
+func ULID() string {
+	s := &ulidState
+	s.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= s.ms {
+		ms = s.ms
+		// Increment the entropy, carrying into the timestamp on overflow
+		i := len(s.entropy) - 1
+		for ; i >= 0; i-- {
+			s.entropy[i]++
+			if s.entropy[i] != 0 {
+				break
+			}
+		}
+		if i < 0 {
+			ms++
+			s.ms = ms
+		}
+	} else {
+		s.ms = ms
+		_, _ = rand.Read(s.entropy[:])
+	}
+	var b [16]byte
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(b[6:], s.entropy[:])
+	s.mu.Unlock()
+
+	// 128 bits as 26 five-bit digits, the first holding the top 3 bits
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = ulidEncoding[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// sonyflakeEpoch is the default start time of Sonyflake IDs
+var sonyflakeEpoch = time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// sonyflakeUnit is the resolution of Sonyflake timestamps
+const sonyflakeUnit = 10 * time.Millisecond
+
+// sonyflake generates Sonyflake IDs for one machine
+type sonyflake struct {
+	mu        sync.Mutex
+	machineID uint16
+	elapsed   int64
+	sequence  uint16
+}
+
+// NewSonyflake returns a generator of Sonyflake IDs: 39 bits of time in
+// 10ms units since 2014-09-01, an 8-bit sequence, and machineID, as
+// decimal strings. IDs sort numerically by creation time across machines
+// with distinct machine IDs. Beyond 256 IDs in 10ms it waits for the next
+// unit.
+func NewSonyflake(machineID uint16) IDGenerator {
+	s := &sonyflake{machineID: machineID}
+	return s.next
+}
+
+// next returns the next ID
+func (s *sonyflake) next() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := int64(time.Since(sonyflakeEpoch) / sonyflakeUnit)
+	if elapsed > s.elapsed {
+		s.elapsed = elapsed
+		s.sequence = 0
+	} else {
+		s.sequence = (s.sequence + 1) & 0xff
+		if s.sequence == 0 {
+			s.elapsed++
+			time.Sleep(sonyflakeEpoch.Add(time.Duration(s.elapsed) * sonyflakeUnit).Sub(time.Now()))
+		}
+	}
+	id := uint64(s.elapsed)<<24 | uint64(s.sequence)<<16 | uint64(s.machineID)
+	return strconv.FormatUint(id, 10)
+}
+
+// entryIDCore adds an entry_id field to each entry, the same in every sink
+type entryIDCore struct {
+	zapcore.Core
+	newID IDGenerator
+}
+
+// With returns a child core
+func (c *entryIDCore) With(fields []zapcore.Field) zapcore.Core {
+	return &entryIDCore{Core: c.Core.With(fields), newID: c.newID}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *entryIDCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write adds the entry's ID
+func (c *entryIDCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	with := make([]zapcore.Field, 0, len(fields)+1)
+	with = append(with, zap.String(entryIDKey, c.newID()))
+	with = append(with, fields...)
+	return c.Core.Write(ent, with)
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestIDGenerators(t *testing.T) {
+	tests := []struct {
+		name    string
+		newID   IDGenerator
+		pattern *regexp.Regexp
+		less    func(a, b string) bool
+	}{
+		{
+			name:    "UUIDv7",
+			newID:   UUIDv7,
+			pattern: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+			less:    func(a, b string) bool { return a < b },
+		},
+		{
+			name:    "ULID",
+			newID:   ULID,
+			pattern: regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+			less:    func(a, b string) bool { return a < b },
+		},
+		{
+			name:    "Sonyflake",
+			newID:   NewSonyflake(42),
+			pattern: regexp.MustCompile(`^[0-9]+$`),
+			less: func(a, b string) bool {
+				x, _ := strconv.ParseUint(a, 10, 64)
+				y, _ := strconv.ParseUint(b, 10, 64)
+				return x < y
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := tt.newID()
+			// Enough IDs to cross several milliseconds and Sonyflake units
+			for range 1000 {
+				id := tt.newID()
+				if !tt.pattern.MatchString(id) {
+					t.Fatalf("malformed ID %q", id)
+				}
+				if !tt.less(prev, id) {
+					t.Fatalf("ID %q does not sort after %q", id, prev)
+				}
+				prev = id
+			}
+		})
+	}
+}
+
+func TestSonyflakeMachineID(t *testing.T) {
+	id, err := strconv.ParseUint(NewSonyflake(0xbeef)(), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine := id & 0xffff; machine != 0xbeef {
+		t.Errorf("machine ID %#x, want 0xbeef", machine)
+	}
+}
+
+func TestEntryIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	n := 0
+	l := newBenchLogger(t, Config{
+		Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path,
+		EntryIDs:    true,
+		IDGenerator: func() string { n++; return "id-" + strconv.Itoa(n) },
+	})
+	if id := l.NewID(); id != "id-1" {
+		t.Errorf("NewID() = %q, want the configured generator's ID", id)
+	}
+
+	l.Info("request")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(readOnlyLine(t, path), &entry); err != nil {
+		t.Fatal(err)
+	}
+	// The console sink sees the same ID, so only one is generated
+	if entry[entryIDKey] != "id-2" || n != 2 {
+		t.Errorf("entry_id = %v after %d IDs, want id-2", entry[entryIDKey], n)
+	}
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// lastResortOutput receives a plaintext line for entries no sink could write
+var lastResortOutput io.Writer = os.Stderr
+
+// lastResortMu keeps last resort lines from interleaving
+var lastResortMu sync.Mutex
+
+// fanoutCore writes entries to every enabled sink, like zapcore's Tee, and
+// notices when all of them fail. In that case it writes a minimal plaintext
+// line with the failure and the original message to lastResortOutput, so a
+// full disk or a closed stdout never silences the logger completely.
+type fanoutCore struct {
+	cores      []zapcore.Core
+	lastResort bool
+	// provenance adds delivered_via and original_time to last resort lines
+	provenance bool
+}
+
+// newFanoutCore combines cores; lastResort enables the stderr fallback
+func newFanoutCore(cores []zapcore.Core, lastResort, provenance bool) zapcore.Core {
+	return &fanoutCore{cores: cores, lastResort: lastResort, provenance: provenance}
+}
+
+// Enabled reports whether any sink accepts level
+func (c *fanoutCore) Enabled(level zapcore.Level) bool {
+	for _, core := range c.cores {
+		if core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns a core whose sinks carry fields
+func (c *fanoutCore) With(fields []zapcore.Field) zapcore.Core {
+	cores := make([]zapcore.Core, len(c.cores))
+	for i, core := range c.cores {
+		cores[i] = core.With(fields)
+	}
+	return &fanoutCore{cores: cores, lastResort: c.lastResort, provenance: c.provenance}
+}
+
+// Check adds this core to the checked entry if any sink is enabled
+func (c *fanoutCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry to every sink enabled for its level. SLO
+// trackers don't count as sinks when deciding whether all of them failed.
+func (c *fanoutCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var errs []error
+	attempted := 0
+	for _, core := range c.cores {
+		if !core.Enabled(ent.Level) {
+			continue
+		}
+		err := core.Write(ent, fields)
+		if _, ok := core.(*sloCore); ok {
+			continue
+		}
+		attempted++
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	if c.lastResort && attempted > 0 && len(errs) == attempted {
+		writeLastResort(ent, err, c.provenance)
+	}
+	return err
+}
+
+// Sync flushes every sink
+func (c *fanoutCore) Sync() error {
+	var errs []error
+	for _, core := range c.cores {
+		if err := core.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeLastResort writes ent's message and the reason it was lost, and
+// its provenance if requested
+func writeLastResort(ent zapcore.Entry, err error, provenance bool) {
+	var b strings.Builder
+	b.WriteString(ent.Time.Format(time.RFC3339))
+	b.WriteString(" logger: every sink failed (")
+	b.WriteString(strings.ReplaceAll(err.Error(), "\n", "; "))
+	b.WriteString("): ")
+	b.WriteString(strings.ToUpper(levelName(ent.Level)))
+	b.WriteByte(' ')
+	b.WriteString(ent.Message)
+	if provenance {
+		b.WriteString(" " + deliveredViaKey + "=" + deliveredViaLastResort)
+		b.WriteString(" " + originalTimeKey + "=" + ent.Time.Format(time.RFC3339Nano))
+	}
+	b.WriteByte('\n')
+
+	lastResortMu.Lock()
+	defer lastResortMu.Unlock()
+	_, _ = io.WriteString(lastResortOutput, b.String())
+}
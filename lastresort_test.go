@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLastResort(t *testing.T) {
+	failing := func() zapcore.Core {
+		w := &failingWriter{fail: map[int]bool{1: true}}
+		return zapcore.NewCore(zapcore.NewJSONEncoder(fileEncoderConfig()), zapcore.AddSync(w), zapcore.DebugLevel)
+	}
+	working := func() zapcore.Core {
+		return zapcore.NewCore(zapcore.NewJSONEncoder(fileEncoderConfig()), zapcore.AddSync(&bytes.Buffer{}), zapcore.DebugLevel)
+	}
+	tests := []struct {
+		name       string
+		cores      []zapcore.Core
+		lastResort bool
+		provenance bool
+		want       []string
+	}{
+		{"every sink failed", []zapcore.Core{failing(), failing()}, true, false, []string{
+			"logger: every sink failed (disk full; disk full): WARN payment declined",
+		}},
+		{"one sink worked", []zapcore.Core{failing(), working()}, true, false, nil},
+		{"disabled", []zapcore.Core{failing()}, false, false, nil},
+		{"provenance", []zapcore.Core{failing()}, true, true, []string{
+			"WARN payment declined " + deliveredViaKey + "=" + deliveredViaLastResort,
+			originalTimeKey + "=2024-03-01T12:30:45.5Z",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prev := lastResortOutput
+			lastResortOutput = &out
+			t.Cleanup(func() { lastResortOutput = prev })
+
+			core := newFanoutCore(tt.cores, tt.lastResort, tt.provenance)
+			ent := zapcore.Entry{
+				Level:   zapcore.WarnLevel,
+				Time:    time.Date(2024, 3, 1, 12, 30, 45, int(500*time.Millisecond), time.UTC),
+				Message: "payment declined",
+			}
+			core.Write(ent, nil)
+
+			if tt.want == nil {
+				if out.Len() > 0 {
+					t.Errorf("wrote a last resort line: %q", out.String())
+				}
+				return
+			}
+			line := out.String()
+			if strings.Count(line, "\n") != 1 || !strings.HasPrefix(line, "2024-03-01T12:30:45Z ") {
+				t.Errorf("last resort line = %q", line)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("last resort line %q is missing %q", line, want)
+				}
+			}
+		})
+	}
+}
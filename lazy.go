@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Enabled reports whether an entry at level would be written by any sink.
+// Use it to guard expensive work that only feeds a log call:
+//
+//	if log.Enabled(zapcore.DebugLevel) {
+//		log.Debug("response", zap.String("body", dump(resp)))
+//	}
+func (l *Logger) Enabled(level zapcore.Level) bool {
+	return l.Core().Enabled(level)
+}
+
+// Lazy returns a field whose value is computed by fn only when the entry is
+// encoded, so it costs nothing when the level is disabled:
+//
+//	log.Debug("request", logger.Lazy(func() zap.Field {
+//		return zap.String("payload", string(mustMarshal(req)))
+//	}))
+//
+// fn runs at most once per entry, even with several sinks. In async mode it
+// runs on the async worker, so it must not depend on state the caller
+// changes after logging. Fields passed to WithField or With are encoded
+// immediately, so Lazy only saves work when passed to a log call.
+func Lazy(fn func() zap.Field) zap.Field {
+	return zap.Inline(&lazyField{fn: fn})
+}
+
+// lazyField adapts a field constructor to zapcore.ObjectMarshaler
+type lazyField struct {
+	once  sync.Once
+	fn    func() zap.Field
+	field []zap.Field
+}
+
+// MarshalLogObject computes the field on first use and adds it to enc
+func (f *lazyField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	f.once.Do(func() {
+		f.field = resolveFields([]zap.Field{f.fn()})
+	})
+	f.field[0].AddTo(enc)
+	return nil
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLazy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path})
+	var calls atomic.Int32
+	payload := func() zap.Field {
+		calls.Add(1)
+		return zap.String("payload", "expensive")
+	}
+
+	if l.Enabled(zapcore.DebugLevel) {
+		t.Error("debug is enabled at level info")
+	}
+	if !l.Enabled(zapcore.InfoLevel) {
+		t.Error("info is disabled at level info")
+	}
+	l.Debug("skipped", Lazy(payload))
+	if n := calls.Load(); n != 0 {
+		t.Errorf("disabled entry computed its lazy field %d times", n)
+	}
+
+	l.Info("request", Lazy(payload))
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("entry written to two sinks computed its lazy field %d times, want 1", n)
+	}
+	line := string(readOnlyLine(t, path))
+	if !strings.Contains(line, `"payload":"expensive"`) {
+		t.Errorf("file entry %s is missing the lazy field", line)
+	}
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceLevel logs below debug, for output such as protocol-level dumps that
+// is too noisy for Debug and needs to be toggled separately
+const TraceLevel = zapcore.DebugLevel - 1
+
+// parseLevel parses a level name, including "trace"
+func parseLevel(s string) (zapcore.Level, error) {
+	if strings.EqualFold(s, "trace") {
+		return TraceLevel, nil
+	}
+	return zapcore.ParseLevel(s)
+}
+
+// levelName returns the lowercase name of level, including "trace"
+func levelName(level zapcore.Level) string {
+	if level == TraceLevel {
+		return "trace"
+	}
+	return level.String()
+}
+
+// lowercaseLevelEncoder is zapcore.LowercaseLevelEncoder with trace support
+func lowercaseLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelName(level))
+}
+
+// Trace logs a message at TraceLevel
+func (l *Logger) Trace(msg string, fields ...zap.Field) {
+	l.logAt(TraceLevel, colorText{}, msg, fields)
+}
+
+// Tracef logs a formatted message at TraceLevel
+func (l *Logger) Tracef(template string, args ...any) {
+	if !l.Core().Enabled(TraceLevel) {
+		return
+	}
+	l.logAt(TraceLevel, colorText{}, fmt.Sprintf(template, args...), nil)
+}
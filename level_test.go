@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{"trace", TraceLevel, false},
+		{"TRACE", TraceLevel, false},
+		{"debug", zapcore.DebugLevel, false},
+		{"warn", zapcore.WarnLevel, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLevel(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLevel(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && levelName(got) != strings.ToLower(tt.in) {
+			t.Errorf("levelName(%v) = %q, want %q", got, levelName(got), strings.ToLower(tt.in))
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{"trace", []string{"dump", "dump 2", "info"}},
+		{"debug", []string{"info"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l := newBenchLogger(t, Config{Level: tt.level, EnableFile: true, FilePath: path})
+
+			l.Trace("dump")
+			l.Tracef("dump %d", 2)
+			l.Info("info")
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for line := range bytes.Lines(data) {
+				var entry struct{ Level, Logger, Msg string }
+				if err := json.Unmarshal(line, &entry); err != nil {
+					t.Fatalf("parse %q: %v", line, err)
+				}
+				if entry.Logger == diagnosticsLoggerName {
+					continue
+				}
+				if entry.Msg != "info" && entry.Level != "trace" {
+					t.Errorf("%s logged at %q, want trace", entry.Msg, entry.Level)
+				}
+				got = append(got, entry.Msg)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("logged %q at level %s, want %q", got, tt.level, tt.want)
+			}
+		})
+	}
+}
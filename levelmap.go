@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelMapping is how one level of this package is expressed by other
+// logging systems, for sinks and adapters that must agree on it
+type LevelMapping struct {
+	Level zapcore.Level
+	// Name is the level's lowercase name, such as "warn"
+	Name string
+	// Syslog is the RFC 5424 severity, 0 (emergency) to 7 (debug), also
+	// used by GELF
+	Syslog int
+	// OTelNumber and OTelText are the OpenTelemetry SeverityNumber, 1 to
+	// 24, and its short name
+	OTelNumber int
+	OTelText   string
+	// GCP is the Google Cloud Logging severity, such as "WARNING"
+	GCP string
+	// Slog is the log/slog level
+	Slog slog.Level
+}
+
+// levelMappings holds the mapping of each level, indexed from TraceLevel.
+// The levels above error have no counterpart in OpenTelemetry and slog,
+// which get the next steps up from their error level.
+var levelMappings = [...]LevelMapping{
+	{TraceLevel, "trace", 7, 1, "TRACE", "DEBUG", slog.LevelDebug - 4},
+	{zapcore.DebugLevel, "debug", 7, 5, "DEBUG", "DEBUG", slog.LevelDebug},
+	{zapcore.InfoLevel, "info", 6, 9, "INFO", "INFO", slog.LevelInfo},
+	{zapcore.WarnLevel, "warn", 4, 13, "WARN", "WARNING", slog.LevelWarn},
+	{zapcore.ErrorLevel, "error", 3, 17, "ERROR", "ERROR", slog.LevelError},
+	{zapcore.DPanicLevel, "dpanic", 2, 18, "ERROR2", "CRITICAL", slog.LevelError + 4},
+	{zapcore.PanicLevel, "panic", 1, 19, "ERROR3", "ALERT", slog.LevelError + 8},
+	{zapcore.FatalLevel, "fatal", 0, 21, "FATAL", "EMERGENCY", slog.LevelError + 12},
+}
+
+// LevelMappings returns the mapping of every level, from trace to fatal
+func LevelMappings() []LevelMapping {
+	return append([]LevelMapping(nil), levelMappings[:]...)
+}
+
+// MapLevel returns the mapping of level. Levels outside trace to fatal
+// are clamped to the nearest one.
+func MapLevel(level zapcore.Level) LevelMapping {
+	level = max(TraceLevel, min(level, zapcore.FatalLevel))
+	return levelMappings[level-TraceLevel]
+}
+
+// LevelFromSyslog returns the level of a syslog severity. Notice reads as
+// info; severities out of range are clamped.
+func LevelFromSyslog(severity int) zapcore.Level {
+	switch {
+	case severity >= 7:
+		return zapcore.DebugLevel
+	case severity >= 5:
+		return zapcore.InfoLevel
+	case severity == 4:
+		return zapcore.WarnLevel
+	case severity == 3:
+		return zapcore.ErrorLevel
+	case severity == 2:
+		return zapcore.DPanicLevel
+	case severity == 1:
+		return zapcore.PanicLevel
+	}
+	return zapcore.FatalLevel
+}
+
+// LevelFromOTel returns the level of an OpenTelemetry SeverityNumber. The
+// numbers in the table read as their level; others read as the level of
+// their range of four, so ERROR4 reads as error, and 0, unspecified, as
+// info.
+func LevelFromOTel(number int) zapcore.Level {
+	for _, m := range levelMappings {
+		if m.OTelNumber == number {
+			return m.Level
+		}
+	}
+	switch {
+	case number <= 0:
+		return zapcore.InfoLevel
+	case number <= 4:
+		return TraceLevel
+	case number <= 8:
+		return zapcore.DebugLevel
+	case number <= 12:
+		return zapcore.InfoLevel
+	case number <= 16:
+		return zapcore.WarnLevel
+	case number <= 20:
+		return zapcore.ErrorLevel
+	}
+	return zapcore.FatalLevel
+}
+
+// LevelFromSlog returns the level of a slog level, rounding down to the
+// nearest mapped one
+func LevelFromSlog(level slog.Level) zapcore.Level {
+	for i := len(levelMappings) - 1; i > 0; i-- {
+		if level >= levelMappings[i].Slog {
+			return levelMappings[i].Level
+		}
+	}
+	return TraceLevel
+}
+
+// levelAliases are the lowercased names syslog and GCP use for levels,
+// beyond those of this package
+var levelAliases = map[string]zapcore.Level{
+	"emerg": zapcore.FatalLevel, "emergency": zapcore.FatalLevel,
+	"alert": zapcore.PanicLevel,
+	"crit":  zapcore.DPanicLevel, "critical": zapcore.DPanicLevel,
+	"err":     zapcore.ErrorLevel,
+	"warning": zapcore.WarnLevel,
+	"notice":  zapcore.InfoLevel, "default": zapcore.InfoLevel,
+	"informational": zapcore.InfoLevel,
+}
+
+// LevelFromName returns the level named name by this package, slog, syslog,
+// GCP, or OpenTelemetry, case-insensitively, such as "warn", "WARNING",
+// "crit", or "ERROR2", reporting false for unknown names
+func LevelFromName(name string) (zapcore.Level, bool) {
+	if level, err := parseLevel(name); err == nil {
+		return level, true
+	}
+	lower := strings.ToLower(name)
+	if level, ok := levelAliases[lower]; ok {
+		return level, true
+	}
+	for _, m := range levelMappings {
+		if strings.EqualFold(m.OTelText, name) {
+			return m.Level, true
+		}
+	}
+	// OpenTelemetry's numbered texts, such as DEBUG2 or FATAL4
+	if n := len(lower); n > 1 && lower[n-1] >= '2' && lower[n-1] <= '4' {
+		if level, err := parseLevel(lower[:n-1]); err == nil {
+			return level, true
+		}
+	}
+	return zapcore.InfoLevel, false
+}
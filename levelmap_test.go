@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelMappingsRoundTrip(t *testing.T) {
+	mappings := LevelMappings()
+	if len(mappings) != 8 || mappings[0].Level != TraceLevel || mappings[7].Level != zapcore.FatalLevel {
+		t.Fatalf("mappings %+v, want trace to fatal", mappings)
+	}
+	for _, m := range mappings {
+		if got := MapLevel(m.Level); got != m {
+			t.Errorf("MapLevel(%v) = %+v", m.Level, got)
+		}
+		if got := LevelFromOTel(m.OTelNumber); got != m.Level {
+			t.Errorf("LevelFromOTel(%d) = %v, want %v", m.OTelNumber, got, m.Level)
+		}
+		if got := LevelFromSlog(m.Slog); got != m.Level {
+			t.Errorf("LevelFromSlog(%v) = %v, want %v", m.Slog, got, m.Level)
+		}
+		if got, ok := LevelFromName(m.Name); !ok || got != m.Level {
+			t.Errorf("LevelFromName(%q) = %v, %v", m.Name, got, ok)
+		}
+		if got, ok := LevelFromName(m.OTelText); !ok || got != m.Level {
+			t.Errorf("LevelFromName(%q) = %v, %v", m.OTelText, got, ok)
+		}
+		// Syslog and GCP have no trace, which reads as debug
+		want := max(m.Level, zapcore.DebugLevel)
+		if got := LevelFromSyslog(m.Syslog); got != want {
+			t.Errorf("LevelFromSyslog(%d) = %v, want %v", m.Syslog, got, want)
+		}
+		if got, ok := LevelFromName(m.GCP); !ok || got != want {
+			t.Errorf("LevelFromName(%q) = %v, %v", m.GCP, got, ok)
+		}
+	}
+
+	// Mutating the returned slice leaves the table alone
+	mappings[0].Name = "changed"
+	if MapLevel(TraceLevel).Name != "trace" {
+		t.Error("LevelMappings returned the table itself")
+	}
+}
+
+func TestMapLevelClamps(t *testing.T) {
+	if got := MapLevel(TraceLevel - 3).Level; got != TraceLevel {
+		t.Errorf("MapLevel below trace = %v", got)
+	}
+	if got := MapLevel(zapcore.FatalLevel + 3).Level; got != zapcore.FatalLevel {
+		t.Errorf("MapLevel above fatal = %v", got)
+	}
+}
+
+func TestLevelConversions(t *testing.T) {
+	tests := []struct {
+		name string
+		got  zapcore.Level
+		want zapcore.Level
+	}{
+		{"syslog notice", LevelFromSyslog(5), zapcore.InfoLevel},
+		{"syslog out of range high", LevelFromSyslog(12), zapcore.DebugLevel},
+		{"syslog out of range low", LevelFromSyslog(-1), zapcore.FatalLevel},
+		{"otel unspecified", LevelFromOTel(0), zapcore.InfoLevel},
+		{"otel TRACE2", LevelFromOTel(2), TraceLevel},
+		{"otel DEBUG3", LevelFromOTel(7), zapcore.DebugLevel},
+		{"otel WARN4", LevelFromOTel(16), zapcore.WarnLevel},
+		{"otel ERROR4", LevelFromOTel(20), zapcore.ErrorLevel},
+		{"otel FATAL2", LevelFromOTel(22), zapcore.FatalLevel},
+		{"slog between levels", LevelFromSlog(slog.LevelInfo + 2), zapcore.InfoLevel},
+		{"slog below trace", LevelFromSlog(slog.LevelDebug - 10), TraceLevel},
+		{"slog far above", LevelFromSlog(slog.LevelError + 100), zapcore.FatalLevel},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLevelFromName(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   zapcore.Level
+		wantOK bool
+	}{
+		{"Warning", zapcore.WarnLevel, true},
+		{"crit", zapcore.DPanicLevel, true},
+		{"EMERG", zapcore.FatalLevel, true},
+		{"notice", zapcore.InfoLevel, true},
+		{"Trace", TraceLevel, true},
+		{"debug2", zapcore.DebugLevel, true},
+		{"FATAL4", zapcore.FatalLevel, true},
+		{"error5", zapcore.InfoLevel, false},
+		{"verbose", zapcore.InfoLevel, false},
+	}
+	for _, tt := range tests {
+		if got, ok := LevelFromName(tt.name); got != tt.want || ok != tt.wantOK {
+			t.Errorf("LevelFromName(%q) = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
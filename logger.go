@@ -1,13 +1,20 @@
 package logger
 
 import (
+	"crypto/cipher"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -20,29 +27,683 @@ var (
 	white  = color.New(color.FgWhite).SprintFunc()
 )
 
+// colorText is text rendered once with and once without color, so hot
+// paths pick a variant instead of formatting on every call
+type colorText struct {
+	colored string
+	plain   string
+}
+
+// newColorText renders s in attr
+func newColorText(attr color.Attribute, s string) colorText {
+	c := color.New(attr)
+	c.EnableColor()
+	return colorText{colored: c.Sprint(s), plain: s}
+}
+
+// String returns the colored variant unless colors are disabled
+func (t colorText) String() string {
+	if color.NoColor {
+		return t.plain
+	}
+	return t.colored
+}
+
+var (
+	// Prefixes of the convenience methods
+	successPrefix  = newColorText(color.FgGreen, "✓ ")
+	progressPrefix = newColorText(color.FgBlue, "→ ")
+	warningPrefix  = newColorText(color.FgYellow, "⚠ ")
+	failurePrefix  = newColorText(color.FgRed, "✗ ")
+
+	// levelLabels are the console level labels, indexed from TraceLevel
+	levelLabels = [...]colorText{
+		newColorText(color.FgWhite, "[TRACE]"),
+		newColorText(color.FgCyan, "[DEBUG]"),
+		newColorText(color.FgGreen, "[INFO]"),
+		newColorText(color.FgYellow, "[WARN]"),
+		newColorText(color.FgRed, "[ERROR]"),
+		newColorText(color.FgRed, "[DPANIC]"),
+		newColorText(color.FgRed, "[PANIC]"),
+		newColorText(color.FgRed, "[FATAL]"),
+	}
+)
+
 // Logger wraps zap.Logger with additional functionality
 type Logger struct {
 	*zap.Logger
-	sugar *zap.SugaredLogger
+	state *loggerState
+
+	// sugar is the sugared form of Logger, built on first use by Sugar
+	sugar atomic.Pointer[zap.SugaredLogger]
+	// wrapped is Logger with the caller skip used by logAt, built on first use
+	wrapped atomic.Pointer[zap.Logger]
+}
+
+// loggerState is shared by a logger and every logger derived from it
+type loggerState struct {
+	mu           sync.Mutex
+	rateLimiters map[string]*rateLimiter
+
+	// pipe is the current pipeline; reloadMu serializes swaps
+	pipe     atomic.Pointer[pipeline]
+	reloadMu sync.Mutex
+	level    zap.AtomicLevel
+	// silences counts the levels held by active Silence calls
+	silences atomic.Int32
+	// filters are the filter rules, set from Config.FilterRules and by
+	// SetFilterRules
+	filters atomic.Pointer[filterSet]
+	// files shares open files with other loggers of a Registry; nil for
+	// loggers created with NewLogger
+	files *fileSet
+	// retiring holds pipelines replaced by Reload until they are released,
+	// which Close waits for
+	retiring []*pipeline
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	closeErr  error
+}
+
+// derive wraps a child zap logger, keeping the shared state
+func (l *Logger) derive(zl *zap.Logger) *Logger {
+	return &Logger{Logger: zl, state: l.state}
 }
 
+// Supported values for Config.Format
+const (
+	// FormatConsole renders each entry on a single colored line (the default)
+	FormatConsole = "console"
+	// FormatDev renders fields on indented lines under the message and
+	// pretty-prints nested values and stack traces
+	FormatDev = "dev"
+	// FormatJSON writes the console as JSON lines, like the file sink, for
+	// containers whose output is collected
+	FormatJSON = "json"
+)
+
 // Config holds logger configuration
 type Config struct {
 	Level      string
 	Format     string
 	EnableFile bool
 	FilePath   string
+	// FileFormat selects the file encoding: "json" (the default), the
+	// compact "json-delta", or the binary "msgpack" and "cbor" formats,
+	// which are read back with NewBinaryDecoder or Logcat
+	FileFormat string
+	// FileSinkOptional keeps the logger working without the file sink,
+	// logging a warning, when its directory can't be created or its file
+	// opened, for example on a read-only filesystem. By default NewLogger
+	// fails instead.
+	FileSinkOptional bool
+	// FileCompression compresses the file sink as it is written:
+	// FileCompressionGzip or FileCompressionNone (the default). Entries are
+	// flushed to the file on Sync, and each Close or Reopen ends a complete
+	// gzip member, so files moved by a rotation tool are already compressed
+	// and read with gunzip, zcat, or OpenLogFile. Start compressing on a
+	// new path rather than one holding uncompressed entries. zstd isn't
+	// supported, as the standard library has no zstd encoder.
+	FileCompression string
+	// Encryption encrypts the file sink's entries at rest when set. Read
+	// them back with WithDecryptionKey or NewDecryptReader.
+	Encryption *FileEncryption
+
+	// ConsoleOutput is the stream the console writes to: OutputStdout (the
+	// default) or OutputStderr. Colors follow whether that stream is a
+	// terminal.
+	ConsoleOutput string
+	// JSONOutput also writes every entry as JSON lines, like the file
+	// sink, to the stream the console doesn't use, when set to OutputStdout
+	// or OutputStderr. A CLI can then pipe machine-readable entries to jq
+	// while the operator watches the colored console on the other stream:
+	// ConsoleOutput OutputStderr with JSONOutput OutputStdout. Colors are
+	// stripped from its messages.
+	JSONOutput string
+
+	// ConsoleMultiline sets how line breaks in messages are shown on the
+	// console: MultilineIndent (the default), MultilineEscape (the default
+	// with FormatJSON), or MultilineSplit. FileMultiline does the same for
+	// the file and network sinks, defaulting to MultilineEscape, which the
+	// structured encoders already provide.
+	ConsoleMultiline string
+	FileMultiline    string
+
+	// ConsoleFraming and FileFraming separate the entries written to the
+	// console and the file: FramingNewline (the default), FramingCRLF,
+	// FramingNUL, or FramingLength, for ingestion agents and Windows tools
+	// that require them. Binary file formats are written unframed unless
+	// FileFraming is set. LogReader and CollectorConfig expect newline or
+	// CRLF framing.
+	ConsoleFraming string
+	FileFraming    string
+
+	// FlattenNestedFields logs map[string]any values passed to WithField
+	// and WithFields as dotted keys, such as "http.method", instead of
+	// nested objects, for backends that don't index nested fields
+	FlattenNestedFields bool
+
+	// EncoderOptions customize both the console and file encoders, for
+	// example their timestamp format or key names. ConsoleEncoderOptions
+	// and FileEncoderOptions are applied to one encoder after them, for
+	// example to show humanized durations on the console only.
+	EncoderOptions        []EncoderOption
+	ConsoleEncoderOptions []EncoderOption
+	FileEncoderOptions    []EncoderOption
+
+	// TimeZone is the zone every sink writes timestamps in: an IANA name
+	// such as "America/New_York", "UTC", or "Local". By default timestamps
+	// are in the clock's zone, normally local. ConsoleTimeZone overrides it
+	// for the console, for example to write UTC to files across a fleet
+	// while consoles show local time. With a zone set, the JSON and binary
+	// sinks write RFC 3339 timestamps with its offset unless the encoder
+	// options choose another format; add WithRFC3339Time to include it on
+	// the console. Import time/tzdata in binaries running where the zone
+	// database may be missing.
+	TimeZone        string
+	ConsoleTimeZone string
+
+	// StacktraceLevel is the minimum level that captures a stack trace.
+	// Defaults to "error"; "off" disables stack traces entirely.
+	StacktraceLevel string
+	// StacktraceMaxFrames limits the number of frames kept per stack trace.
+	// Zero keeps every frame.
+	StacktraceMaxFrames int
+	// StacktraceTrimPaths strips the working directory, GOPATH, and GOROOT
+	// prefixes from the file paths in stack traces
+	StacktraceTrimPaths bool
+
+	// Async moves encoding and writing to a background goroutine. Error and
+	// higher entries use a priority lane that is drained first. Field values
+	// are encoded after the call returns, so they must not be mutated.
+	Async bool
+	// AsyncQueueSize is the capacity of each async lane. Defaults to 1024.
+	AsyncQueueSize int
+	// AsyncDropOnFull drops debug, info, and warn entries instead of
+	// blocking when the async queue is full
+	AsyncDropOnFull bool
+	// AsyncCaller captures only the program counter of each entry's caller
+	// when logging, and looks up its file and line when the entry is
+	// written: on the async worker with Async, and only for entries that
+	// survive sampling. The caller is the first function outside zap and
+	// this package, so zap.AddCallerSkip and zap.WithCaller don't apply.
+	// Reload keeps the setting the logger was created with.
+	AsyncCaller bool
+	// Scopes adds the fields pushed with PushScope to the entries this
+	// logger writes. Loggers without it never look scopes up, so they pay
+	// nothing for them.
+	Scopes bool
+
+	// RateLimit limits how often each distinct level and message may be
+	// logged, in entries per second. Repeats beyond the limit are dropped
+	// and summarized in a later "suppressed N duplicates" entry. Zero
+	// disables limiting.
+	RateLimit rate.Limit
+	// RateLimitBurst is the number of repeats allowed before RateLimit
+	// applies. Defaults to 1.
+	RateLimitBurst int
+
+	// AggregateWindow holds entries back for this long and writes repeats
+	// of the same level, message, and field keys once, with
+	// aggregate_count, first_seen, and last_seen fields. Field values are
+	// taken from the first entry. DPanic and higher entries are never held.
+	// Zero disables aggregation.
+	AggregateWindow time.Duration
+
+	// ErrorSampleWindow writes the first of a run of identical error
+	// entries (same message and error text) in full, with its stack and
+	// fields, and only counts repeats for this long. When the window ends,
+	// an "error repeated N times" rollup reports them and the next
+	// occurrence is written in full again. Zero disables sampling.
+	ErrorSampleWindow time.Duration
+
+	// Session records every run to its own file at a level of its own,
+	// for CLIs whose users attach the file to support requests
+	Session *SessionConfig
+
+	// Network ships entries to a remote collector over TCP or UDP when set
+	Network *NetworkConfig
+	// Breaker guards the remote sinks with a circuit breaker when set: the
+	// network sink, and the cores of NewSentryCore and NewWebhookCore when
+	// passed to AddSink. While it is open, the network sink keeps entries
+	// buffered without dialing and the alerting sinks drop their requests
+	// rather than wait on a degraded backend. State changes are logged as
+	// the logger's own diagnostics.
+	Breaker *BreakerConfig
+
+	// DisableStderrFallback turns off the last resort line written to stderr
+	// when every sink fails to write an entry, for example because the disk
+	// is full or the entry could not be encoded
+	DisableStderrFallback bool
+
+	// FilterRules drop matching entries below a level of their own, such
+	// as a chatty logger's info entries, on top of Level. The first rule
+	// matching an entry decides. SetFilterRules and the admin API replace
+	// them on a running logger; Reload resets them to these.
+	FilterRules []FilterRule
+
+	// Hooks run after each entry the sinks accept is written, for example
+	// to count entries by level or forward errors to an alerting system.
+	// They must be fast and safe for concurrent use; errors they return
+	// are reported like write errors.
+	Hooks []func(zapcore.Entry) error
+
+	// OnFatal runs after a Fatal entry has been written and every sink
+	// flushed, just before the process exits, for example to send an alert
+	// or write a crash dump. A panic in OnFatal is reported and the process
+	// still exits.
+	OnFatal func(ent zapcore.Entry)
+	// FatalAsError writes Fatal entries at error level and returns instead
+	// of exiting, so code paths that call Fatal can be tested
+	FatalAsError bool
+	// CrashReport writes a report file on Panic and Fatal entries when set
+	CrashReport *CrashReportConfig
+	// RecentEntries sizes the in-memory ring of the latest entries at
+	// every level, whatever the sinks' levels, returned by Recent and
+	// included in crash reports. Zero, the default, disables it. While
+	// enabled, debug and trace entries are built even when no sink writes
+	// them, and Enabled reports every level.
+	RecentEntries int
+
+	// Events holds the schemas used by Logger.Event
+	Events *EventRegistry
+
+	// Clock stamps entries with their time, for tests and replay tools that
+	// need deterministic timestamps or NewMonotonicClock on hosts whose
+	// wall clock jumps. Defaults to the system clock.
+	Clock zapcore.Clock
+
+	// IDGenerator generates the IDs the logger creates: correlation IDs in
+	// CorrelationMiddleware, entry IDs, and those from NewID. Use UUIDv7,
+	// ULID, or NewSonyflake to match the IDs a downstream store sorts by.
+	// Defaults to NewCorrelationID's random UUIDs.
+	IDGenerator IDGenerator
+	// EntryIDs adds an entry_id field from IDGenerator to every entry, the
+	// same in each sink, for deduplicating and referencing entries
+	EntryIDs bool
+	// ClockSkew adds the clock's measured offset as clock_skew_ms to every
+	// entry when set
+	ClockSkew *ClockSkewConfig
+
+	// Provenance marks entries not delivered live, so analysis can tell
+	// them from live data: entries the network sink buffers while its
+	// collector is unreachable carry delivered_via "spool", original_time,
+	// and replay=true, and last resort lines end with delivered_via and
+	// original_time
+	Provenance bool
+
+	// TrackVolume counts the encoded bytes each sink writes per logger name
+	// and level, reported by Stats, to show which components produce the
+	// most log volume
+	TrackVolume bool
+
+	// Metrics receives the samples carried by Metric, Gauge, and
+	// Observation fields and WithMetric, for deriving metrics from logs
+	// and forwarding them to a backend such as StatsD. It is called on the
+	// logging goroutine and must be fast and safe for concurrent use.
+	Metrics func(MetricSample)
+
+	// TrackMetrics totals the samples per metric, reported by Stats and
+	// MetricsHandler
+	TrackMetrics bool
+
+	// Deterministic makes two runs of the same code write byte-identical
+	// output, for golden-file tests; see DeterministicEpoch
+	Deterministic bool
+
+	// SLOs are objectives tracked from the entries the logger writes and
+	// reported by Stats
+	SLOs []SLOConfig
 }
 
 // NewLogger creates a new logger instance with color support
 func NewLogger(config Config) (*Logger, error) {
-	level, err := zapcore.ParseLevel(config.Level)
+	return newLogger(config, nil)
+}
+
+// newLogger creates a logger opening its files through files, or through
+// its own set if files is nil, so that its pipelines share one handle per
+// file across reloads and a compressed file stays a single stream
+func newLogger(config Config, files *fileSet) (*Logger, error) {
+	if files == nil {
+		files = newFileSet()
+	}
+	state := &loggerState{level: zap.NewAtomicLevel(), files: files}
+	p, diags, err := buildPipeline(config, state.level, &state.filters, files)
 	if err != nil {
-		return nil, fmt.Errorf("invalid log level: %w", err)
+		return nil, err
 	}
+	state.pipe.Store(p)
+
+	// Create logger with caller information, which swapCore captures
+	// itself under AsyncCaller
+	zapLogger := zap.New(
+		&swapCore{pipe: &state.pipe, silences: &state.silences},
+		zap.WithCaller(!config.AsyncCaller),
+		zap.AddStacktrace(stackEnabler(&state.pipe)),
+		zap.WithFatalHook(fatalHook{pipe: &state.pipe}),
+		zap.WithClock(pipelineClock{pipe: &state.pipe}),
+	)
+	p.res.diag.Store(diagnosticsLogger(zapLogger))
+	diags.emit(zapLogger)
+
+	return &Logger{Logger: zapLogger, state: state}, nil
+}
+
+// NewNop returns a logger that discards everything
+func NewNop() *Logger {
+	state := &loggerState{level: zap.NewAtomicLevelAt(zapcore.FatalLevel + 1)}
+	state.pipe.Store(&pipeline{
+		core:       zapcore.NewNopCore(),
+		stackLevel: zapcore.FatalLevel + 1,
+		res:        &resources{},
+	})
+	zapLogger := zap.New(&swapCore{pipe: &state.pipe, silences: &state.silences})
+	return &Logger{Logger: zapLogger, state: state}
+}
+
+// buildPipeline builds the sinks described by config. The sinks share
+// level, which is set to config.Level, and filters, which is set to
+// config.FilterRules. Files are opened through files if it is not nil.
+func buildPipeline(config Config, level zap.AtomicLevel, filters *atomic.Pointer[filterSet], files *fileSet) (*pipeline, diagnostics, error) {
+	lvl, err := parseLevel(config.Level)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid log level: %w", err)
+	}
+	filterRules, err := newFilterSet(config.FilterRules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stackLevel, err := parseStacktraceLevel(config.StacktraceLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consoleMultilineDefault := MultilineIndent
+	if config.Format == FormatJSON {
+		consoleMultilineDefault = MultilineEscape
+	}
+	consoleMultiline, err := resolveMultiline(config.ConsoleMultiline, consoleMultilineDefault)
+	if err != nil {
+		return nil, nil, err
+	}
+	fileMultiline, err := resolveMultiline(config.FileMultiline, MultilineEscape)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consoleFraming, err := resolveFraming(config.ConsoleFraming)
+	if err != nil {
+		return nil, nil, fmt.Errorf("console sink: %w", err)
+	}
+	fileFraming, err := resolveFraming(config.FileFraming)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file sink: %w", err)
+	}
+	fileCompression, err := resolveFileCompression(config.FileCompression)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file sink: %w", err)
+	}
+	consoleOutput, jsonOutput, err := resolveOutputs(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	zone, err := resolveTimeZone(config.TimeZone)
+	if err != nil {
+		return nil, nil, err
+	}
+	consoleZone, err := resolveTimeZone(config.ConsoleTimeZone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("console: %w", err)
+	}
+	if consoleZone == nil {
+		consoleZone = zone
+	}
+	if consoleOutput == OutputStderr {
+		useStderrColors()
+	}
+
+	if config.ClockSkew != nil && config.ClockSkew.Source == nil {
+		return nil, nil, fmt.Errorf("clock skew: a source is required")
+	}
+
+	var diags diagnostics
+	checkConfig(config, &diags)
+
+	randomID := IDGenerator(newRandomID)
+	if config.Deterministic {
+		config = config.deterministic()
+		randomID = SequentialIDs("")
+	}
+
+	p := &pipeline{config: config, stackLevel: stackLevel, filters: filters, res: newResources()}
+	if config.TrackVolume {
+		p.res.volume = &volumeTracker{}
+	}
+
+	// Console core with colors
+	consoleConfig := withTimeZone(
+		applyEncoderOptions(consoleEncoderConfig(), config.EncoderOptions, config.ConsoleEncoderOptions), consoleZone)
+	var consoleEncoder zapcore.Encoder
+	switch config.Format {
+	case FormatDev:
+		consoleEncoder = newDevEncoder(consoleConfig)
+	case FormatJSON:
+		consoleEncoder = newJSONEncoder(withTimeZone(
+			applyEncoderOptions(fileEncoderConfigIn(consoleZone), config.EncoderOptions, config.ConsoleEncoderOptions), consoleZone))
+	default:
+		consoleEncoder = newConsoleEncoder(consoleConfig)
+	}
+	consoleOut := newSinkMonitor("console", consoleOutput,
+		newFramedWriter(zapcore.AddSync(stdStream(consoleOutput)), consoleFraming, false))
+	p.res.monitors = append(p.res.monitors, consoleOut)
+	consoleCore := trackVolume(newSinkCore(
+		newSafeEncoder(consoleEncoder),
+		consoleOut,
+		level,
+	), p.res.volume, "console")
+	p.sinks = append(p.sinks, newMultilineCore(newGroupCore(consoleCore), consoleMultiline, randomID))
+	names := []string{SinkConsole}
+
+	// JSON stream core if enabled, for programs reading the other stream
+	if jsonOutput != "" {
+		jsonOut := newSinkMonitor("json", jsonOutput,
+			newFramedWriter(zapcore.AddSync(stdStream(jsonOutput)), FramingNewline, false))
+		p.res.monitors = append(p.res.monitors, jsonOut)
+		jsonEncoder := newJSONEncoder(withTimeZone(
+			applyEncoderOptions(fileEncoderConfigIn(zone), config.EncoderOptions, config.FileEncoderOptions), zone))
+		jsonCore := trackVolume(newSinkCore(newSafeEncoder(jsonEncoder), jsonOut, level),
+			p.res.volume, "json")
+		p.sinks = append(p.sinks, &plainCore{Core: jsonCore})
+		names = append(names, SinkJSON)
+	}
+
+	// Validate the network sink before opening anything
+	var netConfig NetworkConfig
+	var netEncoder zapcore.Encoder
+	if config.Network != nil {
+		netConfig, err = config.Network.withDefaults()
+		if err != nil {
+			return nil, nil, err
+		}
+		netEncoder, err = newFileEncoder(netConfig.Format, withTimeZone(
+			applyEncoderOptions(fileEncoderConfigIn(zone), config.EncoderOptions, netConfig.EncoderOptions), zone))
+		if err != nil {
+			return nil, nil, fmt.Errorf("network sink: %w", err)
+		}
+	}
+
+	// File core if enabled
+	if config.EnableFile {
+		fileConfig := withTimeZone(
+			applyEncoderOptions(fileEncoderConfigIn(zone), config.EncoderOptions, config.FileEncoderOptions), zone)
+		fileEncoder, err := newFileEncoder(config.FileFormat, fileConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var fileCipher cipher.AEAD
+		if config.Encryption != nil {
+			fileCipher, err = newFileCipher(config.Encryption.Key)
+			if err != nil {
+				return nil, nil, fmt.Errorf("file sink: %w", err)
+			}
+		}
 
-	// Create console encoder with colors
-	consoleEncoderConfig := zapcore.EncoderConfig{
+		fileWriter, err := openFileSink(config.FilePath, fileCompression, files)
+		switch {
+		case err != nil && config.FileSinkOptional:
+			diags.add(zapcore.WarnLevel, "file sink disabled, logging to the console only",
+				zap.String("file_path", config.FilePath), zap.Error(err))
+		case err != nil:
+			return nil, nil, err
+		default:
+			p.res.files = append(p.res.files, fileWriter)
+			fileOut := newSinkMonitor("file", fileWriter.Name(), zapcore.AddSync(fileWriter))
+			if fileCipher != nil {
+				fileOut.out = newEncryptWriter(fileOut.out, fileCipher)
+			}
+			if config.FileFraming != "" || !isBinaryFormat(config.FileFormat) {
+				fileOut.out = newFramedWriter(fileOut.out, fileFraming, isBinaryFormat(config.FileFormat))
+			}
+			if config.FileFormat == FileFormatJSONDelta {
+				fileOut.out = newDeltaWriter(fileOut.out, fileConfig, randomID())
+			}
+			fileOut.file = fileWriter
+			p.res.monitors = append(p.res.monitors, fileOut)
+			fileCore := trackVolume(newSinkCore(
+				newSafeEncoder(fileEncoder),
+				fileOut,
+				level,
+			), p.res.volume, "file")
+			p.sinks = append(p.sinks, fileCore)
+			names = append(names, SinkFile)
+		}
+	}
+
+	// Session core if configured, at its own level
+	if config.Session != nil {
+		session, err := config.Session.withDefaults()
+		if err != nil {
+			return nil, nil, err
+		}
+		sessionLevel, _ := parseLevel(session.Level)
+		sessionWriter, err := openSessionSink(session, files)
+		if err != nil {
+			diags.add(zapcore.WarnLevel, "session recording disabled",
+				zap.String("session_dir", session.Dir), zap.Error(err))
+		} else {
+			p.res.files = append(p.res.files, sessionWriter)
+			p.res.session = sessionWriter.Name()
+			sessionOut := newSinkMonitor("session", sessionWriter.Name(), zapcore.AddSync(sessionWriter))
+			sessionOut.file = sessionWriter
+			p.res.monitors = append(p.res.monitors, sessionOut)
+			sessionEncoder := newJSONEncoder(withTimeZone(
+				applyEncoderOptions(fileEncoderConfigIn(zone), config.EncoderOptions, config.FileEncoderOptions), zone))
+			sessionCore := trackVolume(newSinkCore(newSafeEncoder(sessionEncoder), sessionOut, sessionLevel),
+				p.res.volume, "session")
+			p.sinks = append(p.sinks, &plainCore{Core: sessionCore})
+			names = append(names, SinkSession)
+		}
+	}
+
+	// Network core if configured
+	if config.Network != nil {
+		var breaker *CircuitBreaker
+		if config.Breaker != nil {
+			breaker = newSinkBreaker("network "+netConfig.Address, *config.Breaker, p.res.diag.Load)
+		}
+		p.res.network = newNetworkWriter(netConfig, breaker)
+		netOut := newSinkMonitor("network", p.res.network.Name(), p.res.network.input())
+		netOut.network = p.res.network
+		p.res.monitors = append(p.res.monitors, netOut)
+		netCore := trackVolume(newSinkCore(newSafeEncoder(netEncoder), netOut, level),
+			p.res.volume, "network")
+		if config.Provenance {
+			netCore = &spoolCore{Core: netCore, writer: p.res.network}
+		}
+		p.sinks = append(p.sinks, netCore)
+		names = append(names, SinkNetwork)
+	}
+
+	// The structured encoders already escape line breaks
+	if fileMultiline != MultilineEscape {
+		for i := 1; i < len(p.sinks); i++ {
+			p.sinks[i] = newMultilineCore(p.sinks[i], fileMultiline, randomID)
+		}
+	}
+	for i := range p.sinks {
+		p.sinks[i] = newRouteCore(names[i], wrapStackCore(p.sinks[i], config))
+		if config.Deterministic {
+			p.sinks[i] = &sortedCore{Core: p.sinks[i]}
+		}
+	}
+	p.names = names
+
+	if len(config.SLOs) > 0 {
+		for _, slo := range config.SLOs {
+			p.res.slos = append(p.res.slos, newSLOTracker(slo))
+		}
+		p.sinks = append(p.sinks, &sloCore{LevelEnabler: level, trackers: p.res.slos})
+	}
+
+	if config.Async {
+		p.res.async = newAsyncQueue(config.AsyncQueueSize, config.AsyncDropOnFull)
+	}
+	if config.AggregateWindow > 0 {
+		p.res.aggregator = newAggregator(config.AggregateWindow)
+	}
+	if config.ErrorSampleWindow > 0 {
+		p.res.errorSampler = newErrorSampler(config.ErrorSampleWindow)
+	}
+	if config.RateLimit > 0 {
+		p.res.rateLimiter = newRateLimiter(config.RateLimit, config.RateLimitBurst)
+	}
+	p.res.recent = newRecentBuffer(config.RecentEntries)
+	if config.CrashReport != nil {
+		p.res.crash = newCrashReporter(config, p.res.recent)
+	}
+	if config.ClockSkew != nil {
+		p.res.skew = newSkewMonitor(*config.ClockSkew)
+	}
+	p.res.metrics = newMetricRecorder(config)
+	p.assemble()
+
+	level.SetLevel(lvl)
+	filters.Store(filterRules)
+	return p, diags, nil
+}
+
+// openFileSink creates the directory of path and opens it through files
+func openFileSink(path, compression string, files *fileSet) (logFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := files.open(path, compression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return f, nil
+}
+
+// openSessionSink opens the session file of this process through files
+func openSessionSink(config SessionConfig, files *fileSet) (logFile, error) {
+	path, err := files.sessionFile(config)
+	if err != nil {
+		return nil, err
+	}
+	f, err := files.open(path, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session file: %w", err)
+	}
+	return f, nil
+}
+
+// consoleEncoderConfig returns the console encoder configuration, with colors
+func consoleEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
 		NameKey:        "logger",
@@ -54,11 +715,13 @@ func NewLogger(config Config) (*Logger, error) {
 		EncodeLevel:    colorLevelEncoder,
 		EncodeTime:     zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05"),
 		EncodeDuration: zapcore.SecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeCaller:   shortCallerEncoder,
 	}
+}
 
-	// Create file encoder without colors
-	fileEncoderConfig := zapcore.EncoderConfig{
+// fileEncoderConfig returns the file encoder configuration, without colors
+func fileEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
 		NameKey:        "logger",
@@ -67,175 +730,171 @@ func NewLogger(config Config) (*Logger, error) {
 		MessageKey:     "msg",
 		StacktraceKey:  "stacktrace",
 		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeLevel:    lowercaseLevelEncoder,
 		EncodeTime:     zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05"),
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
+}
 
-	var cores []zapcore.Core
-
-	// Console core with colors
-	consoleEncoder := zapcore.NewConsoleEncoder(consoleEncoderConfig)
-	consoleCore := zapcore.NewCore(
-		consoleEncoder,
-		zapcore.AddSync(os.Stdout),
-		level,
-	)
-	cores = append(cores, consoleCore)
-
-	// File core if enabled
-	if config.EnableFile {
-		if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory: %w", err)
-		}
-
-		fileWriter, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open log file: %w", err)
-		}
-
-		fileEncoder := zapcore.NewJSONEncoder(fileEncoderConfig)
-		fileCore := zapcore.NewCore(
-			fileEncoder,
-			zapcore.AddSync(fileWriter),
-			level,
-		)
-		cores = append(cores, fileCore)
+// newFileEncoder creates the encoder for Config.FileFormat
+func newFileEncoder(format string, cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch format {
+	case "", FileFormatJSON, FileFormatJSONDelta:
+		return newJSONEncoder(cfg), nil
+	case FileFormatMsgpack, FileFormatCBOR:
+		return newBinaryEncoder(format, cfg)
+	default:
+		return nil, fmt.Errorf("invalid file format %q", format)
 	}
+}
 
-	// Combine cores
-	core := zapcore.NewTee(cores...)
-
-	// Create logger with caller information
-	zapLogger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-
-	return &Logger{
-		Logger: zapLogger,
-		sugar:  zapLogger.Sugar(),
-	}, nil
+// parseStacktraceLevel resolves Config.StacktraceLevel
+func parseStacktraceLevel(s string) (zapcore.LevelEnabler, error) {
+	switch s {
+	case "":
+		return zapcore.ErrorLevel, nil
+	case "off", "none":
+		return zap.LevelEnablerFunc(func(zapcore.Level) bool { return false }), nil
+	}
+	level, err := parseLevel(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stacktrace level: %w", err)
+	}
+	return level, nil
 }
 
 // colorLevelEncoder adds colors to log levels
 func colorLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
-	var levelStr string
-	switch level {
-	case zapcore.DebugLevel:
-		levelStr = cyan("[DEBUG]")
-	case zapcore.InfoLevel:
-		levelStr = green("[INFO]")
-	case zapcore.WarnLevel:
-		levelStr = yellow("[WARN]")
-	case zapcore.ErrorLevel:
-		levelStr = red("[ERROR]")
-	case zapcore.DPanicLevel:
-		levelStr = red("[DPANIC]")
-	case zapcore.PanicLevel:
-		levelStr = red("[PANIC]")
-	case zapcore.FatalLevel:
-		levelStr = red("[FATAL]")
-	default:
-		levelStr = fmt.Sprintf("[%s]", level.String())
+	i := int(level - TraceLevel)
+	if i < 0 || i >= len(levelLabels) {
+		enc.AppendString("[" + level.String() + "]")
+		return
 	}
-	enc.AppendString(levelStr)
+	enc.AppendString(levelLabels[i].String())
 }
 
-// Sugar returns the sugared logger
+// Sugar returns the sugared logger. It is built on first use and carries
+// the same fields as the logger.
 func (l *Logger) Sugar() *zap.SugaredLogger {
-	return l.sugar
+	if s := l.sugar.Load(); s != nil {
+		return s
+	}
+	s := l.Logger.Sugar()
+	if !l.sugar.CompareAndSwap(nil, s) {
+		return l.sugar.Load()
+	}
+	return s
 }
 
 // WithField adds a field to the logger
 func (l *Logger) WithField(key string, value any) *Logger {
-	return &Logger{
-		Logger: l.Logger.With(zap.Any(key, value)),
-		sugar:  l.sugar.With(key, value),
-	}
+	return l.derive(l.Logger.With(l.appendMapField(nil, key, value)...))
 }
 
-// WithFields adds multiple fields to the logger
+// WithFields adds multiple fields to the logger, in key order so output is
+// stable across runs. Values of type map[string]any are logged as nested
+// objects. The fields are typed like WithField's, and the logger's Sugar
+// carries them as such, so sugared key-value pairs added later stay paired.
 func (l *Logger) WithFields(fields map[string]any) *Logger {
 	zapFields := make([]zap.Field, 0, len(fields))
-	for k, v := range fields {
-		zapFields = append(zapFields, zap.Any(k, v))
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		zapFields = l.appendMapField(zapFields, k, fields[k])
+	}
+	return l.derive(l.Logger.With(zapFields...))
+}
+
+// logAt logs msg with prefix at level on behalf of one of the wrapper
+// methods, attributing the entry to that method's caller. Nothing is
+// allocated when level is disabled.
+func (l *Logger) logAt(level zapcore.Level, prefix colorText, msg string, fields []zap.Field) {
+	zl := l.wrapped.Load()
+	if zl == nil {
+		zl = l.Logger.WithOptions(zap.AddCallerSkip(2))
+		l.wrapped.Store(zl)
 	}
-	return &Logger{
-		Logger: l.Logger.With(zapFields...),
-		sugar:  l.sugar.With(fields),
+	if ce := zl.Check(level, msg); ce != nil {
+		ce.Message = prefix.String() + msg
+		ce.Write(resolveFields(fields)...)
 	}
 }
 
 // Convenience methods with colors
 func (l *Logger) Success(msg string, fields ...zap.Field) {
-	l.Info(green("✓ ")+msg, fields...)
+	l.logAt(zapcore.InfoLevel, successPrefix, msg, fields)
 }
 
 func (l *Logger) Progress(msg string, fields ...zap.Field) {
-	l.Info(blue("→ ")+msg, fields...)
+	l.logAt(zapcore.InfoLevel, progressPrefix, msg, fields)
 }
 
 func (l *Logger) Warning(msg string, fields ...zap.Field) {
-	l.Warn(yellow("⚠ ")+msg, fields...)
+	l.logAt(zapcore.WarnLevel, warningPrefix, msg, fields)
 }
 
 func (l *Logger) Failure(msg string, fields ...zap.Field) {
-	l.Error(red("✗ ")+msg, fields...)
+	l.logAt(zapcore.ErrorLevel, failurePrefix, msg, fields)
 }
 
 // Structured logging methods
 func (l *Logger) LogEventProcessed(eventID int, eventName string) {
-	l.Success("Event processed",
+	l.logAt(zapcore.InfoLevel, successPrefix, "Event processed", []zap.Field{
 		zap.Int("event_id", eventID),
 		zap.String("event_name", eventName),
-	)
+	})
 }
 
 func (l *Logger) LogFileDownloaded(fileName, filePath string, fileSize int64) {
-	l.Success("File downloaded",
+	l.logAt(zapcore.InfoLevel, successPrefix, "File downloaded", []zap.Field{
 		zap.String("file_name", fileName),
 		zap.String("file_path", filePath),
 		zap.Int64("file_size", fileSize),
-	)
+	})
 }
 
 func (l *Logger) LogAPIRequest(url string, statusCode int, duration string) {
+	fields := []zap.Field{
+		zap.String("url", url),
+		zap.Int("status_code", statusCode),
+		zap.String("duration", duration),
+	}
 	if statusCode >= 200 && statusCode < 300 {
-		l.Progress("API request completed",
-			zap.String("url", url),
-			zap.Int("status_code", statusCode),
-			zap.String("duration", duration),
-		)
+		l.logAt(zapcore.InfoLevel, progressPrefix, "API request completed", fields)
 	} else {
-		l.Warning("API request failed",
-			zap.String("url", url),
-			zap.Int("status_code", statusCode),
-			zap.String("duration", duration),
-		)
+		l.logAt(zapcore.WarnLevel, warningPrefix, "API request failed", fields)
 	}
 }
 
 func (l *Logger) LogDatabaseOperation(operation, table string, count int) {
-	l.Progress("Database operation completed",
+	l.logAt(zapcore.InfoLevel, progressPrefix, "Database operation completed", []zap.Field{
 		zap.String("operation", operation),
 		zap.String("table", table),
 		zap.Int("count", count),
-	)
+	})
 }
 
 func (l *Logger) LogScrapeSession(sessionID, mode string, stats map[string]int) {
-	fields := []zap.Field{
+	if !l.Core().Enabled(zapcore.InfoLevel) {
+		return
+	}
+	fields := make([]zap.Field, 0, 2+len(stats))
+	fields = append(fields,
 		zap.String("session_id", sessionID),
 		zap.String("mode", mode),
-	}
+	)
 
 	for key, value := range stats {
 		fields = append(fields, zap.Int(key, value))
 	}
 
-	l.Success("Scrape session completed", fields...)
+	l.logAt(zapcore.InfoLevel, successPrefix, "Scrape session completed", fields)
 }
 
 // Sync flushes any buffered log entries
 func (l *Logger) Sync() error {
+	l.state.flushRateLimiters()
+	if rl := l.state.pipe.Load().res.rateLimiter; rl != nil {
+		rl.flush()
+	}
 	return l.Logger.Sync()
 }
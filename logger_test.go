@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestDisabledLevelAllocs checks that logging at a disabled level allocates
+// nothing. Fields passed to such calls still cost the one allocation of
+// their variadic slice, as with zap.
+func TestDisabledLevelAllocs(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "error"})
+	child := l.WithField("request_id", "f3a9")
+	tests := []struct {
+		name string
+		log  func()
+	}{
+		{"Debug", func() { l.Debug("cache lookup") }},
+		{"Trace", func() { l.Trace("cache lookup") }},
+		{"Info", func() { l.Info("cache lookup") }},
+		{"Success", func() { l.Success("upload complete") }},
+		{"Warning", func() { l.Warning("disk nearly full") }},
+		{"child Info", func() { child.Info("request handled") }},
+		{"child Success", func() { child.Success("request handled") }},
+		{"Sugar Debugw", func() { l.Sugar().Debugw("cache lookup", "key", "user:42") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.log); allocs != 0 {
+				t.Errorf("%s at a disabled level allocates %v times, want 0", tt.name, allocs)
+			}
+		})
+	}
+}
+
+// TestEnabledLevelAllocs checks the allocations of enabled calls against
+// the budget of at most two for a plain Info. An Info without fields costs
+// the console encoder's one; fields cost their variadic slice.
+func TestEnabledLevelAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts don't hold under the race detector")
+	}
+	l := newBenchLogger(t, Config{Level: "info"})
+	tests := []struct {
+		name   string
+		log    func()
+		budget float64
+	}{
+		{"Info", func() { l.Info("request handled") }, 1},
+		{"Info with fields", func() { l.Info("request handled", zap.String("method", "GET"), zap.Int("status", 200)) }, 2},
+		{"Success", func() { l.Success("upload complete") }, 2},
+		{"Sugar Infow", func() { l.Sugar().Infow("request handled", "method", "GET", "status", 200) }, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.log); allocs > tt.budget {
+				t.Errorf("%s allocates %v times, want at most %v", tt.name, allocs, tt.budget)
+			}
+		})
+	}
+}
+
+// TestWithAndSugar checks the fields of entries logged through loggers
+// derived with WithField, WithFields, and Sugar, as decoded from the JSON
+// the file sink writes. Repeated keys are all written, in order; readers
+// decoding the entry keep the last.
+func TestWithAndSugar(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func(l *Logger)
+		fields []string
+	}{
+		{
+			name:   "WithFields then Infow",
+			log:    func(l *Logger) { l.WithFields(map[string]any{"b": 2, "a": "x"}).Sugar().Infow("entry", "c", true) },
+			fields: []string{`a="x"`, `b=2`, `c=true`},
+		},
+		{
+			name: "nested map",
+			log: func(l *Logger) {
+				l.WithFields(map[string]any{"req": map[string]any{"id": 7}}).Sugar().Infow("entry", "n", 1)
+			},
+			fields: []string{`req={"id":7}`, `n=1`},
+		},
+		{
+			name:   "Infow overrides WithFields",
+			log:    func(l *Logger) { l.WithFields(map[string]any{"user": "alice"}).Sugar().Infow("entry", "user", "bob") },
+			fields: []string{`user="alice"`, `user="bob"`},
+		},
+		{
+			name:   "WithField twice",
+			log:    func(l *Logger) { l.WithField("k", 1).WithField("k", 2).Sugar().Infow("entry") },
+			fields: []string{`k=1`, `k=2`},
+		},
+		{
+			name:   "sugared With",
+			log:    func(l *Logger) { l.WithField("a", 1).Sugar().With("b", 2).Infow("entry", "a", 3) },
+			fields: []string{`a=1`, `b=2`, `a=3`},
+		},
+		{
+			name: "typed and loose Infow arguments",
+			log: func(l *Logger) {
+				l.WithFields(map[string]any{"a": 1}).Sugar().Infow("entry", zap.Int("b", 2), "c", "d")
+			},
+			fields: []string{`a=1`, `b=2`, `c="d"`},
+		},
+		{
+			name:   "Info overrides WithFields",
+			log:    func(l *Logger) { l.WithFields(map[string]any{"a": 1}).Info("entry", zap.Int("a", 2)) },
+			fields: []string{`a=1`, `a=2`},
+		},
+	}
+	envelope := auditEnvelopeKeys(fileEncoderConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l := newBenchLogger(t, Config{Level: "info", EnableFile: true, FilePath: path})
+			tt.log(l)
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			line := readOnlyLine(t, path)
+			parsed, err := parseJSONFields(line)
+			if err != nil {
+				t.Fatalf("entry isn't a JSON object: %s", line)
+			}
+			var fields []string
+			last := make(map[string]string)
+			for _, f := range parsed {
+				if !slices.Contains(envelope, f.key) {
+					fields = append(fields, f.key+"="+string(f.raw))
+					last[f.key] = string(f.raw)
+				}
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("fields %q, want %q", fields, tt.fields)
+			}
+
+			var decoded map[string]any
+			if err := json.Unmarshal(line, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			for key, raw := range last {
+				if got, _ := json.Marshal(decoded[key]); string(got) != raw {
+					t.Errorf("decoded %s = %s, want %s", key, got, raw)
+				}
+			}
+		})
+	}
+}
+
+// readOnlyLine returns the single line of the file at path
+func readOnlyLine(t *testing.T, path string) []byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines [][]byte
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, slices.Clone(sc.Bytes()))
+	}
+	if len(lines) != 1 {
+		t.Fatalf("file has %d lines, want 1: %q", len(lines), lines)
+	}
+	return lines[0]
+}
@@ -0,0 +1,156 @@
+// Package logmock provides Mock, a logger.FieldLogger that records the
+// calls made to it, for asserting what code under test logs without
+// capturing and parsing its output. Calls are plain values, so they
+// compare with reflect.DeepEqual or testify's assert.Equal:
+//
+//	log := logmock.New()
+//	svc := NewService(log)
+//	svc.Handle(req)
+//	assert.Equal(t, []string{"request handled"}, log.Messages(zapcore.InfoLevel))
+//	assert.True(t, log.Logged(zapcore.ErrorLevel, "retry failed"))
+package logmock
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	logger "go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Call is a logging call recorded by a Mock
+type Call struct {
+	Level   zapcore.Level
+	Message string
+	// Fields holds the call's fields and those added with WithField and
+	// WithFields, encoded as a JSON encoder would see them; nil without
+	// any
+	Fields map[string]any
+}
+
+// record holds the calls of a Mock and the loggers derived from it
+type record struct {
+	mu    sync.Mutex
+	calls []Call
+	level zapcore.Level
+}
+
+// Mock is a logger.FieldLogger recording its calls. Loggers derived with
+// WithField and WithFields record to the same Mock. It is safe for
+// concurrent use.
+type Mock struct {
+	rec    *record
+	fields []zap.Field
+}
+
+var _ logger.FieldLogger = (*Mock)(nil)
+
+// New returns a Mock recording calls at every level
+func New() *Mock {
+	return &Mock{rec: &record{level: logger.TraceLevel}}
+}
+
+// SetLevel makes Enabled report false below level and skips recording
+// those calls, for testing code that checks Enabled
+func (m *Mock) SetLevel(level zapcore.Level) {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	m.rec.level = level
+}
+
+// log records a call
+func (m *Mock) log(level zapcore.Level, msg string, fields []zap.Field) {
+	call := Call{Level: level, Message: msg}
+	if all := slices.Concat(m.fields, fields); len(all) > 0 {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range all {
+			f.AddTo(enc)
+		}
+		call.Fields = enc.Fields
+	}
+
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	if level < m.rec.level {
+		return
+	}
+	m.rec.calls = append(m.rec.calls, call)
+}
+
+// Trace records a call at trace level
+func (m *Mock) Trace(msg string, fields ...zap.Field) { m.log(logger.TraceLevel, msg, fields) }
+
+// Debug records a call at debug level
+func (m *Mock) Debug(msg string, fields ...zap.Field) { m.log(zapcore.DebugLevel, msg, fields) }
+
+// Info records a call at info level
+func (m *Mock) Info(msg string, fields ...zap.Field) { m.log(zapcore.InfoLevel, msg, fields) }
+
+// Warn records a call at warn level
+func (m *Mock) Warn(msg string, fields ...zap.Field) { m.log(zapcore.WarnLevel, msg, fields) }
+
+// Error records a call at error level
+func (m *Mock) Error(msg string, fields ...zap.Field) { m.log(zapcore.ErrorLevel, msg, fields) }
+
+// Enabled reports whether level is at or above the Mock's level
+func (m *Mock) Enabled(level zapcore.Level) bool {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	return level >= m.rec.level
+}
+
+// WithField returns a Mock recording to m with key added to every call
+func (m *Mock) WithField(key string, value any) logger.FieldLogger {
+	return &Mock{rec: m.rec, fields: append(slices.Clip(m.fields), zap.Any(key, value))}
+}
+
+// WithFields returns a Mock recording to m with fields added to every
+// call, in key order
+func (m *Mock) WithFields(fields map[string]any) logger.FieldLogger {
+	with := slices.Clip(m.fields)
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		with = append(with, zap.Any(k, fields[k]))
+	}
+	return &Mock{rec: m.rec, fields: with}
+}
+
+// Calls returns every recorded call, in order
+func (m *Mock) Calls() []Call {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	return slices.Clone(m.rec.calls)
+}
+
+// CallsAt returns the recorded calls at level, in order
+func (m *Mock) CallsAt(level zapcore.Level) []Call {
+	var calls []Call
+	for _, c := range m.Calls() {
+		if c.Level == level {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Messages returns the messages recorded at level, in order
+func (m *Mock) Messages(level zapcore.Level) []string {
+	var msgs []string
+	for _, c := range m.CallsAt(level) {
+		msgs = append(msgs, c.Message)
+	}
+	return msgs
+}
+
+// Logged reports whether msg was recorded at level
+func (m *Mock) Logged(level zapcore.Level, msg string) bool {
+	return slices.Contains(m.Messages(level), msg)
+}
+
+// Reset discards the recorded calls
+func (m *Mock) Reset() {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	m.rec.calls = nil
+}
@@ -0,0 +1,78 @@
+package logmock
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	logger "go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMock(t *testing.T) {
+	log := New()
+	log.Info("started")
+	derived := log.WithField("user", "ada").WithFields(map[string]any{"b": 2, "a": 1})
+	derived.Error("failed", zap.Error(errTest("timeout")))
+	log.Trace("wire")
+
+	want := []Call{
+		{Level: zapcore.InfoLevel, Message: "started"},
+		{Level: zapcore.ErrorLevel, Message: "failed", Fields: map[string]any{
+			"user": "ada", "a": int64(1), "b": int64(2), "error": "timeout",
+		}},
+		{Level: logger.TraceLevel, Message: "wire"},
+	}
+	if got := log.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %+v, want %+v", got, want)
+	}
+	if got := log.Messages(zapcore.ErrorLevel); !reflect.DeepEqual(got, []string{"failed"}) {
+		t.Errorf("Messages(error) = %v", got)
+	}
+	if !log.Logged(zapcore.InfoLevel, "started") || log.Logged(zapcore.WarnLevel, "started") {
+		t.Error("Logged doesn't match level and message")
+	}
+
+	log.Reset()
+	if calls := log.Calls(); calls != nil {
+		t.Errorf("calls after Reset: %v", calls)
+	}
+}
+
+func TestMockSetLevel(t *testing.T) {
+	log := New()
+	log.SetLevel(zapcore.WarnLevel)
+	derived := log.WithField("k", "v")
+	if derived.Enabled(zapcore.InfoLevel) || !derived.Enabled(zapcore.WarnLevel) {
+		t.Error("derived mock doesn't share the level")
+	}
+	derived.Info("dropped")
+	derived.Warn("kept")
+	if got := log.Messages(zapcore.WarnLevel); len(log.Calls()) != 1 || !reflect.DeepEqual(got, []string{"kept"}) {
+		t.Errorf("calls %+v, want only the warning", log.Calls())
+	}
+}
+
+func TestMockConcurrent(t *testing.T) {
+	log := New()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				log.WithField("k", 1).Info("tick")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(log.CallsAt(zapcore.InfoLevel)); n != 800 {
+		t.Errorf("recorded %d calls, want 800", n)
+	}
+}
+
+// errTest is an error with a fixed message
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
@@ -0,0 +1,391 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// followPollInterval is how often a following reader checks for new entries
+const followPollInterval = 250 * time.Millisecond
+
+// LogEntry is an entry read back from a log file
+type LogEntry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Logger  string
+	Caller  string
+	Message string
+	Stack   string
+	// Fields holds every other key of the entry
+	Fields map[string]any
+}
+
+// ReadOption configures a LogReader
+type ReadOption func(*LogReader)
+
+// WithMinLevel skips entries below level
+func WithMinLevel(level zapcore.Level) ReadOption {
+	return func(r *LogReader) { r.minLevel = level }
+}
+
+// WithTimeRange skips entries before since or at or after until; a zero
+// time leaves that end open
+func WithTimeRange(since, until time.Time) ReadOption {
+	return func(r *LogReader) { r.since, r.until = since, until }
+}
+
+// WithFieldMatch skips entries whose field key doesn't equal value, compared
+// in their JSON form so that numbers match regardless of type
+func WithFieldMatch(key string, value any) ReadOption {
+	return func(r *LogReader) {
+		want, _ := json.Marshal(value)
+		r.matches = append(r.matches, fieldMatch{key: key, want: want})
+	}
+}
+
+// WithFollow keeps reading as entries are appended, like tail -f, until the
+// iteration's context is canceled. A file that is truncated or replaced
+// through rotation is read again from the start.
+func WithFollow() ReadOption {
+	return func(r *LogReader) { r.follow = true }
+}
+
+// WithReadFormat reads a file written with Config.FileFormat format instead
+// of JSON. Binary formats can't be followed.
+func WithReadFormat(format string) ReadOption {
+	return func(r *LogReader) { r.format = format }
+}
+
+// WithDecryptionKey reads a file written with Config.Encryption, decrypting
+// its entries with key. Entries written before encryption was enabled are
+// read as they are.
+func WithDecryptionKey(key []byte) ReadOption {
+	return func(r *LogReader) { r.key = key }
+}
+
+// fieldMatch is a WithFieldMatch condition
+type fieldMatch struct {
+	key  string
+	want []byte
+}
+
+// LogReader reads entries from a log file written by the file sink. It
+// expects the default key names; files written with renamed keys still
+// read, with the renamed keys in Fields. Files written with
+// Config.FileCompression are decompressed, but can't be followed.
+type LogReader struct {
+	path string
+	file *os.File
+	r    *bufio.Reader
+	dec  *BinaryDecoder
+	// delta restores the keys omitted by FileFormatJSONDelta
+	delta *deltaState
+	// key and aead decrypt the entries of an encrypted file
+	key  []byte
+	aead cipher.AEAD
+
+	format   string
+	follow   bool
+	minLevel zapcore.Level
+	since    time.Time
+	until    time.Time
+	matches  []fieldMatch
+
+	// offset is how far into file the reader has consumed
+	offset int64
+}
+
+// OpenLogFile opens the log file at path for reading
+func OpenLogFile(path string, opts ...ReadOption) (*LogReader, error) {
+	r := &LogReader{path: path, format: FileFormatJSON, minLevel: TraceLevel}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch r.format {
+	case "", FileFormatJSON, FileFormatJSONDelta:
+	case FileFormatMsgpack, FileFormatCBOR:
+		if r.follow {
+			return nil, fmt.Errorf("following %s log files is not supported", r.format)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported log file format %q", r.format)
+	}
+	if r.key != nil {
+		aead, err := newFileCipher(r.key)
+		if err != nil {
+			return nil, err
+		}
+		r.aead = aead
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open (re)opens the file from the start
+func (r *LogReader) open() error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file = f
+	r.offset = 0
+	r.r = bufio.NewReader(f)
+	if magic, _ := r.r.Peek(2); bytes.Equal(magic, gzipMagic) {
+		if r.follow {
+			return errors.New("following compressed log files is not supported")
+		}
+		gz, err := gzip.NewReader(r.r)
+		if err != nil {
+			return fmt.Errorf("failed to read compressed log file: %w", err)
+		}
+		r.r = bufio.NewReader(gz)
+	}
+	if r.format == FileFormatJSONDelta {
+		r.delta = newDeltaState()
+	}
+	if r.format == FileFormatMsgpack || r.format == FileFormatCBOR {
+		var src io.Reader = r.r
+		if r.aead != nil {
+			src = &decryptReader{r: r.r, aead: r.aead}
+		}
+		r.dec, err = NewBinaryDecoder(src, r.format)
+	}
+	return err
+}
+
+// Close closes the file
+func (r *LogReader) Close() error {
+	return r.file.Close()
+}
+
+// Entries iterates over the entries that pass the reader's filters. A
+// malformed entry yields an error and iteration continues with the next
+// one; the caller may stop at any point. Without WithFollow, iteration ends
+// at the end of the file; with it, iteration ends when ctx is canceled.
+func (r *LogReader) Entries(ctx context.Context) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		for {
+			raw, err := r.next(ctx)
+			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+			if err != nil {
+				if !yield(LogEntry{}, err) {
+					return
+				}
+				continue
+			}
+			entry := parseLogEntry(raw)
+			if !r.keep(entry, raw) {
+				continue
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+// next reads the next raw entry, waiting for more when following
+func (r *LogReader) next(ctx context.Context) (map[string]any, error) {
+	if r.dec != nil {
+		return r.dec.Decode()
+	}
+
+	var line []byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk, err := r.r.ReadBytes('\n')
+		r.offset += int64(len(chunk))
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read log file: %w", err)
+		}
+		if !r.follow {
+			if len(bytes.TrimSpace(line)) == 0 {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+		if rotated, err := r.rotated(); err != nil {
+			return nil, err
+		} else if rotated {
+			line = nil
+			if err := r.open(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return r.next(ctx)
+	}
+	if r.aead != nil {
+		plain, err := decryptLine(r.aead, line)
+		if err != nil {
+			return nil, fmt.Errorf("log entry at offset %d: %w", r.offset-int64(len(line)), err)
+		}
+		line = bytes.TrimSpace(plain)
+	}
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("malformed log entry at offset %d: %w", r.offset-int64(len(line)), err)
+	}
+	if r.delta != nil {
+		return r.delta.apply(raw)
+	}
+	return raw, nil
+}
+
+// wait sleeps for the poll interval unless ctx ends first
+func (r *LogReader) wait(ctx context.Context) error {
+	t := time.NewTimer(followPollInterval)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rotated reports whether the file at path was truncated or replaced
+func (r *LogReader) rotated() (bool, error) {
+	current, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Between a rename and the new file's creation
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat log file: %w", err)
+	}
+	open, err := r.file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to stat log file: %w", err)
+	}
+	return !os.SameFile(current, open) || current.Size() < r.offset, nil
+}
+
+// keep reports whether entry passes the reader's filters
+func (r *LogReader) keep(entry LogEntry, raw map[string]any) bool {
+	if entry.Level < r.minLevel {
+		return false
+	}
+	if !r.since.IsZero() && entry.Time.Before(r.since) {
+		return false
+	}
+	if !r.until.IsZero() && !entry.Time.Before(r.until) {
+		return false
+	}
+	for _, m := range r.matches {
+		v, ok := raw[m.key]
+		if !ok {
+			return false
+		}
+		got, _ := json.Marshal(v)
+		if !bytes.Equal(got, m.want) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLogEntry splits a raw entry into its standard keys and fields
+func parseLogEntry(raw map[string]any) LogEntry {
+	entry := LogEntry{Level: zapcore.InfoLevel, Fields: make(map[string]any, len(raw))}
+	for k, v := range raw {
+		s, isString := v.(string)
+		switch {
+		case k == "time":
+			entry.Time = parseLogTime(v)
+		case k == "level" && isString:
+			if level, ok := LevelFromName(s); ok {
+				entry.Level = level
+			}
+		case k == "logger" && isString:
+			entry.Logger = s
+		case k == "caller" && isString:
+			entry.Caller = s
+		case k == "msg" && isString:
+			entry.Message = s
+		case k == "stacktrace" && isString:
+			entry.Stack = s
+		default:
+			entry.Fields[k] = v
+		}
+	}
+	return entry
+}
+
+// parseLogTime reads a timestamp in any of the formats the encoder options
+// produce: the default layout (in local time), RFC 3339, or a number since
+// the Unix epoch, whose unit is guessed from its magnitude
+func parseLogTime(v any) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case string:
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+			if parsed, err := time.ParseInLocation(layout, t, time.Local); err == nil {
+				return parsed
+			}
+		}
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return time.Time{}
+		}
+		return epochTime(f)
+	case float64:
+		return epochTime(t)
+	case int64:
+		return epochTime(float64(t))
+	case uint64:
+		return epochTime(float64(t))
+	}
+	return time.Time{}
+}
+
+// epochTime converts a number of seconds, milliseconds, microseconds, or
+// nanoseconds since the epoch to a time
+func epochTime(f float64) time.Time {
+	switch abs := math.Abs(f); {
+	case abs >= 1e17:
+		return time.Unix(0, int64(f))
+	case abs >= 1e14:
+		return time.UnixMicro(int64(f))
+	case abs >= 1e11:
+		return time.UnixMilli(int64(f))
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
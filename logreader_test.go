@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func FuzzLogReader(f *testing.F) {
+	formats := []string{FileFormatJSON, FileFormatJSONDelta, FileFormatMsgpack, FileFormatCBOR}
+	for i, format := range formats {
+		f.Add(uint8(i), seedEntries(f, format))
+	}
+	f.Add(uint8(0), []byte("not json\n{\"level\":\"info\",\"time\":7}\n{\"msg\":"))
+	f.Fuzz(func(t *testing.T, format uint8, data []byte) {
+		path := filepath.Join(t.TempDir(), "fuzz.log")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		r, err := OpenLogFile(path, WithReadFormat(formats[int(format)%len(formats)]))
+		if err != nil {
+			return
+		}
+		defer r.Close()
+		n := 0
+		for range r.Entries(context.Background()) {
+			if n++; n > len(data)+1 {
+				t.Fatalf("read %d entries from %d bytes", n, len(data))
+			}
+		}
+	})
+}
+
+// fixedClock is a zapcore.Clock stopped at one time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time                         { return time.Time(c) }
+func (c fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func TestLogReaderTimeZone(t *testing.T) {
+	at := time.Date(2024, 3, 10, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name   string
+		zone   string
+		format string
+	}{
+		{name: "clock zone"},
+		{name: "UTC", zone: "UTC"},
+		{name: "Tokyo", zone: "Asia/Tokyo"},
+		{name: "New York", zone: "America/New_York"},
+		{name: "Tokyo msgpack", zone: "Asia/Tokyo", format: FileFormatMsgpack},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l := newBenchLogger(t, Config{
+				Level:      "info",
+				EnableFile: true,
+				FilePath:   path,
+				FileFormat: tt.format,
+				TimeZone:   tt.zone,
+				Clock:      fixedClock(at),
+			})
+			l.Info("zoned")
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := OpenLogFile(path, WithReadFormat(tt.format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			n := 0
+			for entry, err := range r.Entries(context.Background()) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				n++
+				if !entry.Time.Equal(at) {
+					t.Errorf("read time %v, want %v", entry.Time, at)
+				}
+			}
+			if n != 1 {
+				t.Fatalf("read %d entries, want 1", n)
+			}
+		})
+	}
+}
+
+// readLogMessages returns the messages of the log file at path, read with
+// opts, stopping at the first error
+func readLogMessages(t *testing.T, path string, opts ...ReadOption) ([]string, error) {
+	t.Helper()
+	r, err := OpenLogFile(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var msgs []string
+	for entry, err := range r.Entries(context.Background()) {
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,203 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gelfChunkMagic starts each chunk of a chunked GELF datagram
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// gelfMaxChunks is the most chunks a GELF message may be split into
+const gelfMaxChunks = 128
+
+// GELFMessage is one message received by a GELF receiver
+type GELFMessage struct {
+	Version      string
+	Host         string
+	ShortMessage string
+	FullMessage  string
+	Time         time.Time
+	// Level is the syslog severity, 0 (emergency) to 7 (debug)
+	Level int
+	// Fields holds the additional fields, without their leading underscore
+	Fields map[string]any
+}
+
+// GELF is an in-memory Graylog input. Over TCP it reads NUL-terminated
+// JSON messages, as Config.Network sends them with FramingNUL; over UDP
+// it reads one message per datagram, uncompressed, gzip, or zlib, and
+// reassembles chunked messages.
+type GELF struct {
+	*streamServer
+	messages recorder[GELFMessage]
+	errs     recorder[error]
+
+	mu     sync.Mutex
+	chunks map[string][][]byte
+}
+
+// NewGELF starts a GELF receiver on network, "tcp" or "udp"
+func NewGELF(network string) (*GELF, error) {
+	g := &GELF{chunks: make(map[string][][]byte)}
+	s, err := newStreamServer(network, g.serveConn, g.servePacket)
+	if err != nil {
+		return nil, err
+	}
+	g.streamServer = s
+	return g, nil
+}
+
+// Messages returns the messages received so far, in order
+func (g *GELF) Messages() []GELFMessage {
+	return g.messages.all()
+}
+
+// Errors returns why malformed messages were dropped
+func (g *GELF) Errors() []error {
+	return g.errs.all()
+}
+
+// Wait blocks until n messages were received or ctx is done
+func (g *GELF) Wait(ctx context.Context, n int) error {
+	return g.messages.wait(ctx, n)
+}
+
+// Reset discards the recorded messages and errors
+func (g *GELF) Reset() {
+	g.messages.reset()
+	g.errs.reset()
+}
+
+// serveConn reads NUL-terminated messages from a TCP connection
+func (g *GELF) serveConn(r *bufio.Reader) {
+	for {
+		frame, err := r.ReadBytes(0)
+		if frame = bytes.TrimRight(frame, "\x00\r\n"); len(frame) > 0 {
+			g.record(frame)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// servePacket reads a datagram, reassembling chunked messages
+func (g *GELF) servePacket(p []byte) {
+	if !bytes.HasPrefix(p, gelfChunkMagic) {
+		g.record(p)
+		return
+	}
+	if len(p) < 12 {
+		g.errs.add(errors.New("truncated GELF chunk"))
+		return
+	}
+	id, seq, count := string(p[2:10]), int(p[10]), int(p[11])
+	if count == 0 || count > gelfMaxChunks || seq >= count {
+		g.errs.add(fmt.Errorf("invalid GELF chunk %d of %d", seq, count))
+		return
+	}
+
+	g.mu.Lock()
+	chunks := g.chunks[id]
+	if chunks == nil {
+		chunks = make([][]byte, count)
+		g.chunks[id] = chunks
+	}
+	if len(chunks) != count {
+		g.mu.Unlock()
+		g.errs.add(fmt.Errorf("GELF chunk count changed from %d to %d", len(chunks), count))
+		return
+	}
+	chunks[seq] = p[12:]
+	for _, c := range chunks {
+		if c == nil {
+			g.mu.Unlock()
+			return
+		}
+	}
+	delete(g.chunks, id)
+	g.mu.Unlock()
+	g.record(bytes.Join(chunks, nil))
+}
+
+// record decompresses and decodes one message
+func (g *GELF) record(p []byte) {
+	p, err := gelfDecompress(p)
+	if err != nil {
+		g.errs.add(err)
+		return
+	}
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		g.errs.add(fmt.Errorf("malformed GELF message: %w", err))
+		return
+	}
+
+	msg := GELFMessage{Fields: make(map[string]any)}
+	for k, v := range raw {
+		switch k {
+		case "version":
+			msg.Version, _ = v.(string)
+		case "host":
+			msg.Host, _ = v.(string)
+		case "short_message":
+			msg.ShortMessage, _ = v.(string)
+		case "full_message":
+			msg.FullMessage, _ = v.(string)
+		case "timestamp":
+			if n, ok := v.(json.Number); ok {
+				if secs, err := n.Float64(); err == nil {
+					msg.Time = unixSeconds(secs)
+				}
+			}
+		case "level":
+			n, ok := v.(json.Number)
+			level, err := n.Int64()
+			if !ok || err != nil {
+				// Not GELF's numeric level, such as the logger's "info"
+				msg.Fields[k] = v
+				continue
+			}
+			msg.Level = int(level)
+		default:
+			msg.Fields[strings.TrimPrefix(k, "_")] = v
+		}
+	}
+	g.messages.add(msg)
+}
+
+// gelfDecompress inflates gzip and zlib messages
+func gelfDecompress(p []byte) ([]byte, error) {
+	var zr io.ReadCloser
+	var err error
+	switch {
+	case len(p) >= 2 && p[0] == 0x1f && p[1] == 0x8b:
+		zr, err = gzip.NewReader(bytes.NewReader(p))
+	case len(p) >= 2 && p[0] == 0x78:
+		zr, err = zlib.NewReader(bytes.NewReader(p))
+	default:
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed GELF message: %w", err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed GELF message: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,141 @@
+package logtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HECEvent is one event sent to a HEC receiver
+type HECEvent struct {
+	Time       time.Time
+	Host       string
+	Source     string
+	SourceType string
+	Index      string
+	// Event is the event payload: a string or decoded JSON
+	Event any
+	// Fields are the indexed fields, if any
+	Fields map[string]any
+}
+
+// HEC is an in-memory Splunk HTTP Event Collector accepting batches of
+// JSON events at /services/collector and /services/collector/event, gzip
+// encoded or not. With WithToken, requests must carry
+// "Authorization: Splunk <token>". Responses follow Splunk's, such as
+// {"text":"Success","code":0}.
+type HEC struct {
+	*HTTPReceiver
+	events recorder[HECEvent]
+}
+
+// NewHEC starts a HEC receiver
+func NewHEC(opts ...HTTPOption) *HEC {
+	h := &HEC{}
+	h.HTTPReceiver = newHTTPReceiver("Splunk", h.accept, opts)
+	return h
+}
+
+// EventURL returns the URL of the event endpoint
+func (h *HEC) EventURL() string {
+	return h.URL() + "/services/collector/event"
+}
+
+// Events returns the events received so far, in order
+func (h *HEC) Events() []HECEvent {
+	return h.events.all()
+}
+
+// Wait blocks until n events were received or ctx is done
+func (h *HEC) Wait(ctx context.Context, n int) error {
+	return h.events.wait(ctx, n)
+}
+
+// Reset discards the recorded requests and events
+func (h *HEC) Reset() {
+	h.HTTPReceiver.Reset()
+	h.events.reset()
+}
+
+// hecResponse is HEC's response body
+type hecResponse struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+}
+
+// hecEvent is an event as sent to HEC
+type hecEvent struct {
+	Time       json.RawMessage `json:"time"`
+	Host       string          `json:"host"`
+	Source     string          `json:"source"`
+	SourceType string          `json:"sourcetype"`
+	Index      string          `json:"index"`
+	Event      any             `json:"event"`
+	Fields     map[string]any  `json:"fields"`
+}
+
+// accept records the events of a batch, all or none
+func (h *HEC) accept(r *http.Request, body []byte) (int, any) {
+	if r.Method != http.MethodPost ||
+		(r.URL.Path != "/services/collector" && r.URL.Path != "/services/collector/event") {
+		return http.StatusNotFound, hecResponse{Text: "The requested URL was not found on this server.", Code: 404}
+	}
+
+	var events []HECEvent
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	for {
+		var raw hecEvent
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6}
+		}
+		if raw.Event == nil || raw.Event == "" {
+			return http.StatusBadRequest, hecResponse{Text: "Event field cannot be blank", Code: 13}
+		}
+		t, err := hecTime(raw.Time)
+		if err != nil {
+			return http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6}
+		}
+		events = append(events, HECEvent{
+			Time:       t,
+			Host:       raw.Host,
+			Source:     raw.Source,
+			SourceType: raw.SourceType,
+			Index:      raw.Index,
+			Event:      raw.Event,
+			Fields:     raw.Fields,
+		})
+	}
+	if len(events) == 0 {
+		return http.StatusBadRequest, hecResponse{Text: "No data", Code: 5}
+	}
+	h.events.add(events...)
+	return http.StatusOK, hecResponse{Text: "Success", Code: 0}
+}
+
+// hecTime decodes an event time in epoch seconds, a number or a string
+func hecTime(raw json.RawMessage) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return time.Time{}, nil
+	}
+	s := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, err
+		}
+	}
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return unixSeconds(secs), nil
+}
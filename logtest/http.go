@@ -0,0 +1,187 @@
+package logtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// maxBodySize bounds the request bodies HTTP receivers read
+const maxBodySize = 32 << 20
+
+// HTTPOption configures an HTTP receiver
+type HTTPOption func(*httpOptions)
+
+// httpOptions holds the settings of an HTTP receiver
+type httpOptions struct {
+	token string
+}
+
+// WithToken makes the receiver refuse requests without token, sent as
+// "Authorization: Bearer <token>", or "Splunk <token>" for HEC
+func WithToken(token string) HTTPOption {
+	return func(o *httpOptions) {
+		o.token = token
+	}
+}
+
+// Request is a request received by an HTTP receiver
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	// Body is the request body, decompressed if it was gzip encoded
+	Body []byte
+	// Status is the status the receiver answered with
+	Status int
+}
+
+// HTTPReceiver is an in-memory HTTP endpoint recording the requests sent
+// to it, for testing sinks that post entries, such as webhooks. It accepts
+// every authorized request with 200.
+type HTTPReceiver struct {
+	server *httptest.Server
+	scheme string
+	opts   httpOptions
+	// accept handles an authorized request body, returning the status and
+	// the JSON response, if any
+	accept func(r *http.Request, body []byte) (int, any)
+
+	requests recorder[Request]
+
+	mu       sync.Mutex
+	failures []int
+}
+
+// NewHTTPReceiver starts a receiver accepting every request
+func NewHTTPReceiver(opts ...HTTPOption) *HTTPReceiver {
+	return newHTTPReceiver("Bearer", func(*http.Request, []byte) (int, any) {
+		return http.StatusOK, nil
+	}, opts)
+}
+
+// newHTTPReceiver starts a receiver checking tokens of the given scheme and
+// handling bodies with accept
+func newHTTPReceiver(scheme string, accept func(*http.Request, []byte) (int, any), opts []HTTPOption) *HTTPReceiver {
+	h := &HTTPReceiver{scheme: scheme, accept: accept}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	h.server = httptest.NewServer(http.HandlerFunc(h.serve))
+	return h
+}
+
+// URL returns the receiver's base URL, such as http://127.0.0.1:41234
+func (h *HTTPReceiver) URL() string {
+	return h.server.URL
+}
+
+// Close stops the receiver
+func (h *HTTPReceiver) Close() error {
+	h.server.Close()
+	return nil
+}
+
+// FailNext makes the next n requests fail with status, before their body
+// is looked at, for testing retries
+func (h *HTTPReceiver) FailNext(n, status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for range n {
+		h.failures = append(h.failures, status)
+	}
+}
+
+// Requests returns every request received, including refused and failed
+// ones, in order
+func (h *HTTPReceiver) Requests() []Request {
+	return h.requests.all()
+}
+
+// WaitRequests blocks until n requests, of any status, were received or
+// ctx is done
+func (h *HTTPReceiver) WaitRequests(ctx context.Context, n int) error {
+	return h.requests.wait(ctx, n)
+}
+
+// Reset discards the recorded requests
+func (h *HTTPReceiver) Reset() {
+	h.requests.reset()
+}
+
+// serve records and answers one request
+func (h *HTTPReceiver) serve(w http.ResponseWriter, r *http.Request) {
+	req := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()}
+	status, resp := h.handle(r, &req)
+	req.Status = status
+	h.requests.add(req)
+
+	if resp == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// handle decides the response to r, filling in req's body
+func (h *HTTPReceiver) handle(r *http.Request, req *Request) (int, any) {
+	h.mu.Lock()
+	var failure int
+	if len(h.failures) > 0 {
+		failure, h.failures = h.failures[0], h.failures[1:]
+	}
+	h.mu.Unlock()
+	if failure != 0 {
+		return failure, map[string]string{"error": "injected failure"}
+	}
+
+	if !h.authorized(r) {
+		return http.StatusUnauthorized, map[string]string{"error": "unauthorized"}
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return http.StatusBadRequest, map[string]string{"error": err.Error()}
+	}
+	req.Body = body
+	return h.accept(r, body)
+}
+
+// authorized checks the request's token, if one is required
+func (h *HTTPReceiver) authorized(r *http.Request) bool {
+	if h.opts.token == "" {
+		return true
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), h.scheme+" ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(h.opts.token)) == 1
+}
+
+// readBody reads the request body, decompressing gzip
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return body, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	defer zr.Close()
+	body, err = io.ReadAll(io.LimitReader(zr, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip body: %w", err)
+	}
+	return body, nil
+}
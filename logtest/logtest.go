@@ -0,0 +1,85 @@
+// Package logtest provides in-memory receivers for the log backends remote
+// sinks talk to: Loki, Splunk HEC, GELF, syslog, and plain HTTP. Each runs
+// on a loopback port inside the test process, records what it receives,
+// and can require credentials or fail on demand, so sinks can be tested
+// for batching, retries, and authentication without Docker or network
+// access.
+//
+//	loki := logtest.NewLoki(logtest.WithToken("secret"))
+//	defer loki.Close()
+//	loki.FailNext(2, http.StatusServiceUnavailable)
+//	// ... point the sink at loki.URL() and log ...
+//	if err := loki.Wait(ctx, 10); err != nil {
+//		t.Fatal(err)
+//	}
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// recorder holds the items a receiver has accepted and wakes the
+// goroutines waiting for them
+type recorder[T any] struct {
+	mu      sync.Mutex
+	items   []T
+	changed chan struct{}
+}
+
+// add records items and wakes the waiters
+func (r *recorder[T]) add(items ...T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, items...)
+	if r.changed != nil {
+		close(r.changed)
+		r.changed = nil
+	}
+}
+
+// all returns a copy of the recorded items
+func (r *recorder[T]) all() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]T(nil), r.items...)
+}
+
+// reset discards the recorded items
+func (r *recorder[T]) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = nil
+}
+
+// wait blocks until at least n items are recorded or ctx is done
+func (r *recorder[T]) wait(ctx context.Context, n int) error {
+	for {
+		r.mu.Lock()
+		have := len(r.items)
+		if have >= n {
+			r.mu.Unlock()
+			return nil
+		}
+		if r.changed == nil {
+			r.changed = make(chan struct{})
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("received %d of %d: %w", have, n, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// unixSeconds converts fractional epoch seconds to a time
+func unixSeconds(secs float64) time.Time {
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(math.Round(frac*1e6))*1e3)
+}
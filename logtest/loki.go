@@ -0,0 +1,119 @@
+package logtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lokiPushPath is the path of Loki's push API
+const lokiPushPath = "/loki/api/v1/push"
+
+// LokiEntry is one line pushed to a Loki receiver
+type LokiEntry struct {
+	// Labels are the labels of the line's stream
+	Labels map[string]string
+	Time   time.Time
+	Line   string
+	// Metadata is the line's structured metadata, if any
+	Metadata map[string]string
+}
+
+// Loki is an in-memory Loki accepting JSON pushes to /loki/api/v1/push,
+// gzip encoded or not. Protobuf pushes are refused with 415.
+type Loki struct {
+	*HTTPReceiver
+	entries recorder[LokiEntry]
+}
+
+// NewLoki starts a Loki receiver
+func NewLoki(opts ...HTTPOption) *Loki {
+	l := &Loki{}
+	l.HTTPReceiver = newHTTPReceiver("Bearer", l.accept, opts)
+	return l
+}
+
+// PushURL returns the URL of the push API
+func (l *Loki) PushURL() string {
+	return l.URL() + lokiPushPath
+}
+
+// Entries returns the lines pushed so far, in the order received
+func (l *Loki) Entries() []LokiEntry {
+	return l.entries.all()
+}
+
+// Wait blocks until n lines were pushed or ctx is done
+func (l *Loki) Wait(ctx context.Context, n int) error {
+	return l.entries.wait(ctx, n)
+}
+
+// Reset discards the recorded requests and lines
+func (l *Loki) Reset() {
+	l.HTTPReceiver.Reset()
+	l.entries.reset()
+}
+
+// lokiPush is the body of a JSON push
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string   `json:"stream"`
+		Values [][]json.RawMessage `json:"values"`
+	} `json:"streams"`
+}
+
+// accept records the lines of a push
+func (l *Loki) accept(r *http.Request, body []byte) (int, any) {
+	if r.Method != http.MethodPost || r.URL.Path != lokiPushPath {
+		return http.StatusNotFound, map[string]string{"error": "not found"}
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return http.StatusUnsupportedMediaType, map[string]string{"error": "only JSON pushes are supported"}
+	}
+	var push lokiPush
+	if err := json.Unmarshal(body, &push); err != nil {
+		return http.StatusBadRequest, map[string]string{"error": "invalid push: " + err.Error()}
+	}
+
+	var entries []LokiEntry
+	for _, s := range push.Streams {
+		for _, v := range s.Values {
+			entry, err := lokiValue(s.Stream, v)
+			if err != nil {
+				return http.StatusBadRequest, map[string]string{"error": err.Error()}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	l.entries.add(entries...)
+	return http.StatusNoContent, nil
+}
+
+// lokiValue decodes one [timestamp, line, metadata?] value
+func lokiValue(labels map[string]string, v []json.RawMessage) (LokiEntry, error) {
+	if len(v) < 2 || len(v) > 3 {
+		return LokiEntry{}, errors.New("value must be [timestamp, line] or [timestamp, line, metadata]")
+	}
+	var ts, line string
+	if err := json.Unmarshal(v[0], &ts); err != nil {
+		return LokiEntry{}, errors.New("timestamp must be a string of nanoseconds")
+	}
+	ns, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return LokiEntry{}, errors.New("timestamp must be a string of nanoseconds")
+	}
+	if err := json.Unmarshal(v[1], &line); err != nil {
+		return LokiEntry{}, errors.New("line must be a string")
+	}
+	entry := LokiEntry{Labels: labels, Time: time.Unix(0, ns), Line: line}
+	if len(v) == 3 {
+		if err := json.Unmarshal(v[2], &entry.Metadata); err != nil {
+			return LokiEntry{}, errors.New("metadata must be an object of strings")
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,139 @@
+package logtest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// maxPacketSize bounds the UDP datagrams stream receivers read
+const maxPacketSize = 65536
+
+// streamServer listens on a loopback TCP or UDP port, passing each TCP
+// connection or UDP datagram to a receiver
+type streamServer struct {
+	network  string
+	listener net.Listener
+	packets  net.PacketConn
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newStreamServer listens on network, "tcp" or "udp", serving TCP
+// connections with conn and UDP datagrams with packet
+func newStreamServer(network string, conn func(*bufio.Reader), packet func([]byte)) (*streamServer, error) {
+	s := &streamServer{network: network, conns: make(map[net.Conn]struct{})}
+	switch network {
+	case "tcp":
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen: %w", err)
+		}
+		s.listener = l
+		s.wg.Add(1)
+		go s.accept(conn)
+	case "udp":
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen: %w", err)
+		}
+		s.packets = pc
+		s.wg.Add(1)
+		go s.readPackets(packet)
+	default:
+		return nil, fmt.Errorf("unsupported network %q, want tcp or udp", network)
+	}
+	return s, nil
+}
+
+// Addr returns the address to send to, such as 127.0.0.1:41234
+func (s *streamServer) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.packets.LocalAddr().String()
+}
+
+// Network returns "tcp" or "udp"
+func (s *streamServer) Network() string {
+	return s.network
+}
+
+// DropConnections closes the open TCP connections, for testing how a sink
+// reconnects. New connections are still accepted.
+func (s *streamServer) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.Close()
+	}
+}
+
+// Close stops the receiver and waits for its connections to end
+func (s *streamServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	} else {
+		err = s.packets.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// accept serves TCP connections until the listener closes
+func (s *streamServer) accept(serve func(*bufio.Reader)) {
+	defer s.wg.Done()
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			serve(bufio.NewReader(c))
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+			c.Close()
+		}()
+	}
+}
+
+// readPackets serves UDP datagrams until the connection closes
+func (s *streamServer) readPackets(serve func([]byte)) {
+	defer s.wg.Done()
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := s.packets.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		serve(append([]byte(nil), buf[:n]...))
+	}
+}
@@ -0,0 +1,214 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogMessage is one message received by a syslog receiver. Messages
+// that aren't syslog, such as the JSON lines of Config.Network, have only
+// Message and Raw set.
+type SyslogMessage struct {
+	Facility int
+	Severity int
+	Time     time.Time
+	Hostname string
+	// AppName is the RFC 5424 app name or the RFC 3164 tag
+	AppName string
+	ProcID  string
+	MsgID   string
+	// StructuredData is the raw RFC 5424 structured data, "-" if none
+	StructuredData string
+	Message        string
+	Raw            string
+}
+
+// Syslog is an in-memory syslog server. Over TCP it reads messages framed
+// by octet counting (RFC 6587) or terminated by newlines or NULs; over UDP
+// it reads one message per datagram. It parses RFC 5424 and RFC 3164
+// messages.
+type Syslog struct {
+	*streamServer
+	messages recorder[SyslogMessage]
+}
+
+// NewSyslog starts a syslog receiver on network, "tcp" or "udp"
+func NewSyslog(network string) (*Syslog, error) {
+	s := &Syslog{}
+	server, err := newStreamServer(network, s.serveConn, func(p []byte) {
+		s.record(string(bytes.TrimRight(p, "\x00\r\n")))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.streamServer = server
+	return s, nil
+}
+
+// Messages returns the messages received so far, in order
+func (s *Syslog) Messages() []SyslogMessage {
+	return s.messages.all()
+}
+
+// Wait blocks until n messages were received or ctx is done
+func (s *Syslog) Wait(ctx context.Context, n int) error {
+	return s.messages.wait(ctx, n)
+}
+
+// Reset discards the recorded messages
+func (s *Syslog) Reset() {
+	s.messages.reset()
+}
+
+// serveConn reads the messages of a TCP connection
+func (s *Syslog) serveConn(r *bufio.Reader) {
+	for {
+		first, err := r.Peek(1)
+		if err != nil {
+			return
+		}
+		var msg string
+		if first[0] >= '1' && first[0] <= '9' {
+			// Octet counting: "<length> <message>"
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+			if err != nil || n > maxPacketSize {
+				return
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			msg = string(buf)
+		} else {
+			line, err := readSyslogLine(r)
+			if line == "" && err != nil {
+				return
+			}
+			msg = line
+		}
+		if msg = strings.TrimRight(msg, "\x00\r\n"); msg != "" {
+			s.record(msg)
+		}
+	}
+}
+
+// readSyslogLine reads up to a newline or NUL
+func readSyslogLine(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return b.String(), err
+		}
+		if c == '\n' || c == 0 {
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+	}
+}
+
+// record parses and records one message
+func (s *Syslog) record(raw string) {
+	if raw != "" {
+		s.messages.add(parseSyslog(raw))
+	}
+}
+
+// parseSyslog parses an RFC 5424 or RFC 3164 message
+func parseSyslog(raw string) SyslogMessage {
+	msg := SyslogMessage{Message: raw, Raw: raw}
+	rest, ok := strings.CutPrefix(raw, "<")
+	if !ok {
+		return msg
+	}
+	pri, rest, ok := strings.Cut(rest, ">")
+	p, err := strconv.Atoi(pri)
+	if !ok || err != nil || p < 0 || p > 191 {
+		return msg
+	}
+	msg.Facility, msg.Severity = p/8, p%8
+
+	if after, ok := strings.CutPrefix(rest, "1 "); ok {
+		// RFC 5424: VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+		parts := strings.SplitN(after, " ", 6)
+		if len(parts) < 6 {
+			msg.Message = after
+			return msg
+		}
+		msg.Time, _ = time.Parse(time.RFC3339Nano, parts[0])
+		msg.Hostname = syslogNil(parts[1])
+		msg.AppName = syslogNil(parts[2])
+		msg.ProcID = syslogNil(parts[3])
+		msg.MsgID = syslogNil(parts[4])
+		msg.StructuredData, msg.Message = splitStructuredData(parts[5])
+		msg.Message = strings.TrimPrefix(msg.Message, "\ufeff")
+		return msg
+	}
+
+	// RFC 3164: "Jan _2 15:04:05 HOSTNAME TAG[PID]: MSG"
+	msg.Message = rest
+	if len(rest) < len(time.Stamp)+1 {
+		return msg
+	}
+	t, err := time.Parse(time.Stamp, rest[:len(time.Stamp)])
+	if err != nil {
+		return msg
+	}
+	msg.Time = t.AddDate(time.Now().Year(), 0, 0)
+	fields := strings.SplitN(strings.TrimPrefix(rest[len(time.Stamp):], " "), " ", 2)
+	msg.Hostname = fields[0]
+	msg.Message = ""
+	if len(fields) == 2 {
+		tag, text, ok := strings.Cut(fields[1], ": ")
+		if ok && !strings.Contains(tag, " ") {
+			msg.AppName, msg.ProcID, _ = strings.Cut(strings.TrimSuffix(tag, "]"), "[")
+			msg.Message = text
+		} else {
+			msg.Message = fields[1]
+		}
+	}
+	return msg
+}
+
+// splitStructuredData splits RFC 5424 structured data from the message
+func splitStructuredData(s string) (string, string) {
+	if strings.HasPrefix(s, "-") {
+		return "-", strings.TrimPrefix(strings.TrimPrefix(s, "-"), " ")
+	}
+	// Elements are "[id k="v" ...]", with \] escaped inside values
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		inValue := false
+	element:
+		for i++; i < len(s); i++ {
+			switch {
+			case s[i] == '\\' && inValue:
+				i++
+			case s[i] == '"':
+				inValue = !inValue
+			case s[i] == ']' && !inValue:
+				break element
+			}
+		}
+		i++
+	}
+	i = min(i, len(s))
+	return s[:i], strings.TrimPrefix(s[i:], " ")
+}
+
+// syslogNil returns "" for the nil value "-"
+func syslogNil(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogValuer is implemented by types that control their own structured
+// representation, for example to hide sensitive members. WithField,
+// WithFields, Any, and the convenience methods log a LogValuer as a nested
+// object of the fields it returns instead of reflecting over it.
+type LogValuer interface {
+	LogValue() []zap.Field
+}
+
+// logValuerMarshaler adapts a LogValuer to zapcore.ObjectMarshaler
+type logValuerMarshaler struct {
+	v LogValuer
+}
+
+// MarshalLogObject adds the LogValuer's fields to enc
+func (m logValuerMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range m.v.LogValue() {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+// Any constructs a field like zap.Any, honoring LogValuer
+func Any(key string, value any) zap.Field {
+	if v, ok := value.(LogValuer); ok {
+		return zap.Object(key, logValuerMarshaler{v})
+	}
+	return zap.Any(key, value)
+}
+
+// resolveFields replaces reflected LogValuer fields with their structured
+// form. The input slice is only copied when a replacement is needed.
+func resolveFields(fields []zap.Field) []zap.Field {
+	resolved := fields
+	copied := false
+	for i, f := range fields {
+		if f.Type != zapcore.ReflectType {
+			continue
+		}
+		v, ok := f.Interface.(LogValuer)
+		if !ok {
+			continue
+		}
+		if !copied {
+			resolved = append([]zap.Field(nil), fields...)
+			copied = true
+		}
+		resolved[i] = zap.Object(f.Key, logValuerMarshaler{v})
+	}
+	return resolved
+}
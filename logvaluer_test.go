@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// account logs its ID but never its password
+type account struct {
+	ID       string
+	Password string
+}
+
+func (a account) LogValue() []zap.Field {
+	return []zap.Field{zap.String("id", a.ID)}
+}
+
+func TestLogValuer(t *testing.T) {
+	acct := account{ID: "a1", Password: "hunter2"}
+	tests := []struct {
+		name string
+		log  func(l *Logger)
+	}{
+		{"Any", func(l *Logger) { l.Info("login", Any("account", acct)) }},
+		{"WithField", func(l *Logger) { l.WithField("account", acct).Info("login") }},
+		{"WithFields", func(l *Logger) { l.WithFields(map[string]any{"account": acct}).Info("login") }},
+		{"Success with zap.Any", func(l *Logger) { l.Success("login", zap.Any("account", acct)) }},
+		{"context", func(l *Logger) {
+			l.Ctx(ContextWithFields(context.Background(), zap.Any("account", acct))).Info("login")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+			tt.log(l)
+
+			got, _ := logs.All()[0].ContextMap()["account"].(map[string]any)
+			if want := map[string]any{"id": "a1"}; !maps.Equal(got, want) {
+				t.Errorf("account logged as %v, want %v", logs.All()[0].ContextMap()["account"], want)
+			}
+		})
+	}
+}
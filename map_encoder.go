@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// mapEncoder accumulates context fields in memory for the encoders defined
+// in this package. It tracks open namespaces so clones stay independent.
+type mapEncoder struct {
+	*zapcore.MapObjectEncoder
+	namespace []string
+}
+
+// newMapEncoder creates an empty mapEncoder
+func newMapEncoder() mapEncoder {
+	return mapEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder()}
+}
+
+// OpenNamespace records the namespace path so clones can restore it
+func (e *mapEncoder) OpenNamespace(key string) {
+	e.namespace = append(e.namespace, key)
+	e.MapObjectEncoder.OpenNamespace(key)
+}
+
+// AddReflected stores value in its JSON-normalized form, so later encoding
+// never re-runs the value's marshalers and the caller may mutate it freely
+func (e *mapEncoder) AddReflected(key string, value any) error {
+	normalized, err := normalizeReflected(value)
+	if err != nil {
+		return err
+	}
+	return e.MapObjectEncoder.AddReflected(key, normalized)
+}
+
+// normalizeReflected converts value to the generic form produced by
+// decoding its JSON encoding, with numbers kept as json.Number
+func normalizeReflected(value any) (normalized any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic marshaling value: %v", r)
+		}
+	}()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	err = dec.Decode(&normalized)
+	return normalized, err
+}
+
+// clone copies the accumulated fields, including open namespaces
+func (e *mapEncoder) clone() mapEncoder {
+	c := mapEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		namespace:        append([]string(nil), e.namespace...),
+	}
+	copyNamespaced(c.MapObjectEncoder, e.Fields, e.namespace)
+	return c
+}
+
+// withFields returns a clone with fields added
+func (e *mapEncoder) withFields(fields []zapcore.Field) mapEncoder {
+	c := e.clone()
+	for _, f := range fields {
+		f.AddTo(&c)
+	}
+	return c
+}
+
+// copyNamespaced replays src into dst, reopening each namespace in path so
+// that later fields land in fresh maps rather than the original's
+func copyNamespaced(dst *zapcore.MapObjectEncoder, src map[string]any, path []string) {
+	for k, v := range src {
+		if len(path) > 0 && k == path[0] {
+			continue
+		}
+		dst.AddReflected(k, v)
+	}
+	if len(path) == 0 {
+		return
+	}
+	dst.OpenNamespace(path[0])
+	nested, _ := src[path[0]].(map[string]any)
+	copyNamespaced(dst, nested, path[1:])
+}
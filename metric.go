@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// metricKeyPrefix starts the key of each metric field, so log pipelines
+// can derive metrics from the written entries too
+const metricKeyPrefix = "metric."
+
+// maxMetricNames bounds the metric names Config.TrackMetrics totals;
+// samples of names beyond it are only forwarded
+const maxMetricNames = 1000
+
+// Kinds of metric samples
+const (
+	// MetricCounter samples are added up
+	MetricCounter = "counter"
+	// MetricGauge samples replace the previous value
+	MetricGauge = "gauge"
+	// MetricSummary samples are observations, such as durations, counted
+	// and added up
+	MetricSummary = "summary"
+)
+
+// metricTag marks a field as a metric sample of a kind
+type metricTag string
+
+// Metric returns a field carrying a sample that adds delta to the counter
+// name, written as "metric.<name>": delta in every sink. Entries carrying
+// it feed Config.Metrics and Config.TrackMetrics.
+func Metric(name string, delta float64) zap.Field {
+	return metricField(MetricCounter, name, delta)
+}
+
+// Gauge returns a field carrying a sample that sets the gauge name to value
+func Gauge(name string, value float64) zap.Field {
+	return metricField(MetricGauge, name, value)
+}
+
+// Observation returns a field carrying an observation of value, such as a
+// duration in seconds, for the summary name
+func Observation(name string, value float64) zap.Field {
+	return metricField(MetricSummary, name, value)
+}
+
+// metricField returns a float field tagged with kind, which encoders write
+// like any other
+func metricField(kind, name string, value float64) zap.Field {
+	return zap.Field{
+		Key:       metricKeyPrefix + name,
+		Type:      zapcore.Float64Type,
+		Integer:   int64(math.Float64bits(value)),
+		Interface: metricTag(kind),
+	}
+}
+
+// WithMetric returns a logger whose entries each add delta to the counter
+// name, for example l.WithMetric("orders_processed", 5).Info("batch done").
+// Every entry logged through the returned logger counts, so derive it for
+// the entry carrying the sample; use Metric, Gauge, or Observation fields
+// for a single entry.
+func (l *Logger) WithMetric(name string, delta float64) *Logger {
+	return l.derive(l.Logger.With(Metric(name, delta)))
+}
+
+// MetricSample is a metric sample carried by an entry
+type MetricSample struct {
+	// Name is the metric's name, without the "metric." prefix
+	Name string
+	// Kind is MetricCounter, MetricGauge, or MetricSummary
+	Kind  string
+	Value float64
+	// Time, Level, and Logger describe the entry carrying the sample
+	Time   time.Time
+	Level  zapcore.Level
+	Logger string
+}
+
+// MetricStat totals the samples of one metric for Config.TrackMetrics
+type MetricStat struct {
+	Name string
+	Kind string
+	// Value is the sum of a counter's or summary's samples, or a gauge's
+	// latest value
+	Value float64
+	// Samples is the number of samples recorded
+	Samples uint64
+}
+
+// metricRecorder takes the samples of every entry for a pipeline
+type metricRecorder struct {
+	forward func(MetricSample)
+
+	mu sync.Mutex
+	// totals is nil unless Config.TrackMetrics is set
+	totals map[string]*MetricStat
+}
+
+// newMetricRecorder creates the recorder for config, or returns nil if
+// metrics are neither forwarded nor tracked
+func newMetricRecorder(config Config) *metricRecorder {
+	if config.Metrics == nil && !config.TrackMetrics {
+		return nil
+	}
+	r := &metricRecorder{forward: config.Metrics}
+	if config.TrackMetrics {
+		r.totals = make(map[string]*MetricStat)
+	}
+	return r
+}
+
+// record takes one sample
+func (r *metricRecorder) record(s MetricSample) {
+	if r.forward != nil {
+		r.forward(s)
+	}
+	if r.totals == nil {
+		return
+	}
+	key := s.Kind + "|" + s.Name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.totals[key]
+	if !ok {
+		if len(r.totals) >= maxMetricNames {
+			return
+		}
+		t = &MetricStat{Name: s.Name, Kind: s.Kind}
+		r.totals[key] = t
+	}
+	if s.Kind == MetricGauge {
+		t.Value = s.Value
+	} else {
+		t.Value += s.Value
+	}
+	t.Samples++
+}
+
+// snapshot returns the totals ordered by kind and name
+func (r *metricRecorder) snapshot() []MetricStat {
+	r.mu.Lock()
+	stats := make([]MetricStat, 0, len(r.totals))
+	for _, t := range r.totals {
+		stats = append(stats, *t)
+	}
+	r.mu.Unlock()
+	slices.SortFunc(stats, func(a, b MetricStat) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+	return stats
+}
+
+// metricCore extracts the metric samples of every entry, at any level and
+// before rate limiting or sampling can drop it, so that metrics don't
+// depend on verbosity. It wraps the whole pipeline and adds itself next to
+// it on Check; the wrapped core writes the entry through its own Check.
+type metricCore struct {
+	zapcore.Core
+	rec *metricRecorder
+	// context holds the metric fields added with With
+	context []zapcore.Field
+}
+
+// Enabled reports true, as every level may carry samples
+func (c *metricCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns a child core, remembering the metric fields among fields
+func (c *metricCore) With(fields []zapcore.Field) zapcore.Core {
+	context := slices.Clip(c.context)
+	for _, f := range fields {
+		if isMetricField(f) {
+			context = append(context, f)
+		}
+	}
+	return &metricCore{Core: c.Core.With(fields), rec: c.rec, context: context}
+}
+
+// Check defers to the wrapped core, then adds this core to take the
+// samples
+func (c *metricCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		ce = c.Core.Check(ent, ce)
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write records the entry's samples without writing it
+func (c *metricCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	for _, list := range [][]zapcore.Field{c.context, fields} {
+		for _, f := range list {
+			if !isMetricField(f) {
+				continue
+			}
+			c.rec.record(MetricSample{
+				Name:   strings.TrimPrefix(f.Key, metricKeyPrefix),
+				Kind:   string(f.Interface.(metricTag)),
+				Value:  math.Float64frombits(uint64(f.Integer)),
+				Time:   ent.Time,
+				Level:  ent.Level,
+				Logger: ent.LoggerName,
+			})
+		}
+	}
+	return nil
+}
+
+// isMetricField reports whether f was created by Metric, Gauge, or
+// Observation
+func isMetricField(f zapcore.Field) bool {
+	_, ok := f.Interface.(metricTag)
+	return ok && f.Type == zapcore.Float64Type
+}
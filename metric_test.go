@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var (
+		mu        sync.Mutex
+		forwarded []MetricSample
+	)
+	l := newBenchLogger(t, Config{
+		Level: "warn", Format: FormatJSON, EnableFile: true, FilePath: path,
+		TrackMetrics: true,
+		Metrics: func(s MetricSample) {
+			mu.Lock()
+			defer mu.Unlock()
+			forwarded = append(forwarded, s)
+		},
+	})
+
+	// Samples count at every level, not only the ones written
+	l.WithMetric("orders", 5).Debug("batch done")
+	l.Info("queue", Gauge("queue_depth", 3))
+	l.Info("queue", Gauge("queue_depth", 7))
+	l.Trace("request", Observation("latency_seconds", 0.25))
+	l.Named("api").Warn("slow request", Metric("orders", 2), Observation("latency_seconds", 1.5))
+
+	want := []MetricStat{
+		{Name: "orders", Kind: MetricCounter, Value: 7, Samples: 2},
+		{Name: "queue_depth", Kind: MetricGauge, Value: 7, Samples: 2},
+		{Name: "latency_seconds", Kind: MetricSummary, Value: 1.75, Samples: 2},
+	}
+	if got := l.Stats().Metrics; !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats().Metrics = %+v, want %+v", got, want)
+	}
+
+	mu.Lock()
+	if len(forwarded) != 6 {
+		t.Fatalf("forwarded %d samples, want 6", len(forwarded))
+	}
+	last := forwarded[5]
+	mu.Unlock()
+	if last.Name != "latency_seconds" || last.Kind != MetricSummary || last.Value != 1.5 ||
+		last.Level != zapcore.WarnLevel || last.Logger != "api" || last.Time.IsZero() {
+		t.Errorf("last sample %+v", last)
+	}
+
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	line := string(readOnlyLine(t, path))
+	if !strings.Contains(line, `"metric.orders":2`) || !strings.Contains(line, `"metric.latency_seconds":1.5`) {
+		t.Errorf("file entry %s is missing its metric fields", line)
+	}
+}
+
+func TestMetricRecorderLimit(t *testing.T) {
+	rec := newMetricRecorder(Config{TrackMetrics: true})
+	for i := range maxMetricNames + 10 {
+		rec.record(MetricSample{Name: "m" + strconv.Itoa(i), Kind: MetricCounter, Value: 1})
+	}
+	rec.record(MetricSample{Name: "m", Kind: MetricCounter, Value: 1})
+	if n := len(rec.snapshot()); n != maxMetricNames {
+		t.Errorf("tracked %d names, want %d", n, maxMetricNames)
+	}
+	if newMetricRecorder(Config{}) != nil {
+		t.Error("recorder created without Metrics or TrackMetrics")
+	}
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported values for Config.ConsoleMultiline and Config.FileMultiline
+const (
+	// MultilineEscape writes line breaks in messages as \n, keeping each
+	// entry on one line (the file default)
+	MultilineEscape = "escape"
+	// MultilineIndent indents the continuation lines of a message (the
+	// console default)
+	MultilineIndent = "indent"
+	// MultilineSplit writes each line of a message as its own entry, sharing
+	// a multiline_id field and numbered by multiline_part. Fields and the
+	// stack trace go with the first part.
+	MultilineSplit = "split"
+)
+
+// multilineIndent prefixes continuation lines under MultilineIndent
+const multilineIndent = "    "
+
+// resolveMultiline validates mode, returning def when it is empty
+func resolveMultiline(mode, def string) (string, error) {
+	switch mode {
+	case "":
+		return def, nil
+	case MultilineEscape, MultilineIndent, MultilineSplit:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid multiline mode %q", mode)
+}
+
+// multilineCore rewrites messages containing line breaks before a sink
+// encodes them
+type multilineCore struct {
+	zapcore.Core
+	mode string
+	// newID correlates the parts of a split entry
+	newID IDGenerator
+}
+
+// newMultilineCore wraps a sink core
+func newMultilineCore(core zapcore.Core, mode string, newID IDGenerator) zapcore.Core {
+	return &multilineCore{Core: core, mode: mode, newID: newID}
+}
+
+// With returns a child core with the same mode
+func (c *multilineCore) With(fields []zapcore.Field) zapcore.Core {
+	return &multilineCore{Core: c.Core.With(fields), mode: c.mode, newID: c.newID}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *multilineCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write applies the mode to a multi-line message
+func (c *multilineCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !strings.ContainsAny(ent.Message, "\r\n") {
+		return c.Core.Write(ent, fields)
+	}
+	switch c.mode {
+	case MultilineEscape:
+		ent.Message = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(ent.Message)
+	case MultilineIndent:
+		ent.Message = strings.Join(messageLines(ent.Message), "\n"+multilineIndent)
+	case MultilineSplit:
+		return c.writeSplit(ent, fields)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// writeSplit writes each line of the message as a separate entry
+func (c *multilineCore) writeSplit(ent zapcore.Entry, fields []zapcore.Field) error {
+	lines := messageLines(ent.Message)
+	id := c.newID()
+	var errs []error
+	for i, line := range lines {
+		part := ent
+		part.Message = line
+		parts := []zapcore.Field{
+			zap.String("multiline_id", id),
+			zap.Int("multiline_part", i+1),
+			zap.Int("multiline_parts", len(lines)),
+		}
+		if i == 0 {
+			parts = append(append([]zapcore.Field(nil), fields...), parts...)
+		} else {
+			part.Stack = ""
+		}
+		if err := c.Core.Write(part, parts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// messageLines splits a message on \n, \r\n, or \r
+func messageLines(msg string) []string {
+	msg = strings.ReplaceAll(msg, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(msg, "\r", "\n"), "\n")
+}
+
+// newRandomID returns a random ID, such as the one correlating the parts
+// of a split entry
+func newRandomID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMultiline(t *testing.T) {
+	tests := []struct {
+		mode string
+		msg  string
+		want []string
+	}{
+		{MultilineEscape, "first\nsecond\r\nthird", []string{`first\nsecond\r\nthird`}},
+		{MultilineIndent, "first\nsecond\r\nthird", []string{"first\n    second\n    third"}},
+		{MultilineSplit, "first\nsecond\rthird", []string{"first", "second", "third"}},
+		{MultilineSplit, "one line", []string{"one line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.DebugLevel)
+			core := newMultilineCore(obs, tt.mode, func() string { return "id1" })
+			ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: tt.msg, Stack: "main.main"}
+			if err := core.Write(ent, []zap.Field{zap.String("user", "ana")}); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, e := range logs.All() {
+				got = append(got, e.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("messages = %q, want %q", got, tt.want)
+			}
+			if tt.mode != MultilineSplit || len(tt.want) == 1 {
+				return
+			}
+			for i, e := range logs.All() {
+				fields := e.ContextMap()
+				if fields["multiline_id"] != "id1" || fields["multiline_part"] != int64(i+1) || fields["multiline_parts"] != int64(len(tt.want)) {
+					t.Errorf("part %d fields = %v", i+1, fields)
+				}
+				if _, ok := fields["user"]; ok != (i == 0) {
+					t.Errorf("part %d has user field: %v", i+1, ok)
+				}
+				if (e.Stack != "") != (i == 0) {
+					t.Errorf("part %d stack = %q", i+1, e.Stack)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveMultiline(t *testing.T) {
+	if got, err := resolveMultiline("", MultilineIndent); err != nil || got != MultilineIndent {
+		t.Errorf("resolveMultiline(\"\") = %q, %v", got, err)
+	}
+	if _, err := resolveMultiline("wrap", MultilineIndent); err == nil {
+		t.Error("resolveMultiline accepted an unknown mode")
+	}
+}
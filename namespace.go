@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"maps"
+	"slices"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Namespace returns a logger that nests every field added later, by
+// WithField, WithFields, or a logging call, in an object under name. For
+// example, l.Namespace("http").WithField("method", "GET") logs
+// {"http": {"method": "GET"}}, even when Config.FlattenNestedFields is set.
+func (l *Logger) Namespace(name string) *Logger {
+	return l.derive(l.Logger.With(zap.Namespace(name)))
+}
+
+// fieldMap encodes a map[string]any as a nested object, recursively and in
+// key order
+type fieldMap map[string]any
+
+// MarshalLogObject writes the map's entries
+func (m fieldMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if nested, ok := m[k].(map[string]any); ok {
+			if err := enc.AddObject(k, fieldMap(nested)); err != nil {
+				return err
+			}
+			continue
+		}
+		Any(k, m[k]).AddTo(enc)
+	}
+	return nil
+}
+
+// appendMapField appends the field for key and value to fields. A
+// map[string]any value becomes a nested object, or dotted keys such as
+// "http.method" when Config.FlattenNestedFields is set.
+func (l *Logger) appendMapField(fields []zap.Field, key string, value any) []zap.Field {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return append(fields, Any(key, value))
+	}
+	if !l.state.pipe.Load().config.FlattenNestedFields {
+		return append(fields, zap.Object(key, fieldMap(m)))
+	}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		fields = l.appendMapField(fields, key+"."+k, m[k])
+	}
+	return fields
+}
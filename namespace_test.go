@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNestedFields(t *testing.T) {
+	request := map[string]any{"method": "GET", "headers": map[string]any{"accept": "json"}}
+	tests := []struct {
+		name    string
+		flatten bool
+		log     func(l *Logger)
+		want    map[string]any
+	}{
+		{"namespace", false, func(l *Logger) {
+			l.WithField("service", "api").Namespace("http").WithField("method", "GET").Info("request", zap.Int("status", 200))
+		}, map[string]any{"service": "api", "http": map[string]any{"method": "GET", "status": float64(200)}}},
+		{"namespace ignores flattening", true, func(l *Logger) {
+			l.Namespace("http").WithField("method", "GET").Info("request")
+		}, map[string]any{"http": map[string]any{"method": "GET"}}},
+		{"nested map", false, func(l *Logger) {
+			l.WithField("http", request).Info("request")
+		}, map[string]any{"http": map[string]any{"method": "GET", "headers": map[string]any{"accept": "json"}}}},
+		{"flattened map", true, func(l *Logger) {
+			l.WithFields(map[string]any{"http": request}).Info("request")
+		}, map[string]any{"http.method": "GET", "http.headers.accept": "json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l := newBenchLogger(t, Config{EnableFile: true, FilePath: path, FlattenNestedFields: tt.flatten})
+			tt.log(l)
+			if err := l.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(readOnlyLine(t, path), &got); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []string{"time", "level", "msg", "caller"} {
+				delete(got, key)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
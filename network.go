@@ -0,0 +1,427 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// NetworkConfig configures a sink that ships entries to a remote collector
+type NetworkConfig struct {
+	// Protocol is "tcp" or "udp"
+	Protocol string
+	// Address is the collector's host:port
+	Address string
+	// Framing separates entries on the wire: FramingNewline (the default),
+	// FramingCRLF, FramingNUL, or FramingLength
+	Framing string
+	// Format is the entry encoding: "json" (the default), "msgpack", or "cbor"
+	Format string
+	// EncoderOptions customize the encoder after Config.EncoderOptions
+	EncoderOptions []EncoderOption
+	// TLS enables TLS over TCP when set
+	TLS *tls.Config
+
+	// BufferSize is the number of entries held while the collector is
+	// unreachable; the oldest are dropped beyond it. Defaults to 10000.
+	BufferSize int
+	// DialTimeout bounds each connection attempt. Defaults to 5 seconds.
+	DialTimeout time.Duration
+	// WriteTimeout bounds each write. Defaults to 10 seconds.
+	WriteTimeout time.Duration
+	// MaxBackoff caps the delay between reconnection attempts, which
+	// doubles from 100ms after each failure. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// Warmup, when set, makes the sink connect at startup rather than on
+	// its first entry, holding the entries written until then in a
+	// WarmupBuffer and replaying them in order once connected. Its
+	// DropNewest policy keeps the first entries of a startup that can't
+	// connect, where BufferSize drops the oldest.
+	Warmup *WarmupConfig
+}
+
+// withDefaults fills in unset fields and validates the rest
+func (c NetworkConfig) withDefaults() (NetworkConfig, error) {
+	switch c.Protocol {
+	case "tcp", "udp":
+	default:
+		return c, fmt.Errorf("invalid network protocol %q", c.Protocol)
+	}
+	if c.Address == "" {
+		return c, errors.New("network address is required")
+	}
+	if c.Format == FileFormatJSONDelta {
+		return c, fmt.Errorf("network sink: unsupported format %q", c.Format)
+	}
+	framing, err := resolveFraming(c.Framing)
+	if err != nil {
+		return c, fmt.Errorf("network sink: %w", err)
+	}
+	c.Framing = framing
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 5 * time.Second
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+	return c, nil
+}
+
+// initialBackoff is the first delay before reconnecting
+const initialBackoff = 100 * time.Millisecond
+
+// networkWriter buffers framed entries and writes them to a collector from
+// a background goroutine, reconnecting with backoff after failures. Writes
+// never block on the network.
+type networkWriter struct {
+	config NetworkConfig
+	// breaker, if set, keeps the worker from dialing while it is open
+	breaker *CircuitBreaker
+	// warmup, if set, holds entries until the first connection
+	warmup *WarmupBuffer
+
+	mu    sync.Mutex
+	cond  *sync.Cond
+	queue [][]byte
+	// sending is the number of entries taken by the worker and not yet sent
+	sending   int
+	connected bool
+	// down is set when the last connection attempt or write failed
+	down   bool
+	closed bool
+	// lastErr is the latest connection or send failure
+	lastErr   string
+	lastErrAt time.Time
+
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newNetworkWriter starts a writer for config, which must have defaults
+// applied, guarded by breaker if it isn't nil
+func newNetworkWriter(config NetworkConfig, breaker *CircuitBreaker) *networkWriter {
+	w := &networkWriter{
+		config:  config,
+		breaker: breaker,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	if config.Warmup != nil {
+		w.warmup = NewWarmupBuffer(w, config.Warmup.Capacity, config.Warmup.Policy)
+	}
+	go w.run()
+	return w
+}
+
+// input returns the WriteSyncer entries are written to: the warmup buffer
+// if there is one, or the writer itself
+func (w *networkWriter) input() zapcore.WriteSyncer {
+	if w.warmup != nil {
+		return warmupInput{WarmupBuffer: w.warmup, w: w}
+	}
+	return w
+}
+
+// warmupInput is the warmup buffer of a network writer, whose Sync reports
+// the entries it holds while the collector is unreachable, as the writer's
+// own Sync does for its queue
+type warmupInput struct {
+	*WarmupBuffer
+	w *networkWriter
+}
+
+// Sync fails while entries are held for an unreachable collector
+func (in warmupInput) Sync() error {
+	if n := in.buffered(); n > 0 && in.w.spooling() {
+		return fmt.Errorf("%s: not connected, %d entries buffered", in.w.Name(), n)
+	}
+	return in.WarmupBuffer.Sync()
+}
+
+// Name identifies the collector in errors
+func (w *networkWriter) Name() string {
+	return w.config.Protocol + "://" + w.config.Address
+}
+
+// Write frames p and queues it, dropping the oldest entry if the buffer is full
+func (w *networkWriter) Write(p []byte) (int, error) {
+	frame := w.frame(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, fmt.Errorf("%s: writer is closed", w.Name())
+	}
+	if len(w.queue) >= w.config.BufferSize {
+		w.queue = w.queue[1:]
+		w.dropped.Add(1)
+	}
+	w.queue = append(w.queue, frame)
+	w.cond.Broadcast()
+	return len(p), nil
+}
+
+// frame copies an encoded entry into its wire format
+func (w *networkWriter) frame(p []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(p) + 4)
+	writeFrame(&buf, p, w.config.Framing, isBinaryFormat(w.config.Format))
+	return buf.Bytes()
+}
+
+// Sync waits until every queued entry has been sent. It returns an error
+// without waiting if the collector is unreachable, since entries stay
+// buffered until it comes back.
+func (w *networkWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue)+w.sending > 0 && !w.down && !w.closed {
+		w.cond.Wait()
+	}
+	if n := len(w.queue) + w.sending; n > 0 && w.down {
+		return fmt.Errorf("%s: not connected, %d entries buffered", w.Name(), n)
+	}
+	return nil
+}
+
+// Close sends what it can and stops the writer. Entries still buffered
+// while the collector is unreachable are dropped.
+func (w *networkWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.done
+		return nil
+	}
+	w.closed = true
+	close(w.stop)
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+	held := 0
+	if w.warmup != nil {
+		held = w.warmup.discard()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n := len(w.queue) + held; n > 0 {
+		w.dropped.Add(uint64(len(w.queue)))
+		w.queue = nil
+		return fmt.Errorf("%s: %d entries not sent", w.Name(), n)
+	}
+	return nil
+}
+
+// stats returns a snapshot of the writer's state
+func (w *networkWriter) stats() NetworkStats {
+	var held int
+	var heldDropped uint64
+	if w.warmup != nil {
+		// Read before taking mu: Ready writes to w holding the buffer's lock
+		held, heldDropped = w.warmup.buffered(), w.warmup.Dropped()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return NetworkStats{
+		Address:   w.Name(),
+		Connected: w.connected,
+		Buffered:  len(w.queue) + w.sending + held,
+		Dropped:   w.dropped.Load() + heldDropped,
+	}
+}
+
+// spooling reports whether entries written now wait for a reconnection,
+// because the last connection attempt or write failed
+func (w *networkWriter) spooling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.down
+}
+
+// run sends queued entries until the writer is closed
+func (w *networkWriter) run() {
+	defer close(w.done)
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	backoff := initialBackoff
+	if w.warmup != nil {
+		if conn = w.connect(&backoff); conn == nil {
+			return
+		}
+		// Replays into the queue, which the loop below sends
+		_ = w.warmup.Ready()
+	}
+
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		batch := w.queue
+		w.queue = nil
+		w.sending = len(batch)
+		w.mu.Unlock()
+
+		for len(batch) > 0 {
+			var err error
+			if conn == nil && w.breaker != nil && !w.breaker.allow() {
+				// Entries stay buffered until the breaker lets a dial through
+				if !w.sleep(backoff) {
+					w.requeue(batch)
+					return
+				}
+				backoff = min(backoff*2, w.config.MaxBackoff)
+				continue
+			}
+			if conn == nil {
+				conn, err = w.dial()
+				w.record(err)
+				if err == nil {
+					backoff = initialBackoff
+					w.setConnected(true)
+				}
+			}
+			if conn != nil {
+				_ = conn.SetWriteDeadline(time.Now().Add(w.config.WriteTimeout))
+				if _, err = conn.Write(batch[0]); err == nil {
+					batch = batch[1:]
+					w.sent()
+					continue
+				}
+				w.record(err)
+				conn.Close()
+				conn = nil
+			}
+
+			w.fail(err)
+			if !w.sleep(backoff) {
+				w.requeue(batch)
+				return
+			}
+			backoff = min(backoff*2, w.config.MaxBackoff)
+		}
+
+	}
+}
+
+// connect dials until a connection is made, for a writer with a warmup
+// buffer, returning nil if the writer is closed first
+func (w *networkWriter) connect(backoff *time.Duration) net.Conn {
+	for {
+		if w.breaker == nil || w.breaker.allow() {
+			conn, err := w.dial()
+			w.record(err)
+			if err == nil {
+				*backoff = initialBackoff
+				w.setConnected(true)
+				return conn
+			}
+			w.fail(err)
+		}
+		if !w.sleep(*backoff) {
+			return nil
+		}
+		*backoff = min(*backoff*2, w.config.MaxBackoff)
+	}
+}
+
+// sent records that the worker sent one entry
+func (w *networkWriter) sent() {
+	w.mu.Lock()
+	w.sending--
+	if w.sending == 0 {
+		w.cond.Broadcast()
+	}
+	w.mu.Unlock()
+}
+
+// dial opens a connection to the collector
+func (w *networkWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.config.DialTimeout}
+	if w.config.TLS != nil && w.config.Protocol == "tcp" {
+		conn, err := tls.DialWithDialer(dialer, "tcp", w.config.Address, w.config.TLS)
+		if err != nil {
+			// Not the nil *tls.Conn, which would be a non-nil net.Conn
+			return nil, err
+		}
+		return conn, nil
+	}
+	return dialer.Dial(w.config.Protocol, w.config.Address)
+}
+
+// record passes the outcome of a dial or write to the breaker, if any
+func (w *networkWriter) record(err error) {
+	if w.breaker != nil {
+		w.breaker.record(err)
+	}
+}
+
+// sleep waits for d, returning false if the writer is closed first
+func (w *networkWriter) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// setConnected records the connection state and wakes Sync callers
+func (w *networkWriter) setConnected(connected bool) {
+	w.mu.Lock()
+	w.connected = connected
+	w.down = !connected
+	w.cond.Broadcast()
+	w.mu.Unlock()
+}
+
+// fail records a connection or send failure
+func (w *networkWriter) fail(err error) {
+	w.mu.Lock()
+	w.lastErr = err.Error()
+	w.lastErrAt = time.Now()
+	w.mu.Unlock()
+	w.setConnected(false)
+}
+
+// lastError returns the latest connection or send failure and its time
+func (w *networkWriter) lastError() (string, time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr, w.lastErrAt
+}
+
+// requeue puts unsent entries back at the front of the queue
+func (w *networkWriter) requeue(batch [][]byte) {
+	w.mu.Lock()
+	w.queue = append(batch, w.queue...)
+	w.sending = 0
+	w.cond.Broadcast()
+	w.mu.Unlock()
+}
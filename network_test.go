@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetworkConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  NetworkConfig
+		wantErr bool
+	}{
+		{"tcp", NetworkConfig{Protocol: "tcp", Address: "logs:5140"}, false},
+		{"udp", NetworkConfig{Protocol: "udp", Address: "logs:5140", Framing: FramingLength}, false},
+		{"unknown protocol", NetworkConfig{Protocol: "http", Address: "logs:5140"}, true},
+		{"no address", NetworkConfig{Protocol: "tcp"}, true},
+		{"delta format", NetworkConfig{Protocol: "tcp", Address: "logs:5140", Format: FileFormatJSONDelta}, true},
+		{"unknown framing", NetworkConfig{Protocol: "tcp", Address: "logs:5140", Framing: "xml"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.config.withDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (c.BufferSize != 10000 || c.DialTimeout != 5*time.Second ||
+				c.WriteTimeout != 10*time.Second || c.MaxBackoff != 30*time.Second || c.Framing == "") {
+				t.Errorf("defaults not applied: %+v", c)
+			}
+		})
+	}
+}
+
+// readNetworkEntry reads one JSON line from conn
+func readNetworkEntry(t *testing.T, r *bufio.Reader) map[string]any {
+	t.Helper()
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(line, &entry); err != nil {
+		t.Fatalf("entry %q: %v", line, err)
+	}
+	return entry
+}
+
+func TestNetworkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	l := newBenchLogger(t, Config{
+		Level:   "info",
+		Network: &NetworkConfig{Protocol: "tcp", Address: ln.Addr().String()},
+	})
+	l.Info("shipped")
+	// The network sink's own Sync, as stdout is /dev/null here
+	if err := l.state.pipe.Load().res.network.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	stats := l.Stats().Network
+	if stats == nil || !stats.Connected || stats.Buffered != 0 || stats.Address != "tcp://"+ln.Addr().String() {
+		t.Errorf("stats %+v after Sync", stats)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if entry := readNetworkEntry(t, bufio.NewReader(conn)); entry["msg"] != "shipped" {
+		t.Errorf("collector received %v", entry)
+	}
+}
+
+func TestNetworkTLS(t *testing.T) {
+	// The test server's certificate, trusted by its client
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	roots := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	l := newBenchLogger(t, Config{
+		Level: "info",
+		Network: &NetworkConfig{
+			Protocol: "tcp",
+			Address:  ln.Addr().String(),
+			TLS:      &tls.Config{RootCAs: roots},
+		},
+	})
+	l.Info("encrypted")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if entry := readNetworkEntry(t, bufio.NewReader(conn)); entry["msg"] != "encrypted" {
+		t.Errorf("collector received %v", entry)
+	}
+}
+
+func TestNetworkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	l := newBenchLogger(t, Config{
+		Level:   "info",
+		Network: &NetworkConfig{Protocol: "udp", Address: conn.LocalAddr().String()},
+	})
+	l.Info("datagram")
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64<<10)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(buf[:n]), "\n") || !strings.Contains(string(buf[:n]), `"msg":"datagram"`) {
+		t.Errorf("datagram %q, want one framed entry", buf[:n])
+	}
+}
+
+func TestNetworkUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	config, err := NetworkConfig{Protocol: "tcp", Address: addr, BufferSize: 2}.withDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newNetworkWriter(config, nil)
+	for _, msg := range []string{"first", "second", "third"} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Sync returns once the collector is found unreachable
+	if err := w.Sync(); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("Sync() = %v, want the entries reported buffered", err)
+	}
+	if cause, at := w.lastError(); cause == "" || at.IsZero() {
+		t.Error("connection failure not recorded")
+	}
+	if err := w.Close(); err == nil {
+		t.Error("Close dropped buffered entries without an error")
+	}
+	stats := w.stats()
+	if stats.Connected || stats.Buffered != 0 || stats.Dropped < 1 {
+		t.Errorf("stats %+v, want the overflow and unsent entries dropped", stats)
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("Write succeeded after Close")
+	}
+}
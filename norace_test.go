@@ -0,0 +1,6 @@
+//go:build !race
+
+package logger
+
+// raceEnabled is set under the race detector
+const raceEnabled = false
@@ -0,0 +1,384 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// Option configures a logger created by NewLoggerWithOptions
+type Option func(*optionSet)
+
+// OptionError reports an option that is invalid or conflicts with another
+type OptionError struct {
+	// Option is the option's function name, such as "WithLevel"
+	Option string
+	Err    error
+}
+
+// Error describes the option and the problem
+func (e *OptionError) Error() string {
+	return e.Option + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// optionSet accumulates the configuration built by options and the
+// problems found along the way
+type optionSet struct {
+	config Config
+	errs   []error
+	// applied counts how often each option was given
+	applied map[string]int
+}
+
+// apply records that option was given, failing it with err if not nil
+func (s *optionSet) apply(option string, err error) bool {
+	s.applied[option]++
+	if err != nil {
+		s.errs = append(s.errs, &OptionError{Option: option, Err: err})
+		return false
+	}
+	return true
+}
+
+// fail records a problem with option found after every option applied
+func (s *optionSet) fail(option string, format string, args ...any) {
+	s.errs = append(s.errs, &OptionError{Option: option, Err: fmt.Errorf(format, args...)})
+}
+
+// validate checks the combination of the options given
+func (s *optionSet) validate() {
+	for _, option := range slices.Sorted(maps.Keys(s.applied)) {
+		if n := s.applied[option]; n > 1 && option != "WithHooks" {
+			s.fail(option, "given %d times", n)
+		}
+	}
+	if s.applied["WithJSON"] > 0 && s.applied["WithFormat"] > 0 {
+		s.fail("WithJSON", "conflicts with WithFormat")
+	}
+	if _, _, err := resolveOutputs(s.config); err != nil && s.applied["WithJSONOutput"] > 0 {
+		s.fail("WithJSONOutput", "%w", err)
+	}
+	if !s.config.EnableFile {
+		for _, option := range []string{"WithFileFormat", "WithFileCompression", "WithEncryption"} {
+			if s.applied[option] > 0 {
+				s.fail(option, "requires WithFile")
+			}
+		}
+	}
+}
+
+// NewLoggerWithOptions creates a logger from options, as NewLogger does
+// from a Config. Without options it logs info and above to the console.
+// Every invalid or conflicting option is reported, each as an
+// *OptionError, joined with errors.Join.
+//
+//	log, err := logger.NewLoggerWithOptions(
+//		logger.WithLevel("debug"),
+//		logger.WithFile("/var/log/app.log"),
+//		logger.WithFileCompression(logger.FileCompressionGzip),
+//		logger.WithSampling(10, 5),
+//	)
+func NewLoggerWithOptions(opts ...Option) (*Logger, error) {
+	s := &optionSet{applied: make(map[string]int)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.validate()
+	if err := errors.Join(s.errs...); err != nil {
+		return nil, err
+	}
+	return NewLogger(s.config)
+}
+
+// WithConfig starts from config, for moving from a Config gradually;
+// options given after it change it further. It must be the first option.
+func WithConfig(config Config) Option {
+	return func(s *optionSet) {
+		var err error
+		if len(s.applied) > 0 {
+			err = errors.New("must be the first option")
+		}
+		if s.apply("WithConfig", err) {
+			s.config = config
+		}
+	}
+}
+
+// WithLevel sets the minimum level, such as "debug" or "warn"
+func WithLevel(level string) Option {
+	return func(s *optionSet) {
+		_, err := parseLevel(level)
+		if s.apply("WithLevel", err) {
+			s.config.Level = level
+		}
+	}
+}
+
+// WithFormat sets how the console renders entries: FormatConsole,
+// FormatDev, or FormatJSON
+func WithFormat(format string) Option {
+	return func(s *optionSet) {
+		var err error
+		switch format {
+		case FormatConsole, FormatDev, FormatJSON:
+		default:
+			err = fmt.Errorf("unsupported format %q", format)
+		}
+		if s.apply("WithFormat", err) {
+			s.config.Format = format
+		}
+	}
+}
+
+// WithJSON writes the console as JSON lines, like WithFormat(FormatJSON)
+func WithJSON() Option {
+	return func(s *optionSet) {
+		s.apply("WithJSON", nil)
+		s.config.Format = FormatJSON
+	}
+}
+
+// WithConsoleOutput sets the console's stream: OutputStdout or
+// OutputStderr
+func WithConsoleOutput(output string) Option {
+	return func(s *optionSet) {
+		_, _, err := resolveOutputs(Config{ConsoleOutput: output})
+		if s.apply("WithConsoleOutput", err) {
+			s.config.ConsoleOutput = output
+		}
+	}
+}
+
+// WithJSONOutput also writes JSON lines to output, OutputStdout or
+// OutputStderr, which must differ from the console's, as
+// Config.JSONOutput
+func WithJSONOutput(output string) Option {
+	return func(s *optionSet) {
+		var err error
+		if output != OutputStdout && output != OutputStderr {
+			err = fmt.Errorf("invalid JSON output %q", output)
+		}
+		if s.apply("WithJSONOutput", err) {
+			s.config.JSONOutput = output
+		}
+	}
+}
+
+// WithTimeZone writes every sink's timestamps in zone, as Config.TimeZone
+func WithTimeZone(zone string) Option {
+	return func(s *optionSet) {
+		_, err := resolveTimeZone(zone)
+		if s.apply("WithTimeZone", err) {
+			s.config.TimeZone = zone
+		}
+	}
+}
+
+// WithConsoleTimeZone writes the console's timestamps in zone, as
+// Config.ConsoleTimeZone
+func WithConsoleTimeZone(zone string) Option {
+	return func(s *optionSet) {
+		_, err := resolveTimeZone(zone)
+		if s.apply("WithConsoleTimeZone", err) {
+			s.config.ConsoleTimeZone = zone
+		}
+	}
+}
+
+// WithFile enables the file sink, appending to path
+func WithFile(path string) Option {
+	return func(s *optionSet) {
+		var err error
+		if path == "" {
+			err = errors.New("path is empty")
+		}
+		if s.apply("WithFile", err) {
+			s.config.EnableFile = true
+			s.config.FilePath = path
+		}
+	}
+}
+
+// WithFileFormat sets the file sink's encoding: FileFormatJSON,
+// FileFormatJSONDelta, FileFormatMsgpack, or FileFormatCBOR
+func WithFileFormat(format string) Option {
+	return func(s *optionSet) {
+		_, err := newFileEncoder(format, fileEncoderConfig())
+		if s.apply("WithFileFormat", err) {
+			s.config.FileFormat = format
+		}
+	}
+}
+
+// WithFileCompression compresses the file sink, as Config.FileCompression
+func WithFileCompression(compression string) Option {
+	return func(s *optionSet) {
+		_, err := resolveFileCompression(compression)
+		if s.apply("WithFileCompression", err) {
+			s.config.FileCompression = compression
+		}
+	}
+}
+
+// WithEncryption encrypts the file sink's entries with key, an AES key of
+// 16, 24, or 32 bytes
+func WithEncryption(key []byte) Option {
+	return func(s *optionSet) {
+		_, err := newFileCipher(key)
+		if s.apply("WithEncryption", err) {
+			s.config.Encryption = &FileEncryption{Key: key}
+		}
+	}
+}
+
+// WithSession records each run to its own file in the directory of app,
+// as Config.Session
+func WithSession(app string) Option {
+	return func(s *optionSet) {
+		config := SessionConfig{App: app}
+		_, err := config.withDefaults()
+		if s.apply("WithSession", err) {
+			s.config.Session = &config
+		}
+	}
+}
+
+// WithNetwork ships entries to a remote collector, as Config.Network
+func WithNetwork(config NetworkConfig) Option {
+	return func(s *optionSet) {
+		_, err := config.withDefaults()
+		if s.apply("WithNetwork", err) {
+			s.config.Network = &config
+		}
+	}
+}
+
+// WithAsync writes entries from a background goroutine with lanes of
+// queueSize entries, 0 for the default; dropOnFull drops entries below
+// error instead of blocking when a lane is full
+func WithAsync(queueSize int, dropOnFull bool) Option {
+	return func(s *optionSet) {
+		var err error
+		if queueSize < 0 {
+			err = fmt.Errorf("negative queue size %d", queueSize)
+		}
+		if s.apply("WithAsync", err) {
+			s.config.Async = true
+			s.config.AsyncQueueSize = queueSize
+			s.config.AsyncDropOnFull = dropOnFull
+		}
+	}
+}
+
+// WithAsyncCaller looks up callers when entries are written rather than
+// when they are logged, as Config.AsyncCaller
+func WithAsyncCaller() Option {
+	return func(s *optionSet) {
+		s.apply("WithAsyncCaller", nil)
+		s.config.AsyncCaller = true
+	}
+}
+
+// WithScopes adds the fields pushed with PushScope to every entry, as
+// Config.Scopes
+func WithScopes() Option {
+	return func(s *optionSet) {
+		s.apply("WithScopes", nil)
+		s.config.Scopes = true
+	}
+}
+
+// WithSampling limits each distinct level and message to limit entries
+// per second after burst repeats, summarizing the ones dropped, as
+// Config.RateLimit
+func WithSampling(limit rate.Limit, burst int) Option {
+	return func(s *optionSet) {
+		var err error
+		switch {
+		case limit <= 0:
+			err = fmt.Errorf("limit must be positive, got %g", float64(limit))
+		case burst < 0:
+			err = fmt.Errorf("negative burst %d", burst)
+		}
+		if s.apply("WithSampling", err) {
+			s.config.RateLimit = limit
+			s.config.RateLimitBurst = burst
+		}
+	}
+}
+
+// WithErrorSampling writes the first of a run of identical errors in full
+// and counts repeats for window, as Config.ErrorSampleWindow
+func WithErrorSampling(window time.Duration) Option {
+	return func(s *optionSet) {
+		var err error
+		if window <= 0 {
+			err = fmt.Errorf("window must be positive, got %s", window)
+		}
+		if s.apply("WithErrorSampling", err) {
+			s.config.ErrorSampleWindow = window
+		}
+	}
+}
+
+// WithHooks runs hooks after each entry the sinks accept is written, as
+// Config.Hooks. It may be given more than once.
+func WithHooks(hooks ...func(zapcore.Entry) error) Option {
+	return func(s *optionSet) {
+		var err error
+		for _, hook := range hooks {
+			if hook == nil {
+				err = errors.New("nil hook")
+			}
+		}
+		if s.apply("WithHooks", err) {
+			s.config.Hooks = append(s.config.Hooks, hooks...)
+		}
+	}
+}
+
+// WithOnFatal runs fn before the process exits on a Fatal entry, as
+// Config.OnFatal
+func WithOnFatal(fn func(ent zapcore.Entry)) Option {
+	return func(s *optionSet) {
+		s.apply("WithOnFatal", nil)
+		s.config.OnFatal = fn
+	}
+}
+
+// WithClock stamps entries with clock's time, as Config.Clock
+func WithClock(clock zapcore.Clock) Option {
+	return func(s *optionSet) {
+		s.apply("WithClock", nil)
+		s.config.Clock = clock
+	}
+}
+
+// WithDeterministic makes two runs of the same code write byte-identical
+// output, as Config.Deterministic
+func WithDeterministic() Option {
+	return func(s *optionSet) {
+		s.apply("WithDeterministic", nil)
+		s.config.Deterministic = true
+	}
+}
+
+// WithIDGenerator generates the logger's IDs with gen; entryIDs adds an
+// entry_id field to every entry
+func WithIDGenerator(gen IDGenerator, entryIDs bool) Option {
+	return func(s *optionSet) {
+		s.apply("WithIDGenerator", nil)
+		s.config.IDGenerator = gen
+		s.config.EntryIDs = entryIDs
+	}
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestOptionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want []string // options reported, in order
+	}{
+		{"invalid level", []Option{WithLevel("loud")}, []string{"WithLevel"}},
+		{"invalid format", []Option{WithFormat("xml")}, []string{"WithFormat"}},
+		{"empty file path", []Option{WithFile("")}, []string{"WithFile"}},
+		{"short key", []Option{WithFile("app.log"), WithEncryption([]byte("short"))}, []string{"WithEncryption"}},
+		{"negative queue", []Option{WithAsync(-1, false)}, []string{"WithAsync"}},
+		{"zero sampling", []Option{WithSampling(0, 5)}, []string{"WithSampling"}},
+		{"nil hook", []Option{WithHooks(nil)}, []string{"WithHooks"}},
+		{"config not first", []Option{WithLevel("info"), WithConfig(Config{})}, []string{"WithConfig"}},
+		{"given twice", []Option{WithLevel("info"), WithLevel("debug")}, []string{"WithLevel"}},
+		{"JSON and format", []Option{WithJSON(), WithFormat(FormatDev)}, []string{"WithJSON"}},
+		{"same JSON output", []Option{WithConsoleOutput(OutputStdout), WithJSONOutput(OutputStdout)}, []string{"WithJSONOutput"}},
+		{"file options without a file", []Option{WithFileFormat(FileFormatMsgpack), WithFileCompression(FileCompressionGzip)},
+			[]string{"WithFileFormat", "WithFileCompression"}},
+		{"every problem", []Option{WithLevel("loud"), WithFormat("xml"), WithAsync(-1, true)},
+			[]string{"WithLevel", "WithFormat", "WithAsync"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLoggerWithOptions(tt.opts...)
+			if err == nil {
+				l.Close(context.Background())
+				t.Fatal("NewLoggerWithOptions succeeded")
+			}
+			var got []string
+			for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var optErr *OptionError
+				if !errors.As(err, &optErr) {
+					t.Fatalf("error %v is not an *OptionError", err)
+				}
+				if !strings.HasPrefix(optErr.Error(), optErr.Option+": ") {
+					t.Errorf("error %q does not name its option", optErr.Error())
+				}
+				got = append(got, optErr.Option)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("reported %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLoggerWithOptions(t *testing.T) {
+	discardStdout(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	var hooked []string
+	l, err := NewLoggerWithOptions(
+		WithConfig(Config{Level: "warn"}),
+		WithLevel("info"),
+		WithFile(path),
+		WithHooks(func(ent zapcore.Entry) error { hooked = append(hooked, ent.Message); return nil }),
+		WithHooks(func(zapcore.Entry) error { return nil }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("options applied")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if line := string(readOnlyLine(t, path)); !strings.Contains(line, "options applied") {
+		t.Errorf("file entry %s", line)
+	}
+	if !slices.Equal(hooked, []string{"options applied"}) {
+		t.Errorf("hook saw %v", hooked)
+	}
+}
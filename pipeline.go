@@ -0,0 +1,561 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// errLoggerClosed is returned by writes through a closed logger
+var errLoggerClosed = errors.New("logger is closed")
+
+// pipeline is an immutable snapshot of everything entries flow through:
+// the sink cores, the wrappers around them, and the resources they own.
+// Reconfiguration builds a new pipeline and swaps it in atomically.
+type pipeline struct {
+	config     Config
+	stackLevel zapcore.LevelEnabler
+	// filters holds the filter rules, shared with the logger's state so
+	// SetFilterRules applies without a rebuild
+	filters *atomic.Pointer[filterSet]
+
+	// sinks are the leaf cores built from config, starting with the
+	// console; extra are cores added with AddSink, which survive reloads
+	sinks []zapcore.Core
+	extra []zapcore.Core
+	core  zapcore.Core
+	// recent records entries in the recent ring when Config.RecentEntries
+	// is set. It is checked beside core rather than through it, so entries
+	// below the sinks' level don't reach them.
+	recent *recentCore
+	// root is core and recent without context fields
+	root swapCache
+	// direct combines sinks and extra without the pipeline-wide wrappers,
+	// for records that must be written exactly as given
+	direct zapcore.Core
+	// names are the route names of the sinks built from config, which
+	// precede the SLO trackers among sinks
+	names []string
+
+	res *resources
+}
+
+// resources are owned by a pipeline and shared with copies made by AddSink
+type resources struct {
+	files        []logFile
+	async        *asyncQueue
+	aggregator   *aggregator
+	errorSampler *errorSampler
+	network      *networkWriter
+	slos         []*sloTracker
+	rateLimiter  *rateLimiter
+	// monitors track each sink's writes for Health
+	monitors []*sinkMonitor
+	// volume counts bytes written when Config.TrackVolume is set
+	volume *volumeTracker
+	// recent holds the latest entries when Config.RecentEntries is set
+	recent *recentBuffer
+	// crash writes crash reports when Config.CrashReport is set
+	crash *crashReporter
+	// skew measures the clock's offset when Config.ClockSkew is set
+	skew *skewMonitor
+	// session is the session file's path when Config.Session is set
+	session string
+	// metrics takes metric samples when Config.Metrics or
+	// Config.TrackMetrics is set
+	metrics *metricRecorder
+	// diag is the logger self-diagnostics are written through once the
+	// pipeline is in use, for breaker state changes
+	diag atomic.Pointer[zap.Logger]
+
+	// users counts the writes in progress through the pipeline, plus one
+	// until it is retired. The last write to finish after that releases
+	// the resources, so no write reaches a closed file.
+	users      atomic.Int64
+	retireOnce sync.Once
+	retired    atomic.Pointer[pipeline]
+	// finisher is added last to checked entries, ending their write
+	finisher finishCore
+
+	releaseOnce sync.Once
+	releaseErr  error
+	// released is closed once release has run
+	released chan struct{}
+}
+
+// newResources creates the resources of a new pipeline, in use until the
+// pipeline is retired
+func newResources() *resources {
+	r := &resources{released: make(chan struct{})}
+	r.users.Store(1)
+	r.finisher.res = r
+	return r
+}
+
+// acquire counts a write starting, unless the pipeline was retired. A
+// write that counted itself just before retire still runs; one counted
+// after backs out, so nothing starts once Close has retired the pipeline,
+// even while earlier writes are in progress.
+func (r *resources) acquire() bool {
+	for {
+		n := r.users.Load()
+		if n == 0 {
+			return false
+		}
+		if r.users.CompareAndSwap(n, n+1) {
+			break
+		}
+	}
+	if r.retired.Load() != nil {
+		r.finish()
+		return false
+	}
+	return true
+}
+
+// finish ends a write begun with acquire. The last one to finish after the
+// pipeline is retired releases the resources.
+func (r *resources) finish() {
+	if r.users.Add(-1) == 0 {
+		go r.retired.Load().release()
+	}
+}
+
+// acquirePipeline returns the current pipeline, counted as in use until
+// its resources' finish is called, or nil once the logger is closed
+func acquirePipeline(pipe *atomic.Pointer[pipeline]) *pipeline {
+	for {
+		p := pipe.Load()
+		if p.res.acquire() {
+			return p
+		}
+		// Retired: a reload swapped in another pipeline, unless closed
+		if pipe.Load() == p {
+			return nil
+		}
+	}
+}
+
+// retire marks p as replaced. Its resources are released once the writes
+// in progress finish.
+func (p *pipeline) retire() {
+	p.res.retireOnce.Do(func() {
+		p.res.retired.Store(p)
+		p.res.finish()
+	})
+}
+
+// wait blocks until p's resources are released, returning release's error
+func (p *pipeline) wait() error {
+	<-p.res.released
+	return p.res.releaseErr
+}
+
+// finishCore ends the write of an entry through a pipeline after its
+// cores have written it. It is added to the entry by swapCore.Check.
+type finishCore struct {
+	res *resources
+}
+
+func (c *finishCore) Enabled(zapcore.Level) bool        { return false }
+func (c *finishCore) With([]zapcore.Field) zapcore.Core { return c }
+func (c *finishCore) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+func (c *finishCore) Sync() error { return nil }
+
+// Write ends the entry's write
+func (c *finishCore) Write(zapcore.Entry, []zapcore.Field) error {
+	c.res.finish()
+	return nil
+}
+
+// assemble combines the sink cores and applies the pipeline-wide wrappers
+func (p *pipeline) assemble() {
+	cores := make([]zapcore.Core, 0, len(p.extra)+1)
+	var sinks zapcore.Core = newFanoutCore(p.sinks, !p.config.DisableStderrFallback, p.config.Provenance)
+	if len(p.config.Hooks) > 0 {
+		sinks = zapcore.RegisterHooks(sinks, p.config.Hooks...)
+	}
+	cores = append(cores, sinks)
+	cores = appendAdded(cores, p.extra)
+	if p.res.crash != nil {
+		cores = append(cores, p.res.crash.core())
+	}
+
+	core := zapcore.NewTee(cores...)
+	p.direct = core
+	if p.config.AsyncCaller {
+		core = &callerCore{Core: core}
+	}
+	if p.config.EntryIDs {
+		core = &entryIDCore{Core: core, newID: p.config.idGenerator()}
+	}
+	if p.res.skew != nil {
+		core = &skewCore{Core: core, monitor: p.res.skew}
+	}
+	if p.res.async != nil {
+		core = &asyncCore{Core: core, queue: p.res.async}
+	}
+	if p.res.aggregator != nil {
+		core = &aggregateCore{Core: core, agg: p.res.aggregator}
+	}
+	if p.res.errorSampler != nil {
+		core = &errorSampleCore{Core: core, sampler: p.res.errorSampler}
+	}
+	if p.res.rateLimiter != nil {
+		core = &rateLimitCore{Core: core, limiter: p.res.rateLimiter}
+	}
+	if p.res.metrics != nil {
+		core = &metricCore{Core: core, rec: p.res.metrics}
+	}
+	p.core = core
+	p.root = swapCache{pipe: p, core: core}
+	p.recent = nil
+	if p.res.recent != nil {
+		p.recent = &recentCore{buf: p.res.recent, ctx: newMapEncoder()}
+		p.root.recent = p.recent
+	}
+}
+
+// withoutConsole combines every sink but the console, without the
+// pipeline-wide wrappers, for entries the console shows another way
+func (p *pipeline) withoutConsole() zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(p.extra)+1)
+	if len(p.sinks) > 1 {
+		cores = append(cores, newFanoutCore(p.sinks[1:], false, false))
+	}
+	cores = appendAdded(cores, p.extra)
+	core := zapcore.NewTee(cores...)
+	if p.config.AsyncCaller {
+		core = &callerCore{Core: core}
+	}
+	if p.config.EntryIDs {
+		core = &entryIDCore{Core: core, newID: p.config.idGenerator()}
+	}
+	if p.res.skew != nil {
+		core = &skewCore{Core: core, monitor: p.res.skew}
+	}
+	return core
+}
+
+// appendAdded appends the cores added with AddSink, routed as SinkAdded
+func appendAdded(cores, extra []zapcore.Core) []zapcore.Core {
+	for _, core := range extra {
+		cores = append(cores, newRouteCore(SinkAdded, core))
+	}
+	return cores
+}
+
+// withExtra returns a copy of p sharing its resources, with extra replaced
+func (p *pipeline) withExtra(extra []zapcore.Core) *pipeline {
+	next := *p
+	next.extra = extra
+	next.assemble()
+	return &next
+}
+
+// release writes held entries, drains the async queue, syncs the sinks, and closes owned files.
+// Only the first call does any work.
+func (p *pipeline) release() error {
+	r := p.res
+	r.releaseOnce.Do(func() {
+		var errs []error
+		if r.rateLimiter != nil {
+			r.rateLimiter.flush()
+		}
+		if r.errorSampler != nil {
+			r.errorSampler.close()
+		}
+		if r.aggregator != nil {
+			r.aggregator.close()
+		}
+		if r.async != nil {
+			r.async.close()
+		}
+		if r.skew != nil {
+			r.skew.close()
+		}
+		if err := syncError(p.core.Sync()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync logger: %w", err))
+		}
+		if r.network != nil {
+			if err := r.network.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for _, f := range r.files {
+			if err := f.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close %s: %w", f.Name(), err))
+			}
+		}
+		r.releaseErr = errors.Join(errs...)
+		close(r.released)
+	})
+	return r.releaseErr
+}
+
+// swapCore delegates to the current pipeline. Context fields are kept here
+// and applied to whichever pipeline is current, so loggers derived before a
+// reload keep their fields afterwards.
+type swapCore struct {
+	pipe   *atomic.Pointer[pipeline]
+	fields []zapcore.Field
+	cache  atomic.Pointer[swapCache]
+
+	// silenced counts active Silence calls per level, indexed from TraceLevel;
+	// entries are also silenced by those of parent, the core this one was
+	// derived from. silences counts the levels held by every Silence call
+	// on the logger tree, so logging skips the walk when none is active.
+	silenced [zapcore.FatalLevel - TraceLevel + 1]atomic.Int32
+	parent   *swapCore
+	silences *atomic.Int32
+}
+
+// swapCache is a pipeline's core and recent core with a swapCore's fields
+// applied; recent is nil when the pipeline has no recent ring
+type swapCache struct {
+	pipe   *pipeline
+	core   zapcore.Core
+	recent zapcore.Core
+}
+
+// applied returns p's cores with this core's fields
+func (c *swapCore) applied(p *pipeline) *swapCache {
+	if len(c.fields) == 0 {
+		return &p.root
+	}
+	if cached := c.cache.Load(); cached != nil && cached.pipe == p {
+		return cached
+	}
+	applied := &swapCache{pipe: p, core: p.core.With(c.fields)}
+	if p.recent != nil {
+		applied.recent = p.recent.With(c.fields)
+	}
+	c.cache.Store(applied)
+	return applied
+}
+
+// Enabled reports whether the current pipeline accepts level, which it
+// does at every level while it keeps a recent ring. Fields don't affect
+// levels, so this skips applying them.
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	if c.isSilenced(level) {
+		return false
+	}
+	p := c.pipe.Load()
+	return p.recent != nil || p.core.Enabled(level)
+}
+
+// With returns a child core carrying fields
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &swapCore{pipe: c.pipe, fields: merged, parent: c, silences: c.silences}
+}
+
+// Check defers to the current pipeline, adding the calling goroutine's
+// scope fields under Config.Scopes. They are applied here, on the caller's goroutine, because
+// the write itself may happen on the async worker. Fatal entries become
+// errors under Config.FatalAsError. Under Config.AsyncCaller, the caller
+// is captured here too. The recent ring gets every entry, and the sinks
+// only those at their level.
+//
+// The pipeline is held in use from here until the entry is written, so a
+// reload doesn't close its files under the write. Entries logged after
+// Close are dropped.
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.isSilenced(ent.Level) {
+		return ce
+	}
+	p := acquirePipeline(c.pipe)
+	if p == nil {
+		return ce
+	}
+	if ce = c.check(p, ent, ce); ce == nil {
+		p.res.finish()
+		return nil
+	}
+	return ce.AddCore(ent, &p.res.finisher)
+}
+
+// check performs Check with p acquired
+func (c *swapCore) check(p *pipeline, ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	config := &p.config
+	if ent.Level == zapcore.FatalLevel && config.FatalAsError {
+		ent.Level = zapcore.ErrorLevel
+	}
+	toSinks := p.core.Enabled(ent.Level)
+	if !toSinks && p.recent == nil {
+		return ce
+	}
+	if p.filters != nil {
+		if f := p.filters.Load(); f != nil && f.drops(ent) {
+			return ce
+		}
+	}
+	if config.AsyncCaller {
+		ent.Caller = captureCaller(2)
+	}
+	applied := c.applied(p)
+	core, recent := applied.core, applied.recent
+	if fields := scopeFields(config); len(fields) > 0 {
+		core = core.With(fields)
+		if recent != nil {
+			recent = recent.With(fields)
+		}
+	}
+	if recent != nil {
+		ce = ce.AddCore(ent, recent)
+	}
+	if !toSinks {
+		return ce
+	}
+	return core.Check(ent, ce)
+}
+
+// scopeFields returns the calling goroutine's scope fields under
+// Config.Scopes
+func scopeFields(config *Config) []zapcore.Field {
+	if !config.Scopes {
+		return nil
+	}
+	return ScopeFields()
+}
+
+// Write writes directly to the current pipeline, bypassing level checks
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	p := acquirePipeline(c.pipe)
+	if p == nil {
+		return errLoggerClosed
+	}
+	defer p.res.finish()
+	return c.applied(p).core.Write(ent, fields)
+}
+
+// Sync flushes the current pipeline. It does nothing once the logger is
+// closed, which flushed it.
+func (c *swapCore) Sync() error {
+	p := acquirePipeline(c.pipe)
+	if p == nil {
+		return nil
+	}
+	defer p.res.finish()
+	return p.core.Sync()
+}
+
+// withRootField returns core with field replacing the context fields for
+// which replaces is true, so a setting such as a group or route is carried
+// once rather than repeated by each derived logger
+func withRootField(core zapcore.Core, field zapcore.Field, replaces func(zapcore.Field) bool) zapcore.Core {
+	switch c := core.(type) {
+	case *swapCore:
+		fields := make([]zapcore.Field, 0, len(c.fields)+1)
+		for _, f := range c.fields {
+			if !replaces(f) {
+				fields = append(fields, f)
+			}
+		}
+		return &swapCore{pipe: c.pipe, fields: append(fields, field), parent: c, silences: c.silences}
+	case *rateLimitCore:
+		clone := *c
+		clone.Core = withRootField(c.Core, field, replaces)
+		return &clone
+	}
+	return core.With([]zapcore.Field{field})
+}
+
+// stackEnabler returns a LevelEnabler following the current pipeline's
+// stack trace level
+func stackEnabler(pipe *atomic.Pointer[pipeline]) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return pipe.Load().stackLevel.Enabled(level)
+	})
+}
+
+// SetLevel changes the minimum level of every sink built from Config.
+// It is safe to call while other goroutines are logging.
+func (l *Logger) SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	l.state.level.SetLevel(lvl)
+	return nil
+}
+
+// Level returns the current minimum level of the sinks built from Config
+func (l *Logger) Level() zapcore.Level {
+	return l.state.level.Level()
+}
+
+// AddSink adds core to the logger and every logger derived from it. The
+// core sees entries after rate limiting and, in async mode, on the async
+// worker. It is kept across Reload.
+func (l *Logger) AddSink(core zapcore.Core) {
+	s := l.state
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	p := s.pipe.Load()
+	if p.config.Breaker != nil {
+		if r, ok := core.(interface{ remoteSender() *httpSender }); ok {
+			diag := diagnosticsLogger(l.Logger)
+			r.remoteSender().useBreaker(newSinkBreaker(addedSinkName(core), *p.config.Breaker,
+				func() *zap.Logger { return diag }))
+		}
+	}
+	extra := make([]zapcore.Core, 0, len(p.extra)+1)
+	extra = append(extra, p.extra...)
+	extra = append(extra, core)
+	s.pipe.Store(p.withExtra(extra))
+}
+
+// Reload rebuilds the logger's sinks from config and swaps them in
+// atomically. Loggers derived earlier keep their fields. The previous sinks
+// are drained and their files closed in the background once the writes in
+// progress through them finish.
+func (l *Logger) Reload(config Config) error {
+	l.state.reloadMu.Lock()
+	defer l.state.reloadMu.Unlock()
+	return l.reload(config)
+}
+
+// reload performs Reload with reloadMu held
+func (l *Logger) reload(config Config) error {
+	s := l.state
+	old := s.pipe.Load()
+	// The logger's zap options, which capture callers the other way, are
+	// fixed at creation
+	config.AsyncCaller = old.config.AsyncCaller
+	next, diags, err := buildPipeline(config, s.level, &s.filters, s.files)
+	if err != nil {
+		return err
+	}
+	next = next.withExtra(old.extra)
+	if old.res.recent != nil && next.res.recent != nil {
+		next.res.recent.inherit(old.res.recent)
+	}
+	next.res.diag.Store(diagnosticsLogger(l.Logger))
+	s.pipe.Store(next)
+	s.retiring = append(s.retiring, old)
+	diags.emit(l.Logger)
+
+	old.retire()
+	go func() {
+		if err := old.wait(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: releasing previous sinks: %v\n", err)
+		}
+		s.reloadMu.Lock()
+		s.retiring = slices.DeleteFunc(s.retiring, func(p *pipeline) bool { return p == old })
+		s.reloadMu.Unlock()
+	}()
+	return nil
+}
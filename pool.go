@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferPool holds the encode buffers shared by every sink using the
+// encoders defined in this package
+var bufferPool = newEncodePool()
+
+// encodePool is a buffer pool that counts how often a Get is served by a
+// reused buffer. Sink cores hand buffers back with put after writing them,
+// rather than through Buffer.Free, so the count reflects reuse.
+type encodePool struct {
+	backing buffer.Pool
+	idle    sync.Pool
+
+	gets   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// newEncodePool creates an empty pool
+func newEncodePool() *encodePool {
+	return &encodePool{backing: buffer.NewPool()}
+}
+
+// Get returns an empty buffer
+func (p *encodePool) Get() *buffer.Buffer {
+	p.gets.Add(1)
+	if buf, ok := p.idle.Get().(*buffer.Buffer); ok {
+		return buf
+	}
+	p.misses.Add(1)
+	return p.backing.Get()
+}
+
+// put returns buf to the pool. buf must not be used afterwards.
+func (p *encodePool) put(buf *buffer.Buffer) {
+	buf.Reset()
+	p.idle.Put(buf)
+}
+
+// pooledJSONEncoder is zap's JSON encoder returning entries in bufferPool's
+// buffers, so that the JSON sinks share them with the other encoders. zap
+// encodes into its internal pool, whose buffer is copied and returned.
+type pooledJSONEncoder struct {
+	zapcore.Encoder
+}
+
+// newJSONEncoder creates a JSON encoder using bufferPool
+func newJSONEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &pooledJSONEncoder{Encoder: zapcore.NewJSONEncoder(cfg)}
+}
+
+// Clone copies the encoder
+func (e *pooledJSONEncoder) Clone() zapcore.Encoder {
+	return &pooledJSONEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes the entry into a buffer from bufferPool
+func (e *pooledJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufferPool.Get()
+	buf.Write(encoded.Bytes())
+	encoded.Free()
+	return buf, nil
+}
+
+// usesBufferPool reports whether enc's EncodeEntry returns buffers from
+// bufferPool. zapcore's encoders use zap's internal pool instead.
+func usesBufferPool(enc zapcore.Encoder) bool {
+	if safe, ok := enc.(*safeEncoder); ok {
+		enc = safe.Encoder
+	}
+	switch enc.(type) {
+	case *consoleEncoder, *devEncoder, *binaryEncoder, *pooledJSONEncoder:
+		return true
+	}
+	return false
+}
+
+// sinkCore writes encoded entries to a WriteSyncer, like the core built by
+// zapcore.NewCore, and returns this package's encode buffers to bufferPool
+type sinkCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	out    zapcore.WriteSyncer
+	pooled bool
+	// volume, if set, counts the bytes written under volumeSink
+	volume     *volumeTracker
+	volumeSink string
+}
+
+// newSinkCore creates a core writing entries encoded by enc to out
+func newSinkCore(enc zapcore.Encoder, out zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+	return &sinkCore{LevelEnabler: enab, enc: enc, out: out, pooled: usesBufferPool(enc)}
+}
+
+// Level returns the minimum enabled level
+func (c *sinkCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+// With returns a child core whose encoder carries fields
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &sinkCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out, pooled: c.pooled,
+		volume: c.volume, volumeSink: c.volumeSink}
+}
+
+// Check adds this core to the checked entry if the level is enabled
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write encodes and writes the entry, syncing after entries above error
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	n := buf.Len()
+	_, err = c.out.Write(buf.Bytes())
+	if c.pooled {
+		bufferPool.put(buf)
+	} else {
+		buf.Free()
+	}
+	if err != nil {
+		return err
+	}
+	if c.volume != nil {
+		c.volume.add(c.volumeSink, ent, n)
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		_ = c.Sync()
+	}
+	return nil
+}
+
+// Sync flushes the output
+func (c *sinkCore) Sync() error {
+	return c.out.Sync()
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFileEncodersUseBufferPool(t *testing.T) {
+	for _, format := range []string{FileFormatJSON, FileFormatJSONDelta, FileFormatMsgpack, FileFormatCBOR} {
+		t.Run(format, func(t *testing.T) {
+			enc, err := newFileEncoder(format, fileEncoderConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			enc = newSafeEncoder(enc)
+			if !usesBufferPool(enc) || !usesBufferPool(enc.Clone()) {
+				t.Fatalf("%s encoder doesn't use the buffer pool", format)
+			}
+
+			gets, misses := bufferPool.gets.Load(), bufferPool.misses.Load()
+			core := newSinkCore(enc, zapcore.AddSync(io.Discard), zapcore.DebugLevel)
+			for range 10 {
+				if err := core.Write(zapcore.Entry{Message: "pooled"}, []zapcore.Field{zap.Int("n", 1)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := bufferPool.gets.Load() - gets; got < 10 {
+				t.Errorf("pool served %d gets for 10 entries", got)
+			}
+			if got := bufferPool.misses.Load() - misses; got >= 10 {
+				t.Errorf("pool missed %d of 10 gets, want reuse", got)
+			}
+		})
+	}
+}
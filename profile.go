@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ProfileEnv names the environment variable selecting the profile
+// LoadProfile uses
+const ProfileEnv = "APP_ENV"
+
+// profileExtendsKey names the profile a profile inherits from, folded as
+// by foldProfileKeys
+const profileExtendsKey = "extends"
+
+// profileFile is the layout of a profiles file
+type profileFile struct {
+	// Default is the profile used when ProfileEnv is unset
+	Default  string
+	Profiles map[string]map[string]json.RawMessage
+}
+
+// LoadProfile reads the JSON profiles file at path and returns the Config
+// of the profile named by $APP_ENV, or of the file's default profile when
+// it is unset, so one artifact logs appropriately in each environment. A
+// profile holds Config fields as ConfigSchema describes them, and may
+// extend another, overriding the fields it sets; objects such as Network
+// are merged field by field, and null clears an inherited field:
+//
+//	{
+//	  "Default": "development",
+//	  "Profiles": {
+//	    "base":        {"Level": "info", "EnableFile": true, "FilePath": "/var/log/app.log"},
+//	    "development": {"Extends": "base", "Level": "debug", "Format": "dev"},
+//	    "production":  {"Extends": "base", "Async": true, "Network": {"Address": "logs:5140"}}
+//	  }
+//	}
+func LoadProfile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	return ParseProfile(data, os.Getenv(ProfileEnv))
+}
+
+// ParseProfile returns the Config of the profile name in the JSON profiles
+// data, or of its default profile if name is empty, as LoadProfile does
+func ParseProfile(data []byte, name string) (Config, error) {
+	var file profileFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("invalid profiles: %w", err)
+	}
+	if name == "" {
+		name = file.Default
+	}
+	if name == "" {
+		return Config{}, fmt.Errorf("no profile selected: set %s or a default profile", ProfileEnv)
+	}
+
+	merged, err := resolveProfile(file.Profiles, name, nil)
+	if err != nil {
+		return Config{}, err
+	}
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return Config{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+	var config Config
+	dec = json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return config, nil
+}
+
+// resolveProfile returns the fields of the profile name merged over those
+// of the profiles it extends. chain lists the profiles extending it, to
+// detect cycles.
+func resolveProfile(profiles map[string]map[string]json.RawMessage, name string, chain []string) (map[string]any, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, expected one of %s",
+			name, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	if slices.Contains(chain, name) {
+		return nil, fmt.Errorf("profile %q extends itself through %s", name, strings.Join(append(chain, name), " -> "))
+	}
+	chain = append(chain, name)
+
+	fields := make(map[string]any, len(profile))
+	for k, v := range profile {
+		var value any
+		if err := json.Unmarshal(v, &value); err != nil {
+			return nil, fmt.Errorf("profile %q: %s: %w", name, k, err)
+		}
+		fields[strings.ToLower(k)] = foldProfileKeys(value)
+	}
+	parentName, hasParent := fields[profileExtendsKey]
+	delete(fields, profileExtendsKey)
+	if !hasParent {
+		return fields, nil
+	}
+	parent, ok := parentName.(string)
+	if !ok {
+		return nil, fmt.Errorf("profile %q: Extends must be a profile name", name)
+	}
+	base, err := resolveProfile(profiles, parent, chain)
+	if err != nil {
+		return nil, err
+	}
+	return mergeProfile(base, fields), nil
+}
+
+// mergeProfile returns base with the fields of over set over it, merging
+// nested objects recursively
+func mergeProfile(base, over map[string]any) map[string]any {
+	for k, v := range over {
+		baseObj, baseIsObj := base[k].(map[string]any)
+		overObj, overIsObj := v.(map[string]any)
+		if baseIsObj && overIsObj {
+			base[k] = mergeProfile(baseObj, overObj)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
+
+// foldProfileKeys lowercases the keys of v's objects, as encoding/json
+// matches field names regardless of case, so that "level" in one profile
+// overrides "Level" in the profile it extends
+func foldProfileKeys(v any) any {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	folded := make(map[string]any, len(obj))
+	for k, item := range obj {
+		folded[strings.ToLower(k)] = foldProfileKeys(item)
+	}
+	return folded
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testProfiles = `{
+  "Default": "development",
+  "Profiles": {
+    "base": {"Level": "info", "EnableFile": true, "FilePath": "/var/log/app.log",
+             "Network": {"Protocol": "tcp", "Address": "logs:5140", "BufferSize": 100}},
+    "development": {"Extends": "base", "level": "debug", "Format": "dev", "EnableFile": null},
+    "production": {"Extends": "base", "Async": true, "Network": {"address": "collector:5140"}},
+    "loop-a": {"Extends": "loop-b"},
+    "loop-b": {"Extends": "loop-a"},
+    "orphan": {"Extends": "missing"},
+    "typo": {"Levle": "debug"}
+  }
+}`
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		check   func(t *testing.T, c Config)
+		wantErr bool
+	}{
+		{name: "default", profile: "", check: func(t *testing.T, c Config) {
+			if c.Level != "debug" || c.Format != FormatDev {
+				t.Errorf("level %q format %q, want the overrides", c.Level, c.Format)
+			}
+			if c.EnableFile || c.FilePath != "/var/log/app.log" {
+				t.Errorf("EnableFile %v FilePath %q, want the file cleared by null", c.EnableFile, c.FilePath)
+			}
+		}},
+		{name: "merged object", profile: "production", check: func(t *testing.T, c Config) {
+			if !c.Async || c.Level != "info" || !c.EnableFile {
+				t.Errorf("config %+v, want base fields with Async", c)
+			}
+			if n := c.Network; n == nil || n.Address != "collector:5140" || n.Protocol != "tcp" || n.BufferSize != 100 {
+				t.Errorf("network %+v, want the address overridden field by field", n)
+			}
+		}},
+		{name: "base", profile: "base", check: func(t *testing.T, c Config) {
+			if c.Level != "info" || c.Format != "" {
+				t.Errorf("config %+v", c)
+			}
+		}},
+		{name: "cycle", profile: "loop-a", wantErr: true},
+		{name: "unknown parent", profile: "orphan", wantErr: true},
+		{name: "unknown profile", profile: "staging", wantErr: true},
+		{name: "unknown field", profile: "typo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseProfile([]byte(testProfiles), tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseProfile(%q) succeeded: %+v", tt.profile, c)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestParseProfileNoneSelected(t *testing.T) {
+	if _, err := ParseProfile([]byte(`{"Profiles": {"base": {}}}`), ""); err == nil {
+		t.Error("ParseProfile succeeded without a profile or default")
+	}
+	if _, err := ParseProfile([]byte(`{"Profile": {}}`), "base"); err == nil {
+		t.Error("ParseProfile accepted an unknown top-level key")
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(testProfiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProfileEnv, "production")
+	c, err := LoadProfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Async {
+		t.Errorf("loaded %+v, want the profile named by %s", c, ProfileEnv)
+	}
+	if _, err := LoadProfile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadProfile succeeded without a file")
+	}
+}
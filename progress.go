@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// progressOutput is where live progress bars are drawn, and progressTTY
+// reports whether it is a terminal
+var (
+	progressOutput io.Writer = os.Stdout
+	progressTTY              = isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+)
+
+const (
+	// progressRedraw is how often a live bar is redrawn
+	progressRedraw = 100 * time.Millisecond
+	// progressLogEvery is the longest gap between structured progress
+	// entries; they are also written at every 10% step
+	progressLogEvery = 30 * time.Second
+	// progressBarWidth is the number of cells in a bar
+	progressBarWidth = 30
+)
+
+// spinnerFrames animate progress with an unknown total
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// ProgressBar tracks a long-running task started with StartProgress
+type ProgressBar struct {
+	l     *Logger
+	label string
+	total int
+	start time.Time
+
+	mu        sync.Mutex
+	current   int
+	message   string
+	frame     int
+	lastStep  int
+	lastEntry time.Time
+	done      bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StartProgress starts tracking a task of total steps; a total of zero or
+// less shows a spinner instead of a bar. On a terminal, a live bar is drawn
+// on stdout and only the other sinks receive the structured "progress"
+// entries, written at start, at every 10% step, at least every 30 seconds,
+// and on Done. Without a terminal there is no bar and the entries go to
+// every sink, console included. Other console output while a bar is live
+// may interleave with it.
+func (l *Logger) StartProgress(label string, total int) *ProgressBar {
+	now := time.Now()
+	b := &ProgressBar{l: l, label: label, total: total, start: now, lastEntry: now, stop: make(chan struct{})}
+	b.lastStep = b.step()
+	b.logEntry("Progress started", 0, "")
+	if progressTTY {
+		b.wg.Add(1)
+		go b.redraw()
+	}
+	return b
+}
+
+// Increment advances the task by one step
+func (b *ProgressBar) Increment() {
+	b.Add(1)
+}
+
+// Add advances the task by n steps
+func (b *ProgressBar) Add(n int) {
+	b.mu.Lock()
+	if b.done {
+		b.mu.Unlock()
+		return
+	}
+	b.current += n
+	current, message := b.current, b.message
+	step := b.step()
+	due := step != b.lastStep || time.Since(b.lastEntry) >= progressLogEvery
+	if due {
+		b.lastStep = step
+		b.lastEntry = time.Now()
+	}
+	b.mu.Unlock()
+
+	if due {
+		b.logEntry("Progress", current, message)
+	}
+}
+
+// SetMessage sets the status text shown after the bar and in entries
+func (b *ProgressBar) SetMessage(msg string) {
+	b.mu.Lock()
+	b.message = msg
+	b.mu.Unlock()
+}
+
+// Done stops the bar, leaving its final state on the terminal, and writes
+// a completion entry. Later calls do nothing.
+func (b *ProgressBar) Done() {
+	b.mu.Lock()
+	if b.done {
+		b.mu.Unlock()
+		return
+	}
+	b.done = true
+	current, message := b.current, b.message
+	b.mu.Unlock()
+
+	if progressTTY {
+		close(b.stop)
+		b.wg.Wait()
+		b.mu.Lock()
+		line := b.render()
+		b.mu.Unlock()
+		_, _ = io.WriteString(progressOutput, "\r\033[K"+line+"\n")
+	}
+	b.logEntry("Progress completed", current, message)
+}
+
+// step returns the completed tenth of the task, or -1 without a total
+func (b *ProgressBar) step() int {
+	if b.total <= 0 {
+		return -1
+	}
+	return min(b.current*10/b.total, 10)
+}
+
+// redraw draws the live bar until Done
+func (b *ProgressBar) redraw() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(progressRedraw)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			b.frame++
+			line := b.render()
+			b.mu.Unlock()
+			_, _ = io.WriteString(progressOutput, "\r\033[K"+line)
+		}
+	}
+}
+
+// render formats the bar's current state as one line
+func (b *ProgressBar) render() string {
+	var s strings.Builder
+	elapsed := time.Since(b.start).Truncate(time.Second)
+	if b.total > 0 {
+		filled := min(b.current*progressBarWidth/b.total, progressBarWidth)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+		fmt.Fprintf(&s, "%s %s %d/%d %3.0f%% %s", b.label, green(bar), b.current, b.total,
+			b.percent(), elapsed)
+	} else {
+		frame := spinnerFrames[b.frame%len(spinnerFrames)]
+		if b.done {
+			frame = "✓"
+		}
+		fmt.Fprintf(&s, "%s %s %d %s", cyan(frame), b.label, b.current, elapsed)
+	}
+	if b.message != "" {
+		s.WriteString(" ")
+		s.WriteString(b.message)
+	}
+	return s.String()
+}
+
+// percent returns how much of the task is complete
+func (b *ProgressBar) percent() float64 {
+	if b.total <= 0 {
+		return 0
+	}
+	return float64(b.current) * 100 / float64(b.total)
+}
+
+// logEntry writes a structured progress entry, skipping the console while
+// a live bar is drawn there
+func (b *ProgressBar) logEntry(msg string, current int, message string) {
+	fields := []zap.Field{
+		zap.String("progress", b.label),
+		zap.Int("current", current),
+	}
+	if b.total > 0 {
+		fields = append(fields,
+			zap.Int("total", b.total),
+			zap.Float64("percent", float64(current)*100/float64(b.total)),
+		)
+	}
+	fields = append(fields, zap.Duration("elapsed", time.Since(b.start)))
+	if message != "" {
+		fields = append(fields, zap.String("status", message))
+	}
+
+	if !progressTTY {
+		b.l.Logger.WithOptions(zap.WithCaller(false)).Info(msg, fields...)
+		return
+	}
+	p := acquirePipeline(&b.l.state.pipe)
+	if p == nil {
+		return
+	}
+	defer p.res.finish()
+	core := p.withoutConsole()
+	if root := rootSwapCore(b.l.Logger.Core()); root != nil && len(root.fields) > 0 {
+		core = core.With(root.fields)
+	}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: b.l.now(), Message: msg}
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
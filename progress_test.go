@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// progressCounts lists the "current" field of each progress entry
+func progressCounts(logs *observer.ObservedLogs) []int64 {
+	var counts []int64
+	for _, e := range logs.All() {
+		if n, ok := e.ContextMap()["current"].(int64); ok {
+			counts = append(counts, n)
+		}
+	}
+	return counts
+}
+
+func TestProgress(t *testing.T) {
+	l := newBenchLogger(t, Config{})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	b := l.StartProgress("migrate", 20)
+	for range 20 {
+		b.Increment()
+	}
+	b.SetMessage("done")
+	b.Done()
+	b.Done()
+	b.Increment()
+
+	want := []int64{0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 20}
+	if got := progressCounts(logs); !slices.Equal(got, want) {
+		t.Errorf("progress entries at %v, want %v", got, want)
+	}
+	last := logs.All()[logs.Len()-1]
+	fields := last.ContextMap()
+	if last.Message != "Progress completed" || fields["percent"] != float64(100) || fields["status"] != "done" {
+		t.Errorf("completion entry = %s %v", last.Message, fields)
+	}
+}
+
+func TestProgressSpinner(t *testing.T) {
+	l := newBenchLogger(t, Config{})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	b := l.StartProgress("scan", 0)
+	b.Add(5)
+	b.Done()
+	if got := progressCounts(logs); !slices.Equal(got, []int64{0, 5}) {
+		t.Errorf("spinner entries at %v, want start and completion only", got)
+	}
+	if _, ok := logs.All()[0].ContextMap()["total"]; ok {
+		t.Error("spinner entry has a total")
+	}
+}
+
+func TestProgressTerminal(t *testing.T) {
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevStdout, prevOutput, prevTTY := os.Stdout, progressOutput, progressTTY
+	var bar bytes.Buffer
+	os.Stdout, progressOutput, progressTTY = stdout, &bar, true
+	t.Cleanup(func() {
+		os.Stdout, progressOutput, progressTTY = prevStdout, prevOutput, prevTTY
+		stdout.Close()
+	})
+	withoutColor(t)
+
+	l, err := NewLogger(Config{Level: "info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	b := l.StartProgress("migrate", 4)
+	b.Add(4)
+	b.Done()
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := progressCounts(logs); !slices.Equal(got, []int64{0, 4, 4}) {
+		t.Errorf("progress entries at %v, want [0 4 4]", got)
+	}
+	console, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(console, []byte("Progress")) {
+		t.Errorf("console received progress entries while a bar was live: %q", console)
+	}
+	final := bar.String()[strings.LastIndex(bar.String(), "\r\033[K"):]
+	if !strings.Contains(final, "migrate "+strings.Repeat("█", progressBarWidth)+" 4/4 100%") || !strings.HasSuffix(final, "\n") {
+		t.Errorf("final bar = %q", final)
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// deliveredViaKey names the path that delivered an entry other than live
+	deliveredViaKey = "delivered_via"
+	// originalTimeKey is the time the entry was logged, for backends that
+	// stamp entries with their arrival
+	originalTimeKey = "original_time"
+	// replayKey marks entries delivered after their sink recovered
+	replayKey = "replay"
+
+	// deliveredViaSpool marks entries the network sink buffered while its
+	// collector was unreachable
+	deliveredViaSpool = "spool"
+	// deliveredViaLastResort marks entries written to the last resort
+	// output after every sink failed
+	deliveredViaLastResort = "last_resort"
+)
+
+// spoolCore adds provenance fields to the entries a network sink writes
+// while its collector is unreachable, since they reach it only when the
+// buffer is replayed after reconnecting
+type spoolCore struct {
+	zapcore.Core
+	writer *networkWriter
+}
+
+// With returns a child core
+func (c *spoolCore) With(fields []zapcore.Field) zapcore.Core {
+	return &spoolCore{Core: c.Core.With(fields), writer: c.writer}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *spoolCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write marks the entry as replayed if the collector is down
+func (c *spoolCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.writer.spooling() {
+		return c.Core.Write(ent, fields)
+	}
+	with := make([]zapcore.Field, 0, len(fields)+3)
+	with = append(with, fields...)
+	with = append(with,
+		zap.String(deliveredViaKey, deliveredViaSpool),
+		zap.String(originalTimeKey, ent.Time.Format(time.RFC3339Nano)),
+		zap.Bool(replayKey, true),
+	)
+	return c.Core.Write(ent, with)
+}
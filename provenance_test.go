@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProvenanceSpool(t *testing.T) {
+	// Reserve an address with no collector behind it yet
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := newBenchLogger(t, Config{
+		Level:      "info",
+		Provenance: true,
+		Network:    &NetworkConfig{Protocol: "tcp", Address: addr, MaxBackoff: 50 * time.Millisecond},
+	})
+	writer := l.state.pipe.Load().res.network
+	waitSpooling := func(want bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for writer.spooling() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("spooling never became %v", want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	l.Info("before the failure")
+	waitSpooling(true)
+	l.Info("while down")
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	waitSpooling(false)
+	l.Info("after reconnecting")
+
+	sc := bufio.NewScanner(conn)
+	for _, want := range []struct {
+		msg    string
+		replay bool
+	}{
+		{"before the failure", false},
+		{"while down", true},
+		{"after reconnecting", false},
+	} {
+		if !sc.Scan() {
+			t.Fatalf("collector received no %q entry: %v", want.msg, sc.Err())
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["msg"] != want.msg {
+			t.Fatalf("received %v, want %q", entry["msg"], want.msg)
+		}
+		_, hasTime := entry[originalTimeKey]
+		if want.replay {
+			if entry[deliveredViaKey] != deliveredViaSpool || entry[replayKey] != true || !hasTime {
+				t.Errorf("spooled entry %v is missing its provenance", entry)
+			}
+		} else if _, ok := entry[deliveredViaKey]; ok || hasTime {
+			t.Errorf("live entry %v has provenance fields", entry)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package logger
+
+// raceEnabled is set under the race detector, whose sync.Pool drops items
+// at random, so allocation counts don't hold
+const raceEnabled = true
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitKeys bounds the number of message templates tracked by a
+// config-level limiter; the table is reset when it fills up, after writing
+// the summaries still pending
+const maxRateLimitKeys = 10000
+
+// rateLimiter suppresses entries beyond a rate, per key
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu   sync.Mutex
+	keys map[string]*rateKey
+}
+
+// rateKey tracks one key's limiter and the entries it has suppressed
+type rateKey struct {
+	limiter    *rate.Limiter
+	suppressed uint64
+	core       zapcore.Core
+	ent        zapcore.Entry
+}
+
+// newRateLimiter creates a limiter allowing limit entries per second per key
+func newRateLimiter(limit rate.Limit, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{limit: limit, burst: burst, keys: make(map[string]*rateKey)}
+}
+
+// allow reports whether an entry for key may be written. When it may, it
+// also returns how many entries were suppressed since the last one allowed.
+func (r *rateLimiter) allow(key string, core zapcore.Core, ent zapcore.Entry) (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[key]
+	if !ok {
+		if len(r.keys) >= maxRateLimitKeys {
+			r.flushLocked()
+			r.keys = make(map[string]*rateKey)
+		}
+		k = &rateKey{limiter: rate.NewLimiter(r.limit, r.burst)}
+		r.keys[key] = k
+	}
+
+	if !k.limiter.Allow() {
+		k.suppressed++
+		k.core = core
+		k.ent = ent
+		return 0, false
+	}
+	n := k.suppressed
+	k.suppressed = 0
+	k.core = nil
+	return n, true
+}
+
+// flush writes a summary for every key with suppressed entries
+func (r *rateLimiter) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+// flushLocked is flush for callers holding r.mu
+func (r *rateLimiter) flushLocked() {
+	for key, k := range r.keys {
+		if k.suppressed > 0 {
+			writeSuppressedSummary(k.core, k.ent, key, k.suppressed)
+			k.suppressed = 0
+			k.core = nil
+		}
+	}
+}
+
+// writeSuppressedSummary reports n suppressed entries like ent through core
+func writeSuppressedSummary(core zapcore.Core, ent zapcore.Entry, key string, n uint64) {
+	summary := zapcore.Entry{
+		Level:      ent.Level,
+		Time:       ent.Time,
+		LoggerName: ent.LoggerName,
+		Message:    "suppressed " + strconv.FormatUint(n, 10) + " duplicates",
+	}
+	if ce := core.Check(summary, nil); ce != nil {
+		ce.Write(
+			zap.String("rate_limit_key", key),
+			zap.Uint64("suppressed", n),
+			zap.String("suppressed_msg", ent.Message),
+		)
+	}
+}
+
+// rateLimitCore drops entries its limiter doesn't allow. With an empty key,
+// entries are limited per level and message.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+	key     string
+}
+
+// With returns a child core sharing the same limiter
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter, key: c.key}
+}
+
+// Check consults the limiter before deferring to the wrapped core
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	key := c.key
+	if key == "" {
+		key = ent.Level.String() + "|" + ent.Message
+	}
+	n, ok := c.limiter.allow(key, c.Core, ent)
+	if !ok {
+		return ce
+	}
+	if n > 0 {
+		writeSuppressedSummary(c.Core, ent, key, n)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// rateLimiter returns the limiter registered under key, creating it with
+// the given rate on first use
+func (s *loggerState) rateLimiter(key string, limit rate.Limit, burst int) *rateLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rateLimiters == nil {
+		s.rateLimiters = make(map[string]*rateLimiter)
+	}
+	rl, ok := s.rateLimiters[key]
+	if !ok {
+		rl = newRateLimiter(limit, burst)
+		s.rateLimiters[key] = rl
+	}
+	return rl
+}
+
+// flushRateLimiters emits pending suppression summaries
+func (s *loggerState) flushRateLimiters() {
+	s.mu.Lock()
+	limiters := make([]*rateLimiter, 0, len(s.rateLimiters))
+	for _, rl := range s.rateLimiters {
+		limiters = append(limiters, rl)
+	}
+	s.mu.Unlock()
+
+	for _, rl := range limiters {
+		rl.flush()
+	}
+}
+
+// RateLimited returns a logger whose entries are limited to limit per second
+// with the given burst, shared by every caller using the same key. Entries
+// beyond the rate are dropped and reported later as a single
+// "suppressed N duplicates" entry. The rate is fixed by the first call for a key.
+func (l *Logger) RateLimited(key string, limit rate.Limit, burst int) *Logger {
+	rl := l.state.rateLimiter("key:"+key, limit, burst)
+	return l.derive(l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		// With gives the child its own root, so Silence doesn't reach the parent
+		return &rateLimitCore{Core: core.With(nil), limiter: rl, key: key}
+	})))
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"strconv"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRateLimiterResetWritesPendingSummaries(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	r := newRateLimiter(0, 1)
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "retrying"}
+
+	for range 3 {
+		r.allow("retrying", core, ent)
+	}
+	// Filling the table resets it, which must not lose the two suppressed
+	for i := range maxRateLimitKeys {
+		r.allow("key "+strconv.Itoa(i), core, ent)
+	}
+
+	summaries := logs.FilterMessage("suppressed 2 duplicates").All()
+	if len(summaries) != 1 {
+		t.Fatalf("wrote %d summaries, want 1", len(summaries))
+	}
+	if got := summaries[0].ContextMap()["rate_limit_key"]; got != "retrying" {
+		t.Errorf("summary for key %v, want retrying", got)
+	}
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"slices"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// recentBuffer keeps the latest entries in a ring
+type recentBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+// newRecentBuffer creates the ring for Config.RecentEntries, or returns
+// nil when it is disabled
+func newRecentBuffer(size int) *recentBuffer {
+	if size <= 0 {
+		return nil
+	}
+	return &recentBuffer{entries: make([]LogEntry, size)}
+}
+
+// add records e, replacing the oldest entry when full
+func (b *recentBuffer) add(e LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// last returns up to n of the latest entries, oldest first; every entry
+// if n <= 0
+func (b *recentBuffer) last(n int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var all []LogEntry
+	if b.full {
+		all = slices.Concat(b.entries[b.next:], b.entries[:b.next])
+	} else {
+		all = slices.Clone(b.entries[:b.next])
+	}
+	if n > 0 && n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// inherit copies the entries of old, from a pipeline being replaced
+func (b *recentBuffer) inherit(old *recentBuffer) {
+	for _, e := range old.last(0) {
+		b.add(e)
+	}
+}
+
+// Recent returns up to n of the latest entries at every level, oldest
+// first, or all of them if n <= 0, for attaching context to bug reports
+// and debug endpoints. Entries below the sinks' level are included, so
+// debug context is kept while running at info. It returns nil unless
+// Config.RecentEntries enables the buffer.
+func (l *Logger) Recent(n int) []LogEntry {
+	p := l.state.pipe.Load()
+	if p.res.recent == nil {
+		return nil
+	}
+	return p.res.recent.last(n)
+}
+
+// newLogEntry converts an entry written to a core, with its fields
+// already encoded
+func newLogEntry(ent zapcore.Entry, fields map[string]any) LogEntry {
+	entry := LogEntry{
+		Time:    ent.Time,
+		Level:   ent.Level,
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Stack:   ent.Stack,
+		Fields:  fields,
+	}
+	if ent.Caller.Defined {
+		entry.Caller = ent.Caller.TrimmedPath()
+	}
+	return entry
+}
+
+// recentCore records every entry in a recent buffer. It is kept apart from
+// the sinks and checked by swapCore, so entries below the sinks' level
+// reach the ring without the sinks or the pipeline's wrappers seeing them.
+type recentCore struct {
+	buf *recentBuffer
+	ctx mapEncoder
+}
+
+// Enabled reports true: every level is recorded
+func (c *recentCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns a child core whose entries carry fields
+func (c *recentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recentCore{buf: c.buf, ctx: c.ctx.withFields(fields)}
+}
+
+// Check adds this core to the checked entry
+func (c *recentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+// Write records the entry
+func (c *recentCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.buf.add(newLogEntry(ent, c.ctx.withFields(fields).Fields))
+	return nil
+}
+
+// Sync does nothing
+func (c *recentCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "info", EnableFile: true, FilePath: path, RecentEntries: 3})
+	l.Debug("first")
+	l.Info("second")
+	l.WithField("k", 1).Debug("third")
+	l.Warn("fourth")
+
+	var got []string
+	for _, e := range l.Recent(0) {
+		got = append(got, e.Level.String()+" "+e.Message)
+	}
+	want := []string{"info second", "debug third", "warn fourth"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Recent(0) = %q, want %q", got, want)
+	}
+	if last := l.Recent(2); len(last) != 2 || last[0].Fields["k"] != int64(1) {
+		t.Errorf("Recent(2) = %+v, want the last two, the first with k=1", last)
+	}
+
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "first") || strings.Contains(string(data), "third") {
+		t.Errorf("the file sink wrote debug entries kept only for Recent:\n%s", data)
+	}
+}
+
+func TestRecentOffByDefault(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	l.Info("entry")
+	if got := l.Recent(0); got != nil {
+		t.Errorf("Recent(0) = %v without Config.RecentEntries, want nil", got)
+	}
+	if l.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("debug reported enabled at info without a recent ring")
+	}
+	if raceEnabled {
+		return
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Info("request handled") }); allocs > 1 {
+		t.Errorf("Info with the default config allocates %v times, want at most 1", allocs)
+	}
+}
+
+func TestAdminRecent(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info", RecentEntries: 2})
+	for _, msg := range []string{"first", "second", "third"} {
+		l.Info(msg)
+	}
+	h := l.AdminHandler(StaticCredentials(Credentials{Token: "secret"}))
+
+	tests := []struct {
+		query  string
+		status int
+		want   int
+	}{
+		{"", http.StatusOK, 2},
+		{"?n=1", http.StatusOK, 1},
+		{"?n=999999999999", http.StatusOK, 2},
+		{"?n=-1", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/recent"+tt.query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("GET /recent%s: status %d, want %d", tt.query, rec.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var entries []map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != tt.want {
+			t.Errorf("GET /recent%s: %d entries, want %d", tt.query, len(entries), tt.want)
+		}
+	}
+}
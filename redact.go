@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces values masked by a Redactor
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys are the keys masked by a Redactor created without keys
+var DefaultRedactedKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"api_key", "apikey", "authorization", "auth", "cookie", "session",
+	"private_key", "client_secret", "signature",
+}
+
+// Redactor masks the values of sensitive keys. Keys match case-insensitively,
+// ignoring '-' and '_' differences.
+type Redactor struct {
+	keys map[string]struct{}
+}
+
+// NewRedactor creates a redactor for keys, or DefaultRedactedKeys if none
+// are given
+func NewRedactor(keys ...string) *Redactor {
+	if len(keys) == 0 {
+		keys = DefaultRedactedKeys
+	}
+	r := &Redactor{keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		r.keys[normalizeRedactKey(k)] = struct{}{}
+	}
+	return r
+}
+
+// normalizeRedactKey folds case and separators
+func normalizeRedactKey(k string) string {
+	return strings.ReplaceAll(strings.ToLower(k), "-", "_")
+}
+
+// Redacts reports whether values for key are masked. A nil Redactor masks nothing.
+func (r *Redactor) Redacts(key string) bool {
+	if r == nil {
+		return false
+	}
+	_, ok := r.keys[normalizeRedactKey(key)]
+	return ok
+}
+
+// String returns RedactedValue if key is sensitive and value otherwise
+func (r *Redactor) String(key, value string) string {
+	if r.Redacts(key) {
+		return RedactedValue
+	}
+	return value
+}
+
+// Value masks v if key is sensitive, and otherwise redacts nested maps and
+// slices recursively, returning copies rather than modifying v
+func (r *Redactor) Value(key string, v any) any {
+	if r.Redacts(key) {
+		return RedactedValue
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = r.Value(k, item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = r.Value("", item)
+		}
+		return out
+	}
+	return v
+}
+
+// URL returns u as a string with the password of its userinfo and
+// sensitive query parameter values masked. The password is masked even by
+// a nil Redactor.
+func (r *Redactor) URL(u *url.URL) string {
+	masked := *u
+	if _, ok := u.User.Password(); ok {
+		masked.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	if r == nil || u.RawQuery == "" {
+		return masked.String()
+	}
+	query := u.Query()
+	changed := false
+	for k, values := range query {
+		if r.Redacts(k) {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+			changed = true
+		}
+	}
+	if changed {
+		// Keep the mask readable rather than percent-encoded
+		masked.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(RedactedValue), RedactedValue)
+	}
+	return masked.String()
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Registry manages named loggers, one per tenant, each with its own config
+// and level. Tenants whose configs name the same file share one open handle,
+// and loggers are created once per name, so repeated lookups never leak
+// file descriptors.
+type Registry struct {
+	mu      sync.Mutex
+	loggers map[string]*Logger
+	files   *fileSet
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{loggers: make(map[string]*Logger), files: newFileSet()}
+}
+
+// GetOrCreate returns the logger for tenant, creating it from config on
+// first use. Later calls return the existing logger and ignore config; use
+// its Reload method to change it. Entries carry a "tenant" field.
+func (r *Registry) GetOrCreate(tenant string, config Config) (*Logger, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.loggers[tenant]; ok {
+		return l, nil
+	}
+
+	l, err := newLogger(config, r.files)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", tenant, err)
+	}
+	l = l.derive(l.Logger.With(zap.String("tenant", tenant)))
+	r.loggers[tenant] = l
+	return l, nil
+}
+
+// Get returns the logger for tenant, if it exists
+func (r *Registry) Get(tenant string) (*Logger, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.loggers[tenant]
+	return l, ok
+}
+
+// Tenants returns the names of the registered loggers, sorted
+func (r *Registry) Tenants() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.loggers))
+	for name := range r.loggers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Remove closes the logger for tenant and removes it from the registry
+func (r *Registry) Remove(ctx context.Context, tenant string) error {
+	r.mu.Lock()
+	l, ok := r.loggers[tenant]
+	delete(r.loggers, tenant)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return l.Close(ctx)
+}
+
+// SetLevelAll sets the level of every registered logger
+func (r *Registry) SetLevelAll(level string) error {
+	if _, err := parseLevel(level); err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	for _, l := range r.snapshot() {
+		if err := l.SetLevel(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncAll flushes every registered logger
+func (r *Registry) SyncAll() error {
+	var errs []error
+	for name, l := range r.snapshot() {
+		if err := syncError(l.Sync()); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAll closes every registered logger and empties the registry
+func (r *Registry) CloseAll(ctx context.Context) error {
+	r.mu.Lock()
+	loggers := r.loggers
+	r.loggers = make(map[string]*Logger)
+	r.mu.Unlock()
+
+	var errs []error
+	for name, l := range loggers {
+		if err := l.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshot copies the registered loggers so bulk operations run unlocked
+func (r *Registry) snapshot() map[string]*Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	loggers := make(map[string]*Logger, len(r.loggers))
+	for name, l := range r.loggers {
+		loggers[name] = l
+	}
+	return loggers
+}
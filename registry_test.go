@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	discardStdout(t)
+	path := filepath.Join(t.TempDir(), "tenants.log")
+	config := Config{EnableFile: true, FilePath: path}
+	r := NewRegistry()
+	t.Cleanup(func() { r.CloseAll(context.Background()) })
+
+	acme, err := r.GetOrCreate("acme", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globex, err := r.GetOrCreate("globex", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := r.GetOrCreate("acme", Config{Level: "error"}); again != acme {
+		t.Error("GetOrCreate created a second logger for acme")
+	}
+	if got, ok := r.Get("globex"); !ok || got != globex {
+		t.Error("Get did not return globex")
+	}
+	if got := r.Tenants(); !slices.Equal(got, []string{"acme", "globex"}) {
+		t.Errorf("Tenants = %v", got)
+	}
+	if n := len(r.files.files); n != 1 {
+		t.Errorf("tenants sharing a file opened %d handles, want 1", n)
+	}
+	if _, err := r.GetOrCreate("initech", Config{EnableFile: true, FilePath: path, FileCompression: FileCompressionGzip}); err == nil {
+		t.Error("opened a shared file with another compression")
+	}
+
+	acme.Info("from acme")
+	globex.Info("from globex")
+	if err := r.SetLevelAll("verbose"); err == nil {
+		t.Error("SetLevelAll accepted an invalid level")
+	}
+	if err := r.SetLevelAll("warn"); err != nil {
+		t.Fatal(err)
+	}
+	acme.Info("filtered")
+	globex.Warn("warning")
+	if err := r.SyncAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(context.Background(), "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Get("acme"); ok {
+		t.Error("acme is still registered after Remove")
+	}
+	globex.Warn("after remove")
+	if err := r.CloseAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Tenants(); len(got) != 0 {
+		t.Errorf("Tenants after CloseAll = %v", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range bytes.Lines(data) {
+		var entry struct{ Logger, Msg, Tenant string }
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Logger != diagnosticsLoggerName {
+			got = append(got, entry.Tenant+": "+entry.Msg)
+		}
+	}
+	want := []string{"acme: from acme", "globex: from globex", "globex: warning", "globex: after remove"}
+	if !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package logger
+
+// Reopen reopens the logger's files at their configured paths and
+// reconnects its network sink, keeping every other setting. Call it after
+// the files were moved by an external rotation tool, or after a process
+// changes root, user, or working directory while daemonizing. The previous
+// handles are closed once in-flight entries are written, as with Reload.
+//
+// Files and connections are opened close-on-exec, so processes started
+// with exec don't inherit them. Go processes can't keep running after a
+// bare fork; daemonizing re-executes the binary, which creates its logger
+// anew. Call Close before syscall.Exec so queued entries aren't lost.
+func (l *Logger) Reopen() error {
+	s := l.state
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	p := s.pipe.Load()
+	for _, f := range p.res.files {
+		s.files.forget(f)
+	}
+	return l.reload(p.config)
+}
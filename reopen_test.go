@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "app.log.1")
+	l := newBenchLogger(t, Config{EnableFile: true, FilePath: path})
+	child := l.WithField("worker", "w1")
+
+	l.Info("before rotation")
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("after rotation")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if line := string(readOnlyLine(t, rotated)); !strings.Contains(line, "before rotation") {
+		t.Errorf("rotated file holds %s", line)
+	}
+	line := string(readOnlyLine(t, path))
+	if !strings.Contains(line, "after rotation") || !strings.Contains(line, `"worker":"w1"`) {
+		t.Errorf("reopened file holds %s, want the child's entry", line)
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// retentionKey is the field key for retention classes
+const retentionKey = "retention"
+
+// Retention returns a field marking an entry with a data-retention class,
+// such as "pii" or "audit". SessionConfig.Retention removes session files
+// holding the class once it expires; the main log file is never rotated
+// or deleted by the logger, so retention tooling there selects entries by
+// the field.
+func Retention(class string) zap.Field {
+	return zap.String(retentionKey, class)
+}
+
+// WithRetention returns a logger marking each entry with retention class
+// class, as Retention
+func (l *Logger) WithRetention(class string) *Logger {
+	return l.derive(l.Logger.With(Retention(class)))
+}
+
+// retentionExpired reports whether the session file at path holds entries
+// of a class in retention that has expired by now, counted from the file's
+// last write
+func retentionExpired(path string, retention map[string]time.Duration, now time.Time) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(info.ModTime())
+	// Files younger than every class are kept without reading them
+	if age < slices.Min(slices.Collect(maps.Values(retention))) {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	marker := []byte(`"` + retentionKey + `":`)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if bytes.Contains(line, marker) {
+			var entry struct {
+				Class string `json:"retention"`
+			}
+			if json.Unmarshal(line, &entry) == nil {
+				if ttl, ok := retention[entry.Class]; ok && age >= ttl {
+					return true
+				}
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionRetention(t *testing.T) {
+	const day = 24 * time.Hour
+	files := []struct {
+		name    string
+		content string
+		age     time.Duration
+		kept    bool
+	}{
+		{name: "old-pii", content: `{"msg":"signup","retention":"pii"}`, age: 8 * day},
+		{name: "new-pii", content: `{"msg":"signup","retention":"pii"}`, age: 6 * day, kept: true},
+		{name: "old-plain", content: `{"msg":"started"}`, age: 60 * day, kept: true},
+		{name: "old-audit", content: `{"msg":"started"}` + "\n" + `{"msg":"deleted","retention":"audit"}`, age: 20 * day, kept: true},
+		{name: "older-audit", content: `{"msg":"deleted","retention":"audit"}`, age: 31 * day},
+		{name: "other-class", content: `{"msg":"x","retention":"debug"}`, age: 60 * day, kept: true},
+		{name: "long-line", content: `{"msg":"` + strings.Repeat("x", 100<<10) + `","retention":"pii"}`, age: 8 * day},
+	}
+	dir := t.TempDir()
+	now := time.Now()
+	for _, f := range files {
+		path := filepath.Join(dir, sessionFilePrefix+f.name+".log")
+		if err := os.WriteFile(path, []byte(f.content+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now, now.Add(-f.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	session := &SessionConfig{Dir: dir, MaxFiles: -1, Retention: map[string]time.Duration{"pii": 7 * day, "audit": 30 * day}}
+	l := newBenchLogger(t, Config{Level: "info", Session: session})
+	l.WithRetention("pii").Info("signup")
+	if err := syncError(l.Sync()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(dir, sessionFilePrefix+f.name+".log"))
+		if kept := err == nil; kept != f.kept {
+			t.Errorf("%s kept = %v, want %v", f.name, kept, f.kept)
+		}
+	}
+	data, err := os.ReadFile(l.SessionFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"retention":"pii"`) {
+		t.Errorf("session file lacks the retention class:\n%s", data)
+	}
+}
+
+func TestSessionRetentionInvalid(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Hour} {
+		config := SessionConfig{Dir: t.TempDir(), Retention: map[string]time.Duration{"pii": ttl}}
+		if _, err := config.withDefaults(); err == nil {
+			t.Errorf("withDefaults accepted retention %s", ttl)
+		}
+	}
+}
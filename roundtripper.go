@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RoundTripperOption configures a logging RoundTripper
+type RoundTripperOption func(*loggingRoundTripper)
+
+// WithTransport sets the RoundTripper that performs requests. Defaults to
+// http.DefaultTransport.
+func WithTransport(next http.RoundTripper) RoundTripperOption {
+	return func(rt *loggingRoundTripper) {
+		rt.next = next
+	}
+}
+
+// WithQueryRedactor masks sensitive query parameters in logged URLs.
+// Defaults to NewRedactor().
+func WithQueryRedactor(r *Redactor) RoundTripperOption {
+	return func(rt *loggingRoundTripper) {
+		rt.redactor = r
+	}
+}
+
+// WithBodyLogging logs request and response bodies, truncated to maxBytes
+func WithBodyLogging(maxBytes int) RoundTripperOption {
+	return func(rt *loggingRoundTripper) {
+		rt.maxBody = maxBytes
+	}
+}
+
+// WithRetries retries idempotent requests up to n times on transport errors
+// and 5xx responses, waiting backoff multiplied by the attempt number
+// between tries. Every attempt is logged.
+func WithRetries(n int, backoff time.Duration) RoundTripperOption {
+	return func(rt *loggingRoundTripper) {
+		rt.retries = n
+		rt.backoff = backoff
+	}
+}
+
+// loggingRoundTripper logs every outbound request
+type loggingRoundTripper struct {
+	logger   *zap.Logger
+	next     http.RoundTripper
+	redactor *Redactor
+	maxBody  int
+	retries  int
+	backoff  time.Duration
+}
+
+// NewLoggingRoundTripper returns an http.RoundTripper that logs each request
+// with its method, URL (query parameters redacted), status, latency, and
+// attempt number. Successful responses are logged at info, 4xx and 5xx at
+// warn, and transport errors at error. A correlation ID in the request
+// context is logged and sent as X-Correlation-ID unless the request sets it.
+func NewLoggingRoundTripper(l *Logger, opts ...RoundTripperOption) http.RoundTripper {
+	rt := &loggingRoundTripper{
+		logger:   l.Logger.WithOptions(zap.WithCaller(false)),
+		next:     http.DefaultTransport,
+		redactor: NewRedactor(),
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// RoundTrip performs and logs the request
+func (rt *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := CorrelationIDFromContext(req.Context()); id != "" && req.Header.Get(CorrelationIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+
+	var reqBody []byte
+	if rt.maxBody > 0 && req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		reqBody, req.Body = peekBody(req.Body, rt.maxBody)
+	}
+
+	retryable := rt.retries > 0 && isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	if retryable {
+		// Retries replace the body, which must not be done to the caller's
+		// request
+		req = req.Clone(req.Context())
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+			select {
+			case <-time.After(rt.backoff * time.Duration(attempt-1)):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		start := time.Now()
+		resp, err := rt.next.RoundTrip(req)
+		duration := time.Since(start)
+
+		last := !retryable || attempt > rt.retries || req.Context().Err() != nil
+		failed := err != nil || resp.StatusCode >= 500
+		if failed && !last && resp != nil {
+			// Drain so the connection can be reused by the retry
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		rt.log(req, resp, err, duration, attempt, reqBody, failed && !last)
+		if !failed || last {
+			return resp, err
+		}
+	}
+}
+
+// log writes the entry for one attempt, capturing the response body if
+// configured and the response will be returned to the caller
+func (rt *loggingRoundTripper) log(req *http.Request, resp *http.Response, err error, duration time.Duration, attempt int, reqBody []byte, retrying bool) {
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", rt.redactor.URL(req.URL)),
+		zap.Duration("duration", duration),
+		zap.Int("attempt", attempt),
+	}
+	if id := CorrelationIDFromContext(req.Context()); id != "" {
+		fields = append(fields, zap.String(correlationIDKey, id))
+	}
+	if reqBody != nil {
+		fields = append(fields, zap.ByteString("request_body", reqBody))
+	}
+	if retrying {
+		fields = append(fields, zap.Bool("retrying", true))
+	}
+
+	level := zapcore.InfoLevel
+	msg := "HTTP request completed"
+	switch {
+	case err != nil:
+		level = zapcore.ErrorLevel
+		msg = "HTTP request failed"
+		fields = append(fields, zap.Error(err))
+	case resp.StatusCode >= 400:
+		level = zapcore.WarnLevel
+		msg = "HTTP request returned error status"
+	}
+	if resp != nil {
+		fields = append(fields, zap.Int("status_code", resp.StatusCode))
+		if rt.maxBody > 0 && !retrying && resp.Body != nil && resp.Body != http.NoBody {
+			var respBody []byte
+			respBody, resp.Body = peekBody(resp.Body, rt.maxBody)
+			fields = append(fields, zap.ByteString("response_body", respBody))
+		}
+	}
+
+	if ce := rt.logger.Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// peekBody reads up to max bytes from body and returns them along with a
+// replacement body that yields the full original content
+func peekBody(body io.ReadCloser, max int) ([]byte, io.ReadCloser) {
+	prefix, _ := io.ReadAll(io.LimitReader(body, int64(max)))
+	return prefix, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), body), body}
+}
+
+// isIdempotent reports whether req may be safely retried
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
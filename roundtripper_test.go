@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLoggingRoundTripperRetryKeepsRequest(t *testing.T) {
+	attempts := 0
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d sent body %q", attempts, body)
+		}
+		status := http.StatusOK
+		if attempts == 1 {
+			status = http.StatusServiceUnavailable
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+	})
+	rt := NewLoggingRoundTripper(NewNop(), WithTransport(next), WithRetries(1, 0))
+
+	req, err := http.NewRequest(http.MethodPut, "http://example.com/x", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := req.Body
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if req.Body != body {
+		t.Error("RoundTrip replaced the caller's request body")
+	}
+}
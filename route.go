@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sink names accepted by Logger.To
+const (
+	SinkConsole = "console"
+	SinkFile    = "file"
+	SinkNetwork = "network"
+	// SinkJSON selects the Config.JSONOutput stream
+	SinkJSON = "json"
+	// SinkSession selects the Config.Session file
+	SinkSession = "session"
+	// SinkAdded selects the cores added with AddSink
+	SinkAdded = "added"
+)
+
+// sinkRoute lists the sinks a logger's entries go to. It is carried as a
+// skipped context field, which encoders ignore.
+type sinkRoute []string
+
+// To returns a logger whose entries go only to the named sinks
+// (SinkConsole, SinkJSON, SinkFile, SinkSession, SinkNetwork, or
+// SinkAdded), bypassing the others, for example to keep verbose payload
+// dumps off the console or operator notices out of the file. A later To replaces the earlier one; unknown names select
+// nothing.
+func (l *Logger) To(sinks ...string) *Logger {
+	field := zapcore.Field{Type: zapcore.SkipType, Interface: sinkRoute(slices.Clone(sinks))}
+	return l.derive(l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return withRootField(core, field, isRouteField)
+	})))
+}
+
+// isRouteField reports whether f is a sink route
+func isRouteField(f zapcore.Field) bool {
+	_, ok := f.Interface.(sinkRoute)
+	return ok && f.Type == zapcore.SkipType
+}
+
+// routeCore wraps a sink, disabling it for loggers routed elsewhere
+type routeCore struct {
+	zapcore.Core
+	name     string
+	excluded bool
+}
+
+// newRouteCore wraps the sink named name
+func newRouteCore(name string, core zapcore.Core) zapcore.Core {
+	return &routeCore{Core: core, name: name}
+}
+
+// Enabled reports whether the sink accepts level and isn't routed around
+func (c *routeCore) Enabled(level zapcore.Level) bool {
+	return !c.excluded && c.Core.Enabled(level)
+}
+
+// With returns a child core, applying a route among fields
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	excluded := c.excluded
+	for _, f := range fields {
+		if isRouteField(f) {
+			excluded = !slices.Contains(f.Interface.(sinkRoute), c.name)
+		}
+	}
+	return &routeCore{Core: c.Core.With(fields), name: c.name, excluded: excluded}
+}
+
+// Check defers to the sink unless it is routed around
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.excluded {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRoute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{EnableFile: true, FilePath: path})
+	obs, logs := observer.New(zapcore.InfoLevel)
+	l.AddSink(obs)
+
+	l.Info("everywhere")
+	l.To(SinkFile).Info("file only")
+	l.To(SinkAdded).WithField("k", "v").Info("added only")
+	l.To(SinkFile).To(SinkAdded).Info("rerouted")
+	l.To("printer").Info("nowhere")
+	l.To(SinkFile, SinkAdded).Info("both")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var added []string
+	for _, e := range logs.All() {
+		added = append(added, e.Message)
+	}
+	if want := []string{"everywhere", "added only", "rerouted", "both"}; !slices.Equal(added, want) {
+		t.Errorf("added sink got %q, want %q", added, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file []string
+	for line := range bytes.Lines(data) {
+		var entry struct{ Msg string }
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatal(err)
+		}
+		file = append(file, entry.Msg)
+	}
+	if want := []string{"everywhere", "file only", "both"}; !slices.Equal(file, want) {
+		t.Errorf("file got %q, want %q", file, want)
+	}
+}
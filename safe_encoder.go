@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// safeEncoder recovers from panics raised while encoding, such as a
+// MarshalJSON or MarshalLogObject method panicking on a hostile value, so
+// a broken field never takes down the host application. A field whose
+// encoding panics fails with an error, which zap records as "<key>Error".
+type safeEncoder struct {
+	zapcore.Encoder
+}
+
+// newSafeEncoder wraps enc with panic recovery
+func newSafeEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	if _, ok := enc.(*safeEncoder); ok {
+		return enc
+	}
+	return &safeEncoder{Encoder: enc}
+}
+
+// Clone clones the wrapped encoder, keeping the recovery
+func (e *safeEncoder) Clone() zapcore.Encoder {
+	return &safeEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// AddArray adds an array field, recovering from a panicking marshaler
+func (e *safeEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddArray(key, safeArrayMarshaler{key: key, arr: arr})
+}
+
+// AddObject adds an object field, recovering from a panicking marshaler
+func (e *safeEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.Encoder.AddObject(key, safeObjectMarshaler{key: key, obj: obj})
+}
+
+// AddReflected adds a reflected field, recovering from a panicking marshaler
+func (e *safeEncoder) AddReflected(key string, value any) (err error) {
+	defer recoverField(key, &err)
+	return e.Encoder.AddReflected(key, value)
+}
+
+// recoverField turns a panic while encoding a field into an error
+func recoverField(key string, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic encoding field %q: %v", key, r)
+	}
+}
+
+// safeObjectMarshaler recovers from a panic in obj inside the marshaler
+// itself, so the encoder still closes the object and its output stays
+// well-formed. Nested objects and arrays are guarded the same way.
+type safeObjectMarshaler struct {
+	key string
+	obj zapcore.ObjectMarshaler
+}
+
+// MarshalLogObject marshals the wrapped object, recovering from a panic
+func (m safeObjectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	defer recoverField(m.key, &err)
+	return m.obj.MarshalLogObject(safeObjectEncoder{ObjectEncoder: enc, key: m.key})
+}
+
+// safeArrayMarshaler is safeObjectMarshaler for arrays
+type safeArrayMarshaler struct {
+	key string
+	arr zapcore.ArrayMarshaler
+}
+
+// MarshalLogArray marshals the wrapped array, recovering from a panic
+func (m safeArrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) (err error) {
+	defer recoverField(m.key, &err)
+	return m.arr.MarshalLogArray(safeArrayEncoder{ArrayEncoder: enc, key: m.key})
+}
+
+// safeObjectEncoder guards the objects and arrays nested in a field
+type safeObjectEncoder struct {
+	zapcore.ObjectEncoder
+	key string
+}
+
+// AddArray adds a nested array, recovering from a panicking marshaler
+func (e safeObjectEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.ObjectEncoder.AddArray(key, safeArrayMarshaler{key: e.key, arr: arr})
+}
+
+// AddObject adds a nested object, recovering from a panicking marshaler
+func (e safeObjectEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.ObjectEncoder.AddObject(key, safeObjectMarshaler{key: e.key, obj: obj})
+}
+
+// safeArrayEncoder guards the objects and arrays nested in an array
+type safeArrayEncoder struct {
+	zapcore.ArrayEncoder
+	key string
+}
+
+// AppendArray appends a nested array, recovering from a panicking marshaler
+func (e safeArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(safeArrayMarshaler{key: e.key, arr: arr})
+}
+
+// AppendObject appends a nested object, recovering from a panicking marshaler
+func (e safeArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(safeObjectMarshaler{key: e.key, obj: obj})
+}
+
+// EncodeEntry encodes the entry. If encoding panics, the entry is encoded
+// again with its fields replaced by a description of the panic, and if that
+// also fails an error is returned instead.
+func (e *safeEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (buf *buffer.Buffer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf, err = e.encodeFallback(ent, r)
+		}
+	}()
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// encodeFallback encodes ent with only a description of the panic
+func (e *safeEncoder) encodeFallback(ent zapcore.Entry, cause any) (buf *buffer.Buffer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf, err = nil, fmt.Errorf("panic encoding log entry: %v", cause)
+		}
+	}()
+	field := zapcore.Field{
+		Key:    "encoderPanic",
+		Type:   zapcore.StringType,
+		String: fmt.Sprint(cause),
+	}
+	return e.Encoder.EncodeEntry(ent, []zapcore.Field{field})
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// panickingValue panics whenever it is marshaled
+type panickingValue struct{}
+
+func (panickingValue) MarshalLogObject(zapcore.ObjectEncoder) error { panic("boom") }
+func (panickingValue) MarshalLogArray(zapcore.ArrayEncoder) error   { panic("boom") }
+func (panickingValue) MarshalJSON() ([]byte, error)                 { panic("boom") }
+
+// nestingValue nests a panicking object between two well-behaved fields
+type nestingValue struct{}
+
+func (nestingValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("before", "ok")
+	err := enc.AddObject("inner", panickingValue{})
+	enc.AddString("after", "ok")
+	return err
+}
+
+func TestSafeEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		context []zap.Field
+		fields  []zap.Field
+		want    map[string]any
+	}{
+		{"context object", []zap.Field{zap.Object("obj", panickingValue{})}, []zap.Field{zap.Int("n", 1)}, map[string]any{
+			"objError": `panic encoding field "obj": boom`,
+			"n":        float64(1),
+		}},
+		{"context array", []zap.Field{zap.Array("arr", panickingValue{})}, nil, map[string]any{
+			"arrError": `panic encoding field "arr": boom`,
+		}},
+		{"context reflected", []zap.Field{zap.Reflect("ref", panickingValue{})}, nil, map[string]any{
+			"refError": `panic encoding field "ref": boom`,
+		}},
+		{"context nested", []zap.Field{zap.Object("outer", nestingValue{})}, nil, map[string]any{
+			"outerError": `panic encoding field "outer": boom`,
+		}},
+		{"entry object", nil, []zap.Field{zap.Int("n", 1), zap.Object("obj", panickingValue{})}, map[string]any{
+			"encoderPanic": "boom",
+			"n":            nil,
+		}},
+		{"malformed field", nil, []zap.Field{{Key: "bad", Type: zapcore.ArrayMarshalerType}}, map[string]any{
+			"encoderPanic": "interface conversion: interface is nil, not zapcore.ArrayMarshaler",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := newSafeEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}))
+			if newSafeEncoder(enc) != enc {
+				t.Error("newSafeEncoder wrapped a safe encoder twice")
+			}
+			enc = enc.Clone()
+			for _, f := range tt.context {
+				f.AddTo(enc)
+			}
+			buf, err := enc.EncodeEntry(zapcore.Entry{Message: "hello"}, tt.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer buf.Free()
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("encoded invalid JSON %q: %v", buf.Bytes(), err)
+			}
+			if got["msg"] != "hello" {
+				t.Errorf("msg = %v, want hello", got["msg"])
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %v, want %v in %s", key, got[key], want, buf.Bytes())
+				}
+			}
+		})
+	}
+}
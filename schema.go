@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// configSchemaHints adds enums and descriptions to ConfigSchema, keyed by
+// "Type.Field"
+var configSchemaHints = map[string]map[string]any{
+	"Config.Level": {
+		"enum":        schemaLevels(),
+		"description": "Minimum level; empty means info",
+	},
+	"Config.Format": {
+		"enum":        []any{"", FormatConsole, FormatDev, FormatJSON},
+		"description": "Console rendering; empty means console",
+	},
+	"Config.ConsoleOutput": {
+		"enum":        []any{"", OutputStdout, OutputStderr},
+		"description": "Console stream; empty means stdout",
+	},
+	"Config.JSONOutput": {
+		"enum":        []any{"", OutputStdout, OutputStderr},
+		"description": "Stream also receiving JSON lines, other than the console's; empty disables it",
+	},
+	"Config.FileFormat": {
+		"enum":        []any{"", FileFormatJSON, FileFormatJSONDelta, FileFormatMsgpack, FileFormatCBOR},
+		"description": "File encoding; empty means json",
+	},
+	"Config.ConsoleMultiline": {
+		"enum":        []any{"", MultilineEscape, MultilineIndent, MultilineSplit},
+		"description": "Line breaks in console messages; empty means indent",
+	},
+	"Config.FileMultiline": {
+		"enum":        []any{"", MultilineEscape, MultilineIndent, MultilineSplit},
+		"description": "Line breaks in file and network messages; empty means escape",
+	},
+	"Config.ConsoleFraming": {
+		"enum":        schemaFramings(),
+		"description": "Console entry separator; empty means newline",
+	},
+	"Config.FileFraming": {
+		"enum":        schemaFramings(),
+		"description": "File entry separator; empty means newline, or none for binary formats",
+	},
+	"Config.FileCompression": {
+		"enum":        []any{"", FileCompressionNone, FileCompressionGzip},
+		"description": "File compression; empty means none",
+	},
+	"Config.TimeZone": {
+		"description": "IANA time zone, UTC, or Local for every sink's timestamps; empty keeps the clock's zone",
+	},
+	"Config.ConsoleTimeZone": {
+		"description": "IANA time zone, UTC, or Local for console timestamps; empty means TimeZone",
+	},
+	"Config.StacktraceLevel": {
+		"enum":        append(schemaLevels(), "off", "none"),
+		"description": "Minimum level that captures a stack trace; empty means error",
+	},
+	"Config.RateLimit": {
+		"minimum":     0,
+		"description": "Entries per second for each distinct level and message; 0 disables limiting",
+	},
+	"FileEncryption.Key": {
+		"description": "Base64 AES key of 16, 24, or 32 bytes",
+	},
+	"SessionConfig.Level": {
+		"enum":        schemaLevels(),
+		"description": "Minimum level recorded; empty means debug",
+	},
+	"SessionConfig.Retention": {
+		"description": "How long session files containing each retention class are kept",
+	},
+	"NetworkConfig.Protocol": {"enum": []any{"tcp", "udp"}},
+	"NetworkConfig.Framing":  {"enum": schemaFramings()},
+	"NetworkConfig.Format": {
+		"enum": []any{"", FileFormatJSON, FileFormatMsgpack, FileFormatCBOR},
+	},
+	"SLOConfig.Objective": {"exclusiveMinimum": 0, "maximum": 1},
+}
+
+// schemaLevels lists the level names accepted by Config.Level
+func schemaLevels() []any {
+	levels := []any{""}
+	for l := TraceLevel; l <= zapcore.FatalLevel; l++ {
+		levels = append(levels, levelName(l), strings.ToUpper(levelName(l)))
+	}
+	return levels
+}
+
+// schemaFramings lists the values accepted by the framing fields
+func schemaFramings() []any {
+	return []any{"", FramingNewline, FramingCRLF, FramingNUL, FramingLength}
+}
+
+// ConfigSchema returns a JSON Schema (draft 2020-12) describing Config as
+// decoded by encoding/json, for validating logging configuration in
+// deployment pipelines and editors. Durations are integers in nanoseconds,
+// as encoding/json reads time.Duration. Fields that cannot come from JSON,
+// such as EncoderOptions, Events, SLO predicates, and NetworkConfig.TLS,
+// are left out.
+func ConfigSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeFor[Config]())
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "go-logger Config"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// durationType is reflect's view of time.Duration
+var durationType = reflect.TypeFor[time.Duration]()
+
+// typeSchema describes t, or returns nil if it cannot be decoded from JSON
+func typeSchema(t reflect.Type) map[string]any {
+	if t == durationType {
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		items := typeSchema(t.Elem())
+		if items == nil {
+			return nil
+		}
+		return map[string]any{"type": "array", "items": items}
+	case reflect.Map:
+		values := typeSchema(t.Elem())
+		if t.Key().Kind() != reflect.String || values == nil {
+			return nil
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}
+	case reflect.Struct:
+		// Structs from other packages, like tls.Config, aren't configuration
+		if t.PkgPath() != reflect.TypeFor[Config]().PkgPath() {
+			return nil
+		}
+		return structSchema(t)
+	}
+	return nil
+}
+
+// structSchema describes the exported, JSON-decodable fields of t, or
+// returns nil if there are none
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := typeSchema(f.Type)
+		if field == nil {
+			continue
+		}
+		for k, v := range configSchemaHints[t.Name()+"."+f.Name] {
+			field[k] = v
+		}
+		properties[f.Name] = field
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
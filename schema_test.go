@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// schemaAllows reports whether every property in doc is described by schema
+func schemaAllows(schema map[string]any, doc any) bool {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return true
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	for key, value := range obj {
+		field, ok := properties[key].(map[string]any)
+		if !ok {
+			field, ok = schema["additionalProperties"].(map[string]any)
+		}
+		if !ok || !schemaAllows(field, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConfigSchema(t *testing.T) {
+	data, err := ConfigSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	properties := schema["properties"].(map[string]any)
+
+	level := properties["Level"].(map[string]any)
+	if !slices.Contains(level["enum"].([]any), "trace") {
+		t.Errorf("Level enum %v is missing trace", level["enum"])
+	}
+	if _, ok := properties["EncoderOptions"]; ok {
+		t.Error("schema describes EncoderOptions, which can't come from JSON")
+	}
+	if got := properties["AggregateWindow"].(map[string]any)["type"]; got != "integer" {
+		t.Errorf("AggregateWindow type = %v, want integer", got)
+	}
+
+	docs := []string{
+		`{"Level": "debug", "Format": "json", "EnableFile": true, "FilePath": "app.log"}`,
+		`{"Session": {"Dir": "runs", "MaxFiles": 5, "Retention": {"audit": 86400000000000}}}`,
+		`{"Network": {"Protocol": "tcp", "Address": "localhost:5170"}}`,
+	}
+	for _, doc := range docs {
+		var config Config
+		dec := json.NewDecoder(strings.NewReader(doc))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&config); err != nil {
+			t.Fatalf("%s: %v", doc, err)
+		}
+		var v any
+		json.Unmarshal([]byte(doc), &v)
+		if !schemaAllows(schema, v) {
+			t.Errorf("schema rejects valid config %s", doc)
+		}
+	}
+	if schemaAllows(schema, map[string]any{"Levle": "debug"}) {
+		t.Error("schema allows a misspelled field")
+	}
+}
+
+func TestConfigSchemaHints(t *testing.T) {
+	types := map[string]reflect.Type{}
+	for _, v := range []any{Config{}, FileEncryption{}, SessionConfig{}, NetworkConfig{}, SLOConfig{}} {
+		types[reflect.TypeOf(v).Name()] = reflect.TypeOf(v)
+	}
+	for key := range configSchemaHints {
+		typeName, fieldName, _ := strings.Cut(key, ".")
+		typ, ok := types[typeName]
+		if !ok {
+			t.Errorf("hint %s names an unknown type", key)
+			continue
+		}
+		if _, ok := typ.FieldByName(fieldName); !ok {
+			t.Errorf("hint %s names a field %s doesn't have", key, typeName)
+		}
+	}
+}
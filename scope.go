@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// scopes maps goroutine IDs to their innermost scope; activeScopes counts
+// its entries so logging skips the goroutine lookup when no scope exists
+var (
+	scopes       sync.Map
+	activeScopes atomic.Int64
+)
+
+// minScopeSweep is how many goroutines may hold scopes before PushScope
+// first sweeps out those of goroutines that exited without popping them
+const minScopeSweep = 1024
+
+// nextScopeSweep is the number of scoped goroutines at which PushScope
+// sweeps next; it doubles with the scopes that survive a sweep so sweeps
+// stay rare
+var (
+	nextScopeSweep atomic.Int64
+	scopeSweepMu   sync.Mutex
+)
+
+func init() {
+	nextScopeSweep.Store(minScopeSweep)
+}
+
+// Scope is a set of ambient fields pushed onto the current goroutine with
+// PushScope
+type Scope struct {
+	gid    uint64
+	fields []zap.Field
+	prev   *Scope
+}
+
+// PushScope adds fields to every entry logged by the current goroutine,
+// through any logger with Config.Scopes, until the returned scope is
+// popped. It lets deeply nested code pick up request or session fields
+// without receiving a logger or context. Scopes nest; pop them in reverse
+// order, usually with defer logger.PushScope(...).Pop(). Goroutines started
+// inside a scope do not inherit it; pass ScopeFields to them explicitly.
+// Scopes left behind by goroutines that exit without popping them are swept
+// out once enough goroutines hold scopes.
+func PushScope(fields ...zap.Field) *Scope {
+	gid := goroutineID()
+	var prev *Scope
+	if v, ok := scopes.Load(gid); ok {
+		prev = v.(*Scope)
+	}
+
+	var merged []zap.Field
+	if prev != nil {
+		merged = append(merged, prev.fields...)
+	}
+	merged = append(merged, resolveFields(fields)...)
+
+	// Clip so callers appending to ScopeFields never write into a scope
+	s := &Scope{gid: gid, fields: slices.Clip(merged), prev: prev}
+	scopes.Store(gid, s)
+	if prev == nil && activeScopes.Add(1) >= nextScopeSweep.Load() {
+		sweepScopes()
+	}
+	return s
+}
+
+// sweepScopes removes the scopes of goroutines that no longer exist.
+// Goroutine IDs are never reused, so a scope whose goroutine is missing
+// from a stack dump taken after it was seen can't be popped any more.
+// The dump stops the world, so it only runs as scopes double.
+func sweepScopes() {
+	if !scopeSweepMu.TryLock() {
+		return
+	}
+	defer scopeSweepMu.Unlock()
+
+	seen := make(map[uint64]*Scope)
+	scopes.Range(func(k, v any) bool {
+		seen[k.(uint64)] = v.(*Scope)
+		return true
+	})
+	for gid := range liveGoroutines() {
+		delete(seen, gid)
+	}
+	for gid, s := range seen {
+		// A goroutine that popped or pushed since keeps its newer scope
+		if scopes.CompareAndDelete(gid, s) {
+			activeScopes.Add(-1)
+		}
+	}
+	nextScopeSweep.Store(max(minScopeSweep, 2*activeScopes.Load()))
+}
+
+// liveGoroutines returns the IDs of every running goroutine, parsed from
+// the headers of a full stack dump
+func liveGoroutines() map[uint64]struct{} {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	live := make(map[uint64]struct{})
+	for line := range bytes.Lines(buf) {
+		if id, ok := parseGoroutineID(line); ok {
+			live[id] = struct{}{}
+		}
+	}
+	return live
+}
+
+// Pop removes the scope and any scopes pushed after it that were not popped
+func (s *Scope) Pop() {
+	v, ok := scopes.Load(s.gid)
+	if !ok {
+		return
+	}
+	for top := v.(*Scope); top != nil; top = top.prev {
+		if top != s {
+			continue
+		}
+		if s.prev != nil {
+			scopes.Store(s.gid, s.prev)
+		} else {
+			scopes.Delete(s.gid)
+			activeScopes.Add(-1)
+		}
+		return
+	}
+}
+
+// ScopeFields returns the ambient fields of the current goroutine
+func ScopeFields() []zap.Field {
+	if activeScopes.Load() == 0 {
+		return nil
+	}
+	v, ok := scopes.Load(goroutineID())
+	if !ok {
+		return nil
+	}
+	return v.(*Scope).fields
+}
+
+// goroutineID returns the current goroutine's ID, parsed from the header
+// of its stack trace ("goroutine 18 [running]:")
+func goroutineID() uint64 {
+	var buf [64]byte
+	id, _ := parseGoroutineID(buf[:runtime.Stack(buf[:], false)])
+	return id
+}
+
+// parseGoroutineID parses the ID from a stack trace's goroutine header
+func parseGoroutineID(b []byte) (uint64, bool) {
+	b, ok := bytes.CutPrefix(b, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	return id, err == nil
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"maps"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes bool
+		want   map[string]any
+	}{
+		{name: "opted in", scopes: true, want: map[string]any{"request": "r1", "user": "u1"}},
+		{name: "not opted in", want: map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info", Scopes: tt.scopes})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			outer := PushScope(zap.String("request", "r1"))
+			inner := PushScope(zap.String("user", "u1"))
+			l.Named("db").Info("nested")
+			// Popping the outer scope drops the inner one with it
+			outer.Pop()
+			inner.Pop()
+			l.Info("popped")
+
+			entries := logs.All()
+			if len(entries) != 2 {
+				t.Fatalf("logged %d entries, want 2", len(entries))
+			}
+			if got := entries[0].ContextMap(); !maps.Equal(got, tt.want) {
+				t.Errorf("scoped entry fields = %v, want %v", got, tt.want)
+			}
+			if got := entries[1].ContextMap(); len(got) != 0 {
+				t.Errorf("entry after Pop has fields %v", got)
+			}
+			if n := activeScopes.Load(); n != 0 {
+				t.Errorf("%d scopes left after Pop", n)
+			}
+		})
+	}
+}
+
+// TestScopeLeakGuard checks that scopes of goroutines that exit without
+// popping them are swept out rather than kept forever
+func TestScopeLeakGuard(t *testing.T) {
+	t.Cleanup(sweepScopes)
+	for range 2 * minScopeSweep {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			PushScope(zap.String("request", "leaked"))
+		}()
+		wg.Wait()
+	}
+	if n := activeScopes.Load(); n >= minScopeSweep {
+		t.Errorf("%d leaked scopes kept, want fewer than %d", n, minScopeSweep)
+	}
+
+	s := PushScope(zap.String("request", "live"))
+	defer s.Pop()
+	sweepScopes()
+	if n := activeScopes.Load(); n != 1 {
+		t.Errorf("%d scopes after sweeping, want only the live one", n)
+	}
+	if got := ScopeFields(); len(got) != 1 || got[0].String != "live" {
+		t.Errorf("live scope fields = %v after sweeping", got)
+	}
+}
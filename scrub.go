@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ScrubStats counts what Scrub did
+type ScrubStats struct {
+	// Entries is the number of entries written
+	Entries int
+	// Skipped is the number of malformed entries left out, as their
+	// content can't be checked
+	Skipped int
+}
+
+// urlPattern finds URLs in string values, whose query parameters may hold
+// credentials
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+
+// Scrub writes the entries of src that pass its filters to w as JSON lines
+// with redactor applied, for sharing logs outside the team, such as on
+// vendor tickets. Values of sensitive keys are masked at any depth, and
+// sensitive query parameters are masked in URLs found in any string,
+// including messages. Malformed entries are skipped and counted.
+func Scrub(ctx context.Context, w io.Writer, src *LogReader, redactor *Redactor) (ScrubStats, error) {
+	var stats ScrubStats
+	if src.follow {
+		return stats, errors.New("scrubbing a followed log file is not supported")
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for {
+		raw, err := src.next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if err != nil {
+			stats.Skipped++
+			continue
+		}
+		if !src.keep(parseLogEntry(raw), raw) {
+			continue
+		}
+		if err := enc.Encode(scrubValue(redactor, "", raw)); err != nil {
+			return stats, fmt.Errorf("failed to write scrubbed entry: %w", err)
+		}
+		stats.Entries++
+	}
+	if err := bw.Flush(); err != nil {
+		return stats, fmt.Errorf("failed to write scrubbed entry: %w", err)
+	}
+	return stats, nil
+}
+
+// ScrubFile writes a scrubbed copy of the log file src to dst, readable by
+// its owner only, reading src with opts as OpenLogFile does, for example
+// to decrypt it or keep only an incident's time range. redactor defaults
+// to NewRedactor().
+func ScrubFile(dst, src string, redactor *Redactor, opts ...ReadOption) (ScrubStats, error) {
+	absDst, err := filepath.Abs(dst)
+	if err != nil {
+		return ScrubStats{}, fmt.Errorf("failed to resolve scrubbed copy path: %w", err)
+	}
+	if absSrc, err := filepath.Abs(src); err == nil && absSrc == absDst {
+		return ScrubStats{}, errors.New("scrubbed copy would overwrite the log file")
+	}
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+	r, err := OpenLogFile(src, opts...)
+	if err != nil {
+		return ScrubStats{}, err
+	}
+	defer r.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return ScrubStats{}, fmt.Errorf("failed to create scrubbed copy: %w", err)
+	}
+	stats, err := Scrub(context.Background(), out, r, redactor)
+	if cerr := out.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to write scrubbed copy: %w", cerr)
+	}
+	return stats, err
+}
+
+// scrubValue redacts v, found under key, returning a copy
+func scrubValue(r *Redactor, key string, v any) any {
+	if r.Redacts(key) {
+		return RedactedValue
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = scrubValue(r, k, item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = scrubValue(r, "", item)
+		}
+		return out
+	case string:
+		return urlPattern.ReplaceAllStringFunc(val, func(s string) string {
+			return scrubURL(r, s)
+		})
+	}
+	return v
+}
+
+// scrubURL masks the password and sensitive query parameters of the URL
+// s, returning s unchanged if there are none
+func scrubURL(r *Redactor, s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	masked := *u
+	_, hasPassword := u.User.Password()
+	if hasPassword {
+		masked.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	out := r.URL(&masked)
+	if !hasPassword && out == u.String() {
+		return s
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestScrubFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.log")
+	lines := []string{
+		`{"level":"info","time":"2024-03-01T12:00:00Z","msg":"GET https://api.example.com/v1?token=abc&page=2 done","user":"ada"}`,
+		`{"level":"debug","time":"2024-03-01T12:00:01Z","msg":"cache miss"}`,
+		`not json`,
+		`{"level":"warn","time":"2024-03-01T12:00:02Z","msg":"retry","request":{"headers":{"Authorization":"Bearer x"},"dsn":"postgres://app:hunter2@db/app"},"items":[{"password":"p"}]}`,
+	}
+	if err := os.WriteFile(src, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "shared.log")
+	stats, err := ScrubFile(dst, src, nil, WithMinLevel(zapcore.InfoLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (ScrubStats{Entries: 2, Skipped: 1}) {
+		t.Errorf("stats %+v, want 2 entries and 1 skipped", stats)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("scrubbed copy mode %v, want 0600", mode)
+	}
+
+	f, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if len(got) != 2 {
+		t.Fatalf("scrubbed copy has %d lines: %q", len(got), got)
+	}
+
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(got[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(got[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if want := "GET https://api.example.com/v1?page=2&token=" + RedactedValue + " done"; first["msg"] != want {
+		t.Errorf("message %q, want %q", first["msg"], want)
+	}
+	if first["user"] != "ada" {
+		t.Errorf("unrelated field changed: %v", first["user"])
+	}
+	request := second["request"].(map[string]any)
+	if request["headers"].(map[string]any)["Authorization"] != RedactedValue {
+		t.Errorf("nested header %v not masked", request["headers"])
+	}
+	if request["dsn"] != "postgres://app:xxxxx@db/app" {
+		t.Errorf("URL password not masked: %v", request["dsn"])
+	}
+	if item := second["items"].([]any)[0].(map[string]any); item["password"] != RedactedValue {
+		t.Errorf("key inside an array not masked: %v", item)
+	}
+}
+
+func TestScrubFileSamePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScrubFile(path, path, nil); err == nil {
+		t.Error("ScrubFile overwrote its source")
+	}
+}
+
+func TestScrubFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenLogFile(path, WithFollow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := Scrub(context.Background(), &strings.Builder{}, r, NewRedactor()); err == nil {
+		t.Error("Scrub accepted a followed reader")
+	}
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// selfTestMessage is the message of the entry SelfTest writes
+const selfTestMessage = "logger self-test"
+
+// SelfTestReport is the outcome of SelfTest
+type SelfTestReport struct {
+	// Status is "ok", or "failed" when any sink is
+	Status string `json:"status"`
+	// ID is the self_test_id field of the test entry, to find it in each
+	// destination
+	ID    string           `json:"id"`
+	Sinks []SelfTestResult `json:"sinks"`
+}
+
+// SelfTestResult is the outcome of writing the test entry to one sink
+type SelfTestResult struct {
+	// Kind is "console", "json", "file", "session", "network", or "added"
+	// for cores added with AddSink
+	Kind string `json:"kind"`
+	// Name is the file path or collector address, or the type of an added
+	// core
+	Name string `json:"name"`
+	// Status is "ok" or "failed"
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SelfTester is implemented by cores that check their destination
+// themselves for SelfTest. The cores of NewWebhookCore and NewSentryCore
+// send the test entry synchronously, reporting the DNS, TLS, or
+// authentication failures they otherwise only print to stderr.
+type SelfTester interface {
+	SelfTest(ctx context.Context, ent zapcore.Entry, fields []zapcore.Field) error
+}
+
+// SelfTest writes a test entry to every sink, regardless of its level and
+// bypassing sampling and the async queue, syncs it, and reports what
+// failed: an unwritable file, an unresolvable or unreachable collector, a
+// failed TLS handshake, or a rejected webhook. Run it when rolling out a
+// new configuration. Sinks are tested one at a time; one still running
+// when ctx is done is reported as failed and the rest are skipped. A test
+// entry for an unreachable collector stays buffered and is sent once it
+// comes back. A closed logger reports failed, with no sinks.
+func (l *Logger) SelfTest(ctx context.Context) SelfTestReport {
+	p := acquirePipeline(&l.state.pipe)
+	if p == nil {
+		return SelfTestReport{Status: "failed"}
+	}
+	defer p.res.finish()
+	report := SelfTestReport{Status: "ok", ID: p.config.idGenerator()()}
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: l.now(), Message: selfTestMessage}
+	fields := []zapcore.Field{zap.Bool("self_test", true), zap.String("self_test_id", report.ID)}
+
+	for i, name := range p.names {
+		core, m := p.sinks[i], p.monitor(name)
+		report.add(ctx, name, m.name, func(context.Context) error {
+			return selfTestSink(core, m, ent, fields)
+		})
+	}
+	for _, core := range p.extra {
+		report.add(ctx, SinkAdded, addedSinkName(core), func(ctx context.Context) error {
+			if t, ok := core.(SelfTester); ok {
+				return t.SelfTest(ctx, ent, fields)
+			}
+			if err := core.Write(ent, fields); err != nil {
+				return err
+			}
+			return syncError(core.Sync())
+		})
+	}
+	return report
+}
+
+// add runs test for one sink, bounded by ctx, and records its outcome
+func (r *SelfTestReport) add(ctx context.Context, kind, name string, test func(context.Context) error) {
+	result := SelfTestResult{Kind: kind, Name: name, Status: "ok"}
+	start := time.Now()
+	err := ctx.Err()
+	if err == nil {
+		done := make(chan error, 1)
+		go func() { done <- test(ctx) }()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		r.Status = "failed"
+	}
+	r.Sinks = append(r.Sinks, result)
+}
+
+// selfTestSink writes the test entry to a sink built from Config and syncs
+// it. A network sink's sync waits until the entry is sent or the collector
+// is found unreachable, whose cause is reported.
+func selfTestSink(core zapcore.Core, m *sinkMonitor, ent zapcore.Entry, fields []zapcore.Field) error {
+	err := core.Write(ent, fields)
+	if err == nil {
+		err = syncError(core.Sync())
+	}
+	if err != nil && m.network != nil {
+		if cause, _ := m.network.lastError(); cause != "" {
+			err = fmt.Errorf("%w: %s", err, cause)
+		}
+	}
+	return err
+}
+
+// monitor returns the monitor of the sink built from Config named name
+func (p *pipeline) monitor(name string) *sinkMonitor {
+	for _, m := range p.res.monitors {
+		if m.kind == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// addedSinkName describes a core added with AddSink
+func addedSinkName(core zapcore.Core) string {
+	if n, ok := core.(interface{ sinkName() string }); ok {
+		return n.sinkName()
+	}
+	return fmt.Sprintf("%T", core)
+}
+
+// SelfTestConfig creates a logger from config, runs SelfTest, and closes
+// it, to check a configuration before rolling it out. It fails only if the
+// logger can't be created or closed; sink failures are in the report.
+func SelfTestConfig(ctx context.Context, config Config) (SelfTestReport, error) {
+	l, err := NewLogger(config)
+	if err != nil {
+		return SelfTestReport{}, err
+	}
+	report := l.SelfTest(ctx)
+	if err := l.Close(ctx); err != nil {
+		return report, fmt.Errorf("failed to close logger: %w", err)
+	}
+	return report, nil
+}
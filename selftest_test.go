@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// selfTestingCore is a core that checks its destination itself
+type selfTestingCore struct {
+	zapcore.Core
+	err error
+}
+
+func (c selfTestingCore) SelfTest(context.Context, zapcore.Entry, []zapcore.Field) error {
+	return c.err
+}
+
+func TestSelfTest(t *testing.T) {
+	// An address with no collector behind it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{
+		Level: "error", Format: FormatJSON, EnableFile: true, FilePath: path,
+		Network: &NetworkConfig{Protocol: "tcp", Address: addr, DialTimeout: time.Second},
+	})
+	obs, logs := observer.New(zapcore.ErrorLevel)
+	l.AddSink(obs)
+	l.AddSink(selfTestingCore{Core: zapcore.NewNopCore(), err: errors.New("webhook rejected: 401")})
+
+	report := l.SelfTest(context.Background())
+	if report.Status != "failed" || report.ID == "" {
+		t.Errorf("report status %q ID %q", report.Status, report.ID)
+	}
+	want := []struct{ kind, status, err string }{
+		{SinkConsole, "ok", ""},
+		{SinkFile, "ok", ""},
+		{SinkNetwork, "failed", "refused"},
+		{SinkAdded, "ok", ""},
+		{SinkAdded, "failed", "webhook rejected: 401"},
+	}
+	if len(report.Sinks) != len(want) {
+		t.Fatalf("report sinks %+v", report.Sinks)
+	}
+	for i, w := range want {
+		got := report.Sinks[i]
+		if got.Kind != w.kind || got.Status != w.status || !strings.Contains(got.Error, w.err) {
+			t.Errorf("sink %d = %+v, want %s %s with %q", i, got, w.kind, w.status, w.err)
+		}
+	}
+	if report.Sinks[1].Name != path {
+		t.Errorf("file sink named %q, want its path", report.Sinks[1].Name)
+	}
+
+	// The test entry is written despite the error level
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].Message != selfTestMessage || entries[0].ContextMap()["self_test_id"] != report.ID {
+		t.Errorf("added sink received %+v", entries)
+	}
+	// Close reports the test entry left buffered for the collector
+	_ = l.Close(context.Background())
+	if line := string(readOnlyLine(t, path)); !strings.Contains(line, `"self_test_id":"`+report.ID+`"`) {
+		t.Errorf("file entry %s", line)
+	}
+}
+
+func TestSelfTestCanceled(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := l.SelfTest(ctx)
+	if report.Status != "failed" || len(report.Sinks) != 1 || report.Sinks[0].Error != context.Canceled.Error() {
+		t.Errorf("report %+v, want the console skipped", report)
+	}
+}
+
+func TestSelfTestClosed(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if report := l.SelfTest(context.Background()); report.Status != "failed" || report.Sinks != nil {
+		t.Errorf("closed logger reported %+v", report)
+	}
+}
+
+func TestSelfTestConfig(t *testing.T) {
+	discardStdout(t)
+	report, err := SelfTestConfig(context.Background(), Config{
+		EnableFile: true, FilePath: filepath.Join(t.TempDir(), "app.log"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != "ok" {
+		t.Errorf("report %+v", report)
+	}
+	if _, err := SelfTestConfig(context.Background(), Config{Level: "loud"}); err == nil {
+		t.Error("SelfTestConfig accepted an invalid level")
+	}
+}
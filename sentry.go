@@ -0,0 +1,232 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// SentryConfig configures a sink created with NewSentryCore
+type SentryConfig struct {
+	// DSN is the project's Sentry DSN
+	DSN string
+	// Level selects the entries sent. Defaults to error and above.
+	Level zapcore.LevelEnabler
+	// Environment and Release are attached to every event
+	Environment string
+	Release     string
+	// Timeout bounds each request. Defaults to 5 seconds.
+	Timeout time.Duration
+	// Client sends the events, overriding Timeout
+	Client *http.Client
+}
+
+// sentryCore sends entries to Sentry as events
+type sentryCore struct {
+	zapcore.LevelEnabler
+	config   SentryConfig
+	endpoint string
+	auth     string
+	sender   *httpSender
+	ctx      mapEncoder
+}
+
+// NewSentryCore returns a core that sends entries to Sentry as events,
+// with the entry's fields as extra data and its stack trace, if any, as the
+// exception's stack trace. Add it with Logger.AddSink. Events are sent in
+// the background; Sync, which Fatal calls before exiting, waits for them.
+func NewSentryCore(config SentryConfig) (zapcore.Core, error) {
+	dsn, err := url.Parse(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	key := dsn.User.Username()
+	dir, project := path.Split(strings.TrimSuffix(dsn.Path, "/"))
+	if key == "" || project == "" || dsn.Host == "" {
+		return nil, errors.New("invalid Sentry DSN: want scheme://key@host/project")
+	}
+	if config.Level == nil {
+		config.Level = zapcore.ErrorLevel
+	}
+	return &sentryCore{
+		LevelEnabler: config.Level,
+		config:       config,
+		endpoint:     fmt.Sprintf("%s://%s%sapi/%s/envelope/", dsn.Scheme, dsn.Host, dir, project),
+		auth:         "Sentry sentry_version=7, sentry_client=go-logger, sentry_key=" + key,
+		sender:       newHTTPSender("sentry", config.Client, config.Timeout, nil),
+		ctx:          newMapEncoder(),
+	}, nil
+}
+
+// With returns a child core whose events carry fields
+func (c *sentryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.ctx = c.ctx.withFields(fields)
+	return &clone
+}
+
+// Check adds this core to the checked entry if the level is selected
+func (c *sentryCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write queues the entry as an event. Panic and fatal entries are sent
+// before Write returns, since the process may end right after.
+func (c *sentryCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	p, err := c.envelope(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.sender.send(p)
+	if ent.Level >= zapcore.PanicLevel {
+		c.sender.sync()
+	}
+	return nil
+}
+
+// envelope builds the request sending an entry as an event
+func (c *sentryCore) envelope(ent zapcore.Entry, fields []zapcore.Field) (httpPayload, error) {
+	enc := c.ctx.withFields(fields)
+	event := c.event(ent, enc.Fields)
+	body, err := json.Marshal(event)
+	if err != nil {
+		return httpPayload{}, fmt.Errorf("failed to encode Sentry event: %w", err)
+	}
+	header, _ := json.Marshal(map[string]string{"event_id": event["event_id"].(string)})
+	envelope := make([]byte, 0, len(header)+len(body)+20)
+	envelope = append(envelope, header...)
+	envelope = append(envelope, "\n{\"type\":\"event\"}\n"...)
+	envelope = append(envelope, body...)
+	envelope = append(envelope, '\n')
+	return httpPayload{
+		url:         c.endpoint,
+		contentType: "application/x-sentry-envelope",
+		header:      http.Header{"X-Sentry-Auth": {c.auth}},
+		body:        envelope,
+	}, nil
+}
+
+// SelfTest sends the entry as an event and waits for the response
+func (c *sentryCore) SelfTest(ctx context.Context, ent zapcore.Entry, fields []zapcore.Field) error {
+	p, err := c.envelope(ent, fields)
+	if err != nil {
+		return err
+	}
+	return c.sender.post(ctx, p)
+}
+
+// sinkName describes the Sentry project for SelfTest
+func (c *sentryCore) sinkName() string {
+	if u, err := url.Parse(c.endpoint); err == nil {
+		return "sentry " + u.Host
+	}
+	return "sentry"
+}
+
+// remoteSender returns the sender posting the core's events
+func (c *sentryCore) remoteSender() *httpSender {
+	return c.sender
+}
+
+// Sync waits for queued events to be sent
+func (c *sentryCore) Sync() error {
+	c.sender.sync()
+	return nil
+}
+
+// event builds the Sentry event for an entry
+func (c *sentryCore) event(ent zapcore.Entry, fields map[string]any) map[string]any {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	event := map[string]any{
+		"event_id":  hex.EncodeToString(id[:]),
+		"timestamp": ent.Time.UTC().Format(time.RFC3339Nano),
+		"level":     sentryLevel(ent.Level),
+		"platform":  "go",
+		"message":   map[string]string{"formatted": ent.Message},
+	}
+	if ent.LoggerName != "" {
+		event["logger"] = ent.LoggerName
+	}
+	if c.config.Environment != "" {
+		event["environment"] = c.config.Environment
+	}
+	if c.config.Release != "" {
+		event["release"] = c.config.Release
+	}
+	if len(fields) > 0 {
+		event["extra"] = fields
+	}
+	if ent.Caller.Defined {
+		event["culprit"] = ent.Caller.TrimmedPath()
+	}
+	if ent.Stack != "" {
+		value := ent.Message
+		if s, ok := fields["error"].(string); ok {
+			value = s
+		}
+		event["exception"] = map[string]any{
+			"values": []map[string]any{{
+				"type":       ent.Message,
+				"value":      value,
+				"stacktrace": map[string]any{"frames": sentryFrames(ent.Stack)},
+			}},
+		}
+	}
+	return event
+}
+
+// sentryLevel maps a level to Sentry's names
+func sentryLevel(level zapcore.Level) string {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return "debug"
+	case level == zapcore.InfoLevel:
+		return "info"
+	case level == zapcore.WarnLevel:
+		return "warning"
+	case level <= zapcore.DPanicLevel:
+		return "error"
+	}
+	return "fatal"
+}
+
+// sentryFrames parses a stack trace in zap's format, a function line
+// followed by a tab-indented file:line, into Sentry frames, oldest first
+func sentryFrames(stack string) []map[string]any {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	var frames []map[string]any
+	for i := 0; i+1 < len(lines); i += 2 {
+		fn := strings.TrimSpace(lines[i])
+		loc := strings.TrimSpace(lines[i+1])
+		frame := map[string]any{"function": fn, "in_app": !strings.HasPrefix(fn, "runtime.")}
+		file, line := loc, ""
+		if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+			file, line = loc[:i], loc[i+1:]
+		}
+		frame["abs_path"] = file
+		frame["filename"] = path.Base(file)
+		if n, err := strconv.Atoi(line); err == nil {
+			frame["lineno"] = n
+		}
+		frames = append(frames, frame)
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSessionMaxFiles is how many session files are kept by default
+	defaultSessionMaxFiles = 10
+	// sessionFilePrefix starts the name of each session file
+	sessionFilePrefix = "session-"
+)
+
+// SessionConfig records each run of a CLI to its own JSON file at Level or
+// above, whatever the console's level, so that users asked to attach the
+// log file to a support request have one. Failing to create the file only
+// logs a warning, so recording never stops the tool. Session files may
+// contain sensitive data and are created readable by their owner only.
+type SessionConfig struct {
+	// App names the directory: $XDG_STATE_HOME/<App>/logs, or
+	// ~/.local/state/<App>/logs without XDG_STATE_HOME. Required unless
+	// Dir is set.
+	App string
+	// Dir receives the session files instead
+	Dir string
+	// Level is the minimum level recorded. Defaults to "debug".
+	Level string
+	// MaxFiles is how many session files are kept in the directory, the
+	// oldest removed first. Defaults to 10; negative keeps every file.
+	MaxFiles int
+	// Retention is how long session files holding entries of each retention
+	// class are kept after their last write, for example
+	// {"pii": 7 * 24 * time.Hour}. Expired files are removed when a run
+	// starts, whatever MaxFiles allows.
+	Retention map[string]time.Duration
+}
+
+// withDefaults fills in unset fields and validates the rest
+func (c SessionConfig) withDefaults() (SessionConfig, error) {
+	if c.Dir == "" {
+		if c.App == "" || strings.ContainsAny(c.App, `/\`) || c.App == "." || c.App == ".." {
+			return c, fmt.Errorf("session: invalid app name %q", c.App)
+		}
+		base := os.Getenv("XDG_STATE_HOME")
+		if base == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return c, fmt.Errorf("session: %w", err)
+			}
+			base = filepath.Join(home, ".local", "state")
+		}
+		c.Dir = filepath.Join(base, c.App, "logs")
+	}
+	if c.Level == "" {
+		c.Level = "debug"
+	}
+	if _, err := parseLevel(c.Level); err != nil {
+		return c, fmt.Errorf("session: invalid level: %w", err)
+	}
+	if c.MaxFiles == 0 {
+		c.MaxFiles = defaultSessionMaxFiles
+	}
+	for class, ttl := range c.Retention {
+		if ttl <= 0 {
+			return c, fmt.Errorf("session: retention for class %q must be positive, got %s", class, ttl)
+		}
+	}
+	return c, nil
+}
+
+// SessionFile returns the path of the session file recording this run, or
+// "" without Config.Session or when it couldn't be created, for telling
+// users what to attach:
+//
+//	if path := log.SessionFile(); path != "" {
+//		fmt.Fprintf(os.Stderr, "A full log of this run is in %s\n", path)
+//	}
+func (l *Logger) SessionFile() string {
+	return l.state.pipe.Load().res.session
+}
+
+// sessionFile returns the session file in config's directory for this
+// process, creating it and removing the oldest beyond MaxFiles on first
+// use, so that reloads keep writing to the same file
+func (s *fileSet) sessionFile(config SessionConfig) (string, error) {
+	if s != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if path, ok := s.sessions[config.Dir]; ok {
+			return path, nil
+		}
+	}
+
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+	pruneSessionFiles(config, time.Now())
+	name := sessionFilePrefix + time.Now().UTC().Format("20060102T150405.000000000Z") +
+		"-" + strconv.Itoa(os.Getpid()) + ".log"
+	path := filepath.Join(config.Dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to create session file: %w", err)
+	}
+
+	if s != nil {
+		s.sessions[config.Dir] = path
+	}
+	return path, nil
+}
+
+// pruneSessionFiles makes room for a new session file in config's
+// directory, removing the files whose retention class has expired by now
+// and then the oldest beyond MaxFiles
+func pruneSessionFiles(config SessionConfig, now time.Time) {
+	matches, err := filepath.Glob(filepath.Join(config.Dir, sessionFilePrefix+"*.log"))
+	if err != nil {
+		return
+	}
+	if len(config.Retention) > 0 {
+		matches = slices.DeleteFunc(matches, func(path string) bool {
+			if retentionExpired(path, config.Retention, now) {
+				_ = os.Remove(path)
+				return true
+			}
+			return false
+		})
+	}
+	keep := config.MaxFiles - 1
+	if config.MaxFiles < 0 || len(matches) <= keep {
+		return
+	}
+	// Names start with the UTC time, so they sort oldest first
+	slices.Sort(matches)
+	for _, path := range matches[:len(matches)-keep] {
+		_ = os.Remove(path)
+	}
+}
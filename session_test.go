@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	l := newBenchLogger(t, Config{Level: "warn", Session: &SessionConfig{Dir: dir}})
+	path := l.SessionFile()
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), sessionFilePrefix) {
+		t.Fatalf("session file %q, want one in %s", path, dir)
+	}
+	l.Debug("resolving config")
+	l.Trace("too verbose")
+	l.Success("done")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("session file mode %v, want 0600", mode)
+	}
+	msgs, err := readLogMessages(t, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Recorded at debug whatever the console's level, without colors
+	var got []string
+	for _, msg := range msgs {
+		if !strings.HasPrefix(msg, "colors disabled") {
+			got = append(got, msg)
+		}
+	}
+	if want := []string{"resolving config", "✓ done"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("session recorded %q, want %q", got, want)
+	}
+}
+
+func TestSessionMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for range 3 {
+		l := newBenchLogger(t, Config{Session: &SessionConfig{Dir: dir, MaxFiles: 2}})
+		paths = append(paths, l.SessionFile())
+		if err := l.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, sessionFilePrefix+"*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("kept %d session files, want 2", len(matches))
+	}
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Errorf("oldest session file was kept: %v", err)
+	}
+}
+
+func TestSessionConfigDefaults(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	tests := []struct {
+		name    string
+		config  SessionConfig
+		wantDir string
+		wantErr bool
+	}{
+		{name: "app", config: SessionConfig{App: "mytool"}, wantDir: filepath.Join(state, "mytool", "logs")},
+		{name: "dir", config: SessionConfig{App: "ignored", Dir: "/var/tmp/logs"}, wantDir: "/var/tmp/logs"},
+		{name: "no app", config: SessionConfig{}, wantErr: true},
+		{name: "app with a path", config: SessionConfig{App: "../etc"}, wantErr: true},
+		{name: "dot app", config: SessionConfig{App: ".."}, wantErr: true},
+		{name: "invalid level", config: SessionConfig{App: "mytool", Level: "loud"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.config.withDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (c.Dir != tt.wantDir || c.Level != "debug" || c.MaxFiles != defaultSessionMaxFiles) {
+				t.Errorf("withDefaults() = %+v", c)
+			}
+		})
+	}
+}
+
+func TestSessionUnwritable(t *testing.T) {
+	withoutColor(t)
+	stdout, _ := redirectStd(t)
+	// A file where the directory should be
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.WriteFile(dir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLogger(Config{Level: "info", Session: &SessionConfig{Dir: dir}})
+	if err != nil {
+		t.Fatalf("NewLogger failed instead of disabling recording: %v", err)
+	}
+	if path := l.SessionFile(); path != "" {
+		t.Errorf("SessionFile() = %q without a session file", path)
+	}
+	l.Info("still logging")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	console, err := os.ReadFile(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(console), "session recording disabled") || !strings.Contains(string(console), "still logging") {
+		t.Errorf("console %q, want the warning and the entry", console)
+	}
+}
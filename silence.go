@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Silence suppresses entries at the given levels from this logger until the
+// returned function is called, for example around a library call known to
+// spam warnings:
+//
+//	restore := log.Silence(zapcore.WarnLevel, zapcore.InfoLevel)
+//	defer restore()
+//
+// The logger and every logger derived from it, before or after the call,
+// are affected on every goroutine; its parent keeps logging. Silence calls
+// nest, and a level stays silenced until every call covering it is
+// restored. Panic and Fatal entries are dropped but still panic and exit.
+// A logger whose core was replaced with zap.WrapCore can't be silenced;
+// Silence reports that as a diagnostic and does nothing.
+func (l *Logger) Silence(levels ...zapcore.Level) (restore func()) {
+	root := rootSwapCore(l.Logger.Core())
+	if root == nil {
+		if diag := l.state.pipe.Load().res.diag.Load(); diag != nil {
+			diag.Warn("Silence ignored: the logger's core is wrapped")
+		}
+		return func() {}
+	}
+	var held []zapcore.Level
+	for _, level := range levels {
+		if level >= TraceLevel && level <= zapcore.FatalLevel {
+			root.silenced[level-TraceLevel].Add(1)
+			root.silences.Add(1)
+			held = append(held, level)
+		}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, level := range held {
+				root.silenced[level-TraceLevel].Add(-1)
+				root.silences.Add(-1)
+			}
+		})
+	}
+}
+
+// isSilenced reports whether Silence is suppressing level on this core or
+// one it was derived from
+func (c *swapCore) isSilenced(level zapcore.Level) bool {
+	if c.silences == nil || c.silences.Load() == 0 ||
+		level < TraceLevel || level > zapcore.FatalLevel {
+		return false
+	}
+	for s := c; s != nil; s = s.parent {
+		if s.silenced[level-TraceLevel].Load() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// rootSwapCore finds the swapCore at the root of a logger's core
+func rootSwapCore(core zapcore.Core) *swapCore {
+	switch c := core.(type) {
+	case *swapCore:
+		return c
+	case *rateLimitCore:
+		return rootSwapCore(c.Core)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSilence(t *testing.T) {
+	tests := []struct {
+		name string
+		// target returns the logger to silence, given the root and a child
+		// derived from it before the call
+		target func(root, child *Logger) *Logger
+		want   []string
+	}{
+		{
+			name:   "root",
+			target: func(root, child *Logger) *Logger { return root },
+			want:   []string{"root warn", "child warn", "target warn", "later warn"},
+		},
+		{
+			name:   "child",
+			target: func(root, child *Logger) *Logger { return child },
+			want:   []string{"root info", "root warn", "child warn", "target warn", "later warn"},
+		},
+		{
+			name:   "rate limited",
+			target: func(root, child *Logger) *Logger { return root.RateLimited("silence", 100, 100) },
+			want:   []string{"root info", "root warn", "child info", "child warn", "target warn", "later warn"},
+		},
+		{
+			name:   "group",
+			target: func(root, child *Logger) *Logger { return child.Group("import").Logger },
+			want:   []string{"root info", "root warn", "child info", "child warn", "target warn", "later warn"},
+		},
+		{
+			name: "wrapped core",
+			target: func(root, child *Logger) *Logger {
+				return root.derive(root.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+					return zapcore.RegisterHooks(core)
+				})))
+			},
+			want: []string{
+				"Silence ignored: the logger's core is wrapped",
+				"root info", "root warn", "child info", "child warn",
+				"target info", "target warn", "later info", "later warn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+			child := l.WithField("user", "u1")
+			target := tt.target(l, child)
+			logs.TakeAll()
+
+			restore := target.Silence(zapcore.InfoLevel)
+			later := target.WithField("step", "load")
+			loggers := []struct {
+				name string
+				*Logger
+			}{{"root", l}, {"child", child}, {"target", target}, {"later", later}}
+			for _, lg := range loggers {
+				lg.Info(lg.name + " info")
+				lg.Warn(lg.name + " warn")
+			}
+			restore()
+			restore()
+			later.Info("restored")
+
+			var got []string
+			for _, e := range logs.AllUntimed() {
+				got = append(got, e.Message)
+			}
+			want := append(slices.Clone(tt.want), "restored")
+			if !slices.Equal(got, want) {
+				t.Errorf("logged %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// clockSkewKey is the field key of the measured skew
+	clockSkewKey = "clock_skew_ms"
+	// defaultSkewInterval is how often the skew is measured by default
+	defaultSkewInterval = 5 * time.Minute
+	// skewTimeout bounds each measurement
+	skewTimeout = 5 * time.Second
+	// ntpEpochOffset is the seconds between the NTP epoch, 1900, and 1970
+	ntpEpochOffset = 2208988800
+)
+
+// SkewSource measures how far the local clock is from a reference clock:
+// positive when the local clock is behind
+type SkewSource func(ctx context.Context) (time.Duration, error)
+
+// ClockSkewConfig adds the local clock's offset from a reference as a
+// clock_skew_ms field to every entry, for correlating entries across
+// machines whose clocks drift
+type ClockSkewConfig struct {
+	// Source measures the skew, such as NTPSkew("pool.ntp.org") or a
+	// value from the host's time daemon. Required.
+	Source SkewSource
+	// Interval is how often the skew is measured. Defaults to 5 minutes.
+	// Entries carry the latest successful measurement, and no field before
+	// the first.
+	Interval time.Duration
+}
+
+// NTPSkew returns a source querying the offset from an NTP server with a
+// single SNTP request. server is a host, with an optional port defaulting
+// to 123.
+func NTPSkew(server string) SkewSource {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	return func(ctx context.Context) (time.Duration, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "udp", server)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reach NTP server: %w", err)
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+
+		// LI 0, version 4, mode 3 (client); the transmit timestamp echoes
+		// back as the originate timestamp
+		req := make([]byte, 48)
+		req[0] = 0x23
+		sent := time.Now()
+		binary.BigEndian.PutUint64(req[40:], ntpTimestamp(sent))
+		if _, err := conn.Write(req); err != nil {
+			return 0, fmt.Errorf("failed to query NTP server: %w", err)
+		}
+		resp := make([]byte, 48)
+		n, err := conn.Read(resp)
+		received := time.Now()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read NTP response: %w", err)
+		}
+		if n < 48 || resp[0]&0x07 != 4 || resp[1] == 0 {
+			return 0, errors.New("invalid NTP response")
+		}
+		if binary.BigEndian.Uint64(resp[24:]) != ntpTimestamp(sent) {
+			return 0, errors.New("NTP response doesn't match the request")
+		}
+		serverReceived := ntpTime(binary.BigEndian.Uint64(resp[32:]))
+		serverSent := ntpTime(binary.BigEndian.Uint64(resp[40:]))
+		return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+	}
+}
+
+// ntpTimestamp encodes t as 32.32 fixed-point seconds since 1900
+func ntpTimestamp(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+// ntpTime decodes an NTP timestamp
+func ntpTime(ts uint64) time.Time {
+	secs := int64(ts>>32) - ntpEpochOffset
+	nanos := int64((ts & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(secs, nanos)
+}
+
+// skewMonitor measures the skew in the background for a pipeline
+type skewMonitor struct {
+	config ClockSkewConfig
+	// skew holds the latest measurement in nanoseconds; valid once
+	// measured is set
+	skew     atomic.Int64
+	measured atomic.Bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newSkewMonitor starts measuring the skew for config
+func newSkewMonitor(config ClockSkewConfig) *skewMonitor {
+	if config.Interval <= 0 {
+		config.Interval = defaultSkewInterval
+	}
+	m := &skewMonitor{config: config, stop: make(chan struct{}), done: make(chan struct{})}
+	go m.run()
+	return m
+}
+
+// run measures immediately and then every interval until closed
+func (m *skewMonitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		m.measure()
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// measure takes one measurement, keeping the previous one on failure
+func (m *skewMonitor) measure() {
+	ctx, cancel := context.WithTimeout(context.Background(), skewTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	skew, err := m.config.Source(ctx)
+	if err != nil {
+		return
+	}
+	m.skew.Store(int64(skew))
+	m.measured.Store(true)
+}
+
+// close stops the measurements
+func (m *skewMonitor) close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+}
+
+// current returns the latest skew, if measured
+func (m *skewMonitor) current() (time.Duration, bool) {
+	return time.Duration(m.skew.Load()), m.measured.Load()
+}
+
+// skewCore adds the latest skew to each entry
+type skewCore struct {
+	zapcore.Core
+	monitor *skewMonitor
+}
+
+// With returns a child core
+func (c *skewCore) With(fields []zapcore.Field) zapcore.Core {
+	return &skewCore{Core: c.Core.With(fields), monitor: c.monitor}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *skewCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write adds clock_skew_ms once the skew has been measured
+func (c *skewCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	skew, ok := c.monitor.current()
+	if !ok {
+		return c.Core.Write(ent, fields)
+	}
+	with := make([]zapcore.Field, 0, len(fields)+1)
+	with = append(with, fields...)
+	with = append(with, zap.Int64(clockSkewKey, skew.Milliseconds()))
+	return c.Core.Write(ent, with)
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeNTPServer answers SNTP requests with a clock offset from the local one
+func fakeNTPServer(t *testing.T, offset time.Duration) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		req := make([]byte, 48)
+		for {
+			n, addr, err := conn.ReadFrom(req)
+			if err != nil {
+				return
+			}
+			if n < 48 {
+				continue
+			}
+			resp := make([]byte, 48)
+			resp[0] = 0x24 // version 4, mode 4 (server)
+			resp[1] = 2    // stratum
+			copy(resp[24:32], req[40:48])
+			now := ntpTimestamp(time.Now().Add(offset))
+			binary.BigEndian.PutUint64(resp[32:], now)
+			binary.BigEndian.PutUint64(resp[40:], now)
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestNTPSkew(t *testing.T) {
+	server := fakeNTPServer(t, 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	skew, err := NTPSkew(server)(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := skew - 3*time.Second; diff < -100*time.Millisecond || diff > 100*time.Millisecond {
+		t.Errorf("skew %v, want about 3s", skew)
+	}
+}
+
+func TestNTPTimestamp(t *testing.T) {
+	want := time.Date(2024, 2, 29, 12, 30, 45, 123456789, time.UTC)
+	got := ntpTime(ntpTimestamp(want))
+	// 32-bit fractions resolve to under a nanosecond, lost to truncation
+	if d := want.Sub(got); d < 0 || d > time.Nanosecond {
+		t.Errorf("round trip of %v gave %v", want, got)
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	measured := make(chan struct{})
+	l := newBenchLogger(t, Config{
+		Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path,
+		ClockSkew: &ClockSkewConfig{Source: func(context.Context) (time.Duration, error) {
+			defer close(measured)
+			return -1500 * time.Millisecond, nil
+		}},
+	})
+	<-measured
+	monitor := l.state.pipe.Load().res.skew
+	for _, ok := monitor.current(); !ok; _, ok = monitor.current() {
+		time.Sleep(time.Millisecond)
+	}
+
+	l.Info("synced")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(readOnlyLine(t, path), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry[clockSkewKey] != float64(-1500) {
+		t.Errorf("%s = %v, want -1500", clockSkewKey, entry[clockSkewKey])
+	}
+}
+
+func TestClockSkewUnmeasured(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{
+		Level: "info", Format: FormatJSON, EnableFile: true, FilePath: path,
+		ClockSkew: &ClockSkewConfig{Source: func(context.Context) (time.Duration, error) {
+			return 0, errors.New("unreachable")
+		}},
+	})
+	l.Info("starting")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(readOnlyLine(t, path), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if skew, ok := entry[clockSkewKey]; ok {
+		t.Errorf("entry has %s = %v before any measurement", clockSkewKey, skew)
+	}
+}
+
+func TestClockSkewRequiresSource(t *testing.T) {
+	if _, err := NewLogger(Config{ClockSkew: &ClockSkewConfig{}}); err == nil {
+		t.Error("NewLogger accepted a clock skew config without a source")
+	}
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sloBuckets is the number of buckets an SLO window is divided into
+const sloBuckets = 60
+
+// SLOConfig defines a service level objective derived from log entries
+type SLOConfig struct {
+	// Name identifies the objective in Stats and metrics
+	Name string
+	// Objective is the target ratio of good entries, e.g. 0.999
+	Objective float64
+	// Window is the rolling window the burn rate is computed over.
+	// Defaults to one hour.
+	Window time.Duration
+	// IsBad reports whether an entry counts against the objective. fields
+	// include those added with WithField and friends. Defaults to entries
+	// at error level or above.
+	IsBad func(ent zapcore.Entry, fields []zapcore.Field) bool
+	// Matches limits the objective to the entries it reports true for.
+	// Defaults to every entry.
+	Matches func(ent zapcore.Entry, fields []zapcore.Field) bool
+}
+
+// SLOStatus is a point-in-time view of an objective
+type SLOStatus struct {
+	Name      string
+	Objective float64
+	Window    time.Duration
+	Total     uint64
+	Bad       uint64
+	// ErrorRate is Bad / Total over the window
+	ErrorRate float64
+	// BurnRate is ErrorRate divided by the error budget (1 - Objective).
+	// A burn rate of 1 consumes the budget exactly over the window.
+	BurnRate float64
+}
+
+// sloBucket counts entries observed during one slice of the window
+type sloBucket struct {
+	start int64
+	total uint64
+	bad   uint64
+}
+
+// sloTracker counts good and bad entries over a rolling window
+type sloTracker struct {
+	config SLOConfig
+	width  int64
+
+	mu      sync.Mutex
+	buckets [sloBuckets]sloBucket
+}
+
+// newSLOTracker creates a tracker, filling in defaults
+func newSLOTracker(config SLOConfig) *sloTracker {
+	if config.Window <= 0 {
+		config.Window = time.Hour
+	}
+	if config.IsBad == nil {
+		config.IsBad = func(ent zapcore.Entry, _ []zapcore.Field) bool {
+			return ent.Level >= zapcore.ErrorLevel
+		}
+	}
+	width := int64(config.Window) / sloBuckets
+	if width <= 0 {
+		width = 1
+	}
+	return &sloTracker{config: config, width: width}
+}
+
+// observe records one entry
+func (t *sloTracker) observe(ent zapcore.Entry, fields []zapcore.Field) {
+	if t.config.Matches != nil && !t.config.Matches(ent, fields) {
+		return
+	}
+	bad := t.config.IsBad(ent, fields)
+
+	start := ent.Time.UnixNano() / t.width * t.width
+	t.mu.Lock()
+	b := &t.buckets[(start/t.width)%sloBuckets]
+	if b.start != start {
+		*b = sloBucket{start: start}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+	t.mu.Unlock()
+}
+
+// status summarizes the buckets that fall inside the window ending now
+func (t *sloTracker) status(now time.Time) SLOStatus {
+	s := SLOStatus{
+		Name:      t.config.Name,
+		Objective: t.config.Objective,
+		Window:    t.config.Window,
+	}
+	cutoff := now.UnixNano() - int64(t.config.Window)
+
+	t.mu.Lock()
+	for _, b := range t.buckets {
+		if b.start > cutoff {
+			s.Total += b.total
+			s.Bad += b.bad
+		}
+	}
+	t.mu.Unlock()
+
+	if s.Total > 0 {
+		s.ErrorRate = float64(s.Bad) / float64(s.Total)
+		if budget := 1 - s.Objective; budget > 0 {
+			s.BurnRate = s.ErrorRate / budget
+		}
+	}
+	return s
+}
+
+// sloCore feeds entries to SLO trackers. It sits alongside the sinks so it
+// sees exactly the entries that are written.
+type sloCore struct {
+	zapcore.LevelEnabler
+	trackers []*sloTracker
+	context  []zapcore.Field
+}
+
+// With returns a child core that remembers fields for the predicates
+func (c *sloCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+	return &sloCore{LevelEnabler: c.LevelEnabler, trackers: c.trackers, context: context}
+}
+
+// Check adds this core to the checked entry if the level is enabled
+func (c *sloCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write records the entry with every tracker
+func (c *sloCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.context) > 0 {
+		all = make([]zapcore.Field, 0, len(c.context)+len(fields))
+		all = append(all, c.context...)
+		all = append(all, fields...)
+	}
+	for _, t := range c.trackers {
+		t.observe(ent, all)
+	}
+	return nil
+}
+
+// Sync is a no-op
+func (c *sloCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLOStatsUseClock(t *testing.T) {
+	at := time.Date(2020, 6, 1, 9, 0, 0, 0, time.UTC)
+	l := newBenchLogger(t, Config{
+		Level: "info",
+		Clock: fixedClock(at),
+		SLOs:  []SLOConfig{{Name: "errors", Objective: 0.9, Window: time.Minute}},
+	})
+	l.Info("request handled")
+	l.Error("request failed")
+
+	slos := l.Stats().SLOs
+	if len(slos) != 1 {
+		t.Fatalf("Stats has %d SLOs, want 1", len(slos))
+	}
+	if got := slos[0]; got.Total != 2 || got.Bad != 1 {
+		t.Errorf("SLO counted %d entries, %d bad; want 2 and 1", got.Total, got.Bad)
+	}
+}
@@ -0,0 +1,661 @@
+package logger
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DriverOption configures a driver wrapped with WrapDriver
+type DriverOption func(*driverLogger)
+
+// WithSlowQueryThreshold logs queries that take at least d at warn level
+func WithSlowQueryThreshold(d time.Duration) DriverOption {
+	return func(dl *driverLogger) {
+		dl.slow = d
+	}
+}
+
+// WithQueryLevel sets the level for successful queries. Defaults to debug.
+func WithQueryLevel(level zapcore.Level) DriverOption {
+	return func(dl *driverLogger) {
+		dl.level = level
+	}
+}
+
+// WithQueryArgs logs query arguments, masking those whose names r redacts.
+// Positional arguments (? or $1) are named after the column they are
+// compared with, as in "password = ?", or inserted into, as in
+// "INSERT INTO users (email, password) VALUES ($1, $2)". Include "" among
+// r's keys to mask every positional argument.
+func WithQueryArgs(r *Redactor) DriverOption {
+	return func(dl *driverLogger) {
+		dl.logArgs = true
+		dl.redactor = r
+	}
+}
+
+// driverLogger holds the logging settings shared by a wrapped driver's
+// connections, statements, and rows
+type driverLogger struct {
+	logger   *zap.Logger
+	level    zapcore.Level
+	slow     time.Duration
+	logArgs  bool
+	redactor *Redactor
+}
+
+// log writes one entry for a completed operation
+func (dl *driverLogger) log(query string, args []driver.NamedValue, start time.Time, err error, extra ...zap.Field) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	duration := time.Since(start)
+
+	level := dl.level
+	msg := "SQL query completed"
+	switch {
+	case err != nil:
+		level = zapcore.ErrorLevel
+		msg = "SQL query failed"
+	case dl.slow > 0 && duration >= dl.slow:
+		level = zapcore.WarnLevel
+		msg = "slow SQL query"
+	}
+
+	ce := dl.logger.Check(level, msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(extra)+4)
+	fields = append(fields, zap.String("query", query))
+	fields = append(fields, extra...)
+	fields = append(fields, zap.Duration("duration", duration))
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	if dl.logArgs && len(args) > 0 {
+		fields = append(fields, zap.Array("args", dl.argsMarshaler(query, args)))
+	}
+	ce.Write(fields...)
+}
+
+// argsMarshaler renders the arguments of query with redaction applied
+func (dl *driverLogger) argsMarshaler(query string, args []driver.NamedValue) zapcore.ArrayMarshaler {
+	var columns map[int]string
+	if dl.redactor != nil && slices.ContainsFunc(args, func(arg driver.NamedValue) bool { return arg.Name == "" }) {
+		columns = placeholderColumns(query)
+	}
+	return zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, arg := range args {
+			if dl.redactor.Redacts(arg.Name) || arg.Name == "" && dl.redactor.Redacts(columns[arg.Ordinal]) {
+				enc.AppendString(RedactedValue)
+				continue
+			}
+			if err := enc.AppendReflected(arg.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sqlToken is a token of a query scanned by scanSQL
+type sqlToken struct {
+	text  string
+	ident bool
+	// ordinal numbers positional placeholders from 1; 0 for other tokens
+	ordinal int
+}
+
+// placeholderColumns returns the columns that query's positional
+// placeholders are compared with or inserted into, by ordinal
+func placeholderColumns(query string) map[int]string {
+	tokens := scanSQL(query)
+	columns := make(map[int]string)
+	for i, tok := range tokens {
+		if tok.ordinal > 0 && i >= 2 && isSQLComparison(tokens[i-1]) && tokens[i-2].ident {
+			columns[tok.ordinal] = sqlColumnName(tokens[i-2].text)
+		}
+	}
+	for i, tok := range tokens {
+		if tok.ident && strings.EqualFold(tok.text, "values") {
+			insertedColumns(tokens[:i], tokens[i+1:], columns)
+		}
+	}
+	return columns
+}
+
+// insertedColumns maps the placeholders in the rows of values to the
+// column list that ends before
+func insertedColumns(before, values []sqlToken, columns map[int]string) {
+	if len(before) == 0 || before[len(before)-1].text != ")" {
+		return
+	}
+	var names []string
+	for i := len(before) - 2; ; i-- {
+		if i < 0 {
+			return
+		}
+		if before[i].text == "(" {
+			break
+		}
+		if before[i].ident {
+			names = append(names, sqlColumnName(before[i].text))
+		}
+	}
+	slices.Reverse(names)
+
+	for len(values) > 0 && values[0].text == "(" {
+		depth, pos := 0, 0
+		i := 0
+		for ; i < len(values); i++ {
+			switch tok := values[i]; {
+			case tok.text == "(":
+				depth++
+			case tok.text == ")":
+				depth--
+			case tok.text == "," && depth == 1:
+				pos++
+			case tok.ordinal > 0 && depth == 1 && pos < len(names):
+				columns[tok.ordinal] = names[pos]
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if i+1 >= len(values) || values[i+1].text != "," {
+			return
+		}
+		values = values[i+2:]
+	}
+}
+
+// isSQLComparison reports whether tok compares the operands around it
+func isSQLComparison(tok sqlToken) bool {
+	switch strings.ToLower(tok.text) {
+	case "=", "==", "<>", "!=", "<", ">", "<=", ">=", "like", "ilike":
+		return true
+	}
+	return false
+}
+
+// sqlColumnName strips the table from a qualified column name
+func sqlColumnName(ident string) string {
+	return ident[strings.LastIndexByte(ident, '.')+1:]
+}
+
+// scanSQL splits query into identifiers, placeholders, operators, and
+// punctuation, skipping literals and comments
+func scanSQL(query string) []sqlToken {
+	var tokens []sqlToken
+	positional := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			// '' escapes a quote inside a string
+			j := i + 1
+			for j < len(query) && (query[j] != '\'' || j+1 < len(query) && query[j+1] == '\'') {
+				if query[j] == '\'' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: "''"})
+			i = j + 1
+		case c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, sqlToken{text: query[i+1 : i+1+end], ident: true})
+			i += end + 2
+		case c == '?':
+			positional++
+			tokens = append(tokens, sqlToken{text: "?", ordinal: positional})
+			i++
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(query[i+1 : j])
+			tokens = append(tokens, sqlToken{text: query[i:j], ordinal: n})
+			i = j
+		case isIdentByte(c) && !isDigit(c):
+			j := i + 1
+			for j < len(query) && (isIdentByte(query[j]) || query[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: query[i:j], ident: true})
+			i = j
+		case strings.IndexByte("<>!=", c) >= 0:
+			j := i + 1
+			for j < len(query) && strings.IndexByte("<>!=", query[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: query[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{text: query[i : i+1]})
+			i++
+		}
+	}
+	return tokens
+}
+
+// isIdentByte reports whether c may appear in an unquoted identifier
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c)
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// WrapDriver returns a driver that logs every query and statement executed
+// through d, with its duration, rows affected or returned, and error. Register
+// the result with sql.Register, or use WrapConnector with sql.OpenDB.
+func WrapDriver(d driver.Driver, l *Logger, opts ...DriverOption) driver.Driver {
+	dl := &driverLogger{
+		logger: l.Logger.WithOptions(zap.WithCaller(false)),
+		level:  zapcore.DebugLevel,
+	}
+	for _, opt := range opts {
+		opt(dl)
+	}
+	if dc, ok := d.(driver.DriverContext); ok {
+		return &loggingDriverContext{loggingDriver{d, dl}, dc}
+	}
+	return &loggingDriver{d, dl}
+}
+
+// WrapConnector returns a connector whose connections log like WrapDriver
+func WrapConnector(c driver.Connector, l *Logger, opts ...DriverOption) driver.Connector {
+	wrapped := WrapDriver(c.Driver(), l, opts...)
+	return &loggingConnector{c, wrapped, driverLoggerOf(wrapped)}
+}
+
+// driverLoggerOf returns the settings of a driver created by WrapDriver
+func driverLoggerOf(d driver.Driver) *driverLogger {
+	switch ld := d.(type) {
+	case *loggingDriverContext:
+		return ld.dl
+	case *loggingDriver:
+		return ld.dl
+	}
+	return nil
+}
+
+// loggingDriver wraps a driver.Driver
+type loggingDriver struct {
+	driver.Driver
+	dl *driverLogger
+}
+
+// Open opens a logging connection
+func (d *loggingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{conn, d.dl}, nil
+}
+
+// loggingDriverContext wraps a driver that supports connectors
+type loggingDriverContext struct {
+	loggingDriver
+	dc driver.DriverContext
+}
+
+// OpenConnector opens a logging connector
+func (d *loggingDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.dc.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConnector{c, d, d.dl}, nil
+}
+
+// loggingConnector wraps a driver.Connector
+type loggingConnector struct {
+	driver.Connector
+	driver driver.Driver
+	dl     *driverLogger
+}
+
+// Connect opens a logging connection
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{conn, c.dl}, nil
+}
+
+// Driver returns the wrapped driver
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// loggingConn wraps a driver.Conn. Optional interfaces the underlying
+// connection lacks fall back to database/sql's defaults via driver.ErrSkip.
+type loggingConn struct {
+	driver.Conn
+	dl *driverLogger
+}
+
+// Prepare prepares a logging statement
+func (c *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a logging statement
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.dl.log(query, nil, time.Now(), err, zap.String("operation", "prepare"))
+		return nil, err
+	}
+	ls := &loggingStmt{stmt, c.Conn, query, c.dl}
+	if cc, ok := stmt.(driver.ColumnConverter); ok {
+		return &loggingConverterStmt{ls, cc}, nil
+	}
+	return ls, nil
+}
+
+// BeginTx starts a transaction
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// ExecContext executes and logs a query
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.dl.log(query, args, start, err, resultFields(res, err)...)
+	return res, err
+}
+
+// QueryContext executes a query, logging it when its rows are closed
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		c.dl.log(query, args, start, err)
+		return nil, err
+	}
+	return &loggingRows{Rows: rows, query: query, args: args, start: start, dl: c.dl}, nil
+}
+
+// Ping pings the underlying connection if it supports it
+func (c *loggingConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the underlying connection if it supports it
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the underlying connection is still usable
+func (c *loggingConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue defers to the underlying connection's argument checks
+func (c *loggingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// loggingStmt wraps a prepared statement
+type loggingStmt struct {
+	driver.Stmt
+	conn  driver.Conn
+	query string
+	dl    *driverLogger
+}
+
+// CheckNamedValue defers to the statement's argument checks, or else its
+// connection's, as database/sql does
+func (s *loggingStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	if nc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// loggingConverterStmt wraps a prepared statement that converts its own
+// arguments. It is a separate type because database/sql prefers a
+// statement's converter to its default conversion whenever one exists.
+type loggingConverterStmt struct {
+	*loggingStmt
+	cc driver.ColumnConverter
+}
+
+// ColumnConverter returns the statement's converter for argument idx
+func (s *loggingConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.cc.ColumnConverter(idx)
+}
+
+// Exec executes and logs the statement
+func (s *loggingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query executes the statement
+func (s *loggingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext executes and logs the statement
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = se.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(plainValues(args))
+	}
+	s.dl.log(s.query, args, start, err, resultFields(res, err)...)
+	return res, err
+}
+
+// QueryContext executes the statement, logging it when its rows are closed
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(plainValues(args))
+	}
+	if err != nil {
+		s.dl.log(s.query, args, start, err)
+		return nil, err
+	}
+	return &loggingRows{Rows: rows, query: s.query, args: args, start: start, dl: s.dl}, nil
+}
+
+// loggingRows counts rows and logs the query when closed
+type loggingRows struct {
+	driver.Rows
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	dl     *driverLogger
+	count  int
+	err    error
+	logged bool
+}
+
+// Next advances to the next row, counting it
+func (r *loggingRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+// Close closes the rows and logs the query
+func (r *loggingRows) Close() error {
+	err := r.Rows.Close()
+	if !r.logged {
+		r.logged = true
+		r.dl.log(r.query, r.args, r.start, r.err, zap.Int("rows", r.count))
+	}
+	return err
+}
+
+// The column type methods report what the underlying rows do, falling back
+// to database/sql's defaults for rows that don't describe their columns
+
+// ColumnTypeScanType returns the Go type a column scans into
+func (r *loggingRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeFor[any]()
+}
+
+// ColumnTypeDatabaseTypeName returns a column's database type
+func (r *loggingRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength returns the length of a variable-length column
+func (r *loggingRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable reports whether a column may be null
+func (r *loggingRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale returns a decimal column's precision and scale
+func (r *loggingRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// HasNextResultSet reports whether another result set follows
+func (r *loggingRows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet advances to the next result set, whose rows are counted
+// with the others
+func (r *loggingRows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+// resultFields reports rows affected for a successful exec
+func resultFields(res driver.Result, err error) []zap.Field {
+	if err != nil || res == nil {
+		return nil
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil
+	}
+	return []zap.Field{zap.Int64("rows_affected", n)}
+}
+
+// namedValues converts positional arguments
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// plainValues drops argument names for drivers without context support
+func plainValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
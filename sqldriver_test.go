@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// point is an argument type only the fake driver's checkers accept
+type point struct{ x, y int }
+
+// fakeConnector opens fakeConns
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+// fakeConn runs every query successfully, converting point arguments
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if p, ok := nv.Value.(point); ok {
+		nv.Value = fmt.Sprintf("%d,%d", p.x, p.y)
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// fakeStmt converts its arguments to strings with its own converter
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+func (fakeStmt) ColumnConverter(int) driver.ValueConverter  { return stringConverter{} }
+
+// stringConverter converts any value to its string form
+type stringConverter struct{}
+
+func (stringConverter) ConvertValue(v any) (driver.Value, error) { return fmt.Sprint(v), nil }
+
+// fakeRows returns one row of one TEXT column
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string                     { return []string{"name"} }
+func (r *fakeRows) Close() error                          { return nil }
+func (r *fakeRows) ColumnTypeDatabaseTypeName(int) string { return "TEXT" }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = "alice"
+	return nil
+}
+
+func TestPlaceholderColumns(t *testing.T) {
+	tests := []struct {
+		query string
+		want  map[int]string
+	}{
+		{"SELECT * FROM users WHERE email = ? AND password = ?", map[int]string{1: "email", 2: "password"}},
+		{"SELECT * FROM users u WHERE u.token=$2 AND u.id = $1", map[int]string{1: "id", 2: "token"}},
+		{`UPDATE users SET "api_key" = ?, note = 'a = ?' WHERE id <> ?`, map[int]string{1: "api_key", 2: "id"}},
+		{"INSERT INTO users (email, password) VALUES (?, ?), (?, lower(?))", map[int]string{1: "email", 2: "password", 3: "email"}},
+		{"INSERT INTO users (email, password) VALUES ($1, $2) -- password = ?", map[int]string{1: "email", 2: "password"}},
+		{"SELECT /* name = ? */ ? FROM dual WHERE name LIKE ?", map[int]string{2: "name"}},
+		{"SELECT * FROM users WHERE id IN (?, ?)", map[int]string{}},
+	}
+	for _, tt := range tests {
+		if got := placeholderColumns(tt.query); !maps.Equal(got, tt.want) {
+			t.Errorf("placeholderColumns(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWrapConnectorArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		query    string
+		args     []any
+		want     []any
+	}{
+		{
+			name:     "compared column",
+			redactor: NewRedactor(),
+			query:    "SELECT name FROM users WHERE email = ? AND password = ?",
+			args:     []any{"a@example.com", "hunter2"},
+			want:     []any{"a@example.com", RedactedValue},
+		},
+		{
+			name:     "inserted column",
+			redactor: NewRedactor(),
+			query:    "INSERT INTO users (email, password) VALUES ($1, $2)",
+			args:     []any{"a@example.com", "hunter2"},
+			want:     []any{"a@example.com", RedactedValue},
+		},
+		{
+			name:     "named",
+			redactor: NewRedactor(),
+			query:    "SELECT name FROM users WHERE email = @email AND password = @password",
+			args:     []any{sql.Named("email", "a@example.com"), sql.Named("password", "hunter2")},
+			want:     []any{"a@example.com", RedactedValue},
+		},
+		{
+			name:     "every positional",
+			redactor: NewRedactor(""),
+			query:    "SELECT name FROM users WHERE email = ?",
+			args:     []any{"a@example.com"},
+			want:     []any{RedactedValue},
+		},
+		{
+			name:  "converted by the connection",
+			query: "SELECT name FROM places WHERE at = ?",
+			args:  []any{point{1, 2}},
+			want:  []any{"1,2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+			db := sql.OpenDB(WrapConnector(fakeConnector{}, l, WithQueryLevel(zapcore.InfoLevel), WithQueryArgs(tt.redactor)))
+			defer db.Close()
+
+			if _, err := db.Exec(tt.query, tt.args...); err != nil {
+				t.Fatal(err)
+			}
+			entries := logs.FilterMessage("SQL query completed").All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d queries, want 1", len(entries))
+			}
+			if got := entries[0].ContextMap()["args"]; !slices.Equal(got.([]any), tt.want) {
+				t.Errorf("args = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWrapConnectorOptionalInterfaces checks that statements and rows keep
+// the optional interfaces of the driver they wrap
+func TestWrapConnectorOptionalInterfaces(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, l))
+	defer db.Close()
+
+	stmt, err := db.Prepare("SELECT name FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	// Only the connection's checker accepts a point, and only the
+	// statement's converter a struct
+	for _, arg := range []any{point{1, 2}, struct{ id int }{7}} {
+		if _, err := stmt.Exec(arg); err != nil {
+			t.Errorf("Exec(%v): %v", arg, err)
+		}
+	}
+
+	rows, err := stmt.Query(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := types[0].DatabaseTypeName(); got != "TEXT" {
+		t.Errorf("DatabaseTypeName() = %q, want TEXT", got)
+	}
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stackCore rewrites the captured stack trace before handing the entry to
+// the wrapped core, limiting the frame count and shortening file paths
+type stackCore struct {
+	zapcore.Core
+	maxFrames int
+	prefixes  []string
+}
+
+// wrapStackCore applies the stack trace options in config to core
+func wrapStackCore(core zapcore.Core, config Config) zapcore.Core {
+	if config.StacktraceMaxFrames <= 0 && !config.StacktraceTrimPaths {
+		return core
+	}
+	sc := &stackCore{Core: core, maxFrames: config.StacktraceMaxFrames}
+	if config.StacktraceTrimPaths {
+		sc.prefixes = stackPathPrefixes()
+	}
+	return sc
+}
+
+// stackPathPrefixes returns the directory prefixes stripped from frame paths
+func stackPathPrefixes() []string {
+	var prefixes []string
+	if wd, err := os.Getwd(); err == nil {
+		prefixes = append(prefixes, wd+string(filepath.Separator))
+	}
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		prefixes = append(prefixes,
+			filepath.Join(gopath, "pkg", "mod")+string(filepath.Separator),
+			filepath.Join(gopath, "src")+string(filepath.Separator),
+		)
+	}
+	if build.Default.GOROOT != "" {
+		prefixes = append(prefixes, filepath.Join(build.Default.GOROOT, "src")+string(filepath.Separator))
+	}
+	return prefixes
+}
+
+// With returns a child core that keeps the stack trace options
+func (c *stackCore) With(fields []zapcore.Field) zapcore.Core {
+	return &stackCore{Core: c.Core.With(fields), maxFrames: c.maxFrames, prefixes: c.prefixes}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *stackCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write rewrites the stack trace and writes the entry to the wrapped core
+func (c *stackCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Stack != "" {
+		ent.Stack = c.rewrite(ent.Stack)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// rewrite trims a zap-formatted stack trace. Each frame is a function line
+// followed by a tab-indented file:line line.
+func (c *stackCore) rewrite(stack string) string {
+	lines := strings.Split(stack, "\n")
+	if c.maxFrames > 0 && len(lines) > c.maxFrames*2 {
+		omitted := (len(lines) - c.maxFrames*2 + 1) / 2
+		lines = append(lines[:c.maxFrames*2:c.maxFrames*2], "... "+strconv.Itoa(omitted)+" more frames")
+	}
+	if len(c.prefixes) > 0 {
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "\t") {
+				continue
+			}
+			for _, prefix := range c.prefixes {
+				if strings.HasPrefix(line[1:], prefix) {
+					lines[i] = "\t" + line[1+len(prefix):]
+					break
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
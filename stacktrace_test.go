@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStacktraceLevel(t *testing.T) {
+	tests := []struct {
+		level     string
+		withStack []zapcore.Level
+	}{
+		{level: "", withStack: []zapcore.Level{zapcore.ErrorLevel}},
+		{level: "warn", withStack: []zapcore.Level{zapcore.WarnLevel, zapcore.ErrorLevel}},
+		{level: "off"},
+	}
+	for _, tt := range tests {
+		t.Run("level "+tt.level, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info", StacktraceLevel: tt.level})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+			l.Info("info")
+			l.Warn("warn")
+			l.Error("error")
+
+			var got []zapcore.Level
+			for _, e := range logs.All() {
+				if e.Stack != "" {
+					got = append(got, e.Level)
+				}
+			}
+			if !slices.Equal(got, tt.withStack) {
+				t.Errorf("stack traces at %v, want %v", got, tt.withStack)
+			}
+		})
+	}
+}
+
+func TestStacktraceRewrite(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := func(fn, file string) string { return fn + "\n\t" + file }
+	stack := frame("main.a", filepath.Join(wd, "a.go:1")) + "\n" +
+		frame("main.b", filepath.Join(wd, "b.go:2")) + "\n" +
+		frame("main.c", "/elsewhere/c.go:3")
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{name: "unchanged", want: stack},
+		{
+			name:   "max frames",
+			config: Config{StacktraceMaxFrames: 1},
+			want:   frame("main.a", filepath.Join(wd, "a.go:1")) + "\n... 2 more frames",
+		},
+		{
+			name:   "trim paths",
+			config: Config{StacktraceTrimPaths: true},
+			want:   frame("main.a", "a.go:1") + "\n" + frame("main.b", "b.go:2") + "\n" + frame("main.c", "/elsewhere/c.go:3"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obs, logs := observer.New(zapcore.InfoLevel)
+			core := wrapStackCore(obs, tt.config)
+			ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "failed", Stack: stack}
+			if ce := core.Check(ent, nil); ce != nil {
+				ce.Write()
+			}
+			if got := logs.All()[0].Stack; got != tt.want {
+				t.Errorf("stack:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
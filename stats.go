@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of the logger's internal counters
+type Stats struct {
+	// AsyncDropped is the number of entries dropped because the async
+	// queue was full
+	AsyncDropped uint64
+	// AsyncQueued is the number of entries waiting to be written
+	AsyncQueued int
+	// AsyncLag is how long the most recently written entry waited in the
+	// async queue, or how long the entry being written has been waiting if
+	// a sink is blocked. A growing lag means logging is falling behind.
+	AsyncLag time.Duration
+	// AsyncMaxAge is the longest any entry has waited in the async queue
+	AsyncMaxAge time.Duration
+	// BufferPoolGets and BufferPoolMisses count encode buffer requests and
+	// those that needed a new buffer. The pool is shared by every logger in
+	// the process and backs the console, dev, JSON, msgpack, and CBOR
+	// encoders.
+	BufferPoolGets   uint64
+	BufferPoolMisses uint64
+	// Network reports the network sink, if one is configured
+	Network *NetworkStats
+	// SLOs reports every objective configured in Config.SLOs
+	SLOs []SLOStatus
+	// Volume reports the bytes written per sink, logger, and level, largest
+	// first, when Config.TrackVolume is set
+	Volume []VolumeStat
+	// Metrics totals the metric samples carried by entries, by kind and
+	// name, when Config.TrackMetrics is set
+	Metrics []MetricStat
+}
+
+// NetworkStats describes the network sink
+type NetworkStats struct {
+	Address   string
+	Connected bool
+	// Buffered is the number of entries waiting to be sent
+	Buffered int
+	// Dropped counts entries discarded because the buffer was full or the
+	// logger closed while the collector was unreachable
+	Dropped uint64
+}
+
+// Stats returns a snapshot of the logger's counters. Loggers derived from
+// the same root share them.
+func (l *Logger) Stats() Stats {
+	s := Stats{
+		BufferPoolGets:   bufferPool.gets.Load(),
+		BufferPoolMisses: bufferPool.misses.Load(),
+	}
+	p := l.state.pipe.Load()
+	if p.res.async != nil {
+		s.AsyncDropped = p.res.async.dropped.Load()
+		s.AsyncQueued = p.res.async.queued()
+		s.AsyncLag = p.res.async.lag()
+		s.AsyncMaxAge = time.Duration(p.res.async.maxAge.Load())
+	}
+	if p.res.network != nil {
+		ns := p.res.network.stats()
+		s.Network = &ns
+	}
+	if p.res.volume != nil {
+		s.Volume = p.res.volume.snapshot()
+	}
+	if p.res.metrics != nil && p.res.metrics.totals != nil {
+		s.Metrics = p.res.metrics.snapshot()
+	}
+	// Entries are bucketed by their time, which comes from Config.Clock
+	now := l.now()
+	for _, t := range p.res.slos {
+		s.SLOs = append(s.SLOs, t.status(now))
+	}
+	return s
+}
+
+// BufferPoolHitRate returns the fraction of encode buffer requests served by
+// a reused buffer, or 0 before any request
+func (s Stats) BufferPoolHitRate() float64 {
+	if s.BufferPoolGets == 0 {
+		return 0
+	}
+	return 1 - float64(s.BufferPoolMisses)/float64(s.BufferPoolGets)
+}
+
+// WritePrometheus writes s in the Prometheus text exposition format
+func (s Stats) WritePrometheus(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# TYPE logger_async_dropped_total counter\n")
+	fmt.Fprintf(&b, "logger_async_dropped_total %d\n", s.AsyncDropped)
+	b.WriteString("# TYPE logger_async_queued gauge\n")
+	fmt.Fprintf(&b, "logger_async_queued %d\n", s.AsyncQueued)
+	b.WriteString("# TYPE logger_async_lag_seconds gauge\n")
+	fmt.Fprintf(&b, "logger_async_lag_seconds %g\n", s.AsyncLag.Seconds())
+	b.WriteString("# TYPE logger_async_max_age_seconds gauge\n")
+	fmt.Fprintf(&b, "logger_async_max_age_seconds %g\n", s.AsyncMaxAge.Seconds())
+	b.WriteString("# TYPE logger_buffer_pool_gets_total counter\n")
+	fmt.Fprintf(&b, "logger_buffer_pool_gets_total %d\n", s.BufferPoolGets)
+	b.WriteString("# TYPE logger_buffer_pool_misses_total counter\n")
+	fmt.Fprintf(&b, "logger_buffer_pool_misses_total %d\n", s.BufferPoolMisses)
+
+	if s.Network != nil {
+		connected := 0
+		if s.Network.Connected {
+			connected = 1
+		}
+		b.WriteString("# TYPE logger_network_connected gauge\n")
+		fmt.Fprintf(&b, "logger_network_connected %d\n", connected)
+		b.WriteString("# TYPE logger_network_buffered gauge\n")
+		fmt.Fprintf(&b, "logger_network_buffered %d\n", s.Network.Buffered)
+		b.WriteString("# TYPE logger_network_dropped_total counter\n")
+		fmt.Fprintf(&b, "logger_network_dropped_total %d\n", s.Network.Dropped)
+	}
+
+	sloFamilies := []struct {
+		name, kind string
+		value      func(SLOStatus) any
+	}{
+		{"logger_slo_events", "gauge", func(slo SLOStatus) any { return slo.Total }},
+		{"logger_slo_bad_events", "gauge", func(slo SLOStatus) any { return slo.Bad }},
+		{"logger_slo_burn_rate", "gauge", func(slo SLOStatus) any { return slo.BurnRate }},
+	}
+	if len(s.SLOs) > 0 {
+		for _, family := range sloFamilies {
+			fmt.Fprintf(&b, "# TYPE %s %s\n", family.name, family.kind)
+			for _, slo := range s.SLOs {
+				fmt.Fprintf(&b, "%s{slo=\"%s\"} %v\n", family.name, promLabel(slo.Name), family.value(slo))
+			}
+		}
+	}
+
+	if len(s.Volume) > 0 {
+		for _, family := range []struct {
+			name  string
+			value func(VolumeStat) uint64
+		}{
+			{"logger_volume_entries_total", func(v VolumeStat) uint64 { return v.Entries }},
+			{"logger_volume_bytes_total", func(v VolumeStat) uint64 { return v.Bytes }},
+		} {
+			fmt.Fprintf(&b, "# TYPE %s counter\n", family.name)
+			for _, v := range s.Volume {
+				fmt.Fprintf(&b, "%s{sink=\"%s\",logger=\"%s\",level=\"%s\"} %d\n", family.name,
+					promLabel(v.Sink), promLabel(v.Logger), promLabel(v.Level), family.value(v))
+			}
+		}
+	}
+
+	// Log metrics are grouped into one family per kind, labeled by name
+	for _, family := range []struct {
+		kind, name, promType string
+		value                func(MetricStat) any
+	}{
+		{MetricCounter, "logger_metric_total", "counter", func(m MetricStat) any { return m.Value }},
+		{MetricGauge, "logger_metric_value", "gauge", func(m MetricStat) any { return m.Value }},
+		{MetricSummary, "logger_metric_observed_sum", "counter", func(m MetricStat) any { return m.Value }},
+		{MetricSummary, "logger_metric_observed_count", "counter", func(m MetricStat) any { return m.Samples }},
+	} {
+		typed := false
+		for _, m := range s.Metrics {
+			if m.Kind != family.kind {
+				continue
+			}
+			if !typed {
+				fmt.Fprintf(&b, "# TYPE %s %s\n", family.name, family.promType)
+				typed = true
+			}
+			fmt.Fprintf(&b, "%s{metric=\"%s\"} %v\n", family.name, promLabel(m.Name), family.value(m))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MetricsHandler returns an http.Handler serving Stats in the Prometheus
+// text exposition format
+func (l *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = l.Stats().WritePrometheus(w)
+	})
+}
+
+// promLabel escapes a Prometheus label value
+func promLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported values for Config.ConsoleOutput and Config.JSONOutput
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+// resolveOutputs validates Config.ConsoleOutput and Config.JSONOutput,
+// returning the console's stream and the JSON stream's, "" when disabled
+func resolveOutputs(config Config) (console, json string, err error) {
+	console = config.ConsoleOutput
+	switch console {
+	case "":
+		console = OutputStdout
+	case OutputStdout, OutputStderr:
+	default:
+		return "", "", fmt.Errorf("invalid console output %q", console)
+	}
+	switch config.JSONOutput {
+	case "":
+	case OutputStdout, OutputStderr:
+		if config.JSONOutput == console {
+			return "", "", fmt.Errorf("JSON output and console both write to %s", console)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid JSON output %q", config.JSONOutput)
+	}
+	return console, config.JSONOutput, nil
+}
+
+// stdStream returns the stream named by an output
+func stdStream(output string) *os.File {
+	if output == OutputStderr {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// useStderrColors decides whether to color from stderr instead of stdout,
+// as color does at startup, for a console writing to stderr. Colors are
+// process-wide, so this affects every logger.
+func useStderrColors() {
+	fd := os.Stderr.Fd()
+	color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" ||
+		(!isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd))
+}
+
+// ansiEscape matches the SGR sequences colors are written with
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plainCore strips colors from messages, such as the prefixes of Success
+// and Failure, for a sink read by programs
+type plainCore struct {
+	zapcore.Core
+}
+
+// With returns a child core
+func (c *plainCore) With(fields []zapcore.Field) zapcore.Core {
+	return &plainCore{Core: c.Core.With(fields)}
+}
+
+// Check adds this core to the checked entry if the wrapped core is enabled
+func (c *plainCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write strips colors from the message
+func (c *plainCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if strings.Contains(ent.Message, "\x1b[") {
+		ent.Message = ansiEscape.ReplaceAllString(ent.Message, "")
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestResolveOutputs(t *testing.T) {
+	tests := []struct {
+		console, json       string
+		wantConsole, wantJS string
+		wantErr             bool
+	}{
+		{"", "", OutputStdout, "", false},
+		{OutputStderr, OutputStdout, OutputStderr, OutputStdout, false},
+		{"", OutputStderr, OutputStdout, OutputStderr, false},
+		{"", OutputStdout, "", "", true},
+		{OutputStderr, OutputStderr, "", "", true},
+		{"file", "", "", "", true},
+		{"", "tcp", "", "", true},
+	}
+	for _, tt := range tests {
+		console, js, err := resolveOutputs(Config{ConsoleOutput: tt.console, JSONOutput: tt.json})
+		if (err != nil) != tt.wantErr || console != tt.wantConsole || js != tt.wantJS {
+			t.Errorf("resolveOutputs(%q, %q) = %q, %q, %v", tt.console, tt.json, console, js, err)
+		}
+	}
+}
+
+// redirectStd replaces os.Stdout and os.Stderr with files, returning
+// their paths
+func redirectStd(t *testing.T) (stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	prevOut, prevErr := os.Stdout, os.Stderr
+	for _, std := range []struct {
+		f    **os.File
+		path *string
+		name string
+	}{{&os.Stdout, &stdout, "stdout"}, {&os.Stderr, &stderr, "stderr"}} {
+		*std.path = filepath.Join(dir, std.name)
+		f, err := os.Create(*std.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		*std.f = f
+	}
+	t.Cleanup(func() { os.Stdout, os.Stderr = prevOut, prevErr })
+	return stdout, stderr
+}
+
+func TestJSONOutput(t *testing.T) {
+	withoutColor(t)
+	color.NoColor = false
+	stdout, stderr := redirectStd(t)
+	l, err := NewLogger(Config{Level: "info", ConsoleOutput: OutputStderr, JSONOutput: OutputStdout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !color.NoColor {
+		t.Error("colors enabled for a console on a stderr that isn't a terminal")
+	}
+	// As on a terminal, so the console's prefix is colored
+	color.NoColor = false
+	l.Success("deployed")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	console, err := os.ReadFile(stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(console), successPrefix.colored+"deployed") {
+		t.Errorf("stderr %q, want the console entry", console)
+	}
+	out, err := os.ReadFile(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(out, &entry); err != nil {
+		t.Fatalf("stdout %q is not one JSON line: %v", out, err)
+	}
+	if entry["msg"] != "✓ deployed" {
+		t.Errorf("JSON message %q, want the plain prefix", entry["msg"])
+	}
+}
+
+func TestPlainCore(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	core := (&plainCore{Core: obs}).With(nil)
+	for _, msg := range []string{"\x1b[32m✓ \x1b[0mdeployed", "\x1b[1;31mfailed\x1b[0m", "plain"} {
+		if ce := core.Check(zapcore.Entry{Level: zapcore.InfoLevel, Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	core.Check(zapcore.Entry{Level: zapcore.DebugLevel, Message: "skipped"}, nil).Write()
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	if want := []string{"✓ deployed", "failed", "plain"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("messages %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,448 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// streamClientBuffer is how many entries a client may fall behind by
+	// before further ones are dropped
+	streamClientBuffer = 256
+	// streamHeartbeat is how often an idle stream is written to, so proxies
+	// keep it open and dead clients are noticed
+	streamHeartbeat = 15 * time.Second
+	// streamWriteTimeout bounds each write to a client
+	streamWriteTimeout = 10 * time.Second
+	// websocketGUID is appended to the client's key in the handshake
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// websocketMaxFrame bounds the frames a client may send
+	websocketMaxFrame = 4096
+)
+
+// StreamOption configures StreamHandler
+type StreamOption func(*streamOptions)
+
+// streamOptions holds the settings of a stream handler
+type streamOptions struct {
+	limit      rate.Limit
+	burst      int
+	maxClients int
+}
+
+// WithStreamRateLimit caps the entries sent to each client per second;
+// entries beyond it are dropped and counted. Defaults to 50 per second
+// with a burst of 100.
+func WithStreamRateLimit(limit rate.Limit, burst int) StreamOption {
+	return func(o *streamOptions) {
+		o.limit, o.burst = limit, burst
+	}
+}
+
+// WithStreamMaxClients limits the clients connected at once; further ones
+// are refused with 503. Defaults to 32.
+func WithStreamMaxClients(n int) StreamOption {
+	return func(o *streamOptions) {
+		o.maxClients = n
+	}
+}
+
+// StreamHandler returns an http.Handler streaming the logger's entries as
+// they are written to connected clients, for live debugging dashboards.
+// Clients connect with a GET, as a WebSocket if they ask to upgrade and
+// over Server-Sent Events otherwise, and select entries with the viewer's
+// ?level=, ?q=, and ?field=key=value parameters. The level defaults to
+// the logger's and may go below it, so a client can watch debug entries
+// without the other sinks writing them. Each entry is sent as a JSON
+// object; entries dropped by the per-client rate cap, or because the
+// client fell behind, are counted and reported in the stream as
+// {"dropped": n}, an SSE "dropped" event or a WebSocket message.
+//
+// Requests must carry the same bearer token as AdminHandler. Since
+// browsers can't set headers on EventSource and WebSocket requests, it is
+// also accepted as ?access_token=, which may end up in access logs.
+func (l *Logger) StreamHandler(tokens CredentialProvider, opts ...StreamOption) http.Handler {
+	o := streamOptions{limit: 50, burst: 100, maxClients: 32}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	hub := newStreamHub()
+	l.AddSink(&streamCore{hub: hub, ctx: newMapEncoder()})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !streamAuthorized(r, tokens) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="logger"`)
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		filter, err := parseEntryFilter(r.URL.Query(), l.Level())
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		client := &streamClient{
+			filter:  filter,
+			limiter: rate.NewLimiter(o.limit, o.burst),
+			entries: make(chan viewerEntry, streamClientBuffer),
+		}
+		if !hub.add(client, o.maxClients) {
+			writeAdminError(w, http.StatusServiceUnavailable, "too many stream clients")
+			return
+		}
+		defer hub.remove(client)
+
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			serveWebSocket(w, r, client)
+			return
+		}
+		serveSSE(w, r, client)
+	})
+}
+
+// streamAuthorized checks the bearer token of the header or ?access_token=
+func streamAuthorized(r *http.Request, tokens CredentialProvider) bool {
+	if adminAuthorized(r, tokens) {
+		return true
+	}
+	got := r.URL.Query().Get("access_token")
+	creds, err := tokens.Credentials()
+	if err != nil || creds.Token == "" || got == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(creds.Token)) == 1
+}
+
+// streamClient is one connected client
+type streamClient struct {
+	filter  entryFilter
+	limiter *rate.Limiter
+	entries chan viewerEntry
+	dropped atomic.Int64
+}
+
+// send queues e unless the client is over its rate cap or behind
+func (c *streamClient) send(e viewerEntry) {
+	if !c.limiter.Allow() {
+		c.dropped.Add(1)
+		return
+	}
+	select {
+	case c.entries <- e:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// streamHub fans entries out to the connected clients
+type streamHub struct {
+	mu      sync.RWMutex
+	clients map[*streamClient]struct{}
+	// level is the lowest level any client selects, or above fatal when
+	// there are none
+	level atomic.Int32
+}
+
+// newStreamHub creates a hub without clients
+func newStreamHub() *streamHub {
+	h := &streamHub{clients: make(map[*streamClient]struct{})}
+	h.level.Store(int32(zapcore.FatalLevel + 1))
+	return h
+}
+
+// add registers c unless limit clients are connected
+func (h *streamHub) add(c *streamClient, limit int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limit > 0 && len(h.clients) >= limit {
+		return false
+	}
+	h.clients[c] = struct{}{}
+	h.updateLevel()
+	return true
+}
+
+// remove unregisters c
+func (h *streamHub) remove(c *streamClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+	h.updateLevel()
+}
+
+// updateLevel recomputes the lowest selected level, with mu held
+func (h *streamHub) updateLevel() {
+	level := zapcore.FatalLevel + 1
+	for c := range h.clients {
+		level = min(level, c.filter.minLevel)
+	}
+	h.level.Store(int32(level))
+}
+
+// streamCore passes entries to a hub's clients
+type streamCore struct {
+	hub *streamHub
+	ctx mapEncoder
+}
+
+// Enabled reports whether a client selects level
+func (c *streamCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.Level(c.hub.level.Load())
+}
+
+// With returns a child core whose entries carry fields
+func (c *streamCore) With(fields []zapcore.Field) zapcore.Core {
+	return &streamCore{hub: c.hub, ctx: c.ctx.withFields(fields)}
+}
+
+// Check adds this core to the checked entry if a client selects its level
+func (c *streamCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write queues the entry for every client whose filter it passes
+func (c *streamCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := newLogEntry(ent, c.ctx.withFields(fields).Fields)
+	e := newViewerEntry(entry)
+
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	for client := range c.hub.clients {
+		if client.filter.match(entry) {
+			client.send(e)
+		}
+	}
+	return nil
+}
+
+// Sync does nothing; entries are sent by the clients' handlers
+func (c *streamCore) Sync() error {
+	return nil
+}
+
+// streamMessages calls write with each entry for client, preceded by the
+// count dropped since the last one, and with nil as a heartbeat, until
+// done is closed or write fails
+func streamMessages(done <-chan struct{}, client *streamClient, write func(event string, v any) error) error {
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-heartbeat.C:
+			if err := write("", nil); err != nil {
+				return err
+			}
+		case e := <-client.entries:
+			if n := client.dropped.Swap(0); n > 0 {
+				if err := write("dropped", map[string]int64{"dropped": n}); err != nil {
+					return err
+				}
+			}
+			if err := write("entry", e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// serveSSE streams client's entries as Server-Sent Events
+func serveSSE(w http.ResponseWriter, r *http.Request, client *streamClient) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	_ = streamMessages(r.Context().Done(), client, func(event string, v any) error {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if v == nil {
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if event != "entry" {
+			if _, err := fmt.Fprintf(w, "event: %s\n", event); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", raw); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
+
+// serveWebSocket upgrades the connection and streams client's entries as
+// text messages
+func serveWebSocket(w http.ResponseWriter, r *http.Request, client *streamClient) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		writeAdminError(w, http.StatusBadRequest, "invalid WebSocket handshake")
+		return
+	}
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, "WebSocket not supported")
+		return
+	}
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	ws := &websocketConn{conn: conn, rw: rw}
+	if err := ws.writeRaw([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")); err != nil {
+		return
+	}
+
+	// Read the client's frames, answering pings, until it closes
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = ws.readLoop()
+	}()
+
+	_ = streamMessages(done, client, func(_ string, v any) error {
+		if v == nil {
+			return ws.writeFrame(websocketPing, nil)
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return ws.writeFrame(websocketText, raw)
+	})
+}
+
+// WebSocket opcodes
+const (
+	websocketText  = 0x1
+	websocketClose = 0x8
+	websocketPing  = 0x9
+	websocketPong  = 0xa
+)
+
+// websocketConn is the server side of a WebSocket connection, enough to
+// send text messages and answer the client's control frames
+type websocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	mu   sync.Mutex
+}
+
+// writeRaw writes p to the connection and flushes it
+func (c *websocketConn) writeRaw(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if _, err := c.rw.Write(p); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// writeFrame writes an unfragmented, unmasked frame
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10+len(payload))
+	header[0] = 0x80 | opcode
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	return c.writeRaw(append(header, payload...))
+}
+
+// readLoop reads the client's frames, answering pings and ignoring data,
+// until the client closes the connection or breaks the protocol
+func (c *websocketConn) readLoop() error {
+	var header [2]byte
+	for {
+		if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+			return err
+		}
+		opcode := header[0] & 0x0f
+		if header[1]&0x80 == 0 {
+			return errors.New("unmasked client frame")
+		}
+		n := uint64(header[1] & 0x7f)
+		switch n {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			n = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			n = binary.BigEndian.Uint64(ext[:])
+		}
+		if n > websocketMaxFrame {
+			return fmt.Errorf("client frame of %d bytes too large", n)
+		}
+		var mask [4]byte
+		if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+			return err
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.rw, payload); err != nil {
+			return err
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch opcode {
+		case websocketClose:
+			_ = c.writeFrame(websocketClose, payload[:min(len(payload), 2)])
+			return io.EOF
+		case websocketPing:
+			if err := c.writeFrame(websocketPong, payload); err != nil {
+				return err
+			}
+		}
+	}
+}
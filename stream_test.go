@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// waitEnabled waits until a stream client has connected at level
+func waitEnabled(t *testing.T, l *Logger, level zapcore.Level) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !l.Enabled(level) {
+		if time.Now().After(deadline) {
+			t.Fatal("stream client never connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStreamSSE(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	srv := httptest.NewServer(l.StreamHandler(StaticCredentials(Credentials{Token: "secret"}), WithStreamMaxClients(1)))
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL + "?level=debug"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("stream without a token: %v %v", resp.Status, err)
+	}
+	resp, err := http.Get(srv.URL + "?access_token=secret&level=debug&field=region=eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream: %s %s", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	waitEnabled(t, l, zapcore.DebugLevel)
+
+	second, err := http.Get(srv.URL + "?access_token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("client over the limit: status %d, want 503", second.StatusCode)
+	}
+
+	l.Debug("cache miss", zap.String("region", "us"))
+	l.Debug("cache hit", zap.String("region", "eu"))
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var e viewerEntry
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Message != "cache hit" || e.Level != "debug" {
+			t.Errorf("streamed %+v, want the debug entry for eu", e)
+		}
+		break
+	}
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	l := newBenchLogger(t, Config{Level: "info"})
+	srv := httptest.NewServer(l.StreamHandler(StaticCredentials(Credentials{Token: "secret"})))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	io.WriteString(conn, "GET /?level=warn HTTP/1.1\r\nHost: logger\r\n"+
+		"Authorization: Bearer secret\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: %s accept %q", resp.Status, resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+	waitEnabled(t, l, zapcore.WarnLevel)
+
+	l.Info("ignored")
+	l.Warn("disk almost full")
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != 0x80|websocketText {
+		t.Fatalf("frame header %x, want a text frame", header[0])
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	var e viewerEntry
+	if err := json.Unmarshal(payload, &e); err != nil || e.Message != "disk almost full" {
+		t.Errorf("message %s, want the warn entry: %v", payload, err)
+	}
+
+	// A masked close frame with status 1000 is echoed back
+	frame := []byte{0x80 | websocketClose, 0x80 | 2, 1, 2, 3, 4}
+	frame = binary.BigEndian.AppendUint16(frame, 1000)
+	frame[6] ^= 1
+	frame[7] ^= 2
+	conn.Write(frame)
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[0] != 0x80|websocketClose {
+		t.Errorf("reply frame %x, want close", header[0])
+	}
+}
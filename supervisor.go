@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SupervisorOption configures a Supervisor
+type SupervisorOption func(*Supervisor)
+
+// WithRestarts restarts a process that exits up to max times in a row,
+// successful exits included; a negative max restarts without limit. By
+// default processes aren't restarted.
+func WithRestarts(max int) SupervisorOption {
+	return func(s *Supervisor) {
+		s.maxRestarts = max
+	}
+}
+
+// WithRestartBackoff sets the delay before the first restart, doubled for
+// each further restart up to max. A process that ran for at least max
+// before exiting is restarted after initial again. Defaults to one second
+// and one minute.
+func WithRestartBackoff(initial, max time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.initialBackoff = initial
+		s.maxBackoff = max
+	}
+}
+
+// WithSupervisedOutput sets the options used to capture each process's
+// output with WrapCmd
+func WithSupervisedOutput(opts ...CmdOption) SupervisorOption {
+	return func(s *Supervisor) {
+		s.cmdOpts = opts
+	}
+}
+
+// Supervisor runs child processes, logging their output through WrapCmd
+// and their lifecycle (start, exit, restart, backoff) as entries with a
+// process field
+type Supervisor struct {
+	logger         *Logger
+	maxRestarts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	cmdOpts        []CmdOption
+
+	mu    sync.Mutex
+	procs []supervisedProcess
+}
+
+// supervisedProcess is a command added to a Supervisor
+type supervisedProcess struct {
+	name     string
+	template *exec.Cmd
+}
+
+// NewSupervisor creates a supervisor logging to l
+func (l *Logger) NewSupervisor(opts ...SupervisorOption) *Supervisor {
+	s := &Supervisor{
+		logger:         l,
+		initialBackoff: time.Second,
+		maxBackoff:     time.Minute,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add registers cmd under name. cmd is used as a template and never
+// started itself: each run, including restarts, starts a copy of its path,
+// arguments, environment, directory, and process attributes. Its output
+// must not be redirected.
+func (s *Supervisor) Add(name string, cmd *exec.Cmd) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.procs = append(s.procs, supervisedProcess{name: name, template: cmd})
+}
+
+// Run starts every added process and supervises it until ctx is canceled,
+// when the processes are sent SIGTERM and, after five seconds, killed. It
+// returns once every process has exited for good, with the errors of those
+// that failed to start or exited unsuccessfully the last time.
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mu.Lock()
+	procs := append([]supervisedProcess(nil), s.procs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(procs))
+	for i, p := range procs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.supervise(ctx, p); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// supervise runs one process, restarting it as configured
+func (s *Supervisor) supervise(ctx context.Context, p supervisedProcess) error {
+	// Lifecycle entries describe the child, not the supervising goroutine
+	log := s.logger.derive(s.logger.Logger.WithOptions(
+		zap.WithCaller(false),
+		zap.AddStacktrace(zapcore.InvalidLevel),
+	)).WithField("process", p.name)
+	backoff := s.initialBackoff
+	for restarts := 0; ; restarts++ {
+		cmd := exec.CommandContext(ctx, p.template.Path)
+		// A Cmd built by hand may leave Args empty to run Path without any
+		if len(p.template.Args) > 0 {
+			cmd.Args = p.template.Args
+		}
+		cmd.Env = p.template.Env
+		cmd.Dir = p.template.Dir
+		cmd.SysProcAttr = p.template.SysProcAttr
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+		cmd.WaitDelay = 5 * time.Second
+		if err := log.WrapCmd(cmd, s.cmdOpts...); err != nil {
+			return err
+		}
+
+		start := time.Now()
+		if err := cmd.Start(); err != nil {
+			log.Error("process failed to start", zap.Error(err), zap.Int("restarts", restarts))
+			return err
+		}
+		log.Info("process started", zap.Int("pid", cmd.Process.Pid), zap.Int("restarts", restarts))
+
+		err := cmd.Wait()
+		uptime := time.Since(start)
+		level := zapcore.InfoLevel
+		if err != nil && ctx.Err() == nil {
+			level = zapcore.WarnLevel
+		}
+		fields := []zap.Field{
+			zap.Int("pid", cmd.Process.Pid),
+			zap.Int("exit_code", cmd.ProcessState.ExitCode()),
+			zap.Duration("uptime", uptime),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+		if ce := log.Check(level, "process exited"); ce != nil {
+			ce.Write(fields...)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if s.maxRestarts >= 0 && restarts >= s.maxRestarts {
+			if s.maxRestarts > 0 {
+				log.Error("process restart limit reached", zap.Int("restarts", restarts))
+			}
+			return err
+		}
+
+		if uptime >= s.maxBackoff {
+			backoff = s.initialBackoff
+		}
+		log.Info("process restarting", zap.Duration("backoff", backoff), zap.Int("restarts", restarts+1))
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
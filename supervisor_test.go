@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSupervisor(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      *exec.Cmd
+		restarts int
+		wantErr  bool
+		want     []string
+	}{
+		{
+			name: "args left empty",
+			cmd:  &exec.Cmd{Path: "/bin/true"},
+			want: []string{"process started", "process exited"},
+		},
+		{
+			name:     "restarted until the limit",
+			cmd:      exec.Command("/bin/sh", "-c", "echo working; exit 3"),
+			restarts: 1,
+			wantErr:  true,
+			want: []string{
+				"process started", "working", "process exited", "process restarting",
+				"process started", "working", "process exited", "process restart limit reached",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newBenchLogger(t, Config{Level: "info"})
+			obs, logs := observer.New(zapcore.InfoLevel)
+			l.AddSink(obs)
+
+			s := l.NewSupervisor(WithRestarts(tt.restarts), WithRestartBackoff(time.Millisecond, time.Second))
+			s.Add("worker", tt.cmd)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := s.Run(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("Run error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, e := range logs.AllUntimed() {
+				if e.ContextMap()["process"] == "worker" {
+					got = append(got, e.Message)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("logged %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Table logs msg at info level with a table of rows under headers, for CLI
+// summaries such as migration results. The console renders the table as
+// aligned columns below the entry; other sinks get a "rows" field holding
+// one object per row, keyed by header. Cells beyond the headers are
+// dropped and missing cells are empty.
+func (l *Logger) Table(msg string, headers []string, rows [][]any, fields ...zap.Field) {
+	if !l.Enabled(zapcore.InfoLevel) {
+		return
+	}
+	all := make([]zap.Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	all = append(all, zap.Field{Key: "rows", Type: zapcore.ArrayMarshalerType, Interface: &table{headers: headers, rows: rows}})
+	l.logAt(zapcore.InfoLevel, colorText{}, msg, all)
+}
+
+// table is the "rows" field of an entry logged with Table
+type table struct {
+	headers []string
+	rows    [][]any
+}
+
+// MarshalLogArray writes each row as an object keyed by header
+func (t *table) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, row := range t.rows {
+		if err := enc.AppendObject(tableRow{headers: t.headers, cells: row}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableRow is one row of a table
+type tableRow struct {
+	headers []string
+	cells   []any
+}
+
+// MarshalLogObject writes the row's cells under their headers
+func (r tableRow) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for i, h := range r.headers {
+		if i < len(r.cells) {
+			Any(h, r.cells[i]).AddTo(enc)
+		}
+	}
+	return nil
+}
+
+// splitTable removes a Table field from fields, returning it separately so
+// console encoders can render it as columns
+func splitTable(fields []zapcore.Field) (*table, []zapcore.Field) {
+	for i, f := range fields {
+		if t, ok := f.Interface.(*table); ok && f.Type == zapcore.ArrayMarshalerType {
+			rest := make([]zapcore.Field, 0, len(fields)-1)
+			rest = append(rest, fields[:i]...)
+			return t, append(rest, fields[i+1:]...)
+		}
+	}
+	return nil, fields
+}
+
+// render appends the table as aligned columns, one line per row, each
+// starting with indent. Headers are colored and numbers right-aligned.
+func (t *table) render(buf *buffer.Buffer, indent string) {
+	cells := make([][]string, len(t.rows))
+	numeric := make([]bool, len(t.headers))
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = utf8.RuneCountInString(h)
+		numeric[i] = len(t.rows) > 0
+	}
+	for r, row := range t.rows {
+		cells[r] = make([]string, len(t.headers))
+		for i := range t.headers {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+			cells[r][i] = fmt.Sprint(row[i])
+			widths[i] = max(widths[i], utf8.RuneCountInString(cells[r][i]))
+			numeric[i] = numeric[i] && isNumber(row[i])
+		}
+	}
+
+	line := func(values []string, style func(...any) string, alignNumbers bool) {
+		var b strings.Builder
+		for i, v := range values {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v))
+			switch {
+			case alignNumbers && numeric[i]:
+				v = pad + v
+			case i < len(values)-1:
+				v += pad
+			}
+			if style != nil {
+				v = style(v)
+			}
+			b.WriteString(v)
+		}
+		buf.AppendString(indent)
+		buf.AppendString(strings.TrimRight(b.String(), " "))
+		buf.AppendByte('\n')
+	}
+	line(t.headers, cyan, true)
+	rules := make([]string, len(t.headers))
+	for i, w := range widths {
+		rules[i] = strings.Repeat("─", w)
+	}
+	line(rules, nil, false)
+	for _, row := range cells {
+		line(row, nil, true)
+	}
+}
+
+// isNumber reports whether v is an integer or floating-point value
+func isNumber(v any) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+)
+
+func TestTableRender(t *testing.T) {
+	withoutColor(t)
+	tbl := &table{
+		headers: []string{"migration", "rows", "status"},
+		rows: [][]any{
+			{"add_users", 1200, "ok"},
+			{"backfill_é", 7, nil},
+			{"short"},
+		},
+	}
+	var buf buffer.Buffer
+	tbl.render(&buf, "  ")
+	want := "" +
+		"  migration   rows  status\n" +
+		"  ──────────  ────  ──────\n" +
+		"  add_users   1200  ok\n" +
+		"  backfill_é     7\n" +
+		"  short\n"
+	if got := buf.String(); got != want {
+		t.Errorf("rendered\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableFileRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{EnableFile: true, FilePath: path})
+	l.Table("migrations applied",
+		[]string{"migration", "rows"},
+		[][]any{{"add_users", 1200, "extra"}, {"short"}},
+		zap.String("db", "main"))
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry struct {
+		Msg  string
+		DB   string
+		Rows []map[string]any
+	}
+	if err := json.Unmarshal(readOnlyLine(t, path), &entry); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{{"migration": "add_users", "rows": float64(1200)}, {"migration": "short"}}
+	if entry.Msg != "migrations applied" || entry.DB != "main" || !reflect.DeepEqual(entry.Rows, want) {
+		t.Errorf("file entry = %+v, want rows %v", entry, want)
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// resolveTimeZone loads a Config.TimeZone value: an IANA name such as
+// "Europe/Berlin", "UTC", or "Local". It returns nil for "", which keeps
+// the entries' own zone.
+func resolveTimeZone(name string) (*time.Location, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "UTC":
+		return time.UTC, nil
+	case "Local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// withTimeZone makes cfg encode timestamps in loc, if not nil
+func withTimeZone(cfg zapcore.EncoderConfig, loc *time.Location) zapcore.EncoderConfig {
+	if loc == nil || cfg.EncodeTime == nil {
+		return cfg
+	}
+	encode := cfg.EncodeTime
+	cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		encode(t.In(loc), enc)
+	}
+	return cfg
+}
+
+// fileEncoderConfigIn returns fileEncoderConfig for a sink writing
+// timestamps in zone. With a zone set, they default to RFC 3339, since the
+// default layout has no offset and readers such as LogReader would take
+// it for local time.
+func fileEncoderConfigIn(zone *time.Location) zapcore.EncoderConfig {
+	cfg := fileEncoderConfig()
+	if zone != nil {
+		cfg.EncodeTime = zapcore.RFC3339TimeEncoder
+	}
+	return cfg
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveTimeZone(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    *time.Location
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"UTC", time.UTC, false},
+		{"Local", time.Local, false},
+		{"Mars/Olympus_Mons", nil, true},
+	}
+	for _, tt := range tests {
+		loc, err := resolveTimeZone(tt.name)
+		if (err != nil) != tt.wantErr || loc != tt.want {
+			t.Errorf("resolveTimeZone(%q) = %v, %v", tt.name, loc, err)
+		}
+	}
+	if loc, err := resolveTimeZone("Asia/Tokyo"); err != nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("resolveTimeZone(Asia/Tokyo) = %v, %v", loc, err)
+	}
+}
+
+func TestTimeZoneSinks(t *testing.T) {
+	withoutColor(t)
+	stdout, _ := redirectStd(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := NewLogger(Config{
+		Level:           "info",
+		EnableFile:      true,
+		FilePath:        path,
+		TimeZone:        "Asia/Tokyo",
+		ConsoleTimeZone: "UTC",
+		Clock:           fixedClock(time.Date(2024, 3, 10, 12, 30, 45, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("zoned")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The zoned file switches to RFC 3339 so the offset is kept
+	if line := string(readOnlyLine(t, path)); !strings.Contains(line, `"time":"2024-03-10T21:30:45+09:00"`) {
+		t.Errorf("file entry %s, want Tokyo time", line)
+	}
+	console, err := os.ReadFile(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(console), "2024-03-10 12:30:45") {
+		t.Errorf("console %q, want UTC time", console)
+	}
+}
+
+func TestTimeZoneInvalid(t *testing.T) {
+	for _, config := range []Config{{TimeZone: "Nowhere/City"}, {ConsoleTimeZone: "Nowhere/City"}} {
+		if _, err := NewLogger(config); err == nil {
+			t.Errorf("NewLogger accepted %+v", config)
+		}
+	}
+}
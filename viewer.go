@@ -0,0 +1,485 @@
+package logger
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// viewerDefaultLimit is how many of the latest matching entries the
+	// viewer returns by default, and viewerMaxLimit the most it returns
+	viewerDefaultLimit = 500
+	viewerMaxLimit     = 5000
+	// viewerMaxFacetValues drops a field from the facets once it has more
+	// distinct values than this, such as request IDs
+	viewerMaxFacetValues = 20
+)
+
+// ViewerOption configures ViewerHandler
+type ViewerOption func(*viewer)
+
+// WithViewerFiles adds JSON log files the viewer can show besides the
+// logger's own file sink
+func WithViewerFiles(paths ...string) ViewerOption {
+	return func(v *viewer) {
+		for _, path := range paths {
+			v.extra = append(v.extra, viewerSource{Name: path, path: path, format: FileFormatJSON})
+		}
+	}
+}
+
+// viewer serves the log viewer's API
+type viewer struct {
+	l     *Logger
+	extra []viewerSource
+}
+
+// viewerRecentSource names the logger's recent entries among the sources
+const viewerRecentSource = "recent entries (memory)"
+
+// viewerSource is a log file the viewer can read, or the logger's recent
+// entries when path is empty
+type viewerSource struct {
+	Name   string `json:"name"`
+	path   string
+	format string
+}
+
+// viewerEntry is an entry as returned to the viewer
+type viewerEntry struct {
+	Time    string         `json:"time,omitempty"`
+	Level   string         `json:"level"`
+	Logger  string         `json:"logger,omitempty"`
+	Caller  string         `json:"caller,omitempty"`
+	Message string         `json:"msg"`
+	Stack   string         `json:"stack,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// viewerFacet counts the values of one field among the returned entries
+type viewerFacet struct {
+	Key    string             `json:"key"`
+	Values []viewerFacetValue `json:"values"`
+}
+
+// viewerFacetValue is one value of a facet
+type viewerFacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ViewerHandler returns an http.Handler serving a minimal single-page log
+// viewer, so developers can inspect a running service's logs without shell
+// access. It shows the logger's file sink, any files added with
+// WithViewerFiles, and the in-memory entries of Logger.Recent, filtered
+// by level, text, and field values, with the most common values of each
+// field listed as facets. The page itself is
+// public; the entries it loads require the same bearer token as
+// AdminHandler, which the page asks for. Mount it under a prefix ending in
+// a slash with http.StripPrefix. Routes:
+//
+//	GET /          the viewer page
+//	GET /sources   the files and buffers that can be viewed
+//	GET /entries   the latest matching entries of ?source= (default the
+//	               first), filtered by ?level=, ?q= (case-insensitive
+//	               text), and ?field=key=value (repeatable), up to ?limit=
+func (l *Logger) ViewerHandler(tokens CredentialProvider, opts ...ViewerOption) http.Handler {
+	v := &viewer{l: l}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	api := http.NewServeMux()
+	api.HandleFunc("GET /sources", func(w http.ResponseWriter, _ *http.Request) {
+		writeAdminJSON(w, http.StatusOK, v.sources())
+	})
+	api.HandleFunc("GET /entries", v.serveEntries)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && (r.URL.Path == "/" || r.URL.Path == "") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+			_, _ = w.Write([]byte(viewerPage))
+			return
+		}
+		if !adminAuthorized(r, tokens) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="logger"`)
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+}
+
+// sources lists the viewable files, the logger's file sink first, then
+// the recent entries
+func (v *viewer) sources() []viewerSource {
+	var sources []viewerSource
+	p := v.l.state.pipe.Load()
+	if c := p.config; c.EnableFile && c.FilePath != "" {
+		sources = append(sources, viewerSource{
+			Name:   c.FilePath,
+			path:   c.FilePath,
+			format: cmp.Or(c.FileFormat, FileFormatJSON),
+		})
+	}
+	sources = append(sources, v.extra...)
+	if p.res.recent != nil {
+		sources = append(sources, viewerSource{Name: viewerRecentSource})
+	}
+	return sources
+}
+
+// serveEntries answers GET /entries
+func (v *viewer) serveEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sources := v.sources()
+	if len(sources) == 0 {
+		writeAdminError(w, http.StatusNotFound, "no log files to view")
+		return
+	}
+	source := sources[0]
+	if name := q.Get("source"); name != "" {
+		i := slices.IndexFunc(sources, func(s viewerSource) bool { return s.Name == name })
+		if i < 0 {
+			writeAdminError(w, http.StatusNotFound, "unknown source")
+			return
+		}
+		source = sources[i]
+	}
+
+	filter, err := parseEntryFilter(q, TraceLevel)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := viewerDefaultLimit
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeAdminError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, viewerMaxLimit)
+	}
+
+	var entries []viewerEntry
+	if source.path == "" {
+		entries = v.recentEntries(filter, limit)
+	} else {
+		entries, err = readViewerEntries(r.Context(), source, filter, limit)
+	}
+	if err != nil {
+		writeAdminError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]any{
+		"source":  source.Name,
+		"entries": entries,
+		"facets":  viewerFacets(entries),
+	})
+}
+
+// readViewerEntries returns the last limit entries of source that pass the
+// filters, oldest first
+func readViewerEntries(ctx context.Context, source viewerSource, filter entryFilter, limit int) ([]viewerEntry, error) {
+	r, err := OpenLogFile(source.path, WithReadFormat(source.format), WithMinLevel(filter.minLevel))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entries := make([]viewerEntry, 0, limit)
+	next := 0
+	for entry, err := range r.Entries(ctx) {
+		if err != nil {
+			continue
+		}
+		if !filter.match(entry) {
+			continue
+		}
+		e := newViewerEntry(entry)
+		// Keep the latest limit entries in a ring
+		if len(entries) < limit {
+			entries = append(entries, e)
+		} else {
+			entries[next] = e
+		}
+		next = (next + 1) % limit
+	}
+	if len(entries) == limit {
+		entries = slices.Concat(entries[next:], entries[:next])
+	}
+	return entries, ctx.Err()
+}
+
+// recentEntries returns the last limit of the logger's recent entries that
+// pass filter, oldest first
+func (v *viewer) recentEntries(filter entryFilter, limit int) []viewerEntry {
+	var entries []viewerEntry
+	for _, e := range v.l.Recent(0) {
+		if filter.match(e) {
+			entries = append(entries, newViewerEntry(e))
+		}
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+// newViewerEntry converts entry to the form returned to the viewer
+func newViewerEntry(entry LogEntry) viewerEntry {
+	e := viewerEntry{
+		Level:   levelName(entry.Level),
+		Logger:  entry.Logger,
+		Caller:  entry.Caller,
+		Message: entry.Message,
+		Stack:   entry.Stack,
+		Fields:  entry.Fields,
+	}
+	if !entry.Time.IsZero() {
+		e.Time = entry.Time.Format("2006-01-02 15:04:05.000")
+	}
+	return e
+}
+
+// entryFilter selects entries by level, text, and field values, as given
+// to the viewer and stream handlers
+type entryFilter struct {
+	minLevel zapcore.Level
+	text     string
+	fields   [][2]string
+}
+
+// parseEntryFilter reads ?level=, ?q=, and ?field=key=value (repeatable),
+// defaulting the level to level
+func parseEntryFilter(q url.Values, level zapcore.Level) (entryFilter, error) {
+	f := entryFilter{minLevel: level, text: strings.ToLower(q.Get("q"))}
+	if s := q.Get("level"); s != "" {
+		lvl, err := parseLevel(s)
+		if err != nil {
+			return entryFilter{}, fmt.Errorf("invalid level: %w", err)
+		}
+		f.minLevel = lvl
+	}
+	for _, field := range q["field"] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return entryFilter{}, errors.New("invalid field filter, want key=value")
+		}
+		f.fields = append(f.fields, [2]string{key, value})
+	}
+	return f, nil
+}
+
+// match reports whether entry is at or above the level, contains the text,
+// and has every field value
+func (f entryFilter) match(entry LogEntry) bool {
+	if entry.Level < f.minLevel {
+		return false
+	}
+	for _, field := range f.fields {
+		v, ok := entry.Fields[field[0]]
+		if !ok || viewerValue(v) != field[1] {
+			return false
+		}
+	}
+	if f.text == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(entry.Message), f.text) ||
+		strings.Contains(strings.ToLower(entry.Logger), f.text) ||
+		strings.Contains(strings.ToLower(entry.Caller), f.text) {
+		return true
+	}
+	for k, v := range entry.Fields {
+		if strings.Contains(strings.ToLower(k+"="+viewerValue(v)), f.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// viewerValue renders a field value as the viewer shows and filters it
+func viewerValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	}
+	if raw, err := json.Marshal(v); err == nil {
+		return string(raw)
+	}
+	return fmt.Sprint(v)
+}
+
+// viewerFacets counts the values of each scalar field in entries, leaving
+// out fields with too many distinct values to be useful filters
+func viewerFacets(entries []viewerEntry) []viewerFacet {
+	counts := make(map[string]map[string]int)
+	for _, e := range entries {
+		for k, v := range e.Fields {
+			switch v.(type) {
+			case map[string]any, []any:
+				continue
+			}
+			values := counts[k]
+			if values == nil {
+				values = make(map[string]int)
+				counts[k] = values
+			}
+			if len(values) <= viewerMaxFacetValues {
+				values[viewerValue(v)]++
+			}
+		}
+	}
+
+	var facets []viewerFacet
+	for key, values := range counts {
+		if len(values) > viewerMaxFacetValues {
+			continue
+		}
+		f := viewerFacet{Key: key}
+		for value, n := range values {
+			f.Values = append(f.Values, viewerFacetValue{Value: value, Count: n})
+		}
+		slices.SortFunc(f.Values, func(a, b viewerFacetValue) int {
+			return cmp.Or(b.Count-a.Count, strings.Compare(a.Value, b.Value))
+		})
+		facets = append(facets, f)
+	}
+	slices.SortFunc(facets, func(a, b viewerFacet) int { return strings.Compare(a.Key, b.Key) })
+	return facets
+}
+
+// viewerPage is the viewer's single page. Log content is only ever inserted
+// as text, never as markup.
+const viewerPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Logs</title>
+<style>
+body { font: 13px system-ui, sans-serif; margin: 0; display: flex; flex-direction: column; height: 100vh; }
+header { display: flex; gap: 8px; padding: 8px; background: #f3f3f3; border-bottom: 1px solid #ddd; }
+header input[type=search] { flex: 1; }
+main { display: flex; flex: 1; min-height: 0; }
+aside { width: 240px; overflow: auto; padding: 8px; border-right: 1px solid #ddd; }
+aside h4 { margin: 8px 0 4px; }
+aside a { display: block; cursor: pointer; color: #236; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+#filters span { display: inline-block; background: #dde; padding: 1px 6px; margin: 2px; border-radius: 3px; cursor: pointer; }
+#entries { flex: 1; overflow: auto; font-family: ui-monospace, monospace; }
+.entry { padding: 2px 8px; border-bottom: 1px solid #eee; white-space: pre-wrap; cursor: pointer; }
+.entry .fields, .entry .stack { display: none; color: #555; padding-left: 16px; }
+.entry.open .fields, .entry.open .stack { display: block; }
+.debug, .trace { color: #888; } .warn { color: #a60; } .error, .dpanic, .panic, .fatal { color: #c00; }
+#status { padding: 4px 8px; color: #666; }
+</style>
+</head>
+<body>
+<header>
+<select id="source"></select>
+<select id="level">
+<option value="">all levels</option><option>debug</option><option>info</option>
+<option>warn</option><option>error</option>
+</select>
+<input id="q" type="search" placeholder="Search">
+<input id="token" type="password" placeholder="Token">
+<button id="refresh">Refresh</button>
+</header>
+<main>
+<aside><div id="filters"></div><div id="facets"></div></aside>
+<div id="entries"></div>
+</main>
+<div id="status"></div>
+<script>
+const $ = id => document.getElementById(id);
+const filters = [];
+$("token").value = sessionStorage.getItem("logger-token") || "";
+
+function el(tag, cls, text) {
+  const e = document.createElement(tag);
+  if (cls) e.className = cls;
+  if (text !== undefined) e.textContent = text;
+  return e;
+}
+
+async function get(path) {
+  sessionStorage.setItem("logger-token", $("token").value);
+  const resp = await fetch(path, { headers: { Authorization: "Bearer " + $("token").value } });
+  const body = await resp.json();
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+async function loadSources() {
+  const sources = await get("sources");
+  $("source").replaceChildren(...sources.map(s => el("option", "", s.name)));
+}
+
+async function load() {
+  const params = new URLSearchParams();
+  if ($("source").value) params.set("source", $("source").value);
+  if ($("level").value) params.set("level", $("level").value);
+  if ($("q").value) params.set("q", $("q").value);
+  filters.forEach(f => params.append("field", f));
+  $("status").textContent = "Loading…";
+  try {
+    if (!$("source").options.length) await loadSources();
+    const data = await get("entries?" + params);
+    render(data);
+    $("status").textContent = data.entries.length + " entries from " + data.source;
+  } catch (err) {
+    $("status").textContent = err.message;
+  }
+}
+
+function render(data) {
+  $("entries").replaceChildren(...data.entries.map(e => {
+    const row = el("div", "entry " + e.level);
+    row.append((e.time ? e.time + " " : "") + e.level.toUpperCase() + " " +
+      (e.logger ? e.logger + " " : "") + e.msg);
+    if (e.fields) row.append(el("div", "fields", JSON.stringify(e.fields, null, 2)));
+    if (e.stack) row.append(el("div", "stack", e.stack));
+    row.onclick = () => row.classList.toggle("open");
+    return row;
+  }));
+  $("entries").scrollTop = $("entries").scrollHeight;
+  $("filters").replaceChildren(...filters.map((f, i) => {
+    const chip = el("span", "", f + " ×");
+    chip.onclick = () => { filters.splice(i, 1); load(); };
+    return chip;
+  }));
+  $("facets").replaceChildren(...data.facets.flatMap(f => [
+    el("h4", "", f.key),
+    ...f.values.map(v => {
+      const a = el("a", "", v.value + " (" + v.count + ")");
+      a.onclick = () => { filters.push(f.key + "=" + v.value); load(); };
+      return a;
+    }),
+  ]));
+}
+
+$("refresh").onclick = load;
+$("level").onchange = load;
+$("source").onchange = load;
+$("q").onkeydown = e => { if (e.key === "Enter") load(); };
+if ($("token").value) load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestViewerHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{Level: "info", EnableFile: true, FilePath: path, RecentEntries: 10})
+	l.Info("request handled", zap.String("region", "eu"), zap.Int("status", 200))
+	l.Warn("request slow", zap.String("region", "us"), zap.Int("status", 200))
+	l.Error("request failed", zap.String("region", "eu"), zap.Int("status", 500))
+	if err := syncError(l.Sync()); err != nil {
+		t.Fatal(err)
+	}
+	h := l.ViewerHandler(StaticCredentials(Credentials{Token: "secret"}))
+
+	get := func(target string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if auth {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/", false); rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("page: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec := get("/sources", false); rec.Code != http.StatusUnauthorized {
+		t.Errorf("sources without a token: status %d, want 401", rec.Code)
+	}
+	var sources []viewerSource
+	json.Unmarshal(get("/sources", true).Body.Bytes(), &sources)
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Name)
+	}
+	if !slices.Equal(names, []string{path, viewerRecentSource}) {
+		t.Errorf("sources = %q", names)
+	}
+
+	tests := []struct {
+		name   string
+		query  url.Values
+		status int
+		want   []string
+	}{
+		{"all", nil, http.StatusOK, []string{"request handled", "request slow", "request failed"}},
+		{"level", url.Values{"level": {"warn"}}, http.StatusOK, []string{"request slow", "request failed"}},
+		{"text", url.Values{"q": {"SLOW"}}, http.StatusOK, []string{"request slow"}},
+		{"field", url.Values{"field": {"region=eu", "status=200"}}, http.StatusOK, []string{"request handled"}},
+		{"limit", url.Values{"limit": {"2"}}, http.StatusOK, []string{"request slow", "request failed"}},
+		{"recent", url.Values{"source": {viewerRecentSource}, "level": {"error"}}, http.StatusOK, []string{"request failed"}},
+		{"invalid level", url.Values{"level": {"loud"}}, http.StatusBadRequest, nil},
+		{"invalid field", url.Values{"field": {"region"}}, http.StatusBadRequest, nil},
+		{"invalid limit", url.Values{"limit": {"0"}}, http.StatusBadRequest, nil},
+		{"unknown source", url.Values{"source": {"/etc/passwd"}}, http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get("/entries?"+tt.query.Encode(), true)
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Entries []viewerEntry
+				Facets  []viewerFacet
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range resp.Entries {
+				got = append(got, e.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewerFacets(t *testing.T) {
+	entries := []viewerEntry{
+		{Fields: map[string]any{"region": "eu", "nested": map[string]any{"a": 1.0}}},
+		{Fields: map[string]any{"region": "eu"}},
+		{Fields: map[string]any{"region": "us"}},
+	}
+	for i := range viewerMaxFacetValues + 1 {
+		entries = append(entries, viewerEntry{Fields: map[string]any{"request_id": float64(i)}})
+	}
+	facets := viewerFacets(entries)
+	if len(facets) != 1 || facets[0].Key != "region" {
+		t.Fatalf("facets = %+v, want only region", facets)
+	}
+	want := []viewerFacetValue{{Value: "eu", Count: 2}, {Value: "us", Count: 1}}
+	if !slices.Equal(facets[0].Values, want) {
+		t.Errorf("region values = %+v, want %+v", facets[0].Values, want)
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxVolumeKeys bounds the sink, logger, and level combinations tracked;
+// entries from loggers beyond it are counted under volumeOtherLogger
+const maxVolumeKeys = 10000
+
+// volumeOtherLogger collects the volume of loggers beyond maxVolumeKeys
+const volumeOtherLogger = "(other)"
+
+// VolumeStat is the volume one logger wrote to one sink at one level
+type VolumeStat struct {
+	// Sink is "console", "file", or "network"
+	Sink   string
+	Logger string
+	Level  string
+	// Entries and Bytes count the entries written and their encoded size
+	Entries uint64
+	Bytes   uint64
+}
+
+// volumeKey identifies a volume counter
+type volumeKey struct {
+	sink   string
+	logger string
+	level  zapcore.Level
+}
+
+// volumeCount accumulates the volume of one key
+type volumeCount struct {
+	entries atomic.Uint64
+	bytes   atomic.Uint64
+}
+
+// volumeTracker counts the encoded bytes written per sink, logger, and
+// level for Config.TrackVolume
+type volumeTracker struct {
+	counts sync.Map // volumeKey -> *volumeCount
+	keys   atomic.Int64
+}
+
+// add records an entry of n bytes written to sink
+func (t *volumeTracker) add(sink string, ent zapcore.Entry, n int) {
+	key := volumeKey{sink: sink, logger: ent.LoggerName, level: ent.Level}
+	v, ok := t.counts.Load(key)
+	if !ok {
+		if t.keys.Load() >= maxVolumeKeys {
+			key.logger = volumeOtherLogger
+		}
+		var loaded bool
+		v, loaded = t.counts.LoadOrStore(key, &volumeCount{})
+		if !loaded {
+			t.keys.Add(1)
+		}
+	}
+	c := v.(*volumeCount)
+	c.entries.Add(1)
+	c.bytes.Add(uint64(n))
+}
+
+// snapshot returns the counters, largest volume first
+func (t *volumeTracker) snapshot() []VolumeStat {
+	var stats []VolumeStat
+	t.counts.Range(func(k, v any) bool {
+		key, c := k.(volumeKey), v.(*volumeCount)
+		stats = append(stats, VolumeStat{
+			Sink:    key.sink,
+			Logger:  key.logger,
+			Level:   levelName(key.level),
+			Entries: c.entries.Load(),
+			Bytes:   c.bytes.Load(),
+		})
+		return true
+	})
+	slices.SortFunc(stats, func(a, b VolumeStat) int {
+		return cmp.Or(
+			cmp.Compare(b.Bytes, a.Bytes),
+			cmp.Compare(a.Sink, b.Sink),
+			cmp.Compare(a.Logger, b.Logger),
+			cmp.Compare(a.Level, b.Level),
+		)
+	})
+	return stats
+}
+
+// trackVolume makes a sink core created by newSinkCore record the size of
+// the entries it writes under sink
+func trackVolume(core zapcore.Core, t *volumeTracker, sink string) zapcore.Core {
+	if sc, ok := core.(*sinkCore); ok && t != nil {
+		sc.volume, sc.volumeSink = t, sink
+	}
+	return core
+}
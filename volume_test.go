@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestVolume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newBenchLogger(t, Config{TrackVolume: true, EnableFile: true, FilePath: path})
+	db := l.Named("db")
+	for range 3 {
+		db.Info("query")
+	}
+	l.Warn("slow")
+	if err := syncError(l.Sync()); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := l.Stats().Volume
+	counts := map[volumeKey]VolumeStat{}
+	for _, s := range stats {
+		level, _ := parseLevel(s.Level)
+		counts[volumeKey{s.Sink, s.Logger, level}] = s
+	}
+	tests := []struct {
+		key     volumeKey
+		entries uint64
+	}{
+		{volumeKey{"console", "db", zapcore.InfoLevel}, 3},
+		{volumeKey{"file", "db", zapcore.InfoLevel}, 3},
+		{volumeKey{"file", "", zapcore.WarnLevel}, 1},
+	}
+	for _, tt := range tests {
+		if got := counts[tt.key]; got.Entries != tt.entries || got.Bytes == 0 {
+			t.Errorf("%+v volume = %+v, want %d entries", tt.key, got, tt.entries)
+		}
+	}
+	for i := 1; i < len(stats); i++ {
+		if stats[i].Bytes > stats[i-1].Bytes {
+			t.Errorf("volume not sorted largest first: %+v", stats)
+		}
+	}
+
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fileBytes uint64
+	for _, s := range stats {
+		if s.Sink == "file" {
+			fileBytes += s.Bytes
+		}
+	}
+	if fileBytes != uint64(info.Size()) {
+		t.Errorf("file volume = %d bytes, file holds %d", fileBytes, info.Size())
+	}
+}
+
+func TestVolumeKeyLimit(t *testing.T) {
+	var tracker volumeTracker
+	tracker.keys.Store(maxVolumeKeys)
+	tracker.add("file", zapcore.Entry{LoggerName: "new", Level: zapcore.InfoLevel}, 10)
+	stats := tracker.snapshot()
+	if len(stats) != 1 || stats[0].Logger != volumeOtherLogger || stats[0].Bytes != 10 {
+		t.Errorf("volume past the key limit = %+v", stats)
+	}
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy decides which entries a full buffer discards
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest buffered entry to make room
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the incoming entry
+	DropNewest
+)
+
+// defaultWarmupCapacity is the number of entries a WarmupBuffer holds by default
+const defaultWarmupCapacity = 1000
+
+// WarmupConfig configures the WarmupBuffer of NetworkConfig.Warmup
+type WarmupConfig struct {
+	// Capacity is the number of entries held. Defaults to 1000.
+	Capacity int
+	// Policy decides which entries are dropped beyond Capacity
+	Policy OverflowPolicy
+}
+
+// WarmupBuffer is a WriteSyncer that holds entries written before its
+// destination is ready, such as a remote sink that is still connecting, and
+// replays them in order once Ready is called
+type WarmupBuffer struct {
+	dest     zapcore.WriteSyncer
+	capacity int
+	policy   OverflowPolicy
+
+	mu      sync.Mutex
+	ready   bool
+	pending [][]byte
+	dropped uint64
+}
+
+// NewWarmupBuffer buffers up to capacity entries for dest until Ready is called
+func NewWarmupBuffer(dest zapcore.WriteSyncer, capacity int, policy OverflowPolicy) *WarmupBuffer {
+	if capacity <= 0 {
+		capacity = defaultWarmupCapacity
+	}
+	return &WarmupBuffer{dest: dest, capacity: capacity, policy: policy}
+}
+
+// Write buffers p until the destination is ready, then writes through
+func (w *WarmupBuffer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ready {
+		return w.dest.Write(p)
+	}
+
+	if len(w.pending) >= w.capacity {
+		w.dropped++
+		if w.policy == DropNewest {
+			return len(p), nil
+		}
+		w.pending = w.pending[1:]
+	}
+	// The encoder reuses p after Write returns
+	w.pending = append(w.pending, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// Sync flushes the destination once it is ready
+func (w *WarmupBuffer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.ready {
+		return nil
+	}
+	return w.dest.Sync()
+}
+
+// Ready replays buffered entries to the destination and switches to writing
+// through. It returns the first replay error, if any.
+func (w *WarmupBuffer) Ready() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ready {
+		return nil
+	}
+	w.ready = true
+
+	var firstErr error
+	for _, p := range w.pending {
+		if _, err := w.dest.Write(p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.pending = nil
+	return firstErr
+}
+
+// Dropped returns the number of entries discarded because the buffer was full
+func (w *WarmupBuffer) Dropped() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// buffered returns the number of entries held until Ready
+func (w *WarmupBuffer) buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// discard drops the held entries, returning how many there were
+func (w *WarmupBuffer) discard() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(w.pending)
+	w.pending = nil
+	w.dropped += uint64(n)
+	return n
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNetworkWarmup(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		want   []string
+	}{
+		{name: "drop newest", policy: DropNewest, want: []string{"first", "second", "connected"}},
+		{name: "drop oldest", policy: DropOldest, want: []string{"second", "third", "connected"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			addr := ln.Addr().String()
+			ln.Close()
+
+			l := newBenchLogger(t, Config{
+				Level: "info",
+				Network: &NetworkConfig{
+					Protocol:   "tcp",
+					Address:    addr,
+					MaxBackoff: 5 * time.Millisecond,
+					Warmup:     &WarmupConfig{Capacity: 2, Policy: tt.policy},
+				},
+			})
+			for _, msg := range []string{"first", "second", "third"} {
+				l.Info(msg)
+			}
+			if ns := l.Stats().Network; ns.Buffered != 2 || ns.Dropped != 1 {
+				t.Errorf("before connecting: buffered %d, dropped %d; want 2 and 1", ns.Buffered, ns.Dropped)
+			}
+			network := l.state.pipe.Load().res.network
+			for deadline := time.Now().Add(5 * time.Second); !network.spooling() && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+			}
+			if err := syncError(l.Sync()); err == nil {
+				t.Error("Sync succeeded with entries held for an unreachable collector")
+			}
+
+			ln, err = net.Listen("tcp", addr)
+			if err != nil {
+				t.Skipf("collector address taken: %v", err)
+			}
+			defer ln.Close()
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			// Entries logged before the replay would still be held, or dropped
+			for deadline := time.Now().Add(5 * time.Second); network.warmup.buffered() > 0 && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+			}
+			l.Info("connected")
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			var got []string
+			sc := bufio.NewScanner(conn)
+			for len(got) < len(tt.want) && sc.Scan() {
+				var entry struct {
+					Msg string `json:"msg"`
+				}
+				if err := json.Unmarshal(sc.Bytes(), &entry); err != nil {
+					t.Fatalf("collector received %q: %v", sc.Bytes(), err)
+				}
+				got = append(got, entry.Msg)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("collector received %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
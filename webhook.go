@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// WebhookConfig configures a sink created with NewWebhookCore
+type WebhookConfig struct {
+	// URL receives a POST for each alert, for example a Slack incoming
+	// webhook
+	URL string
+	// Level selects the entries sent. Defaults to dpanic and above.
+	Level zapcore.LevelEnabler
+	// Template renders the request body from a WebhookMessage. Defaults to
+	// a Slack message: {"text": "..."} with the level, message, and fields.
+	Template *template.Template
+	// ContentType is the request's content type. Defaults to
+	// application/json.
+	ContentType string
+	// RateLimit limits alerts per second; entries beyond it are counted in
+	// the next alert's Suppressed. Defaults to one per minute.
+	RateLimit rate.Limit
+	// RateLimitBurst is the number of alerts allowed at once. Defaults to 1.
+	RateLimitBurst int
+	// Credentials authenticate the requests, if set
+	Credentials CredentialProvider
+	// Timeout bounds each request. Defaults to 5 seconds.
+	Timeout time.Duration
+	// Client sends the requests, overriding Timeout
+	Client *http.Client
+}
+
+// WebhookMessage is the data a WebhookConfig.Template renders
+type WebhookMessage struct {
+	Level   string
+	Time    time.Time
+	Logger  string
+	Caller  string
+	Message string
+	Stack   string
+	Fields  map[string]any
+	// Severity is the level in other systems' terms, such as
+	// {{.Severity.Syslog}} or {{.Severity.GCP}}
+	Severity LevelMapping
+	// Suppressed counts the alerts dropped by the rate limit since the
+	// previous one
+	Suppressed int
+}
+
+// FieldsText returns the fields as key=value pairs in key order, one per
+// line
+func (m WebhookMessage) FieldsText() string {
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(m.Fields)) {
+		v := m.Fields[k]
+		if _, ok := v.(string); !ok {
+			if raw, err := json.Marshal(v); err == nil {
+				v = string(raw)
+			}
+		}
+		fmt.Fprintf(&b, "%s=%v\n", k, v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// defaultWebhookTemplate renders a Slack message
+var defaultWebhookTemplate = template.Must(template.New("slack").Funcs(template.FuncMap{
+	"json": func(s string) (string, error) {
+		raw, err := json.Marshal(s)
+		return string(raw), err
+	},
+}).Parse(`{"text": {{json (printf "*%s* %s%s%s%s" .Level .Message
+	(or (and .Logger (printf " (%s)" .Logger)) "")
+	(or (and .Fields (printf "\n` + "```" + `%s` + "```" + `" .FieldsText)) "")
+	(or (and .Suppressed (printf "\n_%d more alerts suppressed_" .Suppressed)) ""))}}}`))
+
+// webhookCore sends selected entries to a webhook
+type webhookCore struct {
+	zapcore.LevelEnabler
+	config *WebhookConfig
+	state  *webhookState
+	ctx    mapEncoder
+}
+
+// webhookState is shared by a webhook core and its children
+type webhookState struct {
+	sender  *httpSender
+	limiter *rate.Limiter
+
+	mu         sync.Mutex
+	suppressed int
+}
+
+// NewWebhookCore returns a core that posts selected entries, by default
+// panic and fatal ones, to a webhook such as Slack's, rate limited so a
+// crash loop doesn't flood the channel. Add it with Logger.AddSink.
+// Requests are sent in the background; Sync, which Fatal calls before
+// exiting, waits for them.
+func NewWebhookCore(config WebhookConfig) (zapcore.Core, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	if config.Level == nil {
+		config.Level = zapcore.DPanicLevel
+	}
+	if config.Template == nil {
+		config.Template = defaultWebhookTemplate
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = rate.Every(time.Minute)
+	}
+	if config.RateLimitBurst <= 0 {
+		config.RateLimitBurst = 1
+	}
+	return &webhookCore{
+		LevelEnabler: config.Level,
+		config:       &config,
+		state: &webhookState{
+			sender:  newHTTPSender("webhook", config.Client, config.Timeout, config.Credentials),
+			limiter: rate.NewLimiter(config.RateLimit, config.RateLimitBurst),
+		},
+		ctx: newMapEncoder(),
+	}, nil
+}
+
+// With returns a child core whose alerts carry fields
+func (c *webhookCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.ctx = c.ctx.withFields(fields)
+	return &clone
+}
+
+// Check adds this core to the checked entry if the level is selected
+func (c *webhookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write renders and queues an alert unless the rate limit is exceeded.
+// Panic and fatal alerts are sent before Write returns, since the process
+// may end right after.
+func (c *webhookCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	s := c.state
+	s.mu.Lock()
+	if !s.limiter.Allow() {
+		s.suppressed++
+		s.mu.Unlock()
+		return nil
+	}
+	suppressed := s.suppressed
+	s.suppressed = 0
+	s.mu.Unlock()
+
+	p, err := c.payload(ent, fields, suppressed)
+	if err != nil {
+		return err
+	}
+	s.sender.send(p)
+	if ent.Level >= zapcore.PanicLevel {
+		s.sender.sync()
+	}
+	return nil
+}
+
+// payload renders the alert for an entry
+func (c *webhookCore) payload(ent zapcore.Entry, fields []zapcore.Field, suppressed int) (httpPayload, error) {
+	msg := WebhookMessage{
+		Level:      strings.ToUpper(levelName(ent.Level)),
+		Severity:   MapLevel(ent.Level),
+		Time:       ent.Time,
+		Logger:     ent.LoggerName,
+		Message:    ent.Message,
+		Stack:      ent.Stack,
+		Fields:     c.ctx.withFields(fields).Fields,
+		Suppressed: suppressed,
+	}
+	if ent.Caller.Defined {
+		msg.Caller = ent.Caller.TrimmedPath()
+	}
+	var body bytes.Buffer
+	if err := c.config.Template.Execute(&body, msg); err != nil {
+		return httpPayload{}, fmt.Errorf("failed to render webhook message: %w", err)
+	}
+	return httpPayload{
+		url:         c.config.URL,
+		contentType: c.config.ContentType,
+		body:        body.Bytes(),
+	}, nil
+}
+
+// SelfTest sends the entry as an alert and waits for the response,
+// bypassing the rate limit
+func (c *webhookCore) SelfTest(ctx context.Context, ent zapcore.Entry, fields []zapcore.Field) error {
+	p, err := c.payload(ent, fields, 0)
+	if err != nil {
+		return err
+	}
+	return c.state.sender.post(ctx, p)
+}
+
+// sinkName describes the webhook for SelfTest by its host, as its URL
+// often holds a token
+func (c *webhookCore) sinkName() string {
+	if u, err := url.Parse(c.config.URL); err == nil {
+		return "webhook " + u.Host
+	}
+	return "webhook"
+}
+
+// remoteSender returns the sender posting the core's alerts
+func (c *webhookCore) remoteSender() *httpSender {
+	return c.state.sender
+}
+
+// Sync waits for queued alerts to be sent
+func (c *webhookCore) Sync() error {
+	c.state.sender.sync()
+	return nil
+}
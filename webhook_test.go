@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// recordingServer records the bodies and headers of requests it receives
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, body)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// received returns the recorded requests and bodies
+func (s *recordingServer) received() ([]*http.Request, [][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests, s.bodies
+}
+
+func TestWebhookCore(t *testing.T) {
+	srv := newRecordingServer(t)
+	core, err := NewWebhookCore(WebhookConfig{URL: srv.URL, RateLimit: rate.Every(50 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if core.Enabled(zapcore.ErrorLevel) || !core.Enabled(zapcore.DPanicLevel) {
+		t.Error("webhook doesn't default to dpanic and above")
+	}
+	core = core.With([]zapcore.Field{zap.String("service", "api")})
+
+	ent := zapcore.Entry{Level: zapcore.DPanicLevel, Message: "invariant broken", LoggerName: "db"}
+	for range 3 {
+		core.Write(ent, []zapcore.Field{zap.Int("shard", 3)})
+	}
+	time.Sleep(60 * time.Millisecond)
+	core.Write(ent, nil)
+	core.Sync()
+
+	requests, bodies := srv.received()
+	if len(requests) != 2 {
+		t.Fatalf("sent %d alerts, want 2", len(requests))
+	}
+	if ct := requests[0].Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type = %q", ct)
+	}
+	var texts []string
+	for _, body := range bodies {
+		var msg struct{ Text string }
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("alert %s is not JSON: %v", body, err)
+		}
+		texts = append(texts, msg.Text)
+	}
+	if want := "*DPANIC* invariant broken (db)\n```service=api\nshard=3```"; texts[0] != want {
+		t.Errorf("first alert = %q, want %q", texts[0], want)
+	}
+	if !strings.HasSuffix(texts[1], "\n_2 more alerts suppressed_") {
+		t.Errorf("second alert = %q, want the suppressed count", texts[1])
+	}
+}
+
+func TestWebhookCoreRequiresURL(t *testing.T) {
+	if _, err := NewWebhookCore(WebhookConfig{}); err == nil {
+		t.Error("NewWebhookCore accepted an empty URL")
+	}
+}
+
+func TestSentryCore(t *testing.T) {
+	srv := newRecordingServer(t)
+	core, err := NewSentryCore(SentryConfig{DSN: strings.Replace(srv.URL, "://", "://key1@", 1) + "/sub/42", Environment: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent := zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Message: "charge failed",
+		Stack:   "main.charge\n\t/src/pay.go:12\nmain.main\n\t/src/main.go:7",
+	}
+	core.Write(ent, []zapcore.Field{zap.String("error", "card declined")})
+	core.Sync()
+
+	requests, bodies := srv.received()
+	if len(requests) != 1 {
+		t.Fatalf("sent %d events, want 1", len(requests))
+	}
+	if got := requests[0].URL.Path; got != "/sub/api/42/envelope/" {
+		t.Errorf("endpoint path = %q", got)
+	}
+	if got := requests[0].Header.Get("X-Sentry-Auth"); !strings.Contains(got, "sentry_key=key1") {
+		t.Errorf("auth header = %q", got)
+	}
+	lines := bytes.Split(bytes.TrimSpace(bodies[0]), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3: %s", len(lines), bodies[0])
+	}
+	var event struct {
+		Level       string
+		Environment string
+		Extra       map[string]any
+		Exception   struct {
+			Values []struct {
+				Value      string
+				Stacktrace struct {
+					Frames []struct {
+						Function string
+						Filename string
+						Lineno   int
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(lines[2], &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Level != "error" || event.Environment != "prod" || event.Extra["error"] != "card declined" {
+		t.Errorf("event = %+v", event)
+	}
+	frames := event.Exception.Values[0].Stacktrace.Frames
+	if event.Exception.Values[0].Value != "card declined" || len(frames) != 2 ||
+		frames[0].Function != "main.main" || frames[1].Filename != "pay.go" || frames[1].Lineno != 12 {
+		t.Errorf("exception = %+v, want frames oldest first", event.Exception)
+	}
+}
+
+func TestSentryCoreInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"", "https://sentry.io/42", "https://key@sentry.io/", "::"} {
+		if _, err := NewSentryCore(SentryConfig{DSN: dsn}); err == nil {
+			t.Errorf("NewSentryCore accepted DSN %q", dsn)
+		}
+	}
+}